@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -15,6 +18,7 @@ import (
 	indexerPackage "github.com/DefiantLabs/cosmos-indexer/indexer"
 	"github.com/DefiantLabs/cosmos-indexer/probe"
 	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	"github.com/DefiantLabs/probe/client"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +32,7 @@ func init() {
 	config.SetupLogFlags(&indexer.Config.Log, indexCmd)
 	config.SetupDatabaseFlags(&indexer.Config.Database, indexCmd)
 	config.SetupProbeFlags(&indexer.Config.Probe, indexCmd)
+	config.SetupSinkFlags(&indexer.Config.Sink, indexCmd)
 	config.SetupThrottlingFlag(&indexer.Config.Base.Throttling, indexCmd)
 	config.SetupIndexSpecificFlags(indexer.Config, indexCmd)
 
@@ -43,12 +48,14 @@ var indexCmd = &cobra.Command{
 	or in the specified config file. Indexes taxable events into a database for easy querying. It is
 	highly recommended to keep this command running as a background service to keep your index up to date.`,
 	PreRunE: setupIndex,
-	Run:     index,
+	RunE:    index,
 }
 
 func HelpOverride(cmd *cobra.Command, args []string) {
 	oldHelpCommand(indexCmd, nil)
-	safeCleanupSetupExit(&indexer)
+	if err := safeCleanupSetupExit(&indexer, nil); err != nil {
+		config.Log.Error("Failed to clean up after printing help", err)
+	}
 }
 
 // GetBuiltinIndexer returns the indexer instance for the index command. Usable for customizing pre-run setup.
@@ -60,9 +67,15 @@ func GetBuiltinIndexer() *indexerPackage.Indexer {
 	return &indexer
 }
 
-func safeCleanupSetupExit(indexer *indexerPackage.Indexer) {
+// safeCleanupSetupExit releases what the setup acquired and runs the pre-exit custom function. It returns the setup error, joined
+// with the error of the pre-exit custom function if it fails.
+func safeCleanupSetupExit(indexer *indexerPackage.Indexer, setupErr error) error {
 	close(indexer.PostSetupDatasetChannel)
 
+	if indexer.LeaderElector != nil {
+		indexer.LeaderElector.Release()
+	}
+
 	if indexer.PreExitCustomFunction != nil {
 		err := indexer.PreExitCustomFunction(&indexerPackage.PreExitCustomDataset{
 			Config: *indexer.Config,
@@ -70,9 +83,11 @@ func safeCleanupSetupExit(indexer *indexerPackage.Indexer) {
 			DryRun: indexer.DryRun,
 		})
 		if err != nil {
-			config.Log.Fatal("Failed to run pre-exit custom function", err)
+			return errors.Join(setupErr, fmt.Errorf("%w: failed to run pre-exit custom function: %w", indexerPackage.ErrCustomFunctionFailed, err))
 		}
 	}
+
+	return setupErr
 }
 
 // setupIndex loads the configuration from file and command line flags, validates the configuration, and sets up the logger and database connection.
@@ -85,8 +100,7 @@ func setupIndex(cmd *cobra.Command, args []string) error {
 
 	err := indexer.Config.Validate()
 	if err != nil {
-		safeCleanupSetupExit(&indexer)
-		return err
+		return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: %w", indexerPackage.ErrConfigInvalid, err))
 	}
 
 	ignoredKeys := config.CheckSuperfluousIndexKeys(viperConf.AllKeys())
@@ -102,21 +116,39 @@ func setupIndex(cmd *cobra.Command, args []string) error {
 		indexer.Config.Base.StartBlock = 1
 	}
 
-	// If DB has not been preset, connect to the database and migrate using the default configuration settings
+	indexer.TransactionLimiter = dbTypes.NewTransactionLimiter(indexer.Config.Database.MaxConcurrentTxns)
+
+	// If DB has not been preset, connect to the database using the default configuration settings
 	if indexer.DB == nil {
-		db, err := ConnectToDBAndMigrate(indexer.Config.Database)
+		db, err := connectToDB(indexer.Config.Database)
 		if err != nil {
-			safeCleanupSetupExit(&indexer)
-			config.Log.Fatal("Could not establish connection to the database", err)
+			return safeCleanupSetupExit(&indexer, err)
 		}
 
 		indexer.DB = db
-	} else {
-		err = dbTypes.MigrateModels(indexer.DB)
-		if err != nil {
-			safeCleanupSetupExit(&indexer)
-			config.Log.Fatal("Error running DB migrations", err)
+	}
+
+	// In HA mode only the leader indexes, followers wait here before running any migrations and take over once the leader is gone
+	if indexer.Config.Base.HAMode && !indexer.Config.Base.Dry {
+		leaseInterval := time.Duration(indexer.Config.Base.HALeaseInterval) * time.Second
+		elector := dbTypes.NewLeaderElector(indexer.DB, indexer.Config.Probe.ChainID)
+
+		config.Log.Info("HA mode enabled, waiting to become the leader")
+		if err := elector.WaitForLeadership(cmd.Context(), leaseInterval); err != nil {
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: error waiting for HA leadership: %w", indexerPackage.ErrDBUnavailable, err))
 		}
+		config.Log.Info("Acquired HA leadership, starting to index")
+		indexer.LeaderElector = elector
+	}
+
+	err = migrateDB(indexer.DB, indexer.Config.Database, indexer.TxColumns)
+	if err != nil {
+		return safeCleanupSetupExit(&indexer, err)
+	}
+
+	err = dbTypes.UseRedactors(indexer.DB, indexer.Redactors)
+	if err != nil {
+		return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: failed to register redactors: %w", indexerPackage.ErrConfigInvalid, err))
 	}
 
 	indexer.DryRun = indexer.Config.Base.Dry
@@ -129,14 +161,12 @@ func setupIndex(cmd *cobra.Command, args []string) error {
 	if indexer.Config.Base.FilterFile != "" {
 		f, err := os.Open(indexer.Config.Base.FilterFile)
 		if err != nil {
-			safeCleanupSetupExit(&indexer)
-			config.Log.Fatalf("Failed to open block event filter file %s: %s", indexer.Config.Base.FilterFile, err)
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: failed to open block event filter file %s: %w", indexerPackage.ErrConfigInvalid, indexer.Config.Base.FilterFile, err))
 		}
 
 		b, err := io.ReadAll(f)
 		if err != nil {
-			safeCleanupSetupExit(&indexer)
-			config.Log.Fatal("Failed to parse block event filter config", err)
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: failed to read block event filter config: %w", indexerPackage.ErrConfigInvalid, err))
 		}
 
 		var fileMessageTypeFilters []filter.MessageTypeFilter
@@ -148,42 +178,97 @@ func setupIndex(cmd *cobra.Command, args []string) error {
 			fileMessageTypeFilters,
 			err = config.ParseJSONFilterConfig(b)
 		if err != nil {
-			safeCleanupSetupExit(&indexer)
-			config.Log.Fatal("Failed to parse block event filter config", err)
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: failed to parse block event filter config: %w", indexerPackage.ErrConfigInvalid, err))
 		}
 
 		indexer.MessageTypeFilters = append(indexer.MessageTypeFilters, fileMessageTypeFilters...)
 	}
 
+	if indexer.Config.Base.MessageSchemaDir != "" {
+		schemas, err := core.LoadMessageSchemas(indexer.Config.Base.MessageSchemaDir)
+		if err != nil {
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: %w", indexerPackage.ErrConfigInvalid, err))
+		}
+
+		config.Log.Infof("Loaded %d message schemas", len(schemas))
+		indexer.TxLookups.MessageSchemas = schemas
+	}
+
+	if indexer.Config.Base.IndexMessageAction {
+		actions, err := core.LoadMessageActions(indexer.Config.Base.ActionMapFile)
+		if err != nil {
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: %w", indexerPackage.ErrConfigInvalid, err))
+		}
+
+		config.Log.Infof("Loaded %d message action labels", len(actions))
+		indexer.TxLookups.MessageActions = actions
+	}
+
+	if indexer.Config.Base.SenderWhitelistFile != "" {
+		whitelist, err := core.LoadSenderWhitelist(indexer.Config.Base.SenderWhitelistFile, indexer.Config.AddressPrefix())
+		if err != nil {
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: %w", indexerPackage.ErrConfigInvalid, err))
+		}
+
+		config.Log.Infof("Loaded %d whitelisted senders", len(whitelist))
+		indexer.TxLookups.SenderWhitelist = whitelist
+	}
+
+	if indexer.Config.Base.HeightAnnotationsFile != "" {
+		annotations, err := core.LoadHeightAnnotations(indexer.Config.Base.HeightAnnotationsFile)
+		if err != nil {
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: %w", indexerPackage.ErrConfigInvalid, err))
+		}
+
+		config.Log.Infof("Loaded %d annotated height ranges", len(annotations))
+		indexer.HeightAnnotations = annotations
+	}
+
+	if indexer.Config.Base.SkipHeightsFile != "" {
+		heights, err := core.LoadSkipHeights(indexer.Config.Base.SkipHeightsFile)
+		if err != nil {
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: %w", indexerPackage.ErrConfigInvalid, err))
+		}
+
+		config.Log.Infof("Loaded %d heights to skip", len(heights))
+		indexer.SkipHeights = heights
+	}
+
 	if len(indexer.CustomModels) != 0 {
 		err = dbTypes.MigrateInterfaces(indexer.DB, indexer.CustomModels)
 		if err != nil {
-			safeCleanupSetupExit(&indexer)
-			config.Log.Fatal("Failed to migrate custom models", err)
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: failed to migrate custom models: %w", indexerPackage.ErrDBUnavailable, err))
 		}
 	}
 
+	// Custom block event parsers index against the normalized block event rows
+	if indexer.Config.Base.EventsStorageMode == config.EventsStorageModeJSONB && (len(indexer.CustomBeginBlockParserTrackers) != 0 || len(indexer.CustomEndBlockParserTrackers) != 0) {
+		return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: custom block event parsers require base.events-storage-mode %s", indexerPackage.ErrConfigInvalid, config.EventsStorageModeNormalized))
+	}
+
+	// Deduplicated events have no row of their own for custom parsers to reference
+	if indexer.Config.Base.DedupeStaticEvents && (len(indexer.CustomBeginBlockParserTrackers) != 0 || len(indexer.CustomEndBlockParserTrackers) != 0) {
+		return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: custom block event parsers cannot be used with base.dedupe-static-events", indexerPackage.ErrConfigInvalid))
+	}
+
 	if len(indexer.CustomBeginBlockParserTrackers) != 0 {
 		err = dbTypes.FindOrCreateCustomBlockEventParsers(indexer.DB, indexer.CustomBeginBlockParserTrackers)
 		if err != nil {
-			safeCleanupSetupExit(&indexer)
-			config.Log.Fatal("Failed to migrate custom block event parsers", err)
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: failed to migrate custom block event parsers: %w", indexerPackage.ErrDBUnavailable, err))
 		}
 	}
 
 	if len(indexer.CustomEndBlockParserTrackers) != 0 {
 		err = dbTypes.FindOrCreateCustomBlockEventParsers(indexer.DB, indexer.CustomEndBlockParserTrackers)
 		if err != nil {
-			safeCleanupSetupExit(&indexer)
-			config.Log.Fatal("Failed to migrate custom block event parsers", err)
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: failed to migrate custom block event parsers: %w", indexerPackage.ErrDBUnavailable, err))
 		}
 	}
 
 	if len(indexer.CustomMessageParserTrackers) != 0 {
 		err = dbTypes.FindOrCreateCustomMessageParsers(indexer.DB, indexer.CustomMessageParserTrackers)
 		if err != nil {
-			safeCleanupSetupExit(&indexer)
-			config.Log.Fatal("Failed to migrate custom message parsers", err)
+			return safeCleanupSetupExit(&indexer, fmt.Errorf("%w: failed to migrate custom message parsers: %w", indexerPackage.ErrDBUnavailable, err))
 		}
 
 	}
@@ -192,15 +277,36 @@ func setupIndex(cmd *cobra.Command, args []string) error {
 }
 
 // SetupIndexer sets up the "indexer" package Indexer instance with the configuration, database, and chain client
-func setupIndexer() *indexerPackage.Indexer {
+func setupIndexer() (*indexerPackage.Indexer, error) {
 	var err error
 
-	config.SetChainConfig(indexer.Config.Probe.AccountPrefix)
+	config.SetChainConfig(indexer.Config.AddressPrefix())
 
 	indexer.ChainClient, err = probe.GetProbeClient(indexer.Config.Probe, indexer.CustomModuleBasics, indexer.CustomMsgTypeRegistry)
 
 	if err != nil {
-		config.Log.Fatal("Failed to create probe client", err)
+		close(indexer.PostSetupDatasetChannel)
+		return nil, fmt.Errorf("%w: failed to create probe client: %w", indexerPackage.ErrConfigInvalid, err)
+	}
+
+	if indexer.Config.Base.UpgradeHeightsFile != "" {
+		indexer.CodecSchedule, err = setupCodecSchedule(indexer.Config.Base.UpgradeHeightsFile, indexer.UpgradeCodecVariants)
+		if err != nil {
+			close(indexer.PostSetupDatasetChannel)
+			return nil, fmt.Errorf("%w: %w", indexerPackage.ErrConfigInvalid, err)
+		}
+	}
+
+	err = verifyNodeChainID(indexer.ChainClient, indexer.Config.Probe.ChainID)
+	if err != nil {
+		close(indexer.PostSetupDatasetChannel)
+		return nil, err
+	}
+
+	err = verifyNodeTxIndex(indexer.ChainClient, indexer.Config)
+	if err != nil {
+		close(indexer.PostSetupDatasetChannel)
+		return nil, err
 	}
 
 	// Depending on the app configuration, wait for the chain to catch up
@@ -221,7 +327,7 @@ func setupIndexer() *indexerPackage.Indexer {
 	}
 	if err != nil {
 		close(indexer.PostSetupDatasetChannel)
-		config.Log.Fatal("Error querying chain status.", err)
+		return nil, fmt.Errorf("%w: error querying chain status: %w", indexerPackage.ErrNodeUnreachable, err)
 	}
 
 	if indexer.PostSetupDatasetChannel != nil {
@@ -241,27 +347,148 @@ func setupIndexer() *indexerPackage.Indexer {
 			DB:          indexer.DB,
 		})
 		if err != nil {
-			config.Log.Fatal("Failed to run post setup custom function", err)
+			return nil, fmt.Errorf("%w: failed to run post setup custom function: %w", indexerPackage.ErrCustomFunctionFailed, err)
 		}
 	}
 
-	return &indexer
+	return &indexer, nil
 }
 
-func index(cmd *cobra.Command, args []string) {
+// setupCodecSchedule builds the codec of each registered variant and switches block decoding to them at the upgrade heights in the file
+func setupCodecSchedule(path string, variants map[string]indexerPackage.CodecVariant) (*core.CodecSchedule, error) {
+	codecs := make(map[string]client.Codec)
+	for name, variant := range variants {
+		codec, err := probe.GetCodec(variant.ModuleBasics, variant.CustomMsgTypeRegistry)
+		if err != nil {
+			return nil, fmt.Errorf("error building codec variant %s: %w", name, err)
+		}
+		codecs[name] = codec
+	}
+
+	return core.LoadCodecSchedule(path, codecs)
+}
+
+// verifyNodeChainID checks the node serves the configured chain, so data from another network is never written under the configured chain ID.
+func verifyNodeChainID(cl *client.ChainClient, chainID string) error {
+	nodeChainID, err := rpc.GetNodeChainID(cl)
+	if err != nil {
+		return fmt.Errorf("%w: error querying node status: %w", indexerPackage.ErrNodeUnreachable, err)
+	}
+
+	return checkChainID(chainID, nodeChainID)
+}
+
+// verifyNodeTxIndex checks the node indexes txs when the txs of blocks are queried through tx search, which fails on nodes with
+// tx indexing disabled. Without tx search a disabled tx index is only reported.
+func verifyNodeTxIndex(cl *client.ChainClient, conf *config.IndexConfig) error {
+	txIndexEnabled, err := rpc.GetNodeTxIndexEnabled(cl)
+	if err != nil {
+		return fmt.Errorf("%w: error querying node status: %w", indexerPackage.ErrNodeUnreachable, err)
+	}
+
+	if txIndexEnabled {
+		return nil
+	}
+
+	if conf.Base.TransactionIndexingEnabled && !conf.Base.SkipBlockByHeightRPCRequest {
+		return fmt.Errorf("%w: the txs of blocks are queried through tx search, enable tx_index on the node or set base.skip-block-by-height-rpc-request to decode them from the block results instead", indexerPackage.ErrNodeTxIndexDisabled)
+	}
+
+	config.Log.Warn("The node has tx indexing disabled, tx search queries against it will fail")
+	return nil
+}
+
+func checkChainID(chainID string, nodeChainID string) error {
+	if chainID != nodeChainID {
+		return fmt.Errorf("%w: configured chain ID is %s, node reports %s", indexerPackage.ErrChainMismatch, chainID, nodeChainID)
+	}
+	return nil
+}
+
+func index(cmd *cobra.Command, args []string) error {
+	runStart := time.Now()
+
 	// Setup the indexer with config, db, and cl
-	idxr := setupIndexer()
+	idxr, err := setupIndexer()
+	if err != nil {
+		return err
+	}
+
 	dbConn, err := idxr.DB.DB()
 	if err != nil {
-		config.Log.Fatal("Failed to connect to DB", err)
+		return fmt.Errorf("%w: %w", indexerPackage.ErrDBUnavailable, err)
 	}
 	defer dbConn.Close()
 
+	if idxr.Config.Base.OtelEndpoint != "" {
+		shutdownTracing, err := core.SetupTracing(cmd.Context(), idxr.Config.Base.OtelEndpoint)
+		if err != nil {
+			return fmt.Errorf("%w: %w", indexerPackage.ErrConfigInvalid, err)
+		}
+		defer func() {
+			// Flush the spans of the last blocks
+			if err := shutdownTracing(context.Background()); err != nil {
+				config.Log.Errorf("Error shutting down the trace exporter: %v", err)
+			}
+		}()
+	}
+
+	idxr.FailedBlocks = &dbTypes.FailedBlockCounter{}
+
+	if idxr.Config.Base.ErrorWebhook != "" {
+		idxr.ErrorWebhook = core.NewErrorWebhook(idxr.Config.Base.ErrorWebhook, idxr.Config.Probe.ChainID)
+		defer idxr.ErrorWebhook.Close(10 * time.Second)
+	}
+
+	if idxr.Config.Sink.URL != "" {
+		sink, err := core.NewInfluxSink(idxr.Config.Sink, idxr.Config.Probe.ChainID)
+		if err != nil {
+			return fmt.Errorf("%w: failed to create the InfluxDB sink: %w", indexerPackage.ErrConfigInvalid, err)
+		}
+		idxr.InfluxSink = sink
+		defer sink.Close(10 * time.Second)
+	}
+
+	if idxr.Config.Base.AuditLogFile != "" {
+		auditLog, err := indexerPackage.OpenAuditLog(idxr.Config.Base.AuditLogFile, idxr.Config.Base.AuditLogFsync, idxr.Config.Probe.ChainID)
+		if err != nil {
+			return fmt.Errorf("%w: failed to open the audit log: %w", indexerPackage.ErrConfigInvalid, err)
+		}
+		idxr.AuditLog = auditLog
+		defer auditLog.Close()
+	}
+
+	if idxr.LeaderElector != nil {
+		defer idxr.LeaderElector.Release()
+
+		leaseInterval := time.Duration(idxr.Config.Base.HALeaseInterval) * time.Second
+		go idxr.LeaderElector.RunHeartbeat(cmd.Context(), leaseInterval, func(err error) {
+			// Another instance may already be writing, stop immediately rather than write concurrently
+			config.Log.Fatal("Lost HA leadership, exiting", err)
+		})
+	}
+
+	if idxr.Config.Base.ReportPhase {
+		startupTip, err := rpc.GetLatestBlockHeightWithRetry(idxr.ChainClient, idxr.Config.Base.RequestRetryAttempts, idxr.Config.Base.RequestRetryMaxWait)
+		if err != nil {
+			return fmt.Errorf("%w: failed to get the chain tip to report the indexer phase from: %w", indexerPackage.ErrNodeUnreachable, err)
+		}
+		idxr.Phase = indexerPackage.NewPhaseTracker(startupTip)
+		config.AddLogField("phase", idxr.Phase.Phase)
+	}
+
 	// blockChans are just the block heights; limit max jobs in the queue, otherwise this queue would contain one
 	// item (block height) for every block on the entire blockchain we're indexing. Furthermore, once the queue
 	// is close to empty, we will spin up a new thread to fill it up with new jobs.
 	blockEnqueueChan := make(chan *core.EnqueueData, 10000)
 
+	// With a run budget the workers read from a separate channel, so handing blocks over can stop at the deadline
+	// while the enqueue function is still running
+	workerEnqueueChan := blockEnqueueChan
+	if idxr.Config.Base.MaxRunDuration > 0 {
+		workerEnqueueChan = make(chan *core.EnqueueData)
+	}
+
 	// This channel represents query job results for the RPC queries to Cosmos Nodes. Every time an RPC query
 	// completes, the query result will be sent to this channel (for later processing by a different thread).
 	// Realistically, I expect that RPC queries will be slower than our relational DB on the local network.
@@ -283,63 +510,214 @@ func index(cmd *cobra.Command, args []string) {
 
 	dbChainID, err := dbTypes.GetDBChainID(idxr.DB, chain)
 	if err != nil {
-		config.Log.Fatal("Failed to add/create chain in DB", err)
+		return fmt.Errorf("%w: failed to add/create chain in DB: %w", indexerPackage.ErrDBUnavailable, err)
+	}
+
+	if !idxr.DryRun {
+		err = core.RecordSkipHeights(idxr.DB, dbChainID, idxr.SkipHeights)
+		if err != nil {
+			return fmt.Errorf("%w: failed to record skipped blocks: %w", indexerPackage.ErrDBUnavailable, err)
+		}
+	}
+
+	// Indexes are only deferred when the run has an end, an open-ended run would never get to recreate them
+	boundedBackfill := idxr.Config.Base.EndBlock != -1 || idxr.Config.Base.ExitWhenCaughtUp || idxr.Config.Base.CatchUpOnly || idxr.Config.Base.MaxRunDuration > 0 || idxr.Config.Base.BlockInputFile != ""
+	deferIndexes := idxr.Config.Database.DeferIndexes && !idxr.DryRun
+	if deferIndexes && !boundedBackfill {
+		config.Log.Warn("database.defer-indexes is only applied to bounded back-fills (base.end-block, base.exit-when-caught-up, base.catch-up-only, base.max-run-duration or base.block-input-file), keeping indexes")
+		deferIndexes = false
+	}
+
+	completionMarkerFile := idxr.Config.Base.CompletionMarkerFile
+	if completionMarkerFile != "" && !boundedBackfill {
+		config.Log.Warn("base.completion-marker-file is only written by bounded runs (base.end-block, base.exit-when-caught-up, base.catch-up-only, base.max-run-duration or base.block-input-file), no marker will be written")
+		completionMarkerFile = ""
+	}
+	if completionMarkerFile != "" {
+		if err := indexerPackage.RemoveCompletionMarker(completionMarkerFile); err != nil {
+			return err
+		}
+	}
+
+	if idxr.Config.Base.ProfileEvents && !boundedBackfill {
+		config.Log.Warn("base.profile-events only profiles bounded runs (base.end-block, base.exit-when-caught-up, base.catch-up-only, base.max-run-duration or base.block-input-file), events will be indexed")
+		idxr.Config.Base.ProfileEvents = false
+	}
+
+	analyzeAfterBackfill := idxr.Config.Database.AnalyzeAfterBackfill != "" && !idxr.DryRun
+	if analyzeAfterBackfill && !boundedBackfill {
+		config.Log.Warn("database.analyze-after-backfill is only applied to bounded back-fills (base.end-block, base.exit-when-caught-up, base.catch-up-only, base.max-run-duration or base.block-input-file), autovacuum keeps the statistics of open-ended runs up to date")
+		analyzeAfterBackfill = false
+	}
+
+	if deferIndexes {
+		droppedIndexes, err := dbTypes.DropDeferrableIndexes(idxr.DB)
+		if err != nil {
+			return fmt.Errorf("%w: failed to drop deferred indexes: %w", indexerPackage.ErrDBUnavailable, err)
+		}
+		config.Log.Infof("Deferred %d indexes until the back-fill completes", len(droppedIndexes))
 	}
 
 	// This block consolidates all base RPC requests into one worker.
 	// Workers read from the enqueued blocks and query blockchain data from the RPC server.
 	var blockRPCWaitGroup sync.WaitGroup
+	inflightLimiter := core.NewInflightLimiter(idxr.Config.Base.MaxInflightBlocks)
+	idxr.Inflight = inflightLimiter
+	idxr.FetchThrottle = core.NewAdaptiveThrottle(time.Duration(idxr.Config.Base.AdaptiveThrottleTargetMs) * time.Millisecond)
 	blockRPCWorkerDataChan := make(chan core.IndexerBlockEventData, 10)
+
+	// With ordered commits the workers hand their blocks to a reorder buffer, which passes them on for processing in enqueue order
+	rpcWorkerEnqueueChan := workerEnqueueChan
+	rpcWorkerOutputChan := blockRPCWorkerDataChan
+	var commitOrder *core.CommitOrder
+	if idxr.Config.Base.OrderedCommits {
+		// Blocks held back by the buffer keep their in-flight slot, a window above the cap would leave the next block in order no slot
+		window := 4 * rpcQueryThreads
+		if idxr.Config.Base.MaxInflightBlocks > 0 && int64(window) > idxr.Config.Base.MaxInflightBlocks {
+			window = int(idxr.Config.Base.MaxInflightBlocks)
+		}
+		commitOrder = core.NewCommitOrder(window, blockRPCWorkerDataChan)
+		rpcWorkerEnqueueChan = make(chan *core.EnqueueData)
+		rpcWorkerOutputChan = make(chan core.IndexerBlockEventData, 10)
+		go commitOrder.Dispatch(workerEnqueueChan, rpcWorkerEnqueueChan)
+		go commitOrder.Run(rpcWorkerOutputChan)
+	}
+
 	for i := 0; i < rpcQueryThreads; i++ {
 		blockRPCWaitGroup.Add(1)
-		go core.BlockRPCWorker(&blockRPCWaitGroup, blockEnqueueChan, dbChainID, idxr.Config.Probe.ChainID, idxr.Config, idxr.ChainClient, idxr.DB, blockRPCWorkerDataChan)
+		go core.BlockRPCWorker(&blockRPCWaitGroup, rpcWorkerEnqueueChan, dbChainID, idxr.Config.Probe.ChainID, idxr.Config, idxr.ChainClient, idxr.CodecSchedule, idxr.DB, idxr.FailedBlocks, inflightLimiter, idxr.FetchThrottle, commitOrder, idxr.ErrorWebhook, rpcWorkerOutputChan)
+	}
+
+	if idxr.Config.Base.BackgroundReindexStartBlock > 0 {
+		// Background blocks are not part of the enqueue order of the main pipeline, Validate rejects them with ordered commits
+		core.StartBackgroundReindex(&blockRPCWaitGroup, dbChainID, idxr.Config, idxr.SkipHeights, idxr.ChainClient, idxr.CodecSchedule, idxr.DB, idxr.FailedBlocks, idxr.FetchThrottle, idxr.ErrorWebhook, blockRPCWorkerDataChan)
 	}
 
 	go func() {
 		blockRPCWaitGroup.Wait()
-		close(blockRPCWorkerDataChan)
+		close(rpcWorkerOutputChan)
 	}()
 
 	// Block BeginBlocker and EndBlocker indexing requirements. Indexes block events that took place in the BeginBlock and EndBlock state transitions
-	blockEventsDataChan := make(chan *indexerPackage.BlockEventsDBData, 4*rpcQueryThreads)
-	txDataChan := make(chan *indexerPackage.DBData, 4*rpcQueryThreads)
+	dbQueueSize := 4 * rpcQueryThreads
+	if idxr.Config.Base.OrderedCommits {
+		// The DB worker selects over both queues, unbuffered queues make it receive the data of a block before the next block is processed
+		dbQueueSize = 0
+	}
+	blockEventsDataChan := make(chan *indexerPackage.BlockEventsDBData, dbQueueSize)
+	txDataChan := make(chan *indexerPackage.DBData, dbQueueSize)
 
 	wg.Add(1)
-	go idxr.ProcessBlocks(&wg, core.HandleFailedBlock, blockRPCWorkerDataChan, blockEventsDataChan, txDataChan, dbChainID, indexer.BlockEventFilterRegistries)
+	go idxr.ProcessBlocks(&wg, idxr.ErrorWebhook.FailedBlockHandler(), blockRPCWorkerDataChan, blockEventsDataChan, txDataChan, dbChainID, indexer.BlockEventFilterRegistries)
 
 	wg.Add(1)
 	go idxr.DoDBUpdates(&wg, txDataChan, blockEventsDataChan, dbChainID)
 
+	if idxr.Config.Base.MaxSustainedLag > 0 {
+		// Poll often enough to detect the sustained lag close to the configured duration
+		lagCheckInterval := time.Duration(idxr.Config.Base.MaxSustainedLagDuration) * time.Second / 10
+		if lagCheckInterval < time.Second {
+			lagCheckInterval = time.Second
+		}
+		go idxr.MonitorSustainedLag(lagCheckInterval, func() (int64, error) {
+			return rpc.GetLatestBlockHeight(idxr.ChainClient)
+		}, func(err error) {
+			config.Log.Fatal("Indexer lag exceeded the configured maximum, exiting", err)
+		})
+	}
+
+	if idxr.Config.Base.StatusSocket != "" {
+		closeStatusSocket, err := idxr.ServeStatusSocket(idxr.Config.Base.StatusSocket, func() (int64, error) {
+			return rpc.GetLatestBlockHeight(idxr.ChainClient)
+		})
+		if err != nil {
+			return fmt.Errorf("%w: failed to serve the status socket: %w", indexerPackage.ErrConfigInvalid, err)
+		}
+		defer func() {
+			if err := closeStatusSocket(); err != nil {
+				config.Log.Warnf("Failed to close the status socket. Err: %v", err)
+			}
+		}()
+	}
+
+	if idxr.Config.Base.IndexMempool {
+		go core.NewMempoolPoller(idxr.ChainClient, idxr.DB).Run(time.Duration(idxr.Config.Base.MempoolPollInterval) * time.Second)
+	}
+
+	if idxr.Config.Base.HeartbeatInterval > 0 {
+		go idxr.RunHeartbeat(time.Duration(idxr.Config.Base.HeartbeatInterval)*time.Second, func() (int64, error) {
+			return rpc.GetLatestBlockHeight(idxr.ChainClient)
+		}, func(height int64, lag int64) {
+			config.Log.Infof("Heartbeat: alive, at height %d, lag %d, in-flight blocks %d, rate limited node responses %d, fetch delay %s", height, lag, inflightLimiter.InFlight(), probe.RateLimitedResponses(), idxr.FetchThrottle.Delay())
+		})
+	}
+
 	switch {
 	// If block enqueue function has been explicitly set, use that
 	case idxr.BlockEnqueueFunction != nil:
 	// Default block enqueue functions based on config values
 	case idxr.Config.Base.ReindexMessageType != "":
-		idxr.BlockEnqueueFunction, err = core.GenerateMsgTypeEnqueueFunction(idxr.DB, *idxr.Config, dbChainID, idxr.Config.Base.ReindexMessageType)
+		idxr.BlockEnqueueFunction, err = core.GenerateMsgTypeEnqueueFunction(idxr.DB, *idxr.Config, idxr.SkipHeights, dbChainID, idxr.Config.Base.ReindexMessageType)
 		if err != nil {
-			config.Log.Fatal("Failed to generate block enqueue function", err)
+			return fmt.Errorf("%w: failed to generate block enqueue function: %w", indexerPackage.ErrEnqueueFailed, err)
 		}
 	case idxr.Config.Base.BlockInputFile != "":
-		idxr.BlockEnqueueFunction, err = core.GenerateBlockFileEnqueueFunction(idxr.DB, *idxr.Config, idxr.ChainClient, dbChainID, idxr.Config.Base.BlockInputFile)
+		idxr.BlockEnqueueFunction, err = core.GenerateBlockFileEnqueueFunction(idxr.DB, *idxr.Config, idxr.SkipHeights, idxr.ChainClient, dbChainID, idxr.Config.Base.BlockInputFile)
 		if err != nil {
-			config.Log.Fatal("Failed to generate block enqueue function", err)
+			return fmt.Errorf("%w: failed to generate block enqueue function: %w", indexerPackage.ErrEnqueueFailed, err)
 		}
 	default:
-		idxr.BlockEnqueueFunction, err = core.GenerateDefaultEnqueueFunction(idxr.DB, *idxr.Config, idxr.ChainClient, dbChainID)
+		idxr.BlockEnqueueFunction, err = core.GenerateDefaultEnqueueFunction(idxr.DB, *idxr.Config, idxr.SkipHeights, idxr.ChainClient, dbChainID)
 		if err != nil {
-			config.Log.Fatal("Failed to generate block enqueue function", err)
+			return fmt.Errorf("%w: failed to generate block enqueue function: %w", indexerPackage.ErrEnqueueFailed, err)
 		}
 	}
 
-	err = idxr.BlockEnqueueFunction(blockEnqueueChan)
-	if err != nil {
-		config.Log.Fatal("Block enqueue failed", err)
+	var deadline time.Time
+	if idxr.Config.Base.MaxRunDuration > 0 {
+		deadline = runStart.Add(time.Duration(idxr.Config.Base.MaxRunDuration) * time.Second)
 	}
 
-	close(blockEnqueueChan)
+	// Blocks that time out are enqueued again before the enqueue channel is closed, until the run budget elapses
+	enqueueFunction := idxr.RetryTimedOutBlocks(idxr.BlockEnqueueFunction, inflightLimiter, deadline)
+
+	if idxr.Config.Base.MaxRunDuration > 0 {
+		budgetElapsed, err := core.EnqueueUntil(deadline, enqueueFunction, blockEnqueueChan, workerEnqueueChan)
+		if err != nil {
+			return fmt.Errorf("%w: %w", indexerPackage.ErrEnqueueFailed, err)
+		}
+		if budgetElapsed {
+			config.Log.Infof("Max run duration of %d seconds reached, finishing the blocks in flight and exiting", idxr.Config.Base.MaxRunDuration)
+		}
+	} else {
+		err = enqueueFunction(blockEnqueueChan)
+		if err != nil {
+			return fmt.Errorf("%w: %w", indexerPackage.ErrEnqueueFailed, err)
+		}
+
+		close(blockEnqueueChan)
+	}
 
 	wg.Wait()
 
+	if deferIndexes {
+		config.Log.Info("Back-fill complete, recreating deferred indexes")
+		err = dbTypes.RecreateDeferredIndexes(idxr.DB)
+		if err != nil {
+			return fmt.Errorf("%w: failed to recreate deferred indexes: %w", indexerPackage.ErrDBUnavailable, err)
+		}
+	}
+
+	// Run after the indexes are recreated so their statistics are gathered too
+	if analyzeAfterBackfill {
+		config.Log.Info("Back-fill complete, refreshing query planner statistics")
+		err = dbTypes.AnalyzeBackfilledTables(idxr.DB, idxr.Config.Database.AnalyzeAfterBackfill == config.AnalyzeAfterBackfillVacuum)
+		if err != nil {
+			// The indexed data is complete, stale statistics only slow queries down until autovacuum runs
+			config.Log.Errorf("Failed to refresh query planner statistics: %v", err)
+		}
+	}
+
 	if indexer.PreExitCustomFunction != nil {
 		err = indexer.PreExitCustomFunction(&indexerPackage.PreExitCustomDataset{
 			Config: *idxr.Config,
@@ -347,7 +725,23 @@ func index(cmd *cobra.Command, args []string) {
 			DryRun: indexer.DryRun,
 		})
 		if err != nil {
-			config.Log.Fatal("Failed to run pre-exit custom function", err)
+			return fmt.Errorf("%w: failed to run pre-exit custom function: %w", indexerPackage.ErrCustomFunctionFailed, err)
+		}
+	}
+
+	if idxr.Config.Base.ProfileEvents {
+		if err := idxr.WriteEventProfile(idxr.Config.Base.ProfileEventsFile); err != nil {
+			return fmt.Errorf("error writing the event profile: %w", err)
 		}
+		config.Log.Infof("Wrote event profile %s", idxr.Config.Base.ProfileEventsFile)
 	}
+
+	if completionMarkerFile != "" {
+		if err := idxr.WriteCompletionMarker(completionMarkerFile); err != nil {
+			return fmt.Errorf("run did not complete cleanly, completion marker not written: %w", err)
+		}
+		config.Log.Infof("Run complete, wrote completion marker %s", completionMarkerFile)
+	}
+
+	return nil
 }
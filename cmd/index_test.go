@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	indexerPackage "github.com/DefiantLabs/cosmos-indexer/indexer"
+	"github.com/DefiantLabs/cosmos-indexer/probe"
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexErrorsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *IndexErrorsTestSuite) TestSetupIndexConfigInvalid() {
+	// Nothing is configured, validation fails before anything is connected to
+	err := setupIndex(indexCmd, nil)
+	suite.Require().Error(err)
+	suite.ErrorIs(err, indexerPackage.ErrConfigInvalid)
+}
+
+func (suite *IndexErrorsTestSuite) TestCheckChainID() {
+	suite.NoError(checkChainID("testchain-1", "testchain-1"))
+	suite.ErrorIs(checkChainID("testchain-1", "otherchain-1"), indexerPackage.ErrChainMismatch)
+}
+
+func (suite *IndexErrorsTestSuite) TestVerifyNodeChainIDUnreachable() {
+	cl, err := probe.GetProbeClient(config.Probe{
+		RPC:           "http://127.0.0.1:1",
+		AccountPrefix: "cosmos",
+		ChainID:       "testchain-1",
+		ChainName:     "testchain",
+	}, nil, nil)
+	suite.Require().NoError(err)
+
+	err = verifyNodeChainID(cl, "testchain-1")
+	suite.ErrorIs(err, indexerPackage.ErrNodeUnreachable)
+}
+
+// statusNode is a mock node answering status requests with the tx_index setting
+func statusNode(txIndex string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID json.RawMessage `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %s, "result": {
+			"node_info": {"network": "testchain-1", "other": {"tx_index": %q}},
+			"sync_info": {"latest_block_height": "100", "catching_up": false},
+			"validator_info": {"voting_power": "0"}
+		}}`, request.ID, txIndex)
+	}))
+}
+
+func (suite *IndexErrorsTestSuite) TestVerifyNodeTxIndex() {
+	node := statusNode("off")
+	defer node.Close()
+
+	cl, err := probe.GetProbeClient(config.Probe{
+		RPC:           node.URL,
+		AccountPrefix: "cosmos",
+		ChainID:       "testchain-1",
+		ChainName:     "testchain",
+	}, nil, nil)
+	suite.Require().NoError(err)
+
+	// Txs are queried through tx search, which the node cannot serve
+	conf := &config.IndexConfig{}
+	conf.Base.TransactionIndexingEnabled = true
+	suite.ErrorIs(verifyNodeTxIndex(cl, conf), indexerPackage.ErrNodeTxIndexDisabled)
+
+	// Only warned about when tx search is not used
+	conf.Base.SkipBlockByHeightRPCRequest = true
+	suite.NoError(verifyNodeTxIndex(cl, conf))
+	conf.Base.SkipBlockByHeightRPCRequest = false
+	conf.Base.TransactionIndexingEnabled = false
+	suite.NoError(verifyNodeTxIndex(cl, conf))
+
+	enabledNode := statusNode("on")
+	defer enabledNode.Close()
+	cl, err = probe.GetProbeClient(config.Probe{
+		RPC:           enabledNode.URL,
+		AccountPrefix: "cosmos",
+		ChainID:       "testchain-1",
+		ChainName:     "testchain",
+	}, nil, nil)
+	suite.Require().NoError(err)
+	conf.Base.TransactionIndexingEnabled = true
+	suite.NoError(verifyNodeTxIndex(cl, conf))
+}
+
+func (suite *IndexErrorsTestSuite) TestConnectToDBUnavailable() {
+	_, err := ConnectToDBAndMigrate(config.Database{
+		Host:     "127.0.0.1",
+		Port:     "1",
+		Database: "test",
+		User:     "test",
+		Password: "test",
+	})
+	suite.ErrorIs(err, indexerPackage.ErrDBUnavailable)
+}
+
+func TestIndexErrorsTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexErrorsTestSuite))
+}
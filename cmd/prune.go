@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	indexerPackage "github.com/DefiantLabs/cosmos-indexer/indexer"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var pruneConfig = &config.PruneConfig{}
+
+func init() {
+	config.SetupLogFlags(&pruneConfig.Log, pruneCmd)
+	config.SetupDatabaseFlags(&pruneConfig.Database, pruneCmd)
+	config.SetupPruneFlags(pruneConfig, pruneCmd)
+	rootCmd.AddCommand(pruneCmd)
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Deletes indexed data below a block height.",
+	Long: `Deletes all indexed blocks, transactions, messages and events below the given height in a single
+	database transaction. Pruning above the current resume cursor (the highest indexed block) is refused unless --force is set.`,
+	PreRunE: setupPrune,
+	RunE:    prune,
+}
+
+func setupPrune(cmd *cobra.Command, args []string) error {
+	BindFlags(cmd, viperConf)
+
+	err := pruneConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	setupLogger(pruneConfig.Log.Level, pruneConfig.Log.Path, pruneConfig.Log.Pretty)
+
+	return nil
+}
+
+func prune(cmd *cobra.Command, args []string) error {
+	database, err := dbTypes.PostgresDbConnectWithRetry(pruneConfig.Database)
+	if err != nil {
+		return fmt.Errorf("%w: could not establish connection to the database: %w", indexerPackage.ErrDBUnavailable, err)
+	}
+
+	var chain models.Chain
+	err = database.Where("chain_id = ?", pruneConfig.ChainID).First(&chain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("chain %s has not been indexed", pruneConfig.ChainID)
+		}
+		return err
+	}
+
+	prunedBlocks, err := dbTypes.PruneBelow(database, chain.ID, pruneConfig.Below, pruneConfig.Force)
+	if err != nil {
+		return err
+	}
+
+	config.Log.Infof("Pruned %d blocks below height %d for chain %s", prunedBlocks, pruneConfig.Below, pruneConfig.ChainID)
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/core"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/probe"
+	probeClient "github.com/DefiantLabs/probe/client"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var reindexMessagesConfig = &config.ReindexMessagesConfig{}
+
+func init() {
+	config.SetupLogFlags(&reindexMessagesConfig.Log, reindexMessagesCmd)
+	config.SetupDatabaseFlags(&reindexMessagesConfig.Database, reindexMessagesCmd)
+	config.SetupReindexMessagesFlags(reindexMessagesConfig, reindexMessagesCmd)
+	rootCmd.AddCommand(reindexMessagesCmd)
+}
+
+var reindexMessagesCmd = &cobra.Command{
+	Use:   "reindex-messages",
+	Short: "Re-decodes the indexed messages of one type from their stored raw bytes.",
+	Long: `Re-decodes every indexed message of the --type message type from the raw message bytes stored with
+	flags.index-tx-message-raw and updates the data derived from it in place: the schema error, the message addresses
+	(with base.index-addresses) and the data of the registered custom message parsers. Other messages, transactions and
+	events are left untouched and the node is not queried. Errors without updating anything if any message of the type
+	was indexed without its raw bytes.`,
+	PreRunE: setupReindexMessages,
+	RunE:    reindexMessages,
+}
+
+func setupReindexMessages(cmd *cobra.Command, args []string) error {
+	BindFlags(cmd, viperConf)
+
+	err := reindexMessagesConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	setupLogger(reindexMessagesConfig.Log.Level, reindexMessagesConfig.Log.Path, reindexMessagesConfig.Log.Pretty)
+
+	if reindexMessagesConfig.AccountPrefix != "" {
+		config.SetChainConfig(reindexMessagesConfig.AccountPrefix)
+	}
+
+	return nil
+}
+
+func reindexMessages(cmd *cobra.Command, args []string) error {
+	database, err := dbTypes.PostgresDbConnectWithRetry(reindexMessagesConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	var chain models.Chain
+	err = database.Where("chain_id = ?", reindexMessagesConfig.ChainID).First(&chain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("chain %s has not been indexed", reindexMessagesConfig.ChainID)
+		}
+		return err
+	}
+
+	var schemas core.MessageSchemas
+	if reindexMessagesConfig.MessageSchemaDir != "" {
+		schemas, err = core.LoadMessageSchemas(reindexMessagesConfig.MessageSchemaDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Messages are decoded with the same types registered with the SDK as the index command
+	codec, err := probe.GetCodec(indexer.CustomModuleBasics, indexer.CustomMsgTypeRegistry)
+	if err != nil {
+		return err
+	}
+
+	if len(indexer.CustomMessageParserTrackers) != 0 {
+		err = dbTypes.FindOrCreateCustomMessageParsers(database, indexer.CustomMessageParserTrackers)
+		if err != nil {
+			return err
+		}
+	}
+
+	redecode := core.NewMessageRedecoder(reindexMessagesConfig.IndexConfig(), &probeClient.ChainClient{Codec: codec}, nil, indexer.CustomMessageParserRegistry, schemas)
+	reindexed, err := dbTypes.ReindexMessages(database, reindexMessagesConfig.IndexConfig(), chain.ID, reindexMessagesConfig.MessageType, reindexMessagesConfig.BatchSize, redecode, indexer.CustomMessageParserTrackers)
+	if err != nil {
+		return err
+	}
+
+	config.Log.Infof("Reindexed %d messages of type %s for chain %s", reindexed, reindexMessagesConfig.MessageType, reindexMessagesConfig.ChainID)
+
+	return nil
+}
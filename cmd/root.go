@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/DefiantLabs/cosmos-indexer/config"
 	"github.com/DefiantLabs/cosmos-indexer/db"
+	indexerPackage "github.com/DefiantLabs/cosmos-indexer/indexer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -101,7 +103,7 @@ func BindFlags(cmd *cobra.Command, v *viper.Viper) {
 		// Apply the viper config value to the flag when the flag is not set and viper has a value
 		if !f.Changed && v.IsSet(configName) {
 			val := v.Get(configName)
-			err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val))
+			err := cmd.Flags().Set(f.Name, configValueToFlagString(val))
 			if err != nil {
 				log.Fatalf("Failed to bind config file value %v. Err: %v", configName, err)
 			}
@@ -109,14 +111,39 @@ func BindFlags(cmd *cobra.Command, v *viper.Viper) {
 	})
 }
 
+// Map values from the config file (e.g. TOML inline tables) are converted to the key=value,key=value format expected by map flags
+func configValueToFlagString(val any) string {
+	mapVal, ok := val.(map[string]any)
+	if !ok {
+		return fmt.Sprintf("%v", val)
+	}
+
+	pairs := make([]string, 0, len(mapVal))
+	for key, value := range mapVal {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
 func setupLogger(logLevel string, logPath string, prettyLogging bool) {
 	config.DoConfigureLogger(logPath, logLevel, prettyLogging)
 }
 
 func ConnectToDBAndMigrate(dbConfig config.Database) (*gorm.DB, error) {
-	database, err := db.PostgresDbConnect(dbConfig.Host, dbConfig.Port, dbConfig.Database, dbConfig.User, dbConfig.Password, strings.ToLower(dbConfig.LogLevel))
+	database, err := connectToDB(dbConfig)
 	if err != nil {
-		config.Log.Fatal("Could not establish connection to the database", err)
+		return nil, err
+	}
+
+	return database, migrateDB(database, dbConfig, nil)
+}
+
+func connectToDB(dbConfig config.Database) (*gorm.DB, error) {
+	database, err := db.PostgresDbConnectWithRetry(dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not establish connection to the database: %w", indexerPackage.ErrDBUnavailable, err)
 	}
 
 	sqldb, _ := database.DB()
@@ -124,10 +151,37 @@ func ConnectToDBAndMigrate(dbConfig config.Database) (*gorm.DB, error) {
 	sqldb.SetMaxOpenConns(100)
 	sqldb.SetConnMaxLifetime(time.Hour)
 
-	err = db.MigrateModels(database)
-	if err != nil {
-		config.Log.Error("Error running DB migrations", err)
+	return database, nil
+}
+
+// migrateDB migrates the indexer models and the tx columns, and creates the optional views, indexes and post-migrate SQL of the
+// database config. It is run on both databases connected by the indexer and databases preset by an embedding application.
+func migrateDB(database *gorm.DB, dbConfig config.Database, txColumns []db.TxColumn) error {
+	if err := db.MigrateModels(database); err != nil {
+		return fmt.Errorf("%w: error running DB migrations: %w", indexerPackage.ErrDBUnavailable, err)
+	}
+
+	if err := db.MigrateTxColumns(database, txColumns); err != nil {
+		return fmt.Errorf("%w: error adding the tx columns: %w", indexerPackage.ErrDBUnavailable, err)
+	}
+
+	if dbConfig.CreateViews {
+		if err := db.CreateViews(database); err != nil {
+			return fmt.Errorf("%w: error creating DB views: %w", indexerPackage.ErrDBUnavailable, err)
+		}
+	}
+
+	if dbConfig.IndexMessageType {
+		if err := db.CreateMessageTypeIndex(database); err != nil {
+			return fmt.Errorf("%w: error creating the message type index: %w", indexerPackage.ErrDBUnavailable, err)
+		}
+	}
+
+	if dbConfig.PostMigrateSQLDir != "" {
+		if _, err := db.ApplyPostMigrateSQL(database, dbConfig.PostMigrateSQLDir); err != nil {
+			return fmt.Errorf("%w: error running post-migrate SQL: %w", indexerPackage.ErrDBUnavailable, err)
+		}
 	}
 
-	return database, err
+	return nil
 }
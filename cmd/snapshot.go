@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var (
+	snapshotConfig = &config.SnapshotConfig{}
+	restoreConfig  = &config.SnapshotConfig{}
+)
+
+func init() {
+	config.SetupLogFlags(&snapshotConfig.Log, snapshotCmd)
+	config.SetupDatabaseFlags(&snapshotConfig.Database, snapshotCmd)
+	config.SetupSnapshotFlags(snapshotConfig, snapshotCmd)
+	rootCmd.AddCommand(snapshotCmd)
+
+	config.SetupLogFlags(&restoreConfig.Log, restoreCmd)
+	config.SetupDatabaseFlags(&restoreConfig.Database, restoreCmd)
+	config.SetupSnapshotFlags(restoreConfig, restoreCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Exports the indexed data to a compressed snapshot file.",
+	Long: `Writes every table in the indexer database schema to a gzipped JSON lines file that can be loaded
+	into another database with the restore command, avoiding a full re-index.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupSnapshotConfig(cmd, snapshotConfig)
+	},
+	RunE: snapshot,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Loads a snapshot file written by the snapshot command.",
+	Long: `Migrates the database and loads a snapshot written by the snapshot command in a single database transaction.
+	The indexer tables must be empty. Columns that only exist in the snapshot or only in the database are skipped,
+	so snapshots can be restored across minor schema versions.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupSnapshotConfig(cmd, restoreConfig)
+	},
+	RunE: restore,
+}
+
+func setupSnapshotConfig(cmd *cobra.Command, conf *config.SnapshotConfig) error {
+	BindFlags(cmd, viperConf)
+
+	err := conf.Validate()
+	if err != nil {
+		return err
+	}
+
+	setupLogger(conf.Log.Level, conf.Log.Path, conf.Log.Pretty)
+
+	return nil
+}
+
+func connectSnapshotDB(conf *config.SnapshotConfig) *gorm.DB {
+	database, err := dbTypes.PostgresDbConnectWithRetry(conf.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+	return database
+}
+
+func snapshot(cmd *cobra.Command, args []string) error {
+	database := connectSnapshotDB(snapshotConfig)
+
+	file, err := os.Create(snapshotConfig.File)
+	if err != nil {
+		return err
+	}
+
+	result, err := dbTypes.Snapshot(database, file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	config.Log.Infof("Wrote snapshot of %d tables to %s", len(result), snapshotConfig.File)
+
+	return nil
+}
+
+func restore(cmd *cobra.Command, args []string) error {
+	database := connectSnapshotDB(restoreConfig)
+
+	err := dbTypes.MigrateModels(database)
+	if err != nil {
+		config.Log.Error("Error running DB migrations", err)
+		return err
+	}
+
+	file, err := os.Open(restoreConfig.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := dbTypes.Restore(database, file)
+	if err != nil {
+		return err
+	}
+
+	config.Log.Infof("Restored %d tables from %s", len(result), restoreConfig.File)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var validateDataConfig = &config.ValidateDataConfig{}
+
+func init() {
+	config.SetupLogFlags(&validateDataConfig.Log, validateDataCmd)
+	config.SetupDatabaseFlags(&validateDataConfig.Database, validateDataCmd)
+	config.SetupValidateDataFlags(validateDataConfig, validateDataCmd)
+	rootCmd.AddCommand(validateDataCmd)
+}
+
+var validateDataCmd = &cobra.Command{
+	Use:   "validate-data",
+	Short: "Checks already-indexed data against the data validators.",
+	Long: `Streams the indexed rows of a chain through the built-in and registered data validators (e.g. required fields
+	and address formats) in a read-only database transaction and reports every violation with its height and transaction hash.
+	Useful to verify existing rows after a parser fix without re-indexing from the node. Exits with an error if any violation is found.`,
+	PreRunE: setupValidateData,
+	RunE:    validateData,
+}
+
+func setupValidateData(cmd *cobra.Command, args []string) error {
+	BindFlags(cmd, viperConf)
+
+	err := validateDataConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	setupLogger(validateDataConfig.Log.Level, validateDataConfig.Log.Path, validateDataConfig.Log.Pretty)
+
+	return nil
+}
+
+func validateData(cmd *cobra.Command, args []string) error {
+	database, err := dbTypes.PostgresDbConnectWithRetry(validateDataConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	var chain models.Chain
+	err = database.Where("chain_id = ?", validateDataConfig.ChainID).First(&chain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("chain %s has not been indexed", validateDataConfig.ChainID)
+		}
+		return err
+	}
+
+	violations, err := dbTypes.ValidateData(database, chain.ID, dbTypes.DataValidators(), validateDataConfig.MaxViolations, func(violation dbTypes.DataViolation) {
+		config.Log.Warnf("[%s] %s row %d at height %d (tx %s): %s", violation.Validator, violation.Table, violation.RowID, violation.Height, violation.TxHash, violation.Reason)
+	})
+	if err != nil {
+		return err
+	}
+
+	if violations != 0 {
+		return fmt.Errorf("found %d data violations for chain %s", violations, validateDataConfig.ChainID)
+	}
+
+	config.Log.Infof("No data violations found for chain %s", validateDataConfig.ChainID)
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/core"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/probe"
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var verifyConfig = &config.VerifyConfig{}
+
+func init() {
+	config.SetupLogFlags(&verifyConfig.Log, verifyCmd)
+	config.SetupDatabaseFlags(&verifyConfig.Database, verifyCmd)
+	config.SetupProbeFlags(&verifyConfig.Probe, verifyCmd)
+	config.SetupVerifyFlags(verifyConfig, verifyCmd)
+	rootCmd.AddCommand(verifyCmd)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Re-fetches indexed blocks from the node and compares them to the stored rows.",
+	Long: `Fetches the blocks from --start to --end from the node again, decodes their transactions with the same codec
+	as the index command and compares them to the stored transactions, fees, messages and message events, reporting every
+	mismatch without writing anything. Catches silent data corruption and drift between the parser versions the blocks were
+	indexed with and the current one. Exits with an error if any mismatch is found.`,
+	PreRunE: setupVerify,
+	RunE:    verify,
+}
+
+// VerifyReport is the JSON document written to the --report-file of the verify command
+type VerifyReport struct {
+	ChainID    string               `json:"chain_id"`
+	StartBlock int64                `json:"start_block"`
+	EndBlock   int64                `json:"end_block"`
+	Mismatches []dbTypes.TxMismatch `json:"mismatches"`
+}
+
+func setupVerify(cmd *cobra.Command, args []string) error {
+	BindFlags(cmd, viperConf)
+
+	err := verifyConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	setupLogger(verifyConfig.Log.Level, verifyConfig.Log.Path, verifyConfig.Log.Pretty)
+
+	config.SetChainConfig(verifyConfig.Probe.AccountPrefix)
+
+	return nil
+}
+
+func verify(cmd *cobra.Command, args []string) error {
+	database, err := dbTypes.PostgresDbConnectWithRetry(verifyConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	var chain models.Chain
+	err = database.Where("chain_id = ?", verifyConfig.Probe.ChainID).First(&chain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("chain %s has not been indexed", verifyConfig.Probe.ChainID)
+		}
+		return err
+	}
+
+	// Txs are decoded with the same types registered with the SDK as the index command
+	chainClient, err := probe.GetProbeClient(verifyConfig.Probe, indexer.CustomModuleBasics, indexer.CustomMsgTypeRegistry)
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := probe.GetHTTPClient(verifyConfig.Probe, 0)
+	if err != nil {
+		return err
+	}
+	rpcClient := rpc.URIClient{Address: chainClient.Config.RPCAddr, Client: httpClient}
+
+	indexConfig := verifyConfig.IndexConfig()
+	report := VerifyReport{ChainID: verifyConfig.Probe.ChainID, StartBlock: verifyConfig.StartBlock, EndBlock: verifyConfig.EndBlock, Mismatches: []dbTypes.TxMismatch{}}
+
+	for height := verifyConfig.StartBlock; height <= verifyConfig.EndBlock; height++ {
+		fetched, err := core.FetchBlockTxs(&indexConfig, chainClient, nil, rpcClient, height)
+		if err != nil {
+			return fmt.Errorf("error fetching block %d from the node: %w", height, err)
+		}
+
+		mismatches, err := dbTypes.VerifyBlockTxs(database, chain.ID, height, fetched, verifyConfig.IndexMessageEvents)
+		if err != nil {
+			return err
+		}
+
+		for _, mismatch := range mismatches {
+			config.Log.Warnf("Block %d mismatch in %s (tx %s): stored %s, fetched %s", mismatch.Height, mismatch.Field, mismatch.TxHash, mismatch.Stored, mismatch.Fetched)
+		}
+		report.Mismatches = append(report.Mismatches, mismatches...)
+	}
+
+	if verifyConfig.ReportFile != "" {
+		serialized, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(verifyConfig.ReportFile, serialized, 0o600); err != nil {
+			return err
+		}
+	}
+
+	blocks := verifyConfig.EndBlock - verifyConfig.StartBlock + 1
+	if len(report.Mismatches) != 0 {
+		return fmt.Errorf("found %d mismatches in %d verified blocks for chain %s", len(report.Mismatches), blocks, verifyConfig.Probe.ChainID)
+	}
+
+	config.Log.Infof("Verified %d blocks for chain %s, no mismatches found", blocks, verifyConfig.Probe.ChainID)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var verifyChecksumsConfig = &config.VerifyChecksumsConfig{}
+
+func init() {
+	config.SetupLogFlags(&verifyChecksumsConfig.Log, verifyChecksumsCmd)
+	config.SetupDatabaseFlags(&verifyChecksumsConfig.Database, verifyChecksumsCmd)
+	config.SetupVerifyChecksumsFlags(verifyChecksumsConfig, verifyChecksumsCmd)
+	rootCmd.AddCommand(verifyChecksumsCmd)
+}
+
+var verifyChecksumsCmd = &cobra.Command{
+	Use:   "verify-checksums",
+	Short: "Recomputes the checksums of indexed blocks and compares them to the stored ones.",
+	Long: `Recomputes the checksum of the stored txs and events of every block of a chain indexed with base.index-block-checksums
+	in a read-only database transaction and reports every block whose stored checksum no longer matches, e.g. after silent data
+	corruption or manual edits. Blocks without a stored checksum are skipped. Exits with an error if any mismatch is found.`,
+	PreRunE: setupVerifyChecksums,
+	RunE:    verifyChecksums,
+}
+
+func setupVerifyChecksums(cmd *cobra.Command, args []string) error {
+	BindFlags(cmd, viperConf)
+
+	err := verifyChecksumsConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	setupLogger(verifyChecksumsConfig.Log.Level, verifyChecksumsConfig.Log.Path, verifyChecksumsConfig.Log.Pretty)
+
+	return nil
+}
+
+func verifyChecksums(cmd *cobra.Command, args []string) error {
+	database, err := dbTypes.PostgresDbConnectWithRetry(verifyChecksumsConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	var chain models.Chain
+	err = database.Where("chain_id = ?", verifyChecksumsConfig.ChainID).First(&chain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("chain %s has not been indexed", verifyChecksumsConfig.ChainID)
+		}
+		return err
+	}
+
+	verified, mismatches, err := dbTypes.VerifyBlockChecksums(database, chain.ID, verifyChecksumsConfig.BatchSize, func(mismatch dbTypes.BlockChecksumMismatch) {
+		config.Log.Warnf("Block %d checksum mismatch: stored %s, computed %s", mismatch.Height, mismatch.Stored, mismatch.Computed)
+	})
+	if err != nil {
+		return err
+	}
+
+	if mismatches != 0 {
+		return fmt.Errorf("found %d checksum mismatches in %d verified blocks for chain %s", mismatches, verified, verifyChecksumsConfig.ChainID)
+	}
+
+	config.Log.Infof("Verified the checksums of %d blocks for chain %s", verified, verifyChecksumsConfig.ChainID)
+
+	return nil
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"os"
 	"reflect"
 	"strings"
 
@@ -18,19 +20,48 @@ type log struct {
 }
 
 type Database struct {
-	Host     string
-	Port     string
-	Database string
-	User     string
-	Password string
-	LogLevel string `mapstructure:"log-level"`
+	Host                 string
+	Port                 string
+	Database             string
+	User                 string
+	Password             string
+	LogLevel             string `mapstructure:"log-level"`
+	CreateViews          bool   `mapstructure:"create-views"`
+	CommitRetries        int64  `mapstructure:"commit-retries"`
+	DeferIndexes         bool   `mapstructure:"defer-indexes"`
+	AnalyzeAfterBackfill string `mapstructure:"analyze-after-backfill"`
+	PostMigrateSQLDir    string `mapstructure:"post-migrate-sql-dir"`
+	ConnectRetries       int64  `mapstructure:"connect-retries"`
+	ConnectRetryDelay    int64  `mapstructure:"connect-retry-delay"`
+	ReconnectRetries     int64  `mapstructure:"reconnect-retries"`
+	MaxConcurrentTxns    int64  `mapstructure:"max-concurrent-txns"`
+	IndexMessageType     bool   `mapstructure:"index-message-type"`
 }
 
+// Statements run on the per-block tables after a bounded back-fill
+const (
+	AnalyzeAfterBackfillAnalyze = "analyze"
+	AnalyzeAfterBackfillVacuum  = "vacuum-analyze"
+)
+
 type Probe struct {
 	RPC           string
 	AccountPrefix string `mapstructure:"account-prefix"`
 	ChainID       string `mapstructure:"chain-id"`
 	ChainName     string `mapstructure:"chain-name"`
+	HTTPProxy     string `mapstructure:"http-proxy"`
+	HTTPSProxy    string `mapstructure:"https-proxy"`
+	NoProxy       string `mapstructure:"no-proxy"`
+	CAFile        string `mapstructure:"ca-file"`
+	MaxRetryAfter int64  `mapstructure:"max-retry-after"`
+}
+
+// Sink is the InfluxDB sink per-block aggregate points are written to alongside the database, disabled when the URL is not set
+type Sink struct {
+	URL    string
+	Org    string
+	Bucket string
+	Token  string
 }
 
 type throttlingBase struct {
@@ -55,6 +86,16 @@ func SetupDatabaseFlags(databaseConf *Database, cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&databaseConf.User, "database.user", "", "database user")
 	cmd.PersistentFlags().StringVar(&databaseConf.Password, "database.password", "", "database password")
 	cmd.PersistentFlags().StringVar(&databaseConf.LogLevel, "database.log-level", "", "database loglevel")
+	cmd.PersistentFlags().BoolVar(&databaseConf.CreateViews, "database.create-views", false, "create convenience SQL views (v_transactions_with_fees, v_transfers) during migration")
+	cmd.PersistentFlags().BoolVar(&databaseConf.IndexMessageType, "database.index-message-type", false, "store the type URL of each message in the type_url column of the messages table and index it, making type filtered message queries fast at the cost of write throughput")
+	cmd.PersistentFlags().BoolVar(&databaseConf.DeferIndexes, "database.defer-indexes", false, "drop the non-unique secondary indexes of the per-block tables at the start of a bounded back-fill and recreate them once it completes")
+	cmd.PersistentFlags().StringVar(&databaseConf.AnalyzeAfterBackfill, "database.analyze-after-backfill", "", "refresh the query planner statistics of the per-block tables once a bounded back-fill completes: \"analyze\" runs ANALYZE, \"vacuum-analyze\" runs VACUUM ANALYZE, empty disables it")
+	cmd.PersistentFlags().StringVar(&databaseConf.PostMigrateSQLDir, "database.post-migrate-sql-dir", "", "directory of .sql files run in lexical order after the built-in migrations, each in its own transaction, applied files are tracked and not run again")
+	cmd.PersistentFlags().Int64Var(&databaseConf.CommitRetries, "database.commit-retries", 3, "number of times a block's DB transaction is re-run when it fails with a serialization failure or deadlock")
+	cmd.PersistentFlags().Int64Var(&databaseConf.MaxConcurrentTxns, "database.max-concurrent-txns", 0, "the maximum number of block commit transactions running at once, independent of the connection pool size (0 disables the limit)")
+	cmd.PersistentFlags().Int64Var(&databaseConf.ConnectRetries, "database.connect-retries", 0, "number of times the initial database connection is retried before giving up, e.g. while the database is still starting")
+	cmd.PersistentFlags().Int64Var(&databaseConf.ConnectRetryDelay, "database.connect-retry-delay", 5, "seconds to wait before the first initial database connection retry, doubled after each further failed attempt")
+	cmd.PersistentFlags().Int64Var(&databaseConf.ReconnectRetries, "database.reconnect-retries", 0, "number of times a block's DB transaction is re-run after the database connection drops mid-run (e.g. a failover), waiting database.connect-retry-delay before the first retry and doubling the wait after each further failure")
 }
 
 func SetupProbeFlags(probeConf *Probe, cmd *cobra.Command) {
@@ -62,6 +103,18 @@ func SetupProbeFlags(probeConf *Probe, cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&probeConf.AccountPrefix, "probe.account-prefix", "", "probe account prefix")
 	cmd.PersistentFlags().StringVar(&probeConf.ChainID, "probe.chain-id", "", "probe chain ID")
 	cmd.PersistentFlags().StringVar(&probeConf.ChainName, "probe.chain-name", "", "probe chain name")
+	cmd.PersistentFlags().StringVar(&probeConf.HTTPProxy, "probe.http-proxy", "", "proxy URL for http node requests, falls back to the HTTP_PROXY env var")
+	cmd.PersistentFlags().StringVar(&probeConf.HTTPSProxy, "probe.https-proxy", "", "proxy URL for https node requests, falls back to the HTTPS_PROXY env var")
+	cmd.PersistentFlags().StringVar(&probeConf.NoProxy, "probe.no-proxy", "", "comma separated hosts that bypass the proxy, falls back to the NO_PROXY env var")
+	cmd.PersistentFlags().StringVar(&probeConf.CAFile, "probe.ca-file", "", "PEM encoded CA bundle to trust in addition to the system roots for node requests")
+	cmd.PersistentFlags().Int64Var(&probeConf.MaxRetryAfter, "probe.max-retry-after", 60, "the longest Retry-After in seconds of a 429 node response that is waited out before retrying, longer waits return the error (0 disables the wait)")
+}
+
+func SetupSinkFlags(sinkConf *Sink, cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&sinkConf.URL, "sink.url", "", "InfluxDB v2 URL (e.g. http://localhost:8086) per-block aggregate points (tx count, gas used and fees) are written to alongside the database, the sink is disabled when empty")
+	cmd.PersistentFlags().StringVar(&sinkConf.Org, "sink.org", "", "InfluxDB organization of the sink bucket")
+	cmd.PersistentFlags().StringVar(&sinkConf.Bucket, "sink.bucket", "", "InfluxDB bucket the sink writes to")
+	cmd.PersistentFlags().StringVar(&sinkConf.Token, "sink.token", "", "InfluxDB API token with write access to the sink bucket")
 }
 
 func SetupThrottlingFlag(throttlingValue *float64, cmd *cobra.Command) {
@@ -84,6 +137,42 @@ func validateDatabaseConf(dbConf Database) error {
 	if util.StrNotSet(dbConf.Password) {
 		return errors.New("database password must be set")
 	}
+	if dbConf.CommitRetries < 0 {
+		return errors.New("database commit-retries must be a positive number or 0")
+	}
+	if dbConf.ConnectRetries < 0 {
+		return errors.New("database connect-retries must be a positive number or 0")
+	}
+	if dbConf.ConnectRetryDelay < 0 {
+		return errors.New("database connect-retry-delay must be a positive number or 0")
+	}
+	if dbConf.ReconnectRetries < 0 {
+		return errors.New("database reconnect-retries must be a positive number or 0")
+	}
+	if dbConf.MaxConcurrentTxns < 0 {
+		return errors.New("database max-concurrent-txns must be a positive number or 0")
+	}
+	switch dbConf.AnalyzeAfterBackfill {
+	case "", AnalyzeAfterBackfillAnalyze, AnalyzeAfterBackfillVacuum:
+	default:
+		return fmt.Errorf("database analyze-after-backfill must be one of \"%s\" or \"%s\", got \"%s\"", AnalyzeAfterBackfillAnalyze, AnalyzeAfterBackfillVacuum, dbConf.AnalyzeAfterBackfill)
+	}
+
+	return nil
+}
+
+func validateSinkConf(sinkConf Sink) error {
+	if sinkConf.URL == "" {
+		return nil
+	}
+
+	sinkURL, err := url.Parse(sinkConf.URL)
+	if err != nil || (sinkURL.Scheme != "http" && sinkURL.Scheme != "https") || sinkURL.Host == "" {
+		return fmt.Errorf("sink url %s must be an http or https URL", sinkConf.URL)
+	}
+	if util.StrNotSet(sinkConf.Org) || util.StrNotSet(sinkConf.Bucket) {
+		return errors.New("sink org and bucket must be set when the sink url is set")
+	}
 
 	return nil
 }
@@ -110,6 +199,24 @@ func validateProbeConf(probeConf Probe) (Probe, error) {
 	if util.StrNotSet(probeConf.ChainName) {
 		return probeConf, errors.New("probe chain-name must be set")
 	}
+	if probeConf.HTTPProxy != "" {
+		if _, err := url.Parse(probeConf.HTTPProxy); err != nil {
+			return probeConf, fmt.Errorf("probe http-proxy is not a valid URL: %w", err)
+		}
+	}
+	if probeConf.HTTPSProxy != "" {
+		if _, err := url.Parse(probeConf.HTTPSProxy); err != nil {
+			return probeConf, fmt.Errorf("probe https-proxy is not a valid URL: %w", err)
+		}
+	}
+	if probeConf.CAFile != "" {
+		if _, err := os.Stat(probeConf.CAFile); err != nil {
+			return probeConf, fmt.Errorf("probe ca-file could not be read: %w", err)
+		}
+	}
+	if probeConf.MaxRetryAfter < 0 {
+		return probeConf, errors.New("probe max-retry-after must be a positive number or 0")
+	}
 	return probeConf, nil
 }
 
@@ -163,3 +270,9 @@ func addProbeConfigKeys(validKeys map[string]struct{}) {
 		validKeys[key] = struct{}{}
 	}
 }
+
+func addSinkConfigKeys(validKeys map[string]struct{}) {
+	for _, key := range getValidConfigKeys(Sink{}, "") {
+		validKeys[key] = struct{}{}
+	}
+}
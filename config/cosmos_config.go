@@ -1,9 +1,24 @@
 package config
 
 import (
+	"errors"
+	"regexp"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+// Bech32 human-readable parts of Cosmos chains are lower case and start with a letter. The length leaves room for the
+// longest derived prefix (valconspub) within the bech32 limits.
+var bech32PrefixRegex = regexp.MustCompile(`^[a-z][a-z0-9]{0,19}$`)
+
+// ValidateBech32Prefix checks that the prefix is a plausible bech32 account address prefix, e.g. cosmos, osmo or kava
+func ValidateBech32Prefix(prefix string) error {
+	if !bech32PrefixRegex.MatchString(prefix) {
+		return errors.New("prefix must be 1 to 20 lower case letters or digits starting with a letter, e.g. cosmos or osmo")
+	}
+	return nil
+}
+
 func setPrefixes(accountAddressPrefix string) {
 	// Set prefixes
 	accountPubKeyPrefix := accountAddressPrefix + "pub"
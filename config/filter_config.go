@@ -176,8 +176,13 @@ func ParseJSONFilterConfigFromType(filterType string, configJSON []byte) (filter
 			return nil, err
 		}
 
+		pattern := newFilter.EventTypeRegexPattern
+		if newFilter.CaseInsensitive {
+			pattern = "(?i)" + pattern
+		}
+
 		// Reinit the filter so that regex compiles
-		regexFilter, err := filter.NewRegexBlockEventFilter(newFilter.EventTypeRegexPattern, newFilter.Inclusive)
+		regexFilter, err := filter.NewRegexBlockEventFilter(pattern, newFilter.Inclusive)
 		if err != nil {
 			return nil, err
 		}
@@ -74,6 +74,71 @@ func (suite *FilterConfigTestSuite) TestParseJSONFilterConfig() {
 	suite.Require().False(messageTypeFilters[0].MessageTypeMatches(filter.MessageTypeData{MessageType: "dne"}))
 }
 
+func eventOfType(eventType string) filter.EventData {
+	return filter.EventData{
+		Event:      models.BlockEvent{BlockEventType: models.BlockEventType{Type: eventType}},
+		Attributes: []models.BlockEventAttribute{{Value: "uatom", BlockEventAttributeKey: models.BlockEventAttributeKey{Key: "denom"}}},
+	}
+}
+
+func (suite *FilterConfigTestSuite) TestCaseInsensitiveEventTypeFilters() {
+	filters, _, err := ParseLifecycleConfig([]json.RawMessage{
+		json.RawMessage(`{"type": "event_type", "event_type": "Transfer", "case_insensitive": true}`),
+		json.RawMessage(`{"type": "regex_event_type", "event_type_regex": "^Transfer$", "case_insensitive": true}`),
+		json.RawMessage(`{"type": "event_type_and_attribute_value", "event_type": "Transfer", "attribute_key": "denom", "attribute_value": "uatom", "case_insensitive": true}`),
+		json.RawMessage(`{"type": "event_type", "event_type": "Transfer"}`),
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(filters, 4)
+
+	for index, caseInsensitiveFilter := range filters[:3] {
+		for _, eventType := range []string{"Transfer", "transfer", "TRANSFER"} {
+			matches, err := caseInsensitiveFilter.EventMatches(eventOfType(eventType))
+			suite.Require().NoError(err)
+			suite.True(matches, "filter %d should match %s", index, eventType)
+		}
+
+		matches, err := caseInsensitiveFilter.EventMatches(eventOfType("transfers"))
+		suite.Require().NoError(err)
+		suite.False(matches, "filter %d", index)
+	}
+
+	// Filters stay case-sensitive by default
+	matches, err := filters[3].EventMatches(eventOfType("transfer"))
+	suite.Require().NoError(err)
+	suite.False(matches)
+}
+
+func (suite *FilterConfigTestSuite) TestExactEventTypeMatchDoesNotAllocate() {
+	exactFilter := filter.DefaultBlockEventTypeFilter{EventType: "transfer"}
+	event := eventOfType("transfer")
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		_, _ = exactFilter.EventMatches(event)
+	})
+	suite.Zero(allocs)
+}
+
+func BenchmarkExactEventTypeMatch(b *testing.B) {
+	exactFilter := filter.DefaultBlockEventTypeFilter{EventType: "transfer"}
+	event := eventOfType("coin_received")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = exactFilter.EventMatches(event)
+	}
+}
+
+func BenchmarkCaseInsensitiveEventTypeMatch(b *testing.B) {
+	caseInsensitiveFilter := filter.DefaultBlockEventTypeFilter{EventType: "Transfer", CaseInsensitive: true}
+	event := eventOfType("transfer")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = caseInsensitiveFilter.EventMatches(event)
+	}
+}
+
 func getMockEventTypeBytes(skipEventTypeKey bool) (json.RawMessage, error) {
 	mockEventType := make(map[string]any)
 
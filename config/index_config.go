@@ -3,7 +3,10 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -14,32 +17,140 @@ type IndexConfig struct {
 	Log      log
 	Probe    Probe
 	Flags    flags
+	Sink     Sink
 }
 
 type indexBase struct {
 	throttlingBase
 	retryBase
-	ReindexMessageType          string `mapstructure:"reindex-message-type"`
-	ReattemptFailedBlocks       bool   `mapstructure:"reattempt-failed-blocks"`
-	StartBlock                  int64  `mapstructure:"start-block"`
-	EndBlock                    int64  `mapstructure:"end-block"`
-	BlockInputFile              string `mapstructure:"block-input-file"`
-	ReIndex                     bool   `mapstructure:"reindex"`
-	RPCWorkers                  int64  `mapstructure:"rpc-workers"`
-	SkipBlockByHeightRPCRequest bool   `mapstructure:"skip-block-by-height-rpc-request"`
-	BlockTimer                  int64  `mapstructure:"block-timer"`
-	WaitForChain                bool   `mapstructure:"wait-for-chain"`
-	WaitForChainDelay           int64  `mapstructure:"wait-for-chain-delay"`
-	TransactionIndexingEnabled  bool   `mapstructure:"index-transactions"`
-	ExitWhenCaughtUp            bool   `mapstructure:"exit-when-caught-up"`
-	BlockEventIndexingEnabled   bool   `mapstructure:"index-block-events"`
-	FilterFile                  string `mapstructure:"filter-file"`
-	Dry                         bool   `mapstructure:"dry"`
+	ReindexMessageType             string            `mapstructure:"reindex-message-type"`
+	ReattemptFailedBlocks          bool              `mapstructure:"reattempt-failed-blocks"`
+	StartBlock                     int64             `mapstructure:"start-block"`
+	ClampStartToAvailable          bool              `mapstructure:"clamp-start-to-available"`
+	EndBlock                       int64             `mapstructure:"end-block"`
+	BlockInputFile                 string            `mapstructure:"block-input-file"`
+	ReIndex                        bool              `mapstructure:"reindex"`
+	BackgroundReindexStartBlock    int64             `mapstructure:"background-reindex-start-block"`
+	BackgroundReindexEndBlock      int64             `mapstructure:"background-reindex-end-block"`
+	BackgroundReindexWorkers       int64             `mapstructure:"background-reindex-workers"`
+	RPCWorkers                     int64             `mapstructure:"rpc-workers"`
+	RPCBatchSize                   int64             `mapstructure:"rpc-batch-size"`
+	MaxInflightBlocks              int64             `mapstructure:"max-inflight-blocks"`
+	AdaptiveThrottleTargetMs       int64             `mapstructure:"adaptive-throttle-target-ms"`
+	OrderedCommits                 bool              `mapstructure:"ordered-commits"`
+	SkipBlockByHeightRPCRequest    bool              `mapstructure:"skip-block-by-height-rpc-request"`
+	BlockTimer                     int64             `mapstructure:"block-timer"`
+	BlockTimerMode                 string            `mapstructure:"block-timer-mode"`
+	BlockTimerInterval             int64             `mapstructure:"block-timer-interval"`
+	WaitForChain                   bool              `mapstructure:"wait-for-chain"`
+	WaitForChainDelay              int64             `mapstructure:"wait-for-chain-delay"`
+	TransactionIndexingEnabled     bool              `mapstructure:"index-transactions"`
+	ExitWhenCaughtUp               bool              `mapstructure:"exit-when-caught-up"`
+	CatchUpOnly                    bool              `mapstructure:"catch-up-only"`
+	MaxRunDuration                 int64             `mapstructure:"max-run-duration"`
+	BlockEventIndexingEnabled      bool              `mapstructure:"index-block-events"`
+	IndexBeginBlockEvents          bool              `mapstructure:"index-begin-block-events"`
+	IndexEndBlockEvents            bool              `mapstructure:"index-end-block-events"`
+	FilterFile                     string            `mapstructure:"filter-file"`
+	SenderWhitelistFile            string            `mapstructure:"sender-whitelist-file"`
+	SkipHeightsFile                string            `mapstructure:"skip-heights-file"`
+	HeightAnnotationsFile          string            `mapstructure:"height-annotations-file"`
+	UpgradeHeightsFile             string            `mapstructure:"upgrade-heights-file"`
+	Dry                            bool              `mapstructure:"dry"`
+	RowTags                        map[string]string `mapstructure:"row-tags"`
+	BlockTimeout                   int64             `mapstructure:"block-timeout"`
+	IndexAddresses                 bool              `mapstructure:"index-addresses"`
+	IndexGovernance                bool              `mapstructure:"index-governance"`
+	CategoryTables                 bool              `mapstructure:"category-tables"`
+	ResolveExecutionContext        bool              `mapstructure:"resolve-execution-context"`
+	CaptureFailedTxLogs            bool              `mapstructure:"capture-failed-tx-logs"`
+	TolerantBlock                  bool              `mapstructure:"tolerant-block"`
+	MessageSchemaDir               string            `mapstructure:"message-schema-dir"`
+	IndexMessageAction             bool              `mapstructure:"index-message-action"`
+	ActionMapFile                  string            `mapstructure:"action-map-file"`
+	IndexSlashing                  bool              `mapstructure:"index-slashing"`
+	IndexEvidence                  bool              `mapstructure:"index-evidence"`
+	IndexRewards                   bool              `mapstructure:"index-rewards"`
+	IndexNFT                       bool              `mapstructure:"index-nft"`
+	IndexBalanceDeltas             bool              `mapstructure:"index-balance-deltas"`
+	IndexSupplyDeltas              bool              `mapstructure:"index-supply-deltas"`
+	SupplyDeltaDenoms              []string          `mapstructure:"supply-delta-denoms"`
+	IndexModuleBalances            bool              `mapstructure:"index-module-balances"`
+	ModuleBalanceAddresses         []string          `mapstructure:"module-balance-addresses"`
+	ModuleBalanceStride            int64             `mapstructure:"module-balance-stride"`
+	IndexSignerInfo                bool              `mapstructure:"index-signer-info"`
+	IndexSignerCount               bool              `mapstructure:"index-signer-count"`
+	IndexTxPosition                bool              `mapstructure:"index-tx-position"`
+	IndexMempool                   bool              `mapstructure:"index-mempool"`
+	MempoolPollInterval            int64             `mapstructure:"mempool-poll-interval"`
+	RecordSourceEndpoint           bool              `mapstructure:"record-source-endpoint"`
+	DenormalizeBlockTime           bool              `mapstructure:"denormalize-block-time"`
+	IndexBlockGas                  bool              `mapstructure:"index-block-gas"`
+	IndexBlockTxStats              bool              `mapstructure:"index-block-tx-stats"`
+	IndexBlockChecksums            bool              `mapstructure:"index-block-checksums"`
+	Bech32Prefix                   string            `mapstructure:"bech32-prefix"`
+	MaxMemoBytes                   int64             `mapstructure:"max-memo-bytes"`
+	HAMode                         bool              `mapstructure:"ha-mode"`
+	HALeaseInterval                int64             `mapstructure:"ha-lease-interval"`
+	OtelEndpoint                   string            `mapstructure:"otel-endpoint"`
+	ErrorWebhook                   string            `mapstructure:"error-webhook"`
+	Backpressure                   string            `mapstructure:"backpressure"`
+	BackpressureSpillFile          string            `mapstructure:"backpressure-spill-file"`
+	ReIndexMode                    string            `mapstructure:"reindex-mode"`
+	DecodeEventAttributes          string            `mapstructure:"decode-event-attributes"`
+	EventsStorageMode              string            `mapstructure:"events-storage-mode"`
+	DedupeStaticEvents             bool              `mapstructure:"dedupe-static-events"`
+	ArchiveFilteredEvents          bool              `mapstructure:"archive-filtered-events"`
+	ArchiveFilteredEventsRetention int64             `mapstructure:"archive-filtered-events-retention"`
+	MaxSustainedLag                int64             `mapstructure:"max-sustained-lag"`
+	MaxSustainedLagDuration        int64             `mapstructure:"max-sustained-lag-duration"`
+	HeartbeatInterval              int64             `mapstructure:"heartbeat-interval"`
+	ReportPhase                    bool              `mapstructure:"report-phase"`
+	StatusSocket                   string            `mapstructure:"status-socket"`
+	CompletionMarkerFile           string            `mapstructure:"completion-marker-file"`
+	ProfileEvents                  bool              `mapstructure:"profile-events"`
+	ProfileEventsFile              string            `mapstructure:"profile-events-file"`
+	AuditLogFile                   string            `mapstructure:"audit-log-file"`
+	AuditLogFsync                  bool              `mapstructure:"audit-log-fsync"`
+	ShardIndex                     int64             `mapstructure:"shard-index"`
+	ShardCount                     int64             `mapstructure:"shard-count"`
 }
 
+// How already indexed transaction data is handled when a block is reindexed
+const (
+	ReIndexModeUpsert  = "upsert"
+	ReIndexModeReplace = "replace"
+)
+
+// How block event attribute keys and values are decoded before storage
+const (
+	DecodeEventAttributesNever  = "never"
+	DecodeEventAttributesAlways = "always"
+	DecodeEventAttributesAuto   = "auto"
+)
+
+// How the filtered block events are stored
+const (
+	EventsStorageModeNormalized = "normalized"
+	EventsStorageModeJSONB      = "jsonb"
+)
+
+// When the block processing rate is reported
+const (
+	BlockTimerModeCount    = "count"
+	BlockTimerModeInterval = "interval"
+)
+
+// Backpressure policies applied when the DB write queue is full
+const (
+	BackpressureBlock      = "block"
+	BackpressureDropToDisk = "drop-to-disk"
+)
+
 // Flags for specific, deeper indexing behavior
 type flags struct {
 	IndexTxMessageRaw        bool `mapstructure:"index-tx-message-raw"`
+	IndexTxRaw               bool `mapstructure:"index-tx-raw"`
 	IndexEmptyTransactions   bool `mapstructure:"index-empty-transactions"`
 	BlockEventsBase64Encoded bool `mapstructure:"block-events-base64-encoded"`
 	IndexMessageEvents       bool `mapstructure:"index-message-events"`
@@ -49,28 +160,104 @@ func SetupIndexSpecificFlags(conf *IndexConfig, cmd *cobra.Command) {
 	// chain indexing
 	cmd.PersistentFlags().Int64Var(&conf.Base.StartBlock, "base.start-block", 0, "block to start indexing at (use -1 to resume from highest block indexed)")
 	cmd.PersistentFlags().Int64Var(&conf.Base.EndBlock, "base.end-block", -1, "block to stop indexing at (use -1 to index indefinitely")
+	cmd.PersistentFlags().BoolVar(&conf.Base.ClampStartToAvailable, "base.clamp-start-to-available", false, "if the start block is below the earliest height the node serves (pruned nodes), start at the earliest height with a warning instead of exiting with an error")
 	cmd.PersistentFlags().StringVar(&conf.Base.BlockInputFile, "base.block-input-file", "", "A file location containing a JSON list of block heights to index. Will override start and end block flags.")
 	cmd.PersistentFlags().BoolVar(&conf.Base.ReIndex, "base.reindex", false, "if true, this will re-attempt to index blocks we have already indexed (defaults to false)")
+	cmd.PersistentFlags().StringVar(&conf.Base.ReIndexMode, "base.reindex-mode", ReIndexModeUpsert, "how existing block data is handled when a block is reindexed with base.reindex: \"upsert\" updates rows in place, \"replace\" atomically deletes all existing transactions, messages and message events, or block events, of the block and the custom parser rows referencing them before re-inserting")
 	cmd.PersistentFlags().BoolVar(&conf.Base.ReattemptFailedBlocks, "base.reattempt-failed-blocks", false, "re-enqueue failed blocks for reattempts at startup.")
+	cmd.PersistentFlags().Int64Var(&conf.Base.ShardIndex, "base.shard-index", 0, "the shard this indexer handles when sharding heights across indexers, only heights where height % base.shard-count == base.shard-index are indexed")
+	cmd.PersistentFlags().Int64Var(&conf.Base.ShardCount, "base.shard-count", 1, "the total number of indexer shards the chain heights are split across (1 disables sharding)")
 	cmd.PersistentFlags().StringVar(&conf.Base.ReindexMessageType, "base.reindex-message-type", "", "a Cosmos message type URL. When set, the block enqueue method will reindex all blocks between start and end block that contain this message type.")
 	// block event indexing
 	cmd.PersistentFlags().BoolVar(&conf.Base.TransactionIndexingEnabled, "base.index-transactions", false, "enable transaction indexing?")
 	cmd.PersistentFlags().BoolVar(&conf.Base.BlockEventIndexingEnabled, "base.index-block-events", false, "enable block beginblocker and endblocker event indexing?")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexBeginBlockEvents, "base.index-begin-block-events", false, "enable block beginblocker event indexing only, base.index-block-events enables both beginblocker and endblocker events")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexEndBlockEvents, "base.index-end-block-events", false, "enable block endblocker event indexing only, base.index-block-events enables both beginblocker and endblocker events")
+	cmd.PersistentFlags().StringVar(&conf.Base.DecodeEventAttributes, "base.decode-event-attributes", DecodeEventAttributesNever, "how block event attributes are normalized before storage: \"never\" stores them as returned, \"always\" base64 decodes them, \"auto\" detects base64 encoded attributes (older CometBFT versions) per block. Values that are not valid UTF-8 are kept base64 encoded and flagged")
+	cmd.PersistentFlags().StringVar(&conf.Base.EventsStorageMode, "base.events-storage-mode", EventsStorageModeNormalized, "how filtered block events are stored: \"normalized\" stores them in the block event, attribute, type and key tables, \"jsonb\" stores them as a single jsonb document in the events column of the blocks table")
+	cmd.PersistentFlags().BoolVar(&conf.Base.DedupeStaticEvents, "base.dedupe-static-events", false, "store block events that are unchanged from the previous block once, extending a run in the static_block_event_runs table instead of storing a new row for every block")
+	cmd.PersistentFlags().BoolVar(&conf.Base.ArchiveFilteredEvents, "base.archive-filtered-events", false, "store the block events dropped by the block event filters gzip compressed in the archived_block_events table instead of discarding them")
+	cmd.PersistentFlags().Int64Var(&conf.Base.ArchiveFilteredEventsRetention, "base.archive-filtered-events-retention", 100000, "number of blocks below the latest indexed block the archived filtered block events are kept for")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexAddresses, "base.index-addresses", false, "store every address referenced by each message (signers, recipients, validators, etc.) in the message_addresses table for address-centric queries")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexGovernance, "base.index-governance", false, "store v1 and v1beta1 governance proposal submissions, deposits and votes in the governance_messages table, one row per option for weighted votes")
+	cmd.PersistentFlags().BoolVar(&conf.Base.CategoryTables, "base.category-tables", false, "copy each message into a per-category table chosen by the module prefix of its type URL (bank_messages, staking_messages, distribution_messages, gov_messages, ibc_messages, wasm_messages), other_messages for uncategorized types")
+	cmd.PersistentFlags().BoolVar(&conf.Base.ResolveExecutionContext, "base.resolve-execution-context", false, "store the chain of wrapping around each message (authz executions, interchain account host txs, ibc-hooks contract calls) in the message_execution_contexts table to attribute actions to their ultimate initiator")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexSlashing, "base.index-slashing", false, "store the slash, jail and liveness block events of validators in the slashing_events table, correlated to the validator operator address (requires base.index-block-events)")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexEvidence, "base.index-evidence", false, "store the duplicate vote (double-sign) and light client attack evidence committed in blocks in the block_evidences table, with the offending validator and infraction height")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexRewards, "base.index-rewards", false, "store distribution module reward and commission withdrawals (from messages) and allocations (from block events) in the reward_events table, one row per denom")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexNFT, "base.index-nft", false, "store NFT mints, transfers and burns of the cosmos nft module and of cw721 contracts (from wasm events) in the nft_activities table with the class ID, token ID and owners")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexBalanceDeltas, "base.index-balance-deltas", false, "store bank module coin_spent and coin_received events (from messages and block events) as signed per address and denom amounts in the balance_deltas table, one row per coin")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexSupplyDeltas, "base.index-supply-deltas", false, "store the supply changes of bank module coinbase (mint) and burn events (from messages and block events) per denom and block in the supply_deltas table")
+	cmd.PersistentFlags().StringSliceVar(&conf.Base.SupplyDeltaDenoms, "base.supply-delta-denoms", nil, "comma separated denoms tracked by base.index-supply-deltas (default all denoms)")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexModuleBalances, "base.index-module-balances", false, "query the bank balances of the base.module-balance-addresses accounts every base.module-balance-stride blocks and store them in the module_balances table, one row per denom (requires base.index-block-events)")
+	cmd.PersistentFlags().StringSliceVar(&conf.Base.ModuleBalanceAddresses, "base.module-balance-addresses", nil, "comma separated bech32 account addresses (e.g. the community pool or fee collector module accounts) snapshotted by base.index-module-balances")
+	cmd.PersistentFlags().Int64Var(&conf.Base.ModuleBalanceStride, "base.module-balance-stride", 100, "balances are snapshotted at heights that are a multiple of this many blocks")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexSignerInfo, "base.index-signer-info", false, "store the public key, sequence and sign mode of each transaction signer in the tx_signer_infos table, multisig signers are expanded to one row per member key")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexSignerCount, "base.index-signer-count", false, "store the number of signatures of each transaction in the num_signers column of the txes table, counting each multisig member that signed")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexTxPosition, "base.index-tx-position", false, "store the position of each transaction in its block's transaction list in the tx_index column of the txes table")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexMempool, "base.index-mempool", false, "poll the node's unconfirmed txs and store each pending tx with the time it was first seen in the mempool_txs table, the committed_height column is set once a block including the tx is indexed (best-effort, depends on the node's mempool)")
+	cmd.PersistentFlags().Int64Var(&conf.Base.MempoolPollInterval, "base.mempool-poll-interval", 1, "seconds between base.index-mempool polls of the node's unconfirmed txs")
+	cmd.PersistentFlags().BoolVar(&conf.Base.HAMode, "base.ha-mode", false, "warm-standby mode, instances indexing the same chain into the same database elect a single leader through a Postgres advisory lock and only the leader indexes")
+	cmd.PersistentFlags().Int64Var(&conf.Base.HALeaseInterval, "base.ha-lease-interval", 5, "seconds between HA leader lease checks and follower attempts to take over the leader lock")
+	cmd.PersistentFlags().StringVar(&conf.Base.OtelEndpoint, "base.otel-endpoint", "", "OTLP/HTTP collector URL (e.g. http://localhost:4318) to export traces of the block fetch, decode and commit stages to, tracing is disabled when empty")
+	cmd.PersistentFlags().StringVar(&conf.Base.ErrorWebhook, "base.error-webhook", "", "URL that receives a best-effort JSON POST (chain_id, height, category, message, attempt, time) whenever a block is marked as failed, delivery never holds up indexing")
+	cmd.PersistentFlags().Int64Var(&conf.Base.MaxMemoBytes, "base.max-memo-bytes", 0, "the maximum number of bytes of a transaction memo that are stored, longer memos are truncated (0 stores the full memo)")
+	cmd.PersistentFlags().StringVar(&conf.Base.Bech32Prefix, "base.bech32-prefix", "", "bech32 account address prefix used to format indexed addresses (e.g. osmo), the validator and consensus prefixes are derived from it, defaults to probe.account-prefix")
+	cmd.PersistentFlags().BoolVar(&conf.Base.RecordSourceEndpoint, "base.record-source-endpoint", false, "store the RPC endpoint each block was fetched from in the source_endpoint column of the blocks table, credentials in the endpoint URL are removed")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexBlockGas, "base.index-block-gas", false, "store the total gas used by the txs of each block and the block max gas consensus param in the gas_used and max_gas columns of the blocks table")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexBlockTxStats, "base.index-block-tx-stats", false, "store the number of txs of each block and of its failed txs in the tx_count and failed_tx_count columns of the blocks table, for tx success rates")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexBlockChecksums, "base.index-block-checksums", false, "store a deterministic SHA-256 checksum of the indexed txs and events of each block in the checksum column of the blocks table, checked by the verify-checksums command")
+	cmd.PersistentFlags().BoolVar(&conf.Base.DenormalizeBlockTime, "base.denormalize-block-time", false, "copy the block timestamp into the indexed block_time column of each transaction row, so transactions can be queried by time without joining the blocks table")
+	cmd.PersistentFlags().BoolVar(&conf.Base.CaptureFailedTxLogs, "base.capture-failed-tx-logs", false, "store the code, codespace and raw log of every failed tx in the failed_tx_logs table, even when the failed tx itself is not indexed")
+	cmd.PersistentFlags().BoolVar(&conf.Base.TolerantBlock, "base.tolerant-block", false, "commit a block with the txs that decoded when some of its txs fail to decode, recording the undecodable txs in the tx_decode_failures table and flagging the block partial, instead of failing the whole block")
+	cmd.PersistentFlags().StringVar(&conf.Base.MessageSchemaDir, "base.message-schema-dir", "", "path to a directory of JSON schema files named after message type URLs (e.g. cosmos.bank.v1beta1.MsgSend.json), decoded messages that do not conform are logged and flagged with the violation in messages.schema_error")
+	cmd.PersistentFlags().BoolVar(&conf.Base.IndexMessageAction, "base.index-message-action", false, "store a human readable action label (e.g. send, delegate, vote) for each message in the indexed action column of the messages table, unmapped type URLs fall back to their last segment (e.g. MsgSwapExactAmountIn)")
+	cmd.PersistentFlags().StringVar(&conf.Base.ActionMapFile, "base.action-map-file", "", "path to a JSON object of message type URLs to action labels that extends and overrides the built-in labels of base.index-message-action (e.g. {\"/osmosis.gamm.v1beta1.MsgSwapExactAmountIn\": \"swap\"})")
 	// filter configs
 	cmd.PersistentFlags().StringVar(&conf.Base.FilterFile, "base.filter-file", "", "path to a file containing a JSON config of block event and message type filters to apply to beginblocker events, endblocker events and TX messages")
+	cmd.PersistentFlags().StringVar(&conf.Base.HeightAnnotationsFile, "base.height-annotations-file", "", "path to a JSON list of inclusive height ranges and tags stored in the annotations column of the blocks in the range, tags of overlapping ranges are merged (e.g. [{\"from\": 1200000, \"to\": 1200100, \"tags\": {\"upgrade\": \"v2\"}}])")
+	cmd.PersistentFlags().StringVar(&conf.Base.SkipHeightsFile, "base.skip-heights-file", "", "path to a JSON list of block heights that are never indexed, they are recorded in the skipped_blocks table with the reason \"manual\" instead")
+	cmd.PersistentFlags().StringVar(&conf.Base.SenderWhitelistFile, "base.sender-whitelist-file", "", "path to a JSON list of bech32 addresses, transactions without a whitelisted signer are skipped (applied together with the message type filters)")
+	cmd.PersistentFlags().StringVar(&conf.Base.UpgradeHeightsFile, "base.upgrade-heights-file", "", "path to a JSON list of chain upgrade heights and the registered codec variant to decode blocks with from that height on (e.g. [{\"height\": 1200000, \"variant\": \"v2\"}])")
 	// other base setting
 	cmd.PersistentFlags().BoolVar(&conf.Base.Dry, "base.dry", false, "index the chain but don't insert data in the DB.")
+	cmd.PersistentFlags().StringToStringVar(&conf.Base.RowTags, "base.row-tags", nil, "a set of key=value tags stored on every indexed block and transaction row, useful for distinguishing datasets (e.g. env=testnet) in a shared database.")
 	cmd.PersistentFlags().Int64Var(&conf.Base.RPCWorkers, "base.rpc-workers", 1, "the number of concurrent RPC request workers to spin up.")
+	cmd.PersistentFlags().Int64Var(&conf.Base.BackgroundReindexStartBlock, "base.background-reindex-start-block", 0, "first block of a historical range re-indexed in replace mode by a separate worker pool while the main pipeline keeps indexing (0 disables the background re-index)")
+	cmd.PersistentFlags().Int64Var(&conf.Base.BackgroundReindexEndBlock, "base.background-reindex-end-block", 0, "last block of the background re-index range, inclusive")
+	cmd.PersistentFlags().Int64Var(&conf.Base.BackgroundReindexWorkers, "base.background-reindex-workers", 1, "the number of RPC workers fetching the blocks of the background re-index range")
+	cmd.PersistentFlags().Int64Var(&conf.Base.RPCBatchSize, "base.rpc-batch-size", 1, "the number of already enqueued blocks each RPC worker fetches the block and block results of in a single JSON-RPC batch request, falls back to individual requests if the endpoint rejects batches (1 disables batching)")
+	cmd.PersistentFlags().Int64Var(&conf.Base.AdaptiveThrottleTargetMs, "base.adaptive-throttle-target-ms", 0, "the target DB commit latency in milliseconds, while the recent commit latency is above it the RPC workers wait a growing delay before fetching each block (0 disables adaptive throttling)")
+	cmd.PersistentFlags().Int64Var(&conf.Base.MaxInflightBlocks, "base.max-inflight-blocks", 0, "the maximum number of blocks held in memory across all pipeline stages (RPC fetch, processing and the DB write queue), new blocks are not fetched until there is room (0 disables the limit)")
+	cmd.PersistentFlags().BoolVar(&conf.Base.OrderedCommits, "base.ordered-commits", false, "commit blocks to the DB in the order they were enqueued (ascending height) even though they are fetched in parallel, trades some throughput for a monotonic write stream")
 	cmd.PersistentFlags().BoolVar(&conf.Base.SkipBlockByHeightRPCRequest, "base.skip-block-by-height-rpc-request", false, "skip the /block?height=<height> RPC request and only attempt the /block_results RPC request. Sometimes pruned nodes will not have return results for the block RPC request, but still return results for the block_result request.")
 	cmd.PersistentFlags().BoolVar(&conf.Base.WaitForChain, "base.wait-for-chain", false, "wait for chain to be in sync?")
 	cmd.PersistentFlags().Int64Var(&conf.Base.WaitForChainDelay, "base.wait-for-chain-delay", 10, "seconds to wait between each check for node to catch up to the chain")
+	cmd.PersistentFlags().StringVar(&conf.Base.Backpressure, "base.backpressure", BackpressureBlock, "policy when the DB write queue is full: \"block\" pauses fetching until the DB catches up, \"drop-to-disk\" drops the block and records only its height in the spill file, the block is re-fetched from the node when it is re-indexed later")
+	cmd.PersistentFlags().StringVar(&conf.Base.BackpressureSpillFile, "base.backpressure-spill-file", "spilled-blocks.json", "file the drop-to-disk backpressure policy appends dropped block heights to, heights spilled by previous runs are kept, usable as a base.block-input-file")
+	cmd.PersistentFlags().Int64Var(&conf.Base.BlockTimeout, "base.block-timeout", 0, "seconds a single block may spend being parsed and written to the DB, not counting the DB queue wait, before it is abandoned, marked as failed and enqueued once more (0 disables the timeout)")
 	cmd.PersistentFlags().Int64Var(&conf.Base.BlockTimer, "base.block-timer", 10000, "print out how long it takes to process this many blocks")
+	cmd.PersistentFlags().StringVar(&conf.Base.BlockTimerMode, "base.block-timer-mode", BlockTimerModeCount, "when the block processing rate is printed: \"count\" every base.block-timer blocks, \"interval\" every base.block-timer-interval seconds regardless of the number of blocks")
+	cmd.PersistentFlags().Int64Var(&conf.Base.BlockTimerInterval, "base.block-timer-interval", 60, "seconds between block processing rate reports in the interval block timer mode")
 	cmd.PersistentFlags().BoolVar(&conf.Base.ExitWhenCaughtUp, "base.exit-when-caught-up", false, "Gets the latest block at runtime and exits when this block has been reached.")
+	cmd.PersistentFlags().BoolVar(&conf.Base.CatchUpOnly, "base.catch-up-only", false, "gets the latest block once at startup and exits once it has been indexed, blocks produced during the run are not indexed. Takes precedence over base.end-block when the tip is lower")
+	cmd.PersistentFlags().Int64Var(&conf.Base.MaxRunDuration, "base.max-run-duration", 0, "seconds after which the indexer stops enqueuing blocks, finishes the blocks in flight and exits successfully regardless of progress (0 disables the limit)")
+	cmd.PersistentFlags().Int64Var(&conf.Base.MaxSustainedLag, "base.max-sustained-lag", 0, "exit with an error if the indexer falls more than this many blocks behind the chain tip for longer than base.max-sustained-lag-duration, only armed once the indexer has caught up (0 disables the check)")
+	cmd.PersistentFlags().Int64Var(&conf.Base.MaxSustainedLagDuration, "base.max-sustained-lag-duration", 300, "seconds the lag must stay above base.max-sustained-lag before exiting")
+	cmd.PersistentFlags().StringVar(&conf.Base.CompletionMarkerFile, "base.completion-marker-file", "", "path of a JSON file with the final height and row counts written once a bounded run completes without failed blocks, removed when the run starts")
+	cmd.PersistentFlags().BoolVar(&conf.Base.ProfileEvents, "base.profile-events", false, "tally the block and message events of a bounded run by type (frequency, blocks seen in, attribute count histogram) and write the report to base.profile-events-file instead of storing the events")
+	cmd.PersistentFlags().StringVar(&conf.Base.ProfileEventsFile, "base.profile-events-file", "event-profile.json", "path of the JSON event profile report written at the end of a base.profile-events run")
+	cmd.PersistentFlags().StringVar(&conf.Base.AuditLogFile, "base.audit-log-file", "", "path of an append-only audit log, separate from the main log, receiving a JSON line with a correlation ID, the height and the rows written per table for every committed block transaction")
+	cmd.PersistentFlags().BoolVar(&conf.Base.AuditLogFsync, "base.audit-log-fsync", false, "sync the audit log file to disk after every record, so no record of a committed transaction is lost on a crash")
+	cmd.PersistentFlags().Int64Var(&conf.Base.HeartbeatInterval, "base.heartbeat-interval", 0, "seconds between heartbeat log lines reporting the indexed height and lag, emitted even when idle and suppressed while catching up (0 disables the heartbeat)")
+	cmd.PersistentFlags().BoolVar(&conf.Base.ReportPhase, "base.report-phase", false, "add the indexer phase (backfilling up to the chain tip at startup, tailing after reaching it, or idle) as a phase field to every log line and a phase tag to the sink points")
+	cmd.PersistentFlags().StringVar(&conf.Base.StatusSocket, "base.status-socket", "", "path of a Unix domain socket answering every connection with a single status line of the indexed height, lag and phase (with base.report-phase) as key=value fields, disabled when empty")
 	cmd.PersistentFlags().Int64Var(&conf.Base.RequestRetryAttempts, "base.request-retry-attempts", 0, "number of RPC query retries to make")
 	cmd.PersistentFlags().Uint64Var(&conf.Base.RequestRetryMaxWait, "base.request-retry-max-wait", 30, "max retry incremental backoff wait time in seconds")
 
 	// flags
 	cmd.PersistentFlags().BoolVar(&conf.Flags.IndexTxMessageRaw, "flags.index-tx-message-raw", false, "if true, this will index the raw message bytes. This will significantly increase the size of the database.")
+	cmd.PersistentFlags().BoolVar(&conf.Flags.IndexTxRaw, "flags.index-tx-raw", false, "if true, this will index the raw bytes of each transaction as included in the block in a separate table. This will significantly increase the size of the database.")
 	cmd.PersistentFlags().BoolVar(&conf.Flags.IndexEmptyTransactions, "flags.index-empty-transactions", true, "if true, this will index transactions that have no messages. Setting this to false when filtering TX message types will result in no transactions being indexed if all message types are filtered out.")
 	cmd.PersistentFlags().BoolVar(&conf.Flags.BlockEventsBase64Encoded, "flags.block-events-base64-encoded", false, "if true, decode the block event attributes and keys as base64. Some versions of CometBFT encode the block event attributes and keys as base64 in the response from RPC.")
 	cmd.PersistentFlags().BoolVar(&conf.Flags.IndexMessageEvents, "flags.index-message-events", true, "if true, skip indexing message events if they are uneeded. This will save space in the database.")
@@ -96,6 +283,11 @@ func (conf *IndexConfig) Validate() error {
 		return err
 	}
 
+	err = validateSinkConf(conf.Sink)
+	if err != nil {
+		return err
+	}
+
 	err = conf.validateBlockInputValues()
 
 	if err != nil {
@@ -115,12 +307,261 @@ func (conf *IndexConfig) Validate() error {
 		}
 	}
 
+	if conf.Base.UpgradeHeightsFile != "" {
+		if _, err := os.Stat(conf.Base.UpgradeHeightsFile); os.IsNotExist(err) {
+			return fmt.Errorf("base.upgrade-heights-file %s does not exist", conf.Base.UpgradeHeightsFile)
+		}
+	}
+
+	if conf.Base.SenderWhitelistFile != "" {
+		if _, err := os.Stat(conf.Base.SenderWhitelistFile); os.IsNotExist(err) {
+			return fmt.Errorf("base.sender-whitelist-file %s does not exist", conf.Base.SenderWhitelistFile)
+		}
+	}
+
+	if conf.Base.HeightAnnotationsFile != "" {
+		if _, err := os.Stat(conf.Base.HeightAnnotationsFile); os.IsNotExist(err) {
+			return fmt.Errorf("base.height-annotations-file %s does not exist", conf.Base.HeightAnnotationsFile)
+		}
+	}
+
+	if conf.Base.SkipHeightsFile != "" {
+		if _, err := os.Stat(conf.Base.SkipHeightsFile); os.IsNotExist(err) {
+			return fmt.Errorf("base.skip-heights-file %s does not exist", conf.Base.SkipHeightsFile)
+		}
+	}
+
+	if conf.Base.ActionMapFile != "" {
+		if !conf.Base.IndexMessageAction {
+			return errors.New("base.action-map-file requires base.index-message-action")
+		}
+		if _, err := os.Stat(conf.Base.ActionMapFile); os.IsNotExist(err) {
+			return fmt.Errorf("base.action-map-file %s does not exist", conf.Base.ActionMapFile)
+		}
+	}
+
+	if conf.Base.Bech32Prefix != "" {
+		if err := ValidateBech32Prefix(conf.Base.Bech32Prefix); err != nil {
+			return fmt.Errorf("base.bech32-prefix: %w", err)
+		}
+	}
+
+	if conf.Base.IndexSlashing && !conf.IndexesBlockEvents() {
+		return errors.New("base.index-slashing requires base.index-block-events")
+	}
+
+	if conf.Base.IndexModuleBalances {
+		if !conf.IndexesBlockEvents() {
+			return errors.New("base.index-module-balances requires base.index-block-events")
+		}
+
+		if len(conf.Base.ModuleBalanceAddresses) == 0 {
+			return errors.New("base.module-balance-addresses must be set when base.index-module-balances is enabled")
+		}
+
+		if conf.Base.ModuleBalanceStride <= 0 {
+			return errors.New("base.module-balance-stride must be a positive number")
+		}
+	}
+
+	err = validateRowTags(conf.Base.RowTags)
+	if err != nil {
+		return err
+	}
+
+	if conf.Base.BlockTimeout < 0 {
+		return errors.New("base.block-timeout must be a positive number or 0")
+	}
+
+	if conf.Base.MaxSustainedLag < 0 {
+		return errors.New("base.max-sustained-lag must be a positive number or 0")
+	}
+
+	if conf.Base.MaxSustainedLag > 0 && conf.Base.MaxSustainedLagDuration <= 0 {
+		return errors.New("base.max-sustained-lag-duration must be a positive number when base.max-sustained-lag is set")
+	}
+
+	if conf.Base.AdaptiveThrottleTargetMs < 0 {
+		return errors.New("base.adaptive-throttle-target-ms must be a positive number or 0")
+	}
+
+	if conf.Base.MaxInflightBlocks < 0 {
+		return errors.New("base.max-inflight-blocks must be a positive number or 0")
+	}
+
+	if conf.Base.ErrorWebhook != "" {
+		webhookURL, err := url.Parse(conf.Base.ErrorWebhook)
+		if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+			return fmt.Errorf("base.error-webhook %s must be an http or https URL", conf.Base.ErrorWebhook)
+		}
+	}
+
+	if conf.Base.HAMode && conf.Base.HALeaseInterval <= 0 {
+		return errors.New("base.ha-lease-interval must be a positive number")
+	}
+
+	if conf.Base.MaxMemoBytes < 0 {
+		return errors.New("base.max-memo-bytes must be a positive number or 0")
+	}
+
+	if conf.Base.MaxRunDuration < 0 {
+		return errors.New("base.max-run-duration must be a positive number or 0")
+	}
+
+	if conf.Base.BackgroundReindexStartBlock < 0 {
+		return errors.New("base.background-reindex-start-block must be a positive number or 0")
+	}
+
+	if conf.Base.BackgroundReindexStartBlock > 0 {
+		if conf.Base.BackgroundReindexEndBlock < conf.Base.BackgroundReindexStartBlock {
+			return fmt.Errorf("base.background-reindex-end-block must be at or after base.background-reindex-start-block (%d), got %d", conf.Base.BackgroundReindexStartBlock, conf.Base.BackgroundReindexEndBlock)
+		}
+		if conf.Base.BackgroundReindexWorkers <= 0 {
+			return errors.New("base.background-reindex-workers must be a positive number")
+		}
+		// Background blocks are written alongside the main pipeline, they would break the monotonic write stream of ordered commits
+		if conf.Base.OrderedCommits {
+			return errors.New("base.background-reindex-start-block cannot be used with base.ordered-commits")
+		}
+	}
+
+	if conf.Base.RPCBatchSize < 0 {
+		return errors.New("base.rpc-batch-size must not be negative (0 disables batching)")
+	}
+
+	if conf.Base.AuditLogFsync && conf.Base.AuditLogFile == "" {
+		return errors.New("base.audit-log-fsync requires base.audit-log-file")
+	}
+
+	if conf.Base.ProfileEvents && conf.Base.ProfileEventsFile == "" {
+		return errors.New("base.profile-events-file must be set with base.profile-events")
+	}
+
+	if conf.Base.IndexMempool && conf.Base.MempoolPollInterval <= 0 {
+		return errors.New("base.mempool-poll-interval must be a positive number")
+	}
+
+	if conf.Base.HeartbeatInterval < 0 {
+		return errors.New("base.heartbeat-interval must be a positive number or 0")
+	}
+
+	if conf.Base.ShardCount == 0 {
+		conf.Base.ShardCount = 1
+	}
+
+	if conf.Base.ShardCount < 0 {
+		return errors.New("base.shard-count must be a positive number")
+	}
+
+	if conf.Base.ShardIndex < 0 || conf.Base.ShardIndex >= conf.Base.ShardCount {
+		return fmt.Errorf("base.shard-index must be between 0 and base.shard-count - 1 (%d), got %d", conf.Base.ShardCount-1, conf.Base.ShardIndex)
+	}
+
+	switch conf.Base.ReIndexMode {
+	case "":
+		conf.Base.ReIndexMode = ReIndexModeUpsert
+	case ReIndexModeUpsert, ReIndexModeReplace:
+	default:
+		return fmt.Errorf("base.reindex-mode must be one of %s or %s, got %s", ReIndexModeUpsert, ReIndexModeReplace, conf.Base.ReIndexMode)
+	}
+
+	switch conf.Base.DecodeEventAttributes {
+	case "":
+		conf.Base.DecodeEventAttributes = DecodeEventAttributesNever
+	case DecodeEventAttributesNever, DecodeEventAttributesAlways, DecodeEventAttributesAuto:
+	default:
+		return fmt.Errorf("base.decode-event-attributes must be one of %s, %s or %s, got %s", DecodeEventAttributesNever, DecodeEventAttributesAlways, DecodeEventAttributesAuto, conf.Base.DecodeEventAttributes)
+	}
+
+	switch conf.Base.EventsStorageMode {
+	case "":
+		conf.Base.EventsStorageMode = EventsStorageModeNormalized
+	case EventsStorageModeNormalized, EventsStorageModeJSONB:
+	default:
+		return fmt.Errorf("base.events-storage-mode must be one of %s or %s, got %s", EventsStorageModeNormalized, EventsStorageModeJSONB, conf.Base.EventsStorageMode)
+	}
+
+	if conf.Base.DedupeStaticEvents && conf.Base.EventsStorageMode != EventsStorageModeNormalized {
+		return fmt.Errorf("base.dedupe-static-events requires base.events-storage-mode %s", EventsStorageModeNormalized)
+	}
+
+	if conf.Base.ArchiveFilteredEvents && conf.Base.ArchiveFilteredEventsRetention <= 0 {
+		return errors.New("base.archive-filtered-events-retention must be a positive number")
+	}
+
+	switch conf.Base.BlockTimerMode {
+	case "":
+		conf.Base.BlockTimerMode = BlockTimerModeCount
+	case BlockTimerModeCount:
+	case BlockTimerModeInterval:
+		if conf.Base.BlockTimerInterval <= 0 {
+			return errors.New("base.block-timer-interval must be a positive number in the interval block timer mode")
+		}
+	default:
+		return fmt.Errorf("base.block-timer-mode must be one of %s or %s, got %s", BlockTimerModeCount, BlockTimerModeInterval, conf.Base.BlockTimerMode)
+	}
+
+	switch conf.Base.Backpressure {
+	case "":
+		conf.Base.Backpressure = BackpressureBlock
+	case BackpressureBlock:
+	case BackpressureDropToDisk:
+		if conf.Base.BackpressureSpillFile == "" {
+			return errors.New("base.backpressure-spill-file must be set when using the drop-to-disk backpressure policy")
+		}
+	default:
+		return fmt.Errorf("base.backpressure must be one of %s or %s, got %s", BackpressureBlock, BackpressureDropToDisk, conf.Base.Backpressure)
+	}
+
+	return nil
+}
+
+var rowTagKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validateRowTags(rowTags map[string]string) error {
+	for key := range rowTags {
+		if !rowTagKeyRegex.MatchString(key) {
+			return fmt.Errorf("base.row-tags key \"%s\" is not a valid identifier", key)
+		}
+	}
 	return nil
 }
 
+// AddressPrefix returns the bech32 account address prefix indexed addresses are formatted with, base.bech32-prefix if set
+// and probe.account-prefix otherwise
+func (conf *IndexConfig) AddressPrefix() string {
+	if conf.Base.Bech32Prefix != "" {
+		return conf.Base.Bech32Prefix
+	}
+	return conf.Probe.AccountPrefix
+}
+
+// InShard reports whether the height belongs to the shard this indexer handles. Always true when sharding is disabled.
+func (conf *IndexConfig) InShard(height int64) bool {
+	if conf.Base.ShardCount <= 1 {
+		return true
+	}
+	return height%conf.Base.ShardCount == conf.Base.ShardIndex
+}
+
+// IndexesBlockEvents reports whether block events are requested from the RPC, which they are when either lifecycle is indexed
+func (conf *IndexConfig) IndexesBlockEvents() bool {
+	return conf.IndexesBeginBlockEvents() || conf.IndexesEndBlockEvents()
+}
+
+// IndexesBeginBlockEvents reports whether BeginBlock events are stored, set by base.index-block-events or base.index-begin-block-events
+func (conf *IndexConfig) IndexesBeginBlockEvents() bool {
+	return conf.Base.BlockEventIndexingEnabled || conf.Base.IndexBeginBlockEvents
+}
+
+// IndexesEndBlockEvents reports whether EndBlock events are stored, set by base.index-block-events or base.index-end-block-events
+func (conf *IndexConfig) IndexesEndBlockEvents() bool {
+	return conf.Base.BlockEventIndexingEnabled || conf.Base.IndexEndBlockEvents
+}
+
 func (conf *IndexConfig) validateBlockInputValues() error {
-	if !conf.Base.TransactionIndexingEnabled && !conf.Base.BlockEventIndexingEnabled {
-		return errors.New("must enable at least one of base.index-transactions or base.index-block-events")
+	if !conf.Base.TransactionIndexingEnabled && !conf.IndexesBlockEvents() {
+		return errors.New("must enable at least one of base.index-transactions, base.index-block-events, base.index-begin-block-events or base.index-end-block-events")
 	}
 
 	if conf.Base.BlockInputFile != "" {
@@ -152,6 +593,7 @@ func CheckSuperfluousIndexKeys(keys []string) []string {
 	addDatabaseConfigKeys(validKeys)
 	addLogConfigKeys(validKeys)
 	addProbeConfigKeys(validKeys)
+	addSinkConfigKeys(validKeys)
 
 	// add base keys
 	for _, key := range getValidConfigKeys(indexBase{}, "base") {
@@ -173,10 +615,20 @@ func CheckSuperfluousIndexKeys(keys []string) []string {
 	// Check keys
 	ignoredKeys := make([]string, 0)
 	for _, key := range keys {
-		if _, ok := validKeys[key]; !ok {
+		if _, ok := validKeys[key]; !ok && !isMapConfigKey(key, validKeys) {
 			ignoredKeys = append(ignoredKeys, key)
 		}
 	}
 
 	return ignoredKeys
 }
+
+// Viper flattens map values (such as base.row-tags) into one key per map entry, so a key is also valid if its parent key is valid
+func isMapConfigKey(key string, validKeys map[string]struct{}) bool {
+	lastSeparator := strings.LastIndex(key, ".")
+	if lastSeparator == -1 {
+		return false
+	}
+	_, ok := validKeys[key[:lastSeparator]]
+	return ok
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"strings"
 	"testing"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -52,6 +54,31 @@ func (suite *IndexConfigTestSuite) TestIndexConfig() {
 	conf.Base.EndBlock = 2
 	err = conf.Validate()
 	suite.Require().NoError(err)
+
+	conf.Base.ShardCount = 3
+	conf.Base.ShardIndex = 3
+	err = conf.Validate()
+	suite.Require().Error(err)
+
+	conf.Base.ShardIndex = -1
+	err = conf.Validate()
+	suite.Require().Error(err)
+
+	conf.Base.ShardIndex = 2
+	err = conf.Validate()
+	suite.Require().NoError(err)
+	suite.True(conf.InShard(5))
+	suite.False(conf.InShard(6))
+
+	conf.Base.BackgroundReindexStartBlock = 1
+	conf.Base.BackgroundReindexEndBlock = 2
+	conf.Base.BackgroundReindexWorkers = 1
+	err = conf.Validate()
+	suite.Require().NoError(err)
+
+	conf.Base.OrderedCommits = true
+	err = conf.Validate()
+	suite.Require().Error(err)
 }
 
 func (suite *IndexConfigTestSuite) TestCheckSuperfluousIndexKeys() {
@@ -67,6 +94,77 @@ func (suite *IndexConfigTestSuite) TestCheckSuperfluousIndexKeys() {
 	suite.Require().Len(validKeys, 1)
 }
 
+func (suite *IndexConfigTestSuite) TestValidateRowTags() {
+	err := validateRowTags(map[string]string{"env": "testnet", "_network_2": "cosmos"})
+	suite.Require().NoError(err)
+
+	err = validateRowTags(map[string]string{"bad-key": "testnet"})
+	suite.Require().Error(err)
+
+	err = validateRowTags(map[string]string{"1env": "testnet"})
+	suite.Require().Error(err)
+
+	ignoredKeys := CheckSuperfluousIndexKeys([]string{"base.row-tags.env"})
+	suite.Require().Len(ignoredKeys, 0)
+}
+
+func (suite *IndexConfigTestSuite) TestBlockEventLifecycleToggles() {
+	cases := []struct {
+		combined, begin, end        bool
+		wantBegin, wantEnd, wantAny bool
+	}{
+		{combined: false, begin: false, end: false, wantBegin: false, wantEnd: false, wantAny: false},
+		{combined: true, begin: false, end: false, wantBegin: true, wantEnd: true, wantAny: true},
+		{combined: false, begin: true, end: false, wantBegin: true, wantEnd: false, wantAny: true},
+		{combined: false, begin: false, end: true, wantBegin: false, wantEnd: true, wantAny: true},
+		{combined: false, begin: true, end: true, wantBegin: true, wantEnd: true, wantAny: true},
+		{combined: true, begin: true, end: false, wantBegin: true, wantEnd: true, wantAny: true},
+	}
+
+	for _, tc := range cases {
+		conf := IndexConfig{}
+		conf.Base.StartBlock = 1
+		conf.Base.EndBlock = 2
+		conf.Base.BlockEventIndexingEnabled = tc.combined
+		conf.Base.IndexBeginBlockEvents = tc.begin
+		conf.Base.IndexEndBlockEvents = tc.end
+
+		suite.Equal(tc.wantBegin, conf.IndexesBeginBlockEvents(), "%+v", tc)
+		suite.Equal(tc.wantEnd, conf.IndexesEndBlockEvents(), "%+v", tc)
+		suite.Equal(tc.wantAny, conf.IndexesBlockEvents(), "%+v", tc)
+
+		// With transaction indexing off, at least one block event lifecycle must remain enabled
+		err := conf.validateBlockInputValues()
+		if tc.wantAny {
+			suite.NoError(err, "%+v", tc)
+		} else {
+			suite.Error(err, "%+v", tc)
+		}
+	}
+}
+
+func (suite *IndexConfigTestSuite) TestBech32Prefix() {
+	conf := IndexConfig{}
+	conf.Probe.AccountPrefix = "cosmos"
+	suite.Equal("cosmos", conf.AddressPrefix())
+
+	conf.Base.Bech32Prefix = "osmo"
+	suite.Equal("osmo", conf.AddressPrefix())
+
+	suite.NoError(ValidateBech32Prefix("kava"))
+	suite.Error(ValidateBech32Prefix(""))
+	suite.Error(ValidateBech32Prefix("Osmo"))
+	suite.Error(ValidateBech32Prefix("1osmo"))
+	suite.Error(ValidateBech32Prefix("osmo-test"))
+
+	// The SDK config is global and sealed once set, so this is the only test that sets it
+	SetChainConfig(conf.AddressPrefix())
+	addressBytes := []byte("address_bytes_______")
+	suite.True(strings.HasPrefix(sdk.AccAddress(addressBytes).String(), "osmo1"))
+	suite.True(strings.HasPrefix(sdk.ValAddress(addressBytes).String(), "osmovaloper1"))
+	suite.True(strings.HasPrefix(sdk.ConsAddress(addressBytes).String(), "osmovalcons1"))
+}
+
 func TestIndexConfig(t *testing.T) {
 	suite.Run(t, new(IndexConfigTestSuite))
 }
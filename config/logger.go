@@ -76,6 +76,16 @@ func (l *Logger) Fatalf(msg string, args ...interface{}) {
 	zlog.Fatal().Msg(fmt.Sprintf(msg, args...))
 }
 
+// AddLogField adds a field with the current value of the function to every log line, empty values are left out. The logger
+// must already be configured.
+func AddLogField(key string, value func() string) {
+	zlog.Logger = zlog.Logger.Hook(zerolog.HookFunc(func(e *zerolog.Event, level zerolog.Level, msg string) {
+		if fieldValue := value(); fieldValue != "" {
+			e.Str(key, fieldValue)
+		}
+	}))
+}
+
 func DoConfigureLogger(logPath string, logLevel string, prettyLogging bool) {
 	writers := io.MultiWriter(os.Stdout)
 	if len(logPath) > 0 {
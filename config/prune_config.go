@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/DefiantLabs/cosmos-indexer/util"
+	"github.com/spf13/cobra"
+)
+
+type PruneConfig struct {
+	Database Database
+	Log      log
+	ChainID  string
+	Below    int64
+	Force    bool
+}
+
+func SetupPruneFlags(conf *PruneConfig, cmd *cobra.Command) {
+	// Reuses the probe chain ID key so the same config file as the index command can be used
+	cmd.PersistentFlags().StringVar(&conf.ChainID, "probe.chain-id", "", "chain ID of the indexed data to prune")
+	cmd.PersistentFlags().Int64Var(&conf.Below, "below", 0, "delete all indexed blocks, transactions, messages and events below this height")
+	cmd.PersistentFlags().BoolVar(&conf.Force, "force", false, "allow pruning above the current resume cursor (the highest indexed block)")
+}
+
+func (conf *PruneConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	if util.StrNotSet(conf.ChainID) {
+		return errors.New("probe chain-id must be set")
+	}
+
+	if conf.Below <= 0 {
+		return errors.New("prune height (--below) must be a positive number")
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/DefiantLabs/cosmos-indexer/util"
+	"github.com/spf13/cobra"
+)
+
+type ReindexMessagesConfig struct {
+	Database         Database
+	Log              log
+	ChainID          string
+	AccountPrefix    string
+	MessageType      string
+	BatchSize        int
+	IndexAddresses   bool
+	MessageSchemaDir string
+}
+
+func SetupReindexMessagesFlags(conf *ReindexMessagesConfig, cmd *cobra.Command) {
+	// Reuses the probe and base keys so the same config file as the index command can be used
+	cmd.PersistentFlags().StringVar(&conf.ChainID, "probe.chain-id", "", "chain ID of the indexed messages to reindex")
+	cmd.PersistentFlags().StringVar(&conf.AccountPrefix, "probe.account-prefix", "", "probe account prefix, used to format the re-extracted message addresses")
+	cmd.PersistentFlags().StringVar(&conf.MessageType, "type", "", "message type URL of the messages to reindex (e.g. /cosmos.bank.v1beta1.MsgSend)")
+	cmd.PersistentFlags().IntVar(&conf.BatchSize, "batch-size", 500, "number of messages updated per database transaction")
+	cmd.PersistentFlags().BoolVar(&conf.IndexAddresses, "base.index-addresses", false, "if true, the message addresses of the reindexed messages are re-extracted and replaced")
+	cmd.PersistentFlags().StringVar(&conf.MessageSchemaDir, "base.message-schema-dir", "", "path to a directory of JSON schema files named after message type URLs, the schema_error of the reindexed messages is recomputed against them")
+}
+
+func (conf *ReindexMessagesConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	if util.StrNotSet(conf.ChainID) {
+		return errors.New("probe chain-id must be set")
+	}
+
+	if util.StrNotSet(conf.MessageType) {
+		return errors.New("type must be set")
+	}
+
+	if conf.IndexAddresses && util.StrNotSet(conf.AccountPrefix) {
+		return errors.New("probe account-prefix must be set when reindexing message addresses")
+	}
+
+	if conf.BatchSize <= 0 {
+		return errors.New("batch-size must be a positive number")
+	}
+
+	return nil
+}
+
+// IndexConfig returns the index config the message parsers are handed while reindexing
+func (conf *ReindexMessagesConfig) IndexConfig() IndexConfig {
+	var indexConfig IndexConfig
+	indexConfig.Database = conf.Database
+	indexConfig.Log = conf.Log
+	indexConfig.Probe.ChainID = conf.ChainID
+	indexConfig.Probe.AccountPrefix = conf.AccountPrefix
+	indexConfig.Base.IndexAddresses = conf.IndexAddresses
+	indexConfig.Base.MessageSchemaDir = conf.MessageSchemaDir
+	indexConfig.Flags.IndexTxMessageRaw = true
+	return indexConfig
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/DefiantLabs/cosmos-indexer/util"
+	"github.com/spf13/cobra"
+)
+
+type SnapshotConfig struct {
+	Database Database
+	Log      log
+	File     string
+}
+
+func SetupSnapshotFlags(conf *SnapshotConfig, cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&conf.File, "file", "cosmos-indexer-snapshot.jsonl.gz", "path of the gzipped snapshot file")
+}
+
+func (conf *SnapshotConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	if util.StrNotSet(conf.File) {
+		return errors.New("snapshot file must be set")
+	}
+
+	return nil
+}
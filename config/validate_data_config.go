@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/DefiantLabs/cosmos-indexer/util"
+	"github.com/spf13/cobra"
+)
+
+type ValidateDataConfig struct {
+	Database      Database
+	Log           log
+	ChainID       string
+	MaxViolations int64
+}
+
+func SetupValidateDataFlags(conf *ValidateDataConfig, cmd *cobra.Command) {
+	// Reuses the probe chain ID key so the same config file as the index command can be used
+	cmd.PersistentFlags().StringVar(&conf.ChainID, "probe.chain-id", "", "chain ID of the indexed data to validate")
+	cmd.PersistentFlags().Int64Var(&conf.MaxViolations, "max-violations", 1000, "stop reporting after this many violations (0 reports every violation)")
+}
+
+func (conf *ValidateDataConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	if util.StrNotSet(conf.ChainID) {
+		return errors.New("probe chain-id must be set")
+	}
+
+	if conf.MaxViolations < 0 {
+		return errors.New("max-violations must be a positive number or 0")
+	}
+
+	return nil
+}
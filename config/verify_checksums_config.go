@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/DefiantLabs/cosmos-indexer/util"
+	"github.com/spf13/cobra"
+)
+
+type VerifyChecksumsConfig struct {
+	Database  Database
+	Log       log
+	ChainID   string
+	BatchSize int
+}
+
+func SetupVerifyChecksumsFlags(conf *VerifyChecksumsConfig, cmd *cobra.Command) {
+	// Reuses the probe chain ID key so the same config file as the index command can be used
+	cmd.PersistentFlags().StringVar(&conf.ChainID, "probe.chain-id", "", "chain ID of the indexed blocks to verify")
+	cmd.PersistentFlags().IntVar(&conf.BatchSize, "batch-size", 500, "number of blocks loaded at a time")
+}
+
+func (conf *VerifyChecksumsConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	if util.StrNotSet(conf.ChainID) {
+		return errors.New("probe chain-id must be set")
+	}
+
+	if conf.BatchSize <= 0 {
+		return errors.New("batch-size must be a positive number")
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type VerifyConfig struct {
+	Database           Database
+	Log                log
+	Probe              Probe
+	StartBlock         int64
+	EndBlock           int64
+	ReportFile         string
+	IndexMessageEvents bool
+	MaxMemoBytes       int64
+	RetryAttempts      int64
+	RetryMaxWait       uint64
+}
+
+func SetupVerifyFlags(conf *VerifyConfig, cmd *cobra.Command) {
+	cmd.PersistentFlags().Int64Var(&conf.StartBlock, "start", 0, "first block height to verify")
+	cmd.PersistentFlags().Int64Var(&conf.EndBlock, "end", 0, "last block height to verify, inclusive")
+	cmd.PersistentFlags().StringVar(&conf.ReportFile, "report-file", "", "path of a JSON file the mismatches found are written to, they are only logged when empty")
+	// Reuses the flags and base keys that change what is stored, so the same config file as the index command can be used
+	cmd.PersistentFlags().BoolVar(&conf.IndexMessageEvents, "flags.index-message-events", true, "compare the message events and their attributes, disable for chains indexed without message events")
+	cmd.PersistentFlags().Int64Var(&conf.MaxMemoBytes, "base.max-memo-bytes", 0, "the memo truncation the blocks were indexed with, fetched memos are truncated the same way before they are compared (0 compares the full memo)")
+	cmd.PersistentFlags().Int64Var(&conf.RetryAttempts, "base.request-retry-attempts", 0, "number of RPC query retries to make")
+	cmd.PersistentFlags().Uint64Var(&conf.RetryMaxWait, "base.request-retry-max-wait", 30, "max retry incremental backoff wait time in seconds")
+}
+
+func (conf *VerifyConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	conf.Probe, err = validateProbeConf(conf.Probe)
+	if err != nil {
+		return err
+	}
+
+	if conf.StartBlock <= 0 {
+		return errors.New("start must be a positive block height")
+	}
+
+	if conf.EndBlock < conf.StartBlock {
+		return fmt.Errorf("end must be at or after start (%d), got %d", conf.StartBlock, conf.EndBlock)
+	}
+
+	if conf.MaxMemoBytes < 0 {
+		return errors.New("base.max-memo-bytes must be a positive number or 0")
+	}
+
+	return nil
+}
+
+// IndexConfig returns the index config the fetched txs are decoded with
+func (conf *VerifyConfig) IndexConfig() IndexConfig {
+	var indexConfig IndexConfig
+	indexConfig.Database = conf.Database
+	indexConfig.Log = conf.Log
+	indexConfig.Probe = conf.Probe
+	indexConfig.Base.MaxMemoBytes = conf.MaxMemoBytes
+	indexConfig.Base.RequestRetryAttempts = conf.RetryAttempts
+	indexConfig.Base.RequestRetryMaxWait = conf.RetryMaxWait
+	indexConfig.Flags.IndexMessageEvents = conf.IndexMessageEvents
+	return indexConfig
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// Weight of the latest commit in the smoothed commit latency
+	adaptiveThrottleSmoothing = 0.3
+	// Upper bound of the inter-fetch delay, so a stalled database cannot stop fetching for longer than this between blocks
+	adaptiveThrottleMaxDelay = 10 * time.Second
+)
+
+// AdaptiveThrottle is the controller of base.adaptive-throttle-target-ms. The DB writer reports the latency of every block commit and
+// the RPC workers wait the controller's delay before fetching each block. While the smoothed commit latency is above the target the
+// delay grows by the overshoot, once it is back under the target the delay decays by a quarter per commit, so fetching slows under
+// database load and recovers when the load clears. A nil throttle never delays.
+type AdaptiveThrottle struct {
+	lock    sync.Mutex
+	target  time.Duration
+	latency time.Duration
+	delay   time.Duration
+}
+
+// NewAdaptiveThrottle creates a throttle keeping the commit latency around the target, a target of 0 returns a nil throttle
+func NewAdaptiveThrottle(target time.Duration) *AdaptiveThrottle {
+	if target <= 0 {
+		return nil
+	}
+	return &AdaptiveThrottle{target: target}
+}
+
+// Observe records the latency of a block commit and adjusts the inter-fetch delay
+func (throttle *AdaptiveThrottle) Observe(latency time.Duration) {
+	if throttle == nil {
+		return
+	}
+
+	throttle.lock.Lock()
+	defer throttle.lock.Unlock()
+
+	if throttle.latency == 0 {
+		throttle.latency = latency
+	} else {
+		throttle.latency += time.Duration(adaptiveThrottleSmoothing * float64(latency-throttle.latency))
+	}
+
+	if throttle.latency > throttle.target {
+		throttle.delay += throttle.latency - throttle.target
+		if throttle.delay > adaptiveThrottleMaxDelay {
+			throttle.delay = adaptiveThrottleMaxDelay
+		}
+	} else {
+		throttle.delay -= throttle.delay / 4
+	}
+}
+
+// Delay returns the current inter-fetch delay
+func (throttle *AdaptiveThrottle) Delay() time.Duration {
+	if throttle == nil {
+		return 0
+	}
+
+	throttle.lock.Lock()
+	defer throttle.lock.Unlock()
+	return throttle.delay
+}
+
+// Wait sleeps for the current inter-fetch delay
+func (throttle *AdaptiveThrottle) Wait() {
+	if delay := throttle.Delay(); delay > 0 {
+		time.Sleep(delay)
+	}
+}
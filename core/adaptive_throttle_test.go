@@ -0,0 +1,74 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AdaptiveThrottleTestSuite struct {
+	suite.Suite
+}
+
+// rampingStore is a mock store whose commits get slower by a fixed step each time
+type rampingStore struct {
+	latency time.Duration
+	step    time.Duration
+}
+
+func (store *rampingStore) commit() time.Duration {
+	store.latency += store.step
+	return store.latency
+}
+
+func (suite *AdaptiveThrottleTestSuite) TestDelayIncreasesWithLatency() {
+	throttle := NewAdaptiveThrottle(100 * time.Millisecond)
+	store := &rampingStore{latency: 20 * time.Millisecond, step: 20 * time.Millisecond}
+
+	// Commits under the target never delay fetching
+	for i := 0; i < 3; i++ {
+		throttle.Observe(store.commit())
+		suite.Zero(throttle.Delay())
+	}
+
+	// Once the ramp crosses the target every slower commit increases the delay
+	var delays []time.Duration
+	for i := 0; i < 20; i++ {
+		throttle.Observe(store.commit())
+		if delay := throttle.Delay(); delay > 0 {
+			delays = append(delays, delay)
+		}
+	}
+	suite.Require().NotEmpty(delays)
+	for i := 1; i < len(delays); i++ {
+		suite.Greater(delays[i], delays[i-1])
+	}
+
+	// The delay decays once the store recovers
+	peak := throttle.Delay()
+	for i := 0; i < 20; i++ {
+		throttle.Observe(10 * time.Millisecond)
+	}
+	suite.Less(throttle.Delay(), peak/10)
+}
+
+func (suite *AdaptiveThrottleTestSuite) TestDelayIsCapped() {
+	throttle := NewAdaptiveThrottle(10 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		throttle.Observe(time.Minute)
+	}
+	suite.Equal(adaptiveThrottleMaxDelay, throttle.Delay())
+}
+
+func (suite *AdaptiveThrottleTestSuite) TestDisabledThrottle() {
+	throttle := NewAdaptiveThrottle(0)
+	suite.Nil(throttle)
+	throttle.Observe(time.Minute)
+	suite.Zero(throttle.Delay())
+	suite.NotPanics(throttle.Wait)
+}
+
+func TestAdaptiveThrottleTestSuite(t *testing.T) {
+	suite.Run(t, new(AdaptiveThrottleTestSuite))
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/parsers"
+	"github.com/cosmos/cosmos-sdk/types"
+	authz "github.com/cosmos/cosmos-sdk/x/authz"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	distTypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	feegrant "github.com/cosmos/cosmos-sdk/x/feegrant"
+	govTypesV1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	govTypesV1Beta1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	stakingTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	ibcTransferTypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+)
+
+// Roles an address can play in a message, stored alongside the address in the message address index
+const (
+	AddressRoleSigner      = "signer"
+	AddressRoleSender      = "sender"
+	AddressRoleRecipient   = "recipient"
+	AddressRoleDelegator   = "delegator"
+	AddressRoleValidator   = "validator"
+	AddressRoleWithdrawer  = "withdrawer"
+	AddressRoleVoter       = "voter"
+	AddressRoleDepositor   = "depositor"
+	AddressRoleProposer    = "proposer"
+	AddressRoleGranter     = "granter"
+	AddressRoleGrantee     = "grantee"
+	AddressRoleIBCReceiver = "ibc_receiver"
+)
+
+// ExtractMessageAddresses returns every address referenced by the message. Signers are always included, recipients, validators etc.
+// are extracted for common Cosmos SDK and IBC message types. Custom parsers implementing parsers.MessageAddressParser may add more.
+// The result is deduplicated on address and role.
+func ExtractMessageAddresses(msg types.Msg, customParsers []parsers.MessageParser, cfg config.IndexConfig) ([]parsers.MessageAddress, error) {
+	var addresses []parsers.MessageAddress
+
+	for _, signer := range msg.GetSigners() {
+		addresses = append(addresses, parsers.MessageAddress{Address: signer.String(), Role: AddressRoleSigner})
+	}
+
+	addresses = append(addresses, extractKnownMessageAddresses(msg)...)
+
+	for _, customParser := range customParsers {
+		if addressParser, ok := customParser.(parsers.MessageAddressParser); ok {
+			customAddresses, err := addressParser.ParseMessageAddresses(msg, cfg)
+			if err != nil {
+				// Still return the addresses found so far so a failing custom parser does not drop the built-in ones
+				return dedupeMessageAddresses(addresses), err
+			}
+			addresses = append(addresses, customAddresses...)
+		}
+	}
+
+	return dedupeMessageAddresses(addresses), nil
+}
+
+func extractKnownMessageAddresses(msg types.Msg) []parsers.MessageAddress {
+	var addresses []parsers.MessageAddress
+	add := func(address string, role string) {
+		addresses = append(addresses, parsers.MessageAddress{Address: address, Role: role})
+	}
+
+	switch typedMsg := msg.(type) {
+	case *bankTypes.MsgSend:
+		add(typedMsg.FromAddress, AddressRoleSender)
+		add(typedMsg.ToAddress, AddressRoleRecipient)
+	case *bankTypes.MsgMultiSend:
+		for _, input := range typedMsg.Inputs {
+			add(input.Address, AddressRoleSender)
+		}
+		for _, output := range typedMsg.Outputs {
+			add(output.Address, AddressRoleRecipient)
+		}
+	case *stakingTypes.MsgDelegate:
+		add(typedMsg.DelegatorAddress, AddressRoleDelegator)
+		add(typedMsg.ValidatorAddress, AddressRoleValidator)
+	case *stakingTypes.MsgUndelegate:
+		add(typedMsg.DelegatorAddress, AddressRoleDelegator)
+		add(typedMsg.ValidatorAddress, AddressRoleValidator)
+	case *stakingTypes.MsgBeginRedelegate:
+		add(typedMsg.DelegatorAddress, AddressRoleDelegator)
+		add(typedMsg.ValidatorSrcAddress, AddressRoleValidator)
+		add(typedMsg.ValidatorDstAddress, AddressRoleValidator)
+	case *stakingTypes.MsgCreateValidator:
+		add(typedMsg.DelegatorAddress, AddressRoleDelegator)
+		add(typedMsg.ValidatorAddress, AddressRoleValidator)
+	case *stakingTypes.MsgEditValidator:
+		add(typedMsg.ValidatorAddress, AddressRoleValidator)
+	case *distTypes.MsgWithdrawDelegatorReward:
+		add(typedMsg.DelegatorAddress, AddressRoleDelegator)
+		add(typedMsg.ValidatorAddress, AddressRoleValidator)
+	case *distTypes.MsgWithdrawValidatorCommission:
+		add(typedMsg.ValidatorAddress, AddressRoleValidator)
+	case *distTypes.MsgSetWithdrawAddress:
+		add(typedMsg.DelegatorAddress, AddressRoleDelegator)
+		add(typedMsg.WithdrawAddress, AddressRoleWithdrawer)
+	case *distTypes.MsgFundCommunityPool:
+		add(typedMsg.Depositor, AddressRoleDepositor)
+	case *govTypesV1Beta1.MsgVote:
+		add(typedMsg.Voter, AddressRoleVoter)
+	case *govTypesV1Beta1.MsgVoteWeighted:
+		add(typedMsg.Voter, AddressRoleVoter)
+	case *govTypesV1Beta1.MsgDeposit:
+		add(typedMsg.Depositor, AddressRoleDepositor)
+	case *govTypesV1Beta1.MsgSubmitProposal:
+		add(typedMsg.Proposer, AddressRoleProposer)
+	case *govTypesV1.MsgVote:
+		add(typedMsg.Voter, AddressRoleVoter)
+	case *govTypesV1.MsgVoteWeighted:
+		add(typedMsg.Voter, AddressRoleVoter)
+	case *govTypesV1.MsgDeposit:
+		add(typedMsg.Depositor, AddressRoleDepositor)
+	case *govTypesV1.MsgSubmitProposal:
+		add(typedMsg.Proposer, AddressRoleProposer)
+	case *authz.MsgGrant:
+		add(typedMsg.Granter, AddressRoleGranter)
+		add(typedMsg.Grantee, AddressRoleGrantee)
+	case *authz.MsgRevoke:
+		add(typedMsg.Granter, AddressRoleGranter)
+		add(typedMsg.Grantee, AddressRoleGrantee)
+	case *authz.MsgExec:
+		add(typedMsg.Grantee, AddressRoleGrantee)
+	case *feegrant.MsgGrantAllowance:
+		add(typedMsg.Granter, AddressRoleGranter)
+		add(typedMsg.Grantee, AddressRoleGrantee)
+	case *feegrant.MsgRevokeAllowance:
+		add(typedMsg.Granter, AddressRoleGranter)
+		add(typedMsg.Grantee, AddressRoleGrantee)
+	case *ibcTransferTypes.MsgTransfer:
+		add(typedMsg.Sender, AddressRoleSender)
+		// The receiver lives on the counterparty chain, stored under its own role so it is not mistaken for a local recipient
+		add(typedMsg.Receiver, AddressRoleIBCReceiver)
+	}
+
+	return addresses
+}
+
+func dedupeMessageAddresses(addresses []parsers.MessageAddress) []parsers.MessageAddress {
+	seen := make(map[parsers.MessageAddress]struct{})
+	var deduped []parsers.MessageAddress
+
+	for _, address := range addresses {
+		if address.Address == "" {
+			continue
+		}
+		if _, ok := seen[address]; ok {
+			continue
+		}
+		seen[address] = struct{}{}
+		deduped = append(deduped, address)
+	}
+
+	return deduped
+}
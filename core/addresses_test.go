@@ -0,0 +1,80 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/parsers"
+	"github.com/cosmos/cosmos-sdk/types"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type extraAddressParser struct {
+	address string
+}
+
+func (p *extraAddressParser) Identifier() string {
+	return "extra-address"
+}
+
+func (p *extraAddressParser) ParseMessage(types.Msg, *txtypes.LogMessage, config.IndexConfig) (*any, error) {
+	return nil, nil
+}
+
+func (p *extraAddressParser) IndexMessage(*any, *gorm.DB, models.Message, []parsers.MessageEventWithAttributes, config.IndexConfig) error {
+	return nil
+}
+
+func (p *extraAddressParser) ParseMessageAddresses(types.Msg, config.IndexConfig) ([]parsers.MessageAddress, error) {
+	return []parsers.MessageAddress{{Address: p.address, Role: "custom"}}, nil
+}
+
+type AddressesTestSuite struct {
+	suite.Suite
+	from string
+	to   string
+}
+
+func (suite *AddressesTestSuite) SetupTest() {
+	suite.from = types.AccAddress([]byte("from_address________")).String()
+	suite.to = types.AccAddress([]byte("to_address__________")).String()
+}
+
+func (suite *AddressesTestSuite) TestMsgSendAddresses() {
+	msg := &bankTypes.MsgSend{
+		FromAddress: suite.from,
+		ToAddress:   suite.to,
+		Amount:      types.NewCoins(types.NewInt64Coin("uatom", 1)),
+	}
+
+	addresses, err := ExtractMessageAddresses(msg, nil, config.IndexConfig{})
+	suite.Require().NoError(err)
+
+	suite.ElementsMatch([]parsers.MessageAddress{
+		{Address: suite.from, Role: AddressRoleSigner},
+		{Address: suite.from, Role: AddressRoleSender},
+		{Address: suite.to, Role: AddressRoleRecipient},
+	}, addresses)
+}
+
+func (suite *AddressesTestSuite) TestCustomParserAddresses() {
+	msg := &bankTypes.MsgSend{
+		FromAddress: suite.from,
+		ToAddress:   suite.to,
+	}
+
+	customParsers := []parsers.MessageParser{&extraAddressParser{address: "extra"}, &extraAddressParser{address: "extra"}}
+	addresses, err := ExtractMessageAddresses(msg, customParsers, config.IndexConfig{})
+	suite.Require().NoError(err)
+
+	suite.Len(addresses, 4)
+	suite.Contains(addresses, parsers.MessageAddress{Address: "extra", Role: "custom"})
+}
+
+func TestAddressesTestSuite(t *testing.T) {
+	suite.Run(t, new(AddressesTestSuite))
+}
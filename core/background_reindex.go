@@ -0,0 +1,70 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/probe/client"
+	"gorm.io/gorm"
+)
+
+// Blocks each background re-index worker may hold in the pipeline at once
+const backgroundReindexInflightPerWorker = 4
+
+// GenerateBackgroundReindexEnqueueFunction enqueues every block of the background re-index range, indexed or not, to be written in
+// replace mode alongside the blocks of the main enqueue function
+func GenerateBackgroundReindexEnqueueFunction(cfg config.IndexConfig, skipHeights SkipHeights) func(chan *EnqueueData) error {
+	return func(blockChan chan *EnqueueData) error {
+		config.Log.Infof("Background re-indexing blocks %d to %d", cfg.Base.BackgroundReindexStartBlock, cfg.Base.BackgroundReindexEndBlock)
+
+		for height := cfg.Base.BackgroundReindexStartBlock; height <= cfg.Base.BackgroundReindexEndBlock; height++ {
+			if !cfg.InShard(height) || skipHeights.Skips(height) {
+				continue
+			}
+
+			if cfg.Base.Throttling != 0 {
+				time.Sleep(time.Second * time.Duration(cfg.Base.Throttling))
+			}
+
+			config.Log.Debugf("Sending block %v to be re-indexed in the background.", height)
+			blockChan <- &EnqueueData{
+				IndexBlockEvents:  cfg.IndexesBlockEvents(),
+				IndexTransactions: cfg.Base.TransactionIndexingEnabled,
+				Height:            height,
+				BackgroundReindex: true,
+			}
+		}
+
+		return nil
+	}
+}
+
+// StartBackgroundReindex starts the workers and the enqueue function of the base.background-reindex range. The workers pass their
+// blocks to the same processing channel as the main pipeline, so both are written by the single DB writer one block at a time and
+// never hold conflicting transactions, even on the same height. The range has its own in-flight limit, its blocks never take the
+// slots the main pipeline needs to make progress. The workers are added to the wait group, which is done once the range is fetched.
+func StartBackgroundReindex(wg *sync.WaitGroup, chainID uint, cfg *config.IndexConfig, skipHeights SkipHeights, chainClient *client.ChainClient, codecs *CodecSchedule, db *gorm.DB, failedBlocks *dbTypes.FailedBlockCounter, fetchThrottle *AdaptiveThrottle, errorWebhook *ErrorWebhook, outputChannel chan IndexerBlockEventData) {
+	workers := int(cfg.Base.BackgroundReindexWorkers)
+	if workers <= 0 {
+		workers = 1
+	}
+
+	blockEnqueueChan := make(chan *EnqueueData, workers)
+	inflightLimiter := NewInflightLimiter(int64(backgroundReindexInflightPerWorker * workers))
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go BlockRPCWorker(wg, blockEnqueueChan, chainID, cfg.Probe.ChainID, cfg, chainClient, codecs, db, failedBlocks, inflightLimiter, fetchThrottle, nil, errorWebhook, outputChannel)
+	}
+
+	go func() {
+		defer close(blockEnqueueChan)
+		if err := GenerateBackgroundReindexEnqueueFunction(*cfg, skipHeights)(blockEnqueueChan); err != nil {
+			config.Log.Error("Background re-index enqueue failed", err)
+			return
+		}
+		config.Log.Info("All blocks of the background re-index range have been enqueued")
+	}()
+}
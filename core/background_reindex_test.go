@@ -0,0 +1,116 @@
+package core
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	probeClient "github.com/DefiantLabs/probe/client"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type BackgroundReindexTestSuite struct {
+	suite.Suite
+}
+
+func (suite *BackgroundReindexTestSuite) TestEnqueueRange() {
+	cfg := config.IndexConfig{}
+	cfg.Base.BackgroundReindexStartBlock = 2
+	cfg.Base.BackgroundReindexEndBlock = 6
+	cfg.Base.TransactionIndexingEnabled = true
+
+	blockChan := make(chan *EnqueueData, 10)
+	suite.Require().NoError(GenerateBackgroundReindexEnqueueFunction(cfg, SkipHeights{4: true})(blockChan))
+	close(blockChan)
+
+	var heights []int64
+	for block := range blockChan {
+		suite.True(block.BackgroundReindex)
+		suite.True(block.IndexTransactions)
+		suite.False(block.IndexBlockEvents)
+		heights = append(heights, block.Height)
+	}
+	suite.Equal([]int64{2, 3, 5, 6}, heights)
+}
+
+func (suite *BackgroundReindexTestSuite) TestReindexWhileTailing() {
+	originalGetBlock := getBlock
+	defer func() { getBlock = originalGetBlock }()
+
+	getBlock = func(cl *probeClient.ChainClient, height int64) (*ctypes.ResultBlock, error) {
+		return &ctypes.ResultBlock{Block: &cmtTypes.Block{Header: cmtTypes.Header{Height: height}}}, nil
+	}
+
+	endpoint := newBlockEndpoint(batchRejected)
+	defer endpoint.Close()
+
+	// The historical range overlaps the first tail heights, both pipelines fetch heights 35 to 40
+	cfg := &config.IndexConfig{}
+	cfg.Base.BlockEventIndexingEnabled = true
+	cfg.Base.BackgroundReindexStartBlock = 1
+	cfg.Base.BackgroundReindexEndBlock = 40
+	cfg.Base.BackgroundReindexWorkers = 2
+
+	chainClient := &probeClient.ChainClient{Config: &probeClient.ChainClientConfig{RPCAddr: endpoint.URL}}
+	tailChan := make(chan *EnqueueData)
+	outputChannel := make(chan IndexerBlockEventData)
+
+	var wg sync.WaitGroup
+	liveLimiter := NewInflightLimiter(2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go BlockRPCWorker(&wg, tailChan, 1, "cosmoshub-4", cfg, chainClient, nil, nil, nil, liveLimiter, nil, nil, nil, outputChannel)
+	}
+	StartBackgroundReindex(&wg, 1, cfg, nil, chainClient, nil, nil, nil, nil, nil, outputChannel)
+
+	// The tail advances a block at a time while the range is re-indexed
+	go func() {
+		defer close(tailChan)
+		for height := int64(35); height <= 60; height++ {
+			tailChan <- &EnqueueData{Height: height, IndexBlockEvents: true}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(outputChannel)
+	}()
+
+	// The single consumer stands in for the DB writer, releasing each block once it is written
+	tailHeights := make(map[int64]bool)
+	reindexedHeights := make(map[int64]bool)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for data := range outputChannel {
+			if data.BackgroundReindex {
+				reindexedHeights[data.BlockData.Block.Height] = true
+			} else {
+				tailHeights[data.BlockData.Block.Height] = true
+			}
+			data.Inflight.Release()
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		suite.FailNow("background re-index and tail did not finish")
+	}
+
+	suite.Len(reindexedHeights, 40)
+	suite.Len(tailHeights, 26)
+	for height := int64(35); height <= 40; height++ {
+		suite.True(reindexedHeights[height])
+		suite.True(tailHeights[height])
+	}
+	suite.Zero(liveLimiter.InFlight())
+}
+
+func TestBackgroundReindexTestSuite(t *testing.T) {
+	suite.Run(t, new(BackgroundReindexTestSuite))
+}
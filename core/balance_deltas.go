@@ -0,0 +1,109 @@
+package core
+
+import (
+	"fmt"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+// Attribute keys of the bank module coin events, holding the address whose balance changed
+var balanceDeltaAddressKeys = map[string]string{
+	models.BalanceDeltaEventTypeCoinSpent:    "spender",
+	models.BalanceDeltaEventTypeCoinReceived: "receiver",
+}
+
+// ExtractMessageBalanceDeltas returns the signed balance delta rows (coin_spent and coin_received events) of the message, one per coin.
+// Rows are returned without block, tx or message IDs.
+func ExtractMessageBalanceDeltas(messageLog *txtypes.LogMessage) ([]models.BalanceDelta, error) {
+	if messageLog == nil {
+		return nil, nil
+	}
+
+	var balanceDeltas []models.BalanceDelta
+	var deltaIndex uint64
+	for _, event := range messageLog.Events {
+		if _, ok := balanceDeltaAddressKeys[event.Type]; !ok {
+			continue
+		}
+
+		keys := make([]string, len(event.Attributes))
+		values := make([]string, len(event.Attributes))
+		for i, attribute := range event.Attributes {
+			keys[i] = attribute.Key
+			values[i] = attribute.Value
+		}
+
+		// Message logs merge the coin events of a message, e.g. a multi-send has a single coin_received event with the receiver and amount repeated per recipient
+		for _, coinEvent := range splitEventAttributes(keys, values) {
+			coinRows, err := balanceDeltaRows(event.Type, coinEvent, deltaIndex)
+			if err != nil {
+				return nil, err
+			}
+			balanceDeltas = append(balanceDeltas, coinRows...)
+			deltaIndex++
+		}
+	}
+
+	return balanceDeltas, nil
+}
+
+// ExtractBlockBalanceDeltas returns the signed balance delta rows (coin_spent and coin_received events) of the block events, one per coin.
+// Rows are returned without block IDs.
+func ExtractBlockBalanceDeltas(height int64, blockEvents []db.BlockEventDBWrapper) ([]models.BalanceDelta, error) {
+	var balanceDeltas []models.BalanceDelta
+	for _, blockEvent := range blockEvents {
+		eventType := blockEvent.BlockEvent.BlockEventType.Type
+		if _, ok := balanceDeltaAddressKeys[eventType]; !ok {
+			continue
+		}
+
+		coinEvent := make(eventAttributeSet)
+		for _, attribute := range blockEvent.Attributes {
+			coinEvent[attribute.BlockEventAttributeKey.Key] = attribute.Value
+		}
+
+		coinRows, err := balanceDeltaRows(eventType, coinEvent, blockEvent.BlockEvent.Index)
+		if err != nil {
+			return nil, err
+		}
+
+		lifecyclePosition := blockEvent.BlockEvent.LifecyclePosition
+		for i := range coinRows {
+			coinRows[i].Height = height
+			coinRows[i].LifecyclePosition = &lifecyclePosition
+		}
+		balanceDeltas = append(balanceDeltas, coinRows...)
+	}
+
+	return balanceDeltas, nil
+}
+
+// balanceDeltaRows returns one row per coin of the event amount, negated for coins spent
+func balanceDeltaRows(eventType string, coinEvent eventAttributeSet, eventIndex uint64) ([]models.BalanceDelta, error) {
+	coins, err := types.ParseCoinsNormalized(coinEvent[rewardAttributeAmount])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s amount %q: %w", eventType, coinEvent[rewardAttributeAmount], err)
+	}
+
+	var rows []models.BalanceDelta
+	for _, coin := range coins {
+		amount := decimal.NewFromBigInt(coin.Amount.BigInt(), 0)
+		if eventType == models.BalanceDeltaEventTypeCoinSpent {
+			amount = amount.Neg()
+		}
+
+		rows = append(rows, models.BalanceDelta{
+			EventIndex: eventIndex,
+			Type:       eventType,
+			Address:    coinEvent[balanceDeltaAddressKeys[eventType]],
+			Amount:     amount,
+			Denom:      coin.Denom,
+		})
+	}
+
+	return rows, nil
+}
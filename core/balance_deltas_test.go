@@ -0,0 +1,124 @@
+package core
+
+import (
+	"testing"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testSender    = "cosmos1sender"
+	testRecipient = "cosmos1recipient"
+	testOsmoDenom = "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"
+)
+
+type BalanceDeltasTestSuite struct {
+	suite.Suite
+}
+
+func (suite *BalanceDeltasTestSuite) TestMultiCoinTransfer() {
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "coin_spent", Attributes: []txtypes.Attribute{
+				{Key: "spender", Value: testSender},
+				{Key: "amount", Value: "1500uatom," + "20" + testOsmoDenom},
+			}},
+			{Type: "coin_received", Attributes: []txtypes.Attribute{
+				{Key: "receiver", Value: testRecipient},
+				{Key: "amount", Value: "1500uatom," + "20" + testOsmoDenom},
+			}},
+			{Type: "transfer", Attributes: []txtypes.Attribute{
+				{Key: "recipient", Value: testRecipient},
+				{Key: "sender", Value: testSender},
+				{Key: "amount", Value: "1500uatom," + "20" + testOsmoDenom},
+			}},
+		},
+	}
+
+	deltas, err := ExtractMessageBalanceDeltas(messageLog)
+	suite.Require().NoError(err)
+	suite.Require().Len(deltas, 4)
+
+	// Coins of a single event are sorted by denom
+	expected := []struct {
+		eventType string
+		address   string
+		amount    int64
+		denom     string
+		index     uint64
+	}{
+		{models.BalanceDeltaEventTypeCoinSpent, testSender, -20, testOsmoDenom, 0},
+		{models.BalanceDeltaEventTypeCoinSpent, testSender, -1500, "uatom", 0},
+		{models.BalanceDeltaEventTypeCoinReceived, testRecipient, 20, testOsmoDenom, 1},
+		{models.BalanceDeltaEventTypeCoinReceived, testRecipient, 1500, "uatom", 1},
+	}
+
+	for i, want := range expected {
+		suite.Equal(want.eventType, deltas[i].Type)
+		suite.Equal(want.address, deltas[i].Address)
+		suite.True(decimal.NewFromInt(want.amount).Equal(deltas[i].Amount), "row %d amount %s", i, deltas[i].Amount)
+		suite.Equal(want.denom, deltas[i].Denom)
+		suite.Equal(want.index, deltas[i].EventIndex)
+		suite.Nil(deltas[i].LifecyclePosition)
+	}
+
+	// The deltas of a transfer net out per denom
+	net := make(map[string]decimal.Decimal)
+	for _, delta := range deltas {
+		net[delta.Denom] = net[delta.Denom].Add(delta.Amount)
+	}
+	suite.True(net["uatom"].IsZero())
+	suite.True(net[testOsmoDenom].IsZero())
+}
+
+func (suite *BalanceDeltasTestSuite) TestMergedMultiSend() {
+	// A multi-send to two recipients, the log merges both coin_received events into a single event
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "coin_received", Attributes: []txtypes.Attribute{
+				{Key: "receiver", Value: "cosmos1recipienta"},
+				{Key: "amount", Value: "100uatom"},
+				{Key: "receiver", Value: "cosmos1recipientb"},
+				{Key: "amount", Value: "200uatom"},
+			}},
+		},
+	}
+
+	deltas, err := ExtractMessageBalanceDeltas(messageLog)
+	suite.Require().NoError(err)
+	suite.Require().Len(deltas, 2)
+	suite.Equal("cosmos1recipienta", deltas[0].Address)
+	suite.True(decimal.NewFromInt(100).Equal(deltas[0].Amount))
+	suite.Equal("cosmos1recipientb", deltas[1].Address)
+	suite.True(decimal.NewFromInt(200).Equal(deltas[1].Amount))
+	suite.NotEqual(deltas[0].EventIndex, deltas[1].EventIndex)
+}
+
+func (suite *BalanceDeltasTestSuite) TestBlockBalanceDeltas() {
+	blockEvents := []db.BlockEventDBWrapper{
+		blockEvent(0, "coin_received", "receiver", "cosmos1mintmodule", "amount", "5000uatom"),
+		blockEvent(1, "mint", "amount", "5000uatom"),
+		blockEvent(2, "coin_spent", "spender", "cosmos1mintmodule", "amount", "5000uatom"),
+	}
+
+	deltas, err := ExtractBlockBalanceDeltas(100, blockEvents)
+	suite.Require().NoError(err)
+	suite.Require().Len(deltas, 2)
+
+	suite.Equal(int64(100), deltas[0].Height)
+	suite.Equal(uint64(0), deltas[0].EventIndex)
+	suite.True(decimal.NewFromInt(5000).Equal(deltas[0].Amount))
+	suite.Require().NotNil(deltas[0].LifecyclePosition)
+	suite.Equal(models.BeginBlockEvent, *deltas[0].LifecyclePosition)
+
+	suite.Equal(uint64(2), deltas[1].EventIndex)
+	suite.True(decimal.NewFromInt(-5000).Equal(deltas[1].Amount))
+}
+
+func TestBalanceDeltasSuite(t *testing.T) {
+	suite.Run(t, new(BalanceDeltasTestSuite))
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"encoding/json"
+	"fmt"
 	"math"
 	"os"
 	"sort"
@@ -19,13 +20,21 @@ import (
 
 var EnqueueFunctions = map[string]func(chan int64) error{}
 
+// Swapped out in tests to avoid needing a live node
+var (
+	getLatestBlockHeightWithRetry    = rpc.GetLatestBlockHeightWithRetry
+	getEarliestAndLatestBlockHeights = rpc.GetEarliestAndLatestBlockHeights
+)
+
 type EnqueueData struct {
 	Height            int64
 	IndexBlockEvents  bool
 	IndexTransactions bool
+	// Set on the blocks of the base.background-reindex range, which are written in replace mode
+	BackgroundReindex bool
 }
 
-func GenerateBlockFileEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, client *client.ChainClient, chainID uint, blockInputFile string) (func(chan *EnqueueData) error, error) {
+func GenerateBlockFileEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, skipHeights SkipHeights, client *client.ChainClient, chainID uint, blockInputFile string) (func(chan *EnqueueData) error, error) {
 	return func(blockChan chan *EnqueueData) error {
 		plan, err := os.ReadFile(blockInputFile)
 		if err != nil {
@@ -66,6 +75,10 @@ func GenerateBlockFileEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, clien
 		unindexableBlockHeights := []uint64{}
 		blockInRange := []uint64{}
 		for _, block := range blocksToIndex {
+			if !cfg.InShard(int64(block)) || skipHeights.Skips(int64(block)) {
+				continue
+			}
+
 			if block > uint64(latestBlock) || block < uint64(earliestBlock) {
 				unindexableBlockHeights = append(unindexableBlockHeights, block)
 			} else {
@@ -90,7 +103,7 @@ func GenerateBlockFileEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, clien
 			config.Log.Debugf("Sending block %v to be indexed.", height)
 			// Add the new block to the queue
 			blockChan <- &EnqueueData{
-				IndexBlockEvents:  cfg.Base.BlockEventIndexingEnabled,
+				IndexBlockEvents:  cfg.IndexesBlockEvents(),
 				IndexTransactions: cfg.Base.TransactionIndexingEnabled,
 				Height:            int64(height),
 			}
@@ -99,35 +112,23 @@ func GenerateBlockFileEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, clien
 	}, nil
 }
 
-func GenerateMsgTypeEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, chainID uint, msgType string) (func(chan *EnqueueData) error, error) {
-	// get the block range
-	startBlock := cfg.Base.StartBlock
-	endBlock := cfg.Base.EndBlock
-	if endBlock == -1 {
-		heighestBlock := dbTypes.GetHighestIndexedBlock(db, chainID)
-		endBlock = heighestBlock.Height
-	}
+// GenerateMsgTypeEnqueueFunction enqueues every indexed block between the start and end block that contains the message type.
+// An end block of -1 leaves the range open-ended, scanning up to the highest block in the DB at enqueue time.
+func GenerateMsgTypeEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, skipHeights SkipHeights, chainID uint, msgType string) (func(chan *EnqueueData) error, error) {
+	return func(blockChan chan *EnqueueData) error {
+		heights, err := dbTypes.GetBlockHeightsWithMessageType(db, chainID, msgType, cfg.Base.StartBlock, cfg.Base.EndBlock)
+		if err != nil {
+			config.Log.Errorf("Error checking DB for blocks to reindex. Err: %v", err)
+			return err
+		}
 
-	rows, err := db.Raw(`SELECT height FROM blocks
-							JOIN txes ON txes.block_id = blocks.id
-							JOIN messages ON messages.tx_id = txes.id
-							JOIN message_types ON message_types.id = messages.message_type_id
-							AND message_types.message_type = ?
-							WHERE height >= ? AND height <= ? AND chain_id = ?::int;
-							`, msgType, startBlock, endBlock, chainID).Rows()
-	if err != nil {
-		config.Log.Errorf("Error checking DB for blocks to reindex. Err: %v", err)
-		return nil, err
-	}
+		config.Log.Infof("Found %d blocks containing message type %s to reindex", len(heights), msgType)
 
-	return func(blockChan chan *EnqueueData) error {
-		defer rows.Close()
-		for rows.Next() {
-			var block int64
-			err = db.ScanRows(rows, &block)
-			if err != nil {
-				config.Log.Fatal("Error getting block height. Err: %v", err)
+		for _, block := range heights {
+			if !cfg.InShard(block) || skipHeights.Skips(block) {
+				continue
 			}
+
 			config.Log.Debugf("Sending block %v to be re-indexed.", block)
 
 			if cfg.Base.Throttling != 0 {
@@ -136,7 +137,7 @@ func GenerateMsgTypeEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, chainID
 
 			// Add the new block to the queue
 			blockChan <- &EnqueueData{
-				IndexBlockEvents:  cfg.Base.BlockEventIndexingEnabled,
+				IndexBlockEvents:  cfg.IndexesBlockEvents(),
 				IndexTransactions: cfg.Base.TransactionIndexingEnabled,
 				Height:            block,
 			}
@@ -151,14 +152,14 @@ func GenerateMsgTypeEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, chainID
 // If reindexing is disabled, it will not reindex blocks that have already been indexed. This means it may skip around finding blocks that have not been
 // indexed according to the current configuration.
 // If failed block reattempts are enabled, it will enqueue those according to the passed in configuration as well.
-func GenerateDefaultEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, client *client.ChainClient, chainID uint) (func(chan *EnqueueData) error, error) {
+func GenerateDefaultEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, skipHeights SkipHeights, client *client.ChainClient, chainID uint) (func(chan *EnqueueData) error, error) {
 	var failedBlockEnqueueData []*EnqueueData
 	if cfg.Base.ReattemptFailedBlocks {
 		var failedEventBlocks []models.FailedEventBlock
 		var failedBlocks []models.FailedBlock
 
 		uniqueBlockFailures := make(map[int64]*EnqueueData)
-		if cfg.Base.BlockEventIndexingEnabled {
+		if cfg.IndexesBlockEvents() {
 			err := db.Table("failed_event_blocks").Where("blockchain_id = ?::int", chainID).Order("height asc").Scan(&failedEventBlocks).Error
 			if err != nil {
 				config.Log.Error("Error retrieving failed event blocks for reenqueue", err)
@@ -195,6 +196,10 @@ func GenerateDefaultEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, client
 		}
 
 		for _, block := range uniqueBlockFailures {
+			// Failed blocks of other shards are left for those shards to reattempt, skipped heights are never reattempted
+			if !cfg.InShard(block.Height) || skipHeights.Skips(block.Height) {
+				continue
+			}
 			failedBlockEnqueueData = append(failedBlockEnqueueData, block)
 		}
 
@@ -208,10 +213,30 @@ func GenerateDefaultEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, client
 	// var lastBlock = cfg.Base.EndBlock
 	// var latestBlock int64 = math.MaxInt64
 
+	explicitStart := startBlock > 0
 	if startBlock <= 0 {
 		startBlock = 1
 	}
 
+	startBlock, err := clampStartToAvailable(cfg, client, startBlock, explicitStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// Catch-up only runs are bounded by the chain tip at startup, blocks produced during the run are left for the next run
+	if cfg.Base.CatchUpOnly {
+		startupTip, err := getLatestBlockHeightWithRetry(client, cfg.Base.RequestRetryAttempts, cfg.Base.RequestRetryMaxWait)
+		if err != nil {
+			config.Log.Errorf("Error getting blockchain latest height. Err: %v", err)
+			return nil, err
+		}
+
+		if endBlock == -1 || startupTip < endBlock {
+			endBlock = startupTip
+		}
+		config.Log.Infof("Catch-up only mode, indexing up to the chain tip at startup, block %d", endBlock)
+	}
+
 	var blocksFromStart []models.Block
 
 	if !reindexing {
@@ -276,7 +301,7 @@ func GenerateDefaultEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, client
 				// This is the latest block height available on the Node.
 
 				var err error
-				latestBlock, err = rpc.GetLatestBlockHeightWithRetry(client, cfg.Base.RequestRetryAttempts, cfg.Base.RequestRetryMaxWait)
+				latestBlock, err = getLatestBlockHeightWithRetry(client, cfg.Base.RequestRetryAttempts, cfg.Base.RequestRetryMaxWait)
 				if err != nil {
 					config.Log.Error("Error getting blockchain latest height. Err: %v", err)
 					return err
@@ -289,6 +314,19 @@ func GenerateDefaultEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, client
 
 				// Already at the latest block, wait for the next block to be available.
 				for currBlock < latestBlock && (currBlock <= endBlock || endBlock == -1) && len(blockChan) != cap(blockChan) {
+					// Heights owned by other shards are never enqueued, so they also never count as gaps for this shard
+					if !cfg.InShard(currBlock) {
+						currBlock++
+						continue
+					}
+
+					// Deliberately skipped heights are accounted for in the skipped blocks table, moving past them is progress
+					if skipHeights.Skips(currBlock) {
+						config.Log.Debugf("Block %d is in the skip heights, skipping", currBlock)
+						currBlock++
+						continue
+					}
+
 					// if we are not re-indexing, skip curr block if already indexed
 					block, blockExists := blocksInDB[currBlock]
 
@@ -298,7 +336,7 @@ func GenerateDefaultEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, client
 
 						needsIndex := false
 
-						if cfg.Base.BlockEventIndexingEnabled && !block.BlockEventsIndexed {
+						if cfg.IndexesBlockEvents() && !block.BlockEventsIndexed {
 							needsIndex = true
 						} else if cfg.Base.TransactionIndexingEnabled && !block.TxIndexed {
 							needsIndex = true
@@ -312,7 +350,7 @@ func GenerateDefaultEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, client
 						config.Log.Debugf("Block %d needs indexing, adding to queue", currBlock)
 						blockChan <- &EnqueueData{
 							Height:            currBlock,
-							IndexBlockEvents:  cfg.Base.BlockEventIndexingEnabled && !block.BlockEventsIndexed,
+							IndexBlockEvents:  cfg.IndexesBlockEvents() && !block.BlockEventsIndexed,
 							IndexTransactions: cfg.Base.TransactionIndexingEnabled && !block.TxIndexed,
 						}
 
@@ -330,7 +368,7 @@ func GenerateDefaultEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, client
 					// Add the new block to the queue
 					blockChan <- &EnqueueData{
 						Height:            currBlock,
-						IndexBlockEvents:  cfg.Base.BlockEventIndexingEnabled,
+						IndexBlockEvents:  cfg.IndexesBlockEvents(),
 						IndexTransactions: cfg.Base.TransactionIndexingEnabled,
 					}
 					currBlock++
@@ -343,3 +381,64 @@ func GenerateDefaultEnqueueFunction(db *gorm.DB, cfg config.IndexConfig, client
 		}
 	}, nil
 }
+
+// EnqueueUntil runs the enqueue function and hands the enqueued blocks over to the RPC workers on workerChan until the deadline.
+// workerChan is closed once the enqueue function returns or the deadline passes, whichever comes first, so the workers finish the
+// blocks in flight and stop. Blocks still queued at the deadline are left for the next run and the enqueue function is abandoned.
+// It returns whether the deadline was reached.
+func EnqueueUntil(deadline time.Time, enqueue func(chan *EnqueueData) error, enqueueChan chan *EnqueueData, workerChan chan *EnqueueData) (bool, error) {
+	defer close(workerChan)
+
+	enqueueErr := make(chan error, 1)
+	go func() {
+		err := enqueue(enqueueChan)
+		enqueueErr <- err
+		close(enqueueChan)
+	}()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return true, nil
+		case block, ok := <-enqueueChan:
+			if !ok {
+				return false, <-enqueueErr
+			}
+
+			select {
+			case workerChan <- block:
+			case <-timer.C:
+				return true, nil
+			}
+		}
+	}
+}
+
+// clampStartToAvailable checks the start block against the earliest height the node still serves, pruned nodes do not serve the
+// blocks below it and every one of them would fail. A start block that was not set explicitly is moved up to the earliest height,
+// an explicit one is only moved up with base.clamp-start-to-available and is an error otherwise.
+func clampStartToAvailable(cfg config.IndexConfig, client *client.ChainClient, startBlock int64, explicitStart bool) (int64, error) {
+	earliestBlock, _, err := getEarliestAndLatestBlockHeights(client)
+	if err != nil {
+		config.Log.Errorf("Error getting blockchain earliest height. Err: %v", err)
+		return 0, err
+	}
+
+	if startBlock >= earliestBlock {
+		return startBlock, nil
+	}
+
+	switch {
+	case !explicitStart:
+		config.Log.Infof("The node's earliest available height is %d, starting there", earliestBlock)
+	case cfg.Base.ClampStartToAvailable:
+		config.Log.Warnf("Start block %d is below the node's earliest available height %d, the node is likely pruned. Starting at %d instead", startBlock, earliestBlock, earliestBlock)
+	default:
+		return 0, fmt.Errorf("start block %d is below the node's earliest available height %d, the node is likely pruned. Set base.clamp-start-to-available to start at the earliest available height instead", startBlock, earliestBlock)
+	}
+
+	return earliestBlock, nil
+}
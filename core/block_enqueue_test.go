@@ -0,0 +1,257 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	probeClient "github.com/DefiantLabs/probe/client"
+	"github.com/stretchr/testify/suite"
+)
+
+type BlockEnqueueTestSuite struct {
+	suite.Suite
+	originalGetEarliestAndLatestBlockHeights func(cl *probeClient.ChainClient) (int64, int64, error)
+}
+
+// The node serves every block from genesis unless a test sets its own earliest height
+func (suite *BlockEnqueueTestSuite) SetupTest() {
+	suite.originalGetEarliestAndLatestBlockHeights = getEarliestAndLatestBlockHeights
+	getEarliestAndLatestBlockHeights = func(cl *probeClient.ChainClient) (int64, int64, error) {
+		return 1, 100, nil
+	}
+}
+
+func (suite *BlockEnqueueTestSuite) TearDownTest() {
+	getEarliestAndLatestBlockHeights = suite.originalGetEarliestAndLatestBlockHeights
+}
+
+func (suite *BlockEnqueueTestSuite) TestDefaultEnqueueShards() {
+	originalGetLatestBlockHeight := getLatestBlockHeightWithRetry
+	defer func() { getLatestBlockHeightWithRetry = originalGetLatestBlockHeight }()
+
+	getLatestBlockHeightWithRetry = func(cl *probeClient.ChainClient, retryMaxAttempts int64, retryMaxWaitSeconds uint64) (int64, error) {
+		return 100, nil
+	}
+
+	cfg := config.IndexConfig{}
+	cfg.Base.StartBlock = 1
+	cfg.Base.EndBlock = 10
+	cfg.Base.ReIndex = true
+	cfg.Base.TransactionIndexingEnabled = true
+	cfg.Base.ShardCount = 3
+	cfg.Base.ShardIndex = 1
+
+	enqueue, err := GenerateDefaultEnqueueFunction(nil, cfg, nil, nil, 1)
+	suite.Require().NoError(err)
+
+	blockChan := make(chan *EnqueueData, 100)
+	err = enqueue(blockChan)
+	suite.Require().NoError(err)
+	close(blockChan)
+
+	var heights []int64
+	for block := range blockChan {
+		heights = append(heights, block.Height)
+	}
+
+	suite.Equal([]int64{1, 4, 7, 10}, heights)
+}
+
+func (suite *BlockEnqueueTestSuite) TestCatchUpOnlyStopsAtStartupTip() {
+	originalGetLatestBlockHeight := getLatestBlockHeightWithRetry
+	defer func() { getLatestBlockHeightWithRetry = originalGetLatestBlockHeight }()
+
+	// The chain produces 5 new blocks between every height query
+	var chainHeight int64 = 15
+	getLatestBlockHeightWithRetry = func(cl *probeClient.ChainClient, retryMaxAttempts int64, retryMaxWaitSeconds uint64) (int64, error) {
+		chainHeight += 5
+		return chainHeight, nil
+	}
+
+	cfg := config.IndexConfig{}
+	cfg.Base.StartBlock = 1
+	cfg.Base.EndBlock = -1
+	cfg.Base.ReIndex = true
+	cfg.Base.TransactionIndexingEnabled = true
+	cfg.Base.CatchUpOnly = true
+
+	enqueue, err := GenerateDefaultEnqueueFunction(nil, cfg, nil, nil, 1)
+	suite.Require().NoError(err)
+
+	// Blocks produced after startup must be ignored, the enqueue function has to return on its own
+	blockChan := make(chan *EnqueueData, 100)
+	err = enqueue(blockChan)
+	suite.Require().NoError(err)
+	close(blockChan)
+
+	var heights []int64
+	for block := range blockChan {
+		heights = append(heights, block.Height)
+	}
+
+	suite.Require().Len(heights, 20)
+	suite.Equal(int64(1), heights[0])
+	suite.Equal(int64(20), heights[len(heights)-1])
+	suite.Greater(chainHeight, int64(20))
+}
+
+func (suite *BlockEnqueueTestSuite) TestSkipHeightsAreNeverEnqueued() {
+	originalGetLatestBlockHeight := getLatestBlockHeightWithRetry
+	defer func() { getLatestBlockHeightWithRetry = originalGetLatestBlockHeight }()
+
+	getLatestBlockHeightWithRetry = func(cl *probeClient.ChainClient, retryMaxAttempts int64, retryMaxWaitSeconds uint64) (int64, error) {
+		return 100, nil
+	}
+
+	path := filepath.Join(suite.T().TempDir(), "skip-heights.json")
+	suite.Require().NoError(os.WriteFile(path, []byte("[3, 5, 6]"), 0o600))
+	heights, err := LoadSkipHeights(path)
+	suite.Require().NoError(err)
+
+	cfg := config.IndexConfig{}
+	cfg.Base.StartBlock = 1
+	cfg.Base.EndBlock = 8
+	cfg.Base.ReIndex = true
+	cfg.Base.TransactionIndexingEnabled = true
+
+	enqueue, err := GenerateDefaultEnqueueFunction(nil, cfg, heights, nil, 1)
+	suite.Require().NoError(err)
+
+	// The enqueue function still reaches the end block, the skipped heights do not hold it up
+	blockChan := make(chan *EnqueueData, 100)
+	suite.Require().NoError(enqueue(blockChan))
+	close(blockChan)
+
+	var enqueued []int64
+	for block := range blockChan {
+		enqueued = append(enqueued, block.Height)
+	}
+	suite.Equal([]int64{1, 2, 4, 7, 8}, enqueued)
+	suite.Equal([]int64{3, 5, 6}, heights.Heights())
+}
+
+func (suite *BlockEnqueueTestSuite) TestEnqueueUntilStopsAtDeadline() {
+	originalGetLatestBlockHeight := getLatestBlockHeightWithRetry
+	defer func() { getLatestBlockHeightWithRetry = originalGetLatestBlockHeight }()
+
+	// The chain is far ahead, the run can only end through the budget
+	getLatestBlockHeightWithRetry = func(cl *probeClient.ChainClient, retryMaxAttempts int64, retryMaxWaitSeconds uint64) (int64, error) {
+		return 1_000_000_000, nil
+	}
+
+	cfg := config.IndexConfig{}
+	cfg.Base.StartBlock = 1
+	cfg.Base.EndBlock = -1
+	cfg.Base.ReIndex = true
+	cfg.Base.TransactionIndexingEnabled = true
+
+	enqueue, err := GenerateDefaultEnqueueFunction(nil, cfg, nil, nil, 1)
+	suite.Require().NoError(err)
+
+	// A single worker stands in for the RPC and DB workers, committing the heights it is handed in order
+	workerChan := make(chan *EnqueueData)
+	var committed []int64
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for block := range workerChan {
+			time.Sleep(time.Millisecond)
+			committed = append(committed, block.Height)
+		}
+	}()
+
+	const budget = 100 * time.Millisecond
+	start := time.Now()
+	budgetElapsed, err := EnqueueUntil(start.Add(budget), enqueue, make(chan *EnqueueData, 10000), workerChan)
+	suite.Require().NoError(err)
+	suite.True(budgetElapsed)
+
+	// The in-flight block finishes and the worker stops on its own
+	wg.Wait()
+	suite.GreaterOrEqual(time.Since(start), budget)
+	suite.Less(time.Since(start), budget+time.Second)
+
+	// Every block handed over was committed without gaps, so the next run resumes right after the last one
+	suite.Require().NotEmpty(committed)
+	for i, height := range committed {
+		suite.Require().Equal(int64(i+1), height)
+	}
+}
+
+func (suite *BlockEnqueueTestSuite) TestEnqueueUntilFinishesBeforeDeadline() {
+	enqueue := func(blockChan chan *EnqueueData) error {
+		for height := int64(1); height <= 3; height++ {
+			blockChan <- &EnqueueData{Height: height}
+		}
+		return nil
+	}
+
+	workerChan := make(chan *EnqueueData, 10)
+	budgetElapsed, err := EnqueueUntil(time.Now().Add(time.Minute), enqueue, make(chan *EnqueueData, 10), workerChan)
+	suite.Require().NoError(err)
+	suite.False(budgetElapsed)
+
+	var heights []int64
+	for block := range workerChan {
+		heights = append(heights, block.Height)
+	}
+	suite.Equal([]int64{1, 2, 3}, heights)
+}
+
+func (suite *BlockEnqueueTestSuite) TestStartBelowEarliestAvailableHeight() {
+	originalGetLatestBlockHeight := getLatestBlockHeightWithRetry
+	defer func() { getLatestBlockHeightWithRetry = originalGetLatestBlockHeight }()
+
+	getLatestBlockHeightWithRetry = func(cl *probeClient.ChainClient, retryMaxAttempts int64, retryMaxWaitSeconds uint64) (int64, error) {
+		return 100, nil
+	}
+	// A pruned node that no longer serves the blocks below 95
+	getEarliestAndLatestBlockHeights = func(cl *probeClient.ChainClient) (int64, int64, error) {
+		return 95, 100, nil
+	}
+
+	cfg := config.IndexConfig{}
+	cfg.Base.StartBlock = 1
+	cfg.Base.EndBlock = 97
+	cfg.Base.ReIndex = true
+	cfg.Base.TransactionIndexingEnabled = true
+
+	_, err := GenerateDefaultEnqueueFunction(nil, cfg, nil, nil, 1)
+	suite.ErrorContains(err, "start block 1 is below the node's earliest available height 95")
+
+	enqueuedHeights := func(cfg config.IndexConfig) []int64 {
+		enqueue, err := GenerateDefaultEnqueueFunction(nil, cfg, nil, nil, 1)
+		suite.Require().NoError(err)
+
+		blockChan := make(chan *EnqueueData, 100)
+		suite.Require().NoError(enqueue(blockChan))
+		close(blockChan)
+
+		var heights []int64
+		for block := range blockChan {
+			heights = append(heights, block.Height)
+		}
+		return heights
+	}
+
+	cfg.Base.ClampStartToAvailable = true
+	suite.Equal([]int64{95, 96, 97}, enqueuedHeights(cfg))
+
+	// Without an explicit start block the earliest available height is used either way
+	cfg.Base.ClampStartToAvailable = false
+	cfg.Base.StartBlock = 0
+	suite.Equal([]int64{95, 96, 97}, enqueuedHeights(cfg))
+
+	// Start blocks the node serves are left alone
+	cfg.Base.StartBlock = 96
+	suite.Equal([]int64{96, 97}, enqueuedHeights(cfg))
+}
+
+func TestBlockEnqueueTestSuite(t *testing.T) {
+	suite.Run(t, new(BlockEnqueueTestSuite))
+}
@@ -1,7 +1,8 @@
 package core
 
 import (
-	"encoding/base64"
+	"context"
+	"fmt"
 
 	abci "github.com/cometbft/cometbft/abci/types"
 
@@ -13,7 +14,11 @@ import (
 	"github.com/DefiantLabs/cosmos-indexer/rpc"
 )
 
-func ProcessRPCBlockResults(conf config.IndexConfig, block models.Block, blockResults *rpc.CustomBlockResults, customBeginBlockParsers map[string][]parsers.BlockEventParser, customEndBlockParsers map[string][]parsers.BlockEventParser) (*db.BlockDBWrapper, error) {
+func ProcessRPCBlockResults(ctx context.Context, conf config.IndexConfig, block models.Block, blockResults *rpc.CustomBlockResults, customBeginBlockParsers map[string][]parsers.BlockEventParser, customEndBlockParsers map[string][]parsers.BlockEventParser) (*db.BlockDBWrapper, error) {
+	if blockResults == nil {
+		return nil, fmt.Errorf("ProcessRPCBlockResults: block results data is missing for block %d", block.Height)
+	}
+
 	var blockDBWrapper db.BlockDBWrapper
 
 	blockDBWrapper.Block = &block
@@ -22,12 +27,12 @@ func ProcessRPCBlockResults(conf config.IndexConfig, block models.Block, blockRe
 	blockDBWrapper.UniqueBlockEventTypes = make(map[string]models.BlockEventType)
 
 	var err error
-	blockDBWrapper.BeginBlockEvents, err = ProcessRPCBlockEvents(blockDBWrapper.Block, blockResults.BeginBlockEvents, models.BeginBlockEvent, blockDBWrapper.UniqueBlockEventTypes, blockDBWrapper.UniqueBlockEventAttributeKeys, customBeginBlockParsers, conf)
+	blockDBWrapper.BeginBlockEvents, err = ProcessRPCBlockEvents(ctx, blockDBWrapper.Block, blockResults.BeginBlockEvents, models.BeginBlockEvent, blockDBWrapper.UniqueBlockEventTypes, blockDBWrapper.UniqueBlockEventAttributeKeys, customBeginBlockParsers, conf)
 	if err != nil {
 		return nil, err
 	}
 
-	blockDBWrapper.EndBlockEvents, err = ProcessRPCBlockEvents(blockDBWrapper.Block, blockResults.EndBlockEvents, models.EndBlockEvent, blockDBWrapper.UniqueBlockEventTypes, blockDBWrapper.UniqueBlockEventAttributeKeys, customEndBlockParsers, conf)
+	blockDBWrapper.EndBlockEvents, err = ProcessRPCBlockEvents(ctx, blockDBWrapper.Block, blockResults.EndBlockEvents, models.EndBlockEvent, blockDBWrapper.UniqueBlockEventTypes, blockDBWrapper.UniqueBlockEventAttributeKeys, customEndBlockParsers, conf)
 	if err != nil {
 		return nil, err
 	}
@@ -35,10 +40,16 @@ func ProcessRPCBlockResults(conf config.IndexConfig, block models.Block, blockRe
 	return &blockDBWrapper, nil
 }
 
-func ProcessRPCBlockEvents(block *models.Block, blockEvents []abci.Event, blockLifecyclePosition models.BlockLifecyclePosition, uniqueEventTypes map[string]models.BlockEventType, uniqueAttributeKeys map[string]models.BlockEventAttributeKey, customParsers map[string][]parsers.BlockEventParser, conf config.IndexConfig) ([]db.BlockEventDBWrapper, error) {
+func ProcessRPCBlockEvents(ctx context.Context, block *models.Block, blockEvents []abci.Event, blockLifecyclePosition models.BlockLifecyclePosition, uniqueEventTypes map[string]models.BlockEventType, uniqueAttributeKeys map[string]models.BlockEventAttributeKey, customParsers map[string][]parsers.BlockEventParser, conf config.IndexConfig) ([]db.BlockEventDBWrapper, error) {
 	beginBlockEvents := make([]db.BlockEventDBWrapper, len(blockEvents))
+	encoded := eventAttributesEncoded(conf, blockEvents)
 
 	for index, event := range blockEvents {
+		// Parsers are not interrupted, a cancelled context stops processing before the next event
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		eventType := models.BlockEventType{
 			Type: event.Type,
 		}
@@ -54,26 +65,9 @@ func ProcessRPCBlockEvents(block *models.Block, blockEvents []abci.Event, blockL
 		beginBlockEvents[index].Attributes = make([]models.BlockEventAttribute, len(event.Attributes))
 
 		for attrIndex, attribute := range event.Attributes {
-
-			var value string
-			var keyItem string
-			if conf.Flags.BlockEventsBase64Encoded {
-				// Should we even be decoding these from base64? What are the implications?
-				valueBytes, err := base64.StdEncoding.DecodeString(attribute.Value)
-				if err != nil {
-					return nil, err
-				}
-
-				keyBytes, err := base64.StdEncoding.DecodeString(attribute.Key)
-				if err != nil {
-					return nil, err
-				}
-
-				value = string(valueBytes)
-				keyItem = string(keyBytes)
-			} else {
-				value = attribute.Value
-				keyItem = attribute.Key
+			keyItem, value, valueBase64, err := normalizeEventAttribute(attribute.Key, attribute.Value, encoded)
+			if err != nil {
+				return nil, err
 			}
 
 			key := models.BlockEventAttributeKey{
@@ -82,6 +76,7 @@ func ProcessRPCBlockEvents(block *models.Block, blockEvents []abci.Event, blockL
 
 			beginBlockEvents[index].Attributes[attrIndex] = models.BlockEventAttribute{
 				Value:                  value,
+				ValueBase64:            valueBase64,
 				BlockEventAttributeKey: key,
 				Index:                  uint64(attrIndex),
 			}
@@ -92,13 +87,13 @@ func ProcessRPCBlockEvents(block *models.Block, blockEvents []abci.Event, blockL
 
 		if customParsers != nil {
 			if customBlockEventParsers, ok := customParsers[event.Type]; ok {
-				for index, customParser := range customBlockEventParsers {
+				for parserIndex, customParser := range customBlockEventParsers {
 					// We deliberately ignore the error here, as we want to continue processing the block events even if a custom parser fails
 					parsedData, err := customParser.ParseBlockEvent(event, conf)
 					beginBlockEvents[index].BlockEventParsedDatasets = append(beginBlockEvents[index].BlockEventParsedDatasets, parsers.BlockEventParsedData{
 						Data:   parsedData,
 						Error:  err,
-						Parser: &customBlockEventParsers[index],
+						Parser: &customBlockEventParsers[parserIndex],
 					})
 				}
 			}
@@ -110,9 +105,15 @@ func ProcessRPCBlockEvents(block *models.Block, blockEvents []abci.Event, blockL
 }
 
 func FilterRPCBlockEvents(blockEvents []db.BlockEventDBWrapper, filterRegistry filter.StaticBlockEventFilterRegistry) ([]db.BlockEventDBWrapper, error) {
+	filteredBlockEvents, _, err := PartitionRPCBlockEvents(blockEvents, filterRegistry)
+	return filteredBlockEvents, err
+}
+
+// PartitionRPCBlockEvents applies the filters like FilterRPCBlockEvents, also returning the block events the filters dropped
+func PartitionRPCBlockEvents(blockEvents []db.BlockEventDBWrapper, filterRegistry filter.StaticBlockEventFilterRegistry) ([]db.BlockEventDBWrapper, []db.BlockEventDBWrapper, error) {
 	// If there are no filters, just return the block events
 	if len(filterRegistry.BlockEventFilters) == 0 && len(filterRegistry.RollingWindowEventFilters) == 0 {
-		return blockEvents, nil
+		return blockEvents, nil, nil
 	}
 
 	filterIndexes := make(map[int]bool)
@@ -128,7 +129,7 @@ func FilterRPCBlockEvents(blockEvents []db.BlockEventDBWrapper, filterRegistry f
 		for _, filter := range filterRegistry.BlockEventFilters {
 			patternMatch, err := filter.EventMatches(filterEvent)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if patternMatch {
 				filterIndexes[index] = filter.IncludeMatch()
@@ -151,7 +152,7 @@ func FilterRPCBlockEvents(blockEvents []db.BlockEventDBWrapper, filterRegistry f
 
 				patternMatches, err := rollingWindowFilter.EventsMatch(filterEvents)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 
 				if patternMatches {
@@ -165,12 +166,15 @@ func FilterRPCBlockEvents(blockEvents []db.BlockEventDBWrapper, filterRegistry f
 
 	// Filter the block events based on the indexes that matched the registered patterns
 	filteredBlockEvents := make([]db.BlockEventDBWrapper, 0)
+	var droppedBlockEvents []db.BlockEventDBWrapper
 
 	for index, blockEvent := range blockEvents {
 		if filterIndexes[index] {
 			filteredBlockEvents = append(filteredBlockEvents, blockEvent)
+		} else {
+			droppedBlockEvents = append(droppedBlockEvents, blockEvent)
 		}
 	}
 
-	return filteredBlockEvents, nil
+	return filteredBlockEvents, droppedBlockEvents, nil
 }
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/filter"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type BlockEventsTestSuite struct {
+	suite.Suite
+}
+
+func encodedAttribute(key string, value []byte) abci.EventAttribute {
+	return abci.EventAttribute{
+		Key:   base64.StdEncoding.EncodeToString([]byte(key)),
+		Value: base64.StdEncoding.EncodeToString(value),
+	}
+}
+
+func transferEvents(encode bool) []abci.Event {
+	if encode {
+		return []abci.Event{{
+			Type: "transfer",
+			Attributes: []abci.EventAttribute{
+				encodedAttribute("recipient", []byte("cosmos1recipient")),
+				encodedAttribute("sender", []byte("cosmos1sender")),
+				encodedAttribute("amount", []byte("100uatom")),
+			},
+		}}
+	}
+
+	return []abci.Event{{
+		Type: "transfer",
+		Attributes: []abci.EventAttribute{
+			{Key: "recipient", Value: "cosmos1recipient"},
+			{Key: "sender", Value: "cosmos1sender"},
+			{Key: "amount", Value: "100uatom"},
+		},
+	}}
+}
+
+func (suite *BlockEventsTestSuite) processEvents(mode string, events []abci.Event) []models.BlockEventAttribute {
+	conf := config.IndexConfig{}
+	conf.Base.DecodeEventAttributes = mode
+
+	processed, err := ProcessRPCBlockEvents(context.Background(), &models.Block{}, events, models.BeginBlockEvent, map[string]models.BlockEventType{}, map[string]models.BlockEventAttributeKey{}, nil, conf)
+	suite.Require().NoError(err)
+	suite.Require().Len(processed, 1)
+	return processed[0].Attributes
+}
+
+func (suite *BlockEventsTestSuite) assertTransferAttributes(attributes []models.BlockEventAttribute) {
+	suite.Require().Len(attributes, 3)
+	suite.Equal("recipient", attributes[0].BlockEventAttributeKey.Key)
+	suite.Equal("cosmos1recipient", attributes[0].Value)
+	suite.Equal("sender", attributes[1].BlockEventAttributeKey.Key)
+	suite.Equal("cosmos1sender", attributes[1].Value)
+	suite.Equal("amount", attributes[2].BlockEventAttributeKey.Key)
+	suite.Equal("100uatom", attributes[2].Value)
+	for _, attribute := range attributes {
+		suite.False(attribute.ValueBase64)
+	}
+}
+
+func (suite *BlockEventsTestSuite) TestAutoDetectsEncodedAttributes() {
+	suite.assertTransferAttributes(suite.processEvents(config.DecodeEventAttributesAuto, transferEvents(true)))
+}
+
+func (suite *BlockEventsTestSuite) TestAutoKeepsPlainAttributes() {
+	suite.assertTransferAttributes(suite.processEvents(config.DecodeEventAttributesAuto, transferEvents(false)))
+}
+
+func (suite *BlockEventsTestSuite) TestAlwaysDecodes() {
+	suite.assertTransferAttributes(suite.processEvents(config.DecodeEventAttributesAlways, transferEvents(true)))
+}
+
+func (suite *BlockEventsTestSuite) TestNeverDecodes() {
+	attributes := suite.processEvents(config.DecodeEventAttributesNever, transferEvents(true))
+	suite.Equal(base64.StdEncoding.EncodeToString([]byte("recipient")), attributes[0].BlockEventAttributeKey.Key)
+}
+
+func (suite *BlockEventsTestSuite) TestInvalidUTF8ValueStaysBase64() {
+	rawValue := []byte{0xff, 0xfe, 0x00, 0x01}
+	events := []abci.Event{{
+		Type: "store",
+		Attributes: []abci.EventAttribute{
+			encodedAttribute("key", []byte("c3RvcmU=")),
+			encodedAttribute("value", rawValue),
+		},
+	}}
+
+	attributes := suite.processEvents(config.DecodeEventAttributesAuto, events)
+	suite.Require().Len(attributes, 2)
+	suite.Equal("key", attributes[0].BlockEventAttributeKey.Key)
+	suite.False(attributes[0].ValueBase64)
+	suite.Equal("value", attributes[1].BlockEventAttributeKey.Key)
+	suite.Equal(base64.StdEncoding.EncodeToString(rawValue), attributes[1].Value)
+	suite.True(attributes[1].ValueBase64)
+}
+
+func (suite *BlockEventsTestSuite) TestPartitionReturnsDroppedEvents() {
+	blockEvents := []db.BlockEventDBWrapper{
+		{BlockEvent: models.BlockEvent{Index: 0, BlockEventType: models.BlockEventType{Type: "coin_spent"}}},
+		{BlockEvent: models.BlockEvent{Index: 1, BlockEventType: models.BlockEventType{Type: "mint"}}},
+		{BlockEvent: models.BlockEvent{Index: 2, BlockEventType: models.BlockEventType{Type: "coin_received"}}},
+	}
+	registry := filter.StaticBlockEventFilterRegistry{BlockEventFilters: []filter.BlockEventFilter{filter.NewDefaultBlockEventTypeFilter("mint", true)}}
+
+	included, dropped, err := PartitionRPCBlockEvents(blockEvents, registry)
+	suite.Require().NoError(err)
+	suite.Equal(blockEvents[1:2], included)
+	suite.Equal([]db.BlockEventDBWrapper{blockEvents[0], blockEvents[2]}, dropped)
+}
+
+func TestBlockEventsSuite(t *testing.T) {
+	suite.Run(t, new(BlockEventsTestSuite))
+}
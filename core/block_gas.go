@@ -0,0 +1,28 @@
+package core
+
+import (
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// getBlockMaxGas queries the block max gas consensus param at the height, overridden in tests
+var getBlockMaxGas = rpc.GetBlockMaxGas
+
+// BlockGasUsed sums the gas used by every tx of the block, from the block results when they were fetched and from the tx
+// responses otherwise. Blocks without txs used no gas. It returns false when neither dataset is available.
+func BlockGasUsed(blockResults *rpc.CustomBlockResults, txsResponse *txTypes.GetTxsEventResponse) (int64, bool) {
+	var gasUsed int64
+	switch {
+	case blockResults != nil:
+		for _, txResult := range blockResults.TxsResults {
+			gasUsed += txResult.GasUsed
+		}
+	case txsResponse != nil:
+		for _, txResponse := range txsResponse.TxResponses {
+			gasUsed += txResponse.GasUsed
+		}
+	default:
+		return 0, false
+	}
+	return gasUsed, true
+}
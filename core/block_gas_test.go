@@ -0,0 +1,57 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	abci "github.com/cometbft/cometbft/abci/types"
+	sdkTypes "github.com/cosmos/cosmos-sdk/types"
+	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/stretchr/testify/suite"
+)
+
+type BlockGasTestSuite struct {
+	suite.Suite
+}
+
+func (suite *BlockGasTestSuite) TestGasUsedFromBlockResults() {
+	blockResults := &rpc.CustomBlockResults{TxsResults: []*abci.ResponseDeliverTx{
+		{GasUsed: 85000},
+		{GasUsed: 120500},
+		// Failed txs still use gas
+		{Code: 5, GasUsed: 40000},
+	}}
+
+	gasUsed, ok := BlockGasUsed(blockResults, nil)
+	suite.Require().True(ok)
+	suite.Equal(int64(85000+120500+40000), gasUsed)
+}
+
+func (suite *BlockGasTestSuite) TestGasUsedFromTxResponses() {
+	txsResponse := &txTypes.GetTxsEventResponse{TxResponses: []*sdkTypes.TxResponse{
+		{GasUsed: 85000},
+		{GasUsed: 120500},
+	}}
+
+	gasUsed, ok := BlockGasUsed(nil, txsResponse)
+	suite.Require().True(ok)
+	suite.Equal(int64(205500), gasUsed)
+}
+
+func (suite *BlockGasTestSuite) TestBlockWithoutTxs() {
+	gasUsed, ok := BlockGasUsed(&rpc.CustomBlockResults{}, nil)
+	suite.Require().True(ok)
+	suite.Zero(gasUsed)
+
+	gasUsed, ok = BlockGasUsed(nil, &txTypes.GetTxsEventResponse{})
+	suite.Require().True(ok)
+	suite.Zero(gasUsed)
+
+	// Without any tx data the gas used is unknown
+	_, ok = BlockGasUsed(nil, nil)
+	suite.False(ok)
+}
+
+func TestBlockGasTestSuite(t *testing.T) {
+	suite.Run(t, new(BlockGasTestSuite))
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// BlockTxStats counts the txs of the block and the txs that failed (non-zero code), from the block results when they were fetched
+// and from the tx responses otherwise. It returns false when neither dataset is available.
+func BlockTxStats(blockResults *rpc.CustomBlockResults, txsResponse *txTypes.GetTxsEventResponse) (txCount int64, failedTxCount int64, ok bool) {
+	switch {
+	case blockResults != nil:
+		for _, txResult := range blockResults.TxsResults {
+			txCount++
+			if txResult.Code != 0 {
+				failedTxCount++
+			}
+		}
+	case txsResponse != nil:
+		for _, txResponse := range txsResponse.TxResponses {
+			txCount++
+			if txResponse.Code != 0 {
+				failedTxCount++
+			}
+		}
+	default:
+		return 0, 0, false
+	}
+	return txCount, failedTxCount, true
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	abci "github.com/cometbft/cometbft/abci/types"
+	sdkTypes "github.com/cosmos/cosmos-sdk/types"
+	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/stretchr/testify/suite"
+)
+
+type BlockTxStatsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *BlockTxStatsTestSuite) TestMixedBlockResults() {
+	blockResults := &rpc.CustomBlockResults{TxsResults: []*abci.ResponseDeliverTx{
+		{Code: 0},
+		{Code: 5, Codespace: "sdk"},
+		{Code: 0},
+		{Code: 11, Codespace: "sdk"},
+		{Code: 0},
+	}}
+
+	txCount, failedTxCount, ok := BlockTxStats(blockResults, nil)
+	suite.Require().True(ok)
+	suite.Equal(int64(5), txCount)
+	suite.Equal(int64(2), failedTxCount)
+}
+
+func (suite *BlockTxStatsTestSuite) TestMixedTxResponses() {
+	txsResponse := &txTypes.GetTxsEventResponse{TxResponses: []*sdkTypes.TxResponse{
+		{Code: 0},
+		{Code: 13},
+	}}
+
+	txCount, failedTxCount, ok := BlockTxStats(nil, txsResponse)
+	suite.Require().True(ok)
+	suite.Equal(int64(2), txCount)
+	suite.Equal(int64(1), failedTxCount)
+}
+
+func (suite *BlockTxStatsTestSuite) TestWithoutTxData() {
+	txCount, failedTxCount, ok := BlockTxStats(&rpc.CustomBlockResults{}, nil)
+	suite.Require().True(ok)
+	suite.Zero(txCount)
+	suite.Zero(failedTxCount)
+
+	_, _, ok = BlockTxStats(nil, nil)
+	suite.False(ok)
+}
+
+func TestBlockTxStatsTestSuite(t *testing.T) {
+	suite.Run(t, new(BlockTxStatsTestSuite))
+}
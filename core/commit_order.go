@@ -0,0 +1,154 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+)
+
+// CommitOrder is the reorder buffer of base.ordered-commits. Blocks are fetched by the RPC workers in parallel and complete in any order,
+// the buffer holds completed blocks back until every block handed to the workers before them has completed or been dropped, so blocks
+// leave the buffer in the order they were enqueued. The enqueue functions enqueue blocks in ascending height order.
+type CommitOrder struct {
+	lock sync.Mutex
+	// Heights handed to the workers that have not left the buffer yet, in hand-over order
+	pending []int64
+	// Completed blocks per height, a nil entry is a dropped block
+	completed map[int64][]*IndexerBlockEventData
+	// Limits the number of pending blocks, so the buffer cannot grow without bound behind a slow block
+	window chan struct{}
+	// Signalled when a block is dropped, so Run passes on the blocks held back behind it
+	dropped chan struct{}
+	output  chan IndexerBlockEventData
+}
+
+// NewCommitOrder creates a reorder buffer holding at most window blocks between their hand-over to the workers and leaving the buffer
+func NewCommitOrder(window int, output chan IndexerBlockEventData) *CommitOrder {
+	if window < 1 {
+		window = 1
+	}
+
+	return &CommitOrder{
+		completed: make(map[int64][]*IndexerBlockEventData),
+		window:    make(chan struct{}, window),
+		dropped:   make(chan struct{}, 1),
+		output:    output,
+	}
+}
+
+// Dispatch hands the enqueued blocks over to the workers, recording the order they were handed over in. The worker channel is closed
+// once the enqueue channel is.
+func (order *CommitOrder) Dispatch(blockEnqueueChan chan *EnqueueData, workerChan chan *EnqueueData) {
+	defer close(workerChan)
+
+	for block := range blockEnqueueChan {
+		order.window <- struct{}{}
+
+		order.lock.Lock()
+		order.pending = append(order.pending, block.Height)
+		order.lock.Unlock()
+
+		workerChan <- block
+	}
+}
+
+// Run passes the blocks completed by the workers on to the output channel in hand-over order. It is the only sender on the output
+// channel, which it sends on without holding the buffer lock. The output channel is closed once the worker output channel is.
+func (order *CommitOrder) Run(workerOutputChan chan IndexerBlockEventData) {
+	defer close(order.output)
+
+	for {
+		var ready []*IndexerBlockEventData
+		select {
+		case blockData, ok := <-workerOutputChan:
+			if !ok {
+				// Every worker has exited, so no more blocks are dropped either
+				order.send(order.takeReady())
+				order.dropHeldBack()
+				return
+			}
+			ready = order.complete(blockData.BlockData.Block.Height, &blockData)
+		case <-order.dropped:
+			ready = order.takeReady()
+		}
+		order.send(ready)
+	}
+}
+
+// Drop records that the block at the height will not be completed, e.g. because it could not be fetched, so the blocks after it are
+// not held back. A nil buffer ignores drops.
+func (order *CommitOrder) Drop(height int64) {
+	if order == nil {
+		return
+	}
+
+	order.lock.Lock()
+	order.completed[height] = append(order.completed[height], nil)
+	order.lock.Unlock()
+
+	select {
+	case order.dropped <- struct{}{}:
+	default:
+	}
+}
+
+// complete records the completed block and returns the blocks that can leave the buffer
+func (order *CommitOrder) complete(height int64, blockData *IndexerBlockEventData) []*IndexerBlockEventData {
+	order.lock.Lock()
+	order.completed[height] = append(order.completed[height], blockData)
+	order.lock.Unlock()
+
+	return order.takeReady()
+}
+
+// takeReady removes the blocks that are next in hand-over order from the buffer, dropped blocks are skipped
+func (order *CommitOrder) takeReady() []*IndexerBlockEventData {
+	order.lock.Lock()
+	defer order.lock.Unlock()
+
+	var ready []*IndexerBlockEventData
+	for len(order.pending) != 0 {
+		next := order.pending[0]
+		completed := order.completed[next]
+		if len(completed) == 0 {
+			break
+		}
+
+		if len(completed) == 1 {
+			delete(order.completed, next)
+		} else {
+			order.completed[next] = completed[1:]
+		}
+		order.pending = order.pending[1:]
+		<-order.window
+
+		if completed[0] != nil {
+			ready = append(ready, completed[0])
+		}
+	}
+	return ready
+}
+
+func (order *CommitOrder) send(ready []*IndexerBlockEventData) {
+	for _, blockData := range ready {
+		order.output <- *blockData
+	}
+}
+
+// dropHeldBack drops the blocks still held back once every worker has exited. They are waiting on a block handed over before them that
+// never came back, passing them on would commit them out of order.
+func (order *CommitOrder) dropHeldBack() {
+	order.lock.Lock()
+	defer order.lock.Unlock()
+
+	for _, height := range order.pending {
+		for _, blockData := range order.completed[height] {
+			if blockData != nil {
+				config.Log.Errorf("Block %d was held back on a block enqueued before it that never completed, dropping it", height)
+				blockData.Inflight.Release()
+			}
+		}
+		delete(order.completed, height)
+	}
+	order.pending = nil
+}
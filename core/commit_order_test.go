@@ -0,0 +1,111 @@
+package core
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommitOrderTestSuite struct {
+	suite.Suite
+}
+
+// Runs blocks through workers that complete them out of order, with gaps in the enqueued heights and blocks that fail to fetch
+func (suite *CommitOrderTestSuite) TestCommittedHeightsStrictlyIncreasing() {
+	output := make(chan IndexerBlockEventData)
+	order := NewCommitOrder(6, output)
+
+	blockEnqueueChan := make(chan *EnqueueData, 10)
+	workerChan := make(chan *EnqueueData)
+	workerOutputChan := make(chan IndexerBlockEventData, 10)
+
+	go order.Dispatch(blockEnqueueChan, workerChan)
+	go order.Run(workerOutputChan)
+
+	var expected []int64
+	go func() {
+		for height := int64(100); height < 400; height++ {
+			// Skipped heights are never enqueued
+			if height%5 == 0 {
+				continue
+			}
+			blockEnqueueChan <- &EnqueueData{Height: height}
+		}
+		close(blockEnqueueChan)
+	}()
+	for height := int64(100); height < 400; height++ {
+		if height%5 != 0 && height%7 != 0 {
+			expected = append(expected, height)
+		}
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for block := range workerChan {
+				time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond) //nolint:gosec
+				// Some blocks fail to fetch and are never passed on
+				if block.Height%7 == 0 {
+					order.Drop(block.Height)
+					continue
+				}
+				workerOutputChan <- IndexerBlockEventData{BlockData: &ctypes.ResultBlock{Block: &cmtTypes.Block{Header: cmtTypes.Header{Height: block.Height}}}}
+			}
+		}()
+	}
+
+	go func() {
+		workers.Wait()
+		close(workerOutputChan)
+	}()
+
+	var committed []int64
+	for blockData := range output {
+		height := blockData.BlockData.Block.Height
+		if len(committed) != 0 {
+			suite.Require().Greater(height, committed[len(committed)-1])
+		}
+		committed = append(committed, height)
+	}
+
+	suite.Equal(expected, committed)
+}
+
+func (suite *CommitOrderTestSuite) TestHeldBackBlocksDroppedAtShutdown() {
+	output := make(chan IndexerBlockEventData, 2)
+	order := NewCommitOrder(2, output)
+
+	blockEnqueueChan := make(chan *EnqueueData, 2)
+	workerChan := make(chan *EnqueueData, 2)
+	workerOutputChan := make(chan IndexerBlockEventData, 2)
+	blockEnqueueChan <- &EnqueueData{Height: 1}
+	blockEnqueueChan <- &EnqueueData{Height: 2}
+	close(blockEnqueueChan)
+	order.Dispatch(blockEnqueueChan, workerChan)
+
+	// The block at height 1 never comes back from the workers
+	limiter := NewInflightLimiter(0)
+	workerOutputChan <- IndexerBlockEventData{BlockData: &ctypes.ResultBlock{Block: &cmtTypes.Block{Header: cmtTypes.Header{Height: 2}}}, Inflight: limiter.Admit()}
+	close(workerOutputChan)
+	order.Run(workerOutputChan)
+
+	_, ok := <-output
+	suite.False(ok)
+	suite.Equal(int64(0), limiter.InFlight())
+}
+
+func (suite *CommitOrderTestSuite) TestNilCommitOrderIgnoresDrops() {
+	var order *CommitOrder
+	suite.NotPanics(func() { order.Drop(10) })
+}
+
+func TestCommitOrder(t *testing.T) {
+	suite.Run(t, new(CommitOrderTestSuite))
+}
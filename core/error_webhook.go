@@ -0,0 +1,150 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+)
+
+const (
+	errorWebhookQueueSize = 100
+	errorWebhookRetries   = 2
+	errorWebhookTimeout   = 10 * time.Second
+)
+
+// FailedBlockEvent is the JSON payload posted to the error webhook when a block is marked as failed
+type FailedBlockEvent struct {
+	ChainID  string    `json:"chain_id"`
+	Height   int64     `json:"height"`
+	Category string    `json:"category"`
+	Message  string    `json:"message"`
+	Attempt  int       `json:"attempt"`
+	Time     time.Time `json:"time"`
+}
+
+// ErrorWebhook posts failed block events to a URL in the background. Delivery is best-effort: events are dropped when the
+// queue is full and given up on after a few failed deliveries, so alerting never holds up indexing.
+type ErrorWebhook struct {
+	url        string
+	chainID    string
+	client     *http.Client
+	retryDelay time.Duration
+	queue      chan FailedBlockEvent
+	done       chan struct{}
+
+	attemptsLock sync.Mutex
+	attempts     map[int64]int
+}
+
+// NewErrorWebhook creates a webhook posting the failed blocks of the chain to the URL and starts its delivery worker
+func NewErrorWebhook(url string, chainID string) *ErrorWebhook {
+	webhook := &ErrorWebhook{
+		url:        url,
+		chainID:    chainID,
+		client:     &http.Client{Timeout: errorWebhookTimeout},
+		retryDelay: time.Second,
+		queue:      make(chan FailedBlockEvent, errorWebhookQueueSize),
+		done:       make(chan struct{}),
+		attempts:   make(map[int64]int),
+	}
+
+	go webhook.run()
+	return webhook
+}
+
+// FailedBlockHandler returns a handler that logs failed blocks with HandleFailedBlock and notifies the webhook of them.
+// The handler of a nil webhook only logs.
+func (webhook *ErrorWebhook) FailedBlockHandler() FailedBlockHandler {
+	return func(height int64, code BlockProcessingFailure, err error) {
+		HandleFailedBlock(height, code, err)
+		webhook.Notify(height, code, err)
+	}
+}
+
+// Notify queues a failed block event without waiting for its delivery. The attempt counts the failures of the height in this run.
+func (webhook *ErrorWebhook) Notify(height int64, code BlockProcessingFailure, err error) {
+	if webhook == nil {
+		return
+	}
+
+	webhook.attemptsLock.Lock()
+	webhook.attempts[height]++
+	attempt := webhook.attempts[height]
+	webhook.attemptsLock.Unlock()
+
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+
+	event := FailedBlockEvent{
+		ChainID:  webhook.chainID,
+		Height:   height,
+		Category: code.String(),
+		Message:  message,
+		Attempt:  attempt,
+		Time:     time.Now().UTC(),
+	}
+
+	select {
+	case webhook.queue <- event:
+	default:
+		config.Log.Warnf("Error webhook queue is full, dropping the event for failed block %d", height)
+	}
+}
+
+// Close stops accepting events and waits up to the timeout for the queued events to be delivered
+func (webhook *ErrorWebhook) Close(timeout time.Duration) {
+	close(webhook.queue)
+	select {
+	case <-webhook.done:
+	case <-time.After(timeout):
+		config.Log.Warn("Timed out delivering the queued error webhook events")
+	}
+}
+
+func (webhook *ErrorWebhook) run() {
+	defer close(webhook.done)
+	for event := range webhook.queue {
+		webhook.deliver(event)
+	}
+}
+
+func (webhook *ErrorWebhook) deliver(event FailedBlockEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		config.Log.Errorf("Error encoding error webhook event for block %d. Err: %v", event.Height, err)
+		return
+	}
+
+	for try := 0; try <= errorWebhookRetries; try++ {
+		if try > 0 {
+			time.Sleep(webhook.retryDelay * time.Duration(try))
+		}
+
+		err = webhook.post(payload)
+		if err == nil {
+			return
+		}
+	}
+
+	config.Log.Warnf("Failed to deliver the error webhook event for block %d, giving up. Err: %v", event.Height, err)
+}
+
+func (webhook *ErrorWebhook) post(payload []byte) error {
+	resp, err := webhook.client.Post(webhook.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
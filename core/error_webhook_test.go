@@ -0,0 +1,101 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorWebhookTestSuite struct {
+	suite.Suite
+}
+
+// webhookServer records the payloads it receives, failing the first failures requests
+func webhookServer(failures int) (*httptest.Server, func() []map[string]any) {
+	var lock sync.Mutex
+	var payloads []map[string]any
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		requests++
+		if requests <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]any
+		if err := json.Unmarshal(body, &payload); err == nil && r.Header.Get("Content-Type") == "application/json" {
+			payloads = append(payloads, payload)
+		}
+	}))
+
+	return server, func() []map[string]any {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]map[string]any{}, payloads...)
+	}
+}
+
+func (suite *ErrorWebhookTestSuite) TestFailedBlockPayload() {
+	// The first delivery fails and is retried
+	server, received := webhookServer(1)
+	defer server.Close()
+
+	webhook := NewErrorWebhook(server.URL, "cosmoshub-4")
+	webhook.retryDelay = time.Millisecond
+	handleFailedBlock := webhook.FailedBlockHandler()
+
+	handleFailedBlock(100, BlockProcessingTimeout, errors.New("context deadline exceeded"))
+	handleFailedBlock(100, UnprocessableTxError, errors.New("tx decode failed"))
+	webhook.Close(5 * time.Second)
+
+	payloads := received()
+	suite.Require().Len(payloads, 2)
+
+	first := payloads[0]
+	suite.Len(first, 6)
+	suite.Equal("cosmoshub-4", first["chain_id"])
+	suite.Equal(float64(100), first["height"])
+	suite.Equal("block_processing_timeout", first["category"])
+	suite.Equal("context deadline exceeded", first["message"])
+	suite.Equal(float64(1), first["attempt"])
+	_, err := time.Parse(time.RFC3339Nano, first["time"].(string))
+	suite.NoError(err)
+
+	// A repeated failure of the same height counts as another attempt
+	suite.Equal("unprocessable_tx", payloads[1]["category"])
+	suite.Equal(float64(2), payloads[1]["attempt"])
+}
+
+func (suite *ErrorWebhookTestSuite) TestNotifyDoesNotBlock() {
+	// The server never answers in time and the queue fills up, reporting failures must still return immediately
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+	}))
+	defer server.Close()
+	defer close(blocked)
+
+	webhook := NewErrorWebhook(server.URL, "cosmoshub-4")
+
+	start := time.Now()
+	for height := int64(1); height <= 2*errorWebhookQueueSize; height++ {
+		webhook.Notify(height, BlockQueryError, errors.New("rpc unavailable"))
+	}
+	suite.Less(time.Since(start), time.Second)
+}
+
+func TestErrorWebhookTestSuite(t *testing.T) {
+	suite.Run(t, new(ErrorWebhookTestSuite))
+}
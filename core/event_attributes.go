@@ -0,0 +1,82 @@
+package core
+
+import (
+	"encoding/base64"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	abci "github.com/cometbft/cometbft/abci/types"
+)
+
+// eventAttributesEncoded reports whether the attributes of the events should be base64 decoded before storage, according to the
+// configured mode. Auto-detection treats the events as encoded when every attribute key is valid base64 decoding to a printable key,
+// plain keys such as "sender" or "amount" are either not valid base64 or decode to unprintable bytes.
+func eventAttributesEncoded(conf config.IndexConfig, events []abci.Event) bool {
+	if conf.Flags.BlockEventsBase64Encoded {
+		return true
+	}
+
+	switch conf.Base.DecodeEventAttributes {
+	case config.DecodeEventAttributesAlways:
+		return true
+	case config.DecodeEventAttributesAuto:
+	default:
+		return false
+	}
+
+	hasAttributes := false
+	for _, event := range events {
+		for _, attribute := range event.Attributes {
+			hasAttributes = true
+			keyBytes, err := base64.StdEncoding.DecodeString(attribute.Key)
+			if err != nil || !printableKey(keyBytes) {
+				return false
+			}
+		}
+	}
+
+	return hasAttributes
+}
+
+func printableKey(key []byte) bool {
+	if len(key) == 0 || !utf8.Valid(key) {
+		return false
+	}
+
+	for _, r := range string(key) {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeEventAttribute returns the attribute key and value as stored. Encoded attributes are base64 decoded, values that do not decode to
+// valid UTF-8 are kept in their base64 form and flagged so the original bytes are not lost.
+func normalizeEventAttribute(key string, value string, encoded bool) (string, string, bool, error) {
+	if !encoded {
+		return key, value, false, nil
+	}
+
+	keyBytes, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	valueBytes, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	decodedKey := key
+	if utf8.Valid(keyBytes) {
+		decodedKey = string(keyBytes)
+	}
+
+	if !utf8.Valid(valueBytes) {
+		return decodedKey, value, true, nil
+	}
+
+	return decodedKey, string(valueBytes), false, nil
+}
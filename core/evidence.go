@@ -0,0 +1,56 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	sdkTypes "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ExtractBlockEvidence returns the misbehavior evidence committed in the block (base.index-evidence), nil for the usual block
+// without evidence. Rows are returned without block IDs.
+func ExtractBlockEvidence(blockData *ctypes.ResultBlock) ([]models.BlockEvidence, error) {
+	if blockData == nil || blockData.Block == nil || len(blockData.Block.Evidence.Evidence) == 0 {
+		return nil, nil
+	}
+
+	var rows []models.BlockEvidence
+	for index, evidence := range blockData.Block.Evidence.Evidence {
+		row := models.BlockEvidence{
+			Height:           blockData.Block.Height,
+			EvidenceIndex:    index,
+			Hash:             fmt.Sprintf("%X", evidence.Hash()),
+			InfractionHeight: evidence.Height(),
+			Time:             evidence.Time(),
+		}
+
+		switch typedEvidence := evidence.(type) {
+		case *cmtTypes.DuplicateVoteEvidence:
+			if typedEvidence.VoteA == nil || typedEvidence.VoteB == nil {
+				return nil, fmt.Errorf("duplicate vote evidence %d of block %d is missing a vote", index, blockData.Block.Height)
+			}
+			row.Type = models.EvidenceTypeDuplicateVote
+			row.ValidatorAddress = sdkTypes.ConsAddress(typedEvidence.VoteA.ValidatorAddress).String()
+			row.VoteARound = &typedEvidence.VoteA.Round
+			row.VoteBRound = &typedEvidence.VoteB.Round
+			row.ValidatorPower = typedEvidence.ValidatorPower
+			row.TotalVotingPower = typedEvidence.TotalVotingPower
+			rows = append(rows, row)
+		case *cmtTypes.LightClientAttackEvidence:
+			row.Type = models.EvidenceTypeLightClientAttack
+			row.TotalVotingPower = typedEvidence.TotalVotingPower
+			for _, validator := range typedEvidence.ByzantineValidators {
+				validatorRow := row
+				validatorRow.ValidatorAddress = sdkTypes.ConsAddress(validator.Address).String()
+				validatorRow.ValidatorPower = validator.VotingPower
+				rows = append(rows, validatorRow)
+			}
+		default:
+			return nil, fmt.Errorf("unknown evidence type %T in block %d", evidence, blockData.Block.Height)
+		}
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	sdkTypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type EvidenceTestSuite struct {
+	suite.Suite
+}
+
+func (suite *EvidenceTestSuite) TestDuplicateVoteEvidence() {
+	validator := make([]byte, 20)
+	for i := range validator {
+		validator[i] = 7
+	}
+	infractionTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	evidence := &cmtTypes.DuplicateVoteEvidence{
+		VoteA:            &cmtTypes.Vote{Height: 95, Round: 0, ValidatorAddress: validator},
+		VoteB:            &cmtTypes.Vote{Height: 95, Round: 1, ValidatorAddress: validator},
+		TotalVotingPower: 1000,
+		ValidatorPower:   50,
+		Timestamp:        infractionTime,
+	}
+	blockData := &ctypes.ResultBlock{Block: &cmtTypes.Block{
+		Header:   cmtTypes.Header{Height: 100},
+		Evidence: cmtTypes.EvidenceData{Evidence: cmtTypes.EvidenceList{evidence}},
+	}}
+
+	rows, err := ExtractBlockEvidence(blockData)
+	suite.Require().NoError(err)
+	suite.Require().Len(rows, 1)
+
+	suite.Equal(models.EvidenceTypeDuplicateVote, rows[0].Type)
+	suite.Equal(int64(100), rows[0].Height)
+	suite.Equal(int64(95), rows[0].InfractionHeight)
+	suite.Equal(0, rows[0].EvidenceIndex)
+	suite.Equal(sdkTypes.ConsAddress(validator).String(), rows[0].ValidatorAddress)
+	suite.Equal(fmt.Sprintf("%X", evidence.Hash()), rows[0].Hash)
+	suite.Equal(int64(50), rows[0].ValidatorPower)
+	suite.Equal(int64(1000), rows[0].TotalVotingPower)
+	suite.Equal(infractionTime, rows[0].Time)
+	suite.Require().NotNil(rows[0].VoteARound)
+	suite.Require().NotNil(rows[0].VoteBRound)
+	suite.Equal(int32(0), *rows[0].VoteARound)
+	suite.Equal(int32(1), *rows[0].VoteBRound)
+}
+
+func (suite *EvidenceTestSuite) TestBlockWithoutEvidence() {
+	rows, err := ExtractBlockEvidence(&ctypes.ResultBlock{Block: &cmtTypes.Block{Header: cmtTypes.Header{Height: 100}}})
+	suite.Require().NoError(err)
+	suite.Nil(rows)
+}
+
+func TestEvidenceTestSuite(t *testing.T) {
+	suite.Run(t, new(EvidenceTestSuite))
+}
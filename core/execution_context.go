@@ -0,0 +1,154 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/address"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	authz "github.com/cosmos/cosmos-sdk/x/authz"
+	icaTypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/types"
+	ibcTransferTypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	chanTypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// Kinds of wrapping recorded in the execution context of a message
+const (
+	ExecutionContextAuthz    = "authz"
+	ExecutionContextICAHost  = "ica_host"
+	ExecutionContextIBCHooks = "ibc_hooks"
+)
+
+// The message ibc-hooks executes on behalf of the intermediary sender
+const ibcHooksExecuteTypeURL = "/cosmwasm.wasm.v1.MsgExecuteContract"
+
+// Prefix ibc-hooks derives the intermediary sender of a packet from
+const ibcHooksSenderPrefix = "ibc-wasm-hook-intermediary"
+
+// ResolveExecutionContext returns the chain of wrapping (base.resolve-execution-context) around the actions a message executes:
+// authz executions, interchain account txs of the ICA host and ibc-hooks contract calls. Each layer records who initiated it and the
+// address the wrapped message runs as, the outermost layer's initiator is the ultimate initiator of the action. Nested layers are
+// resolved in order, messages wrapping several messages have one layer per wrapped message. The codec is used to unpack the
+// interchain account txs.
+func ResolveExecutionContext(cdc codec.BinaryCodec, msg types.Msg) ([]models.MessageExecutionContext, error) {
+	var layers []models.MessageExecutionContext
+	err := resolveExecutionContext(cdc, msg, 0, &layers)
+	return layers, err
+}
+
+func resolveExecutionContext(cdc codec.BinaryCodec, msg types.Msg, depth int, layers *[]models.MessageExecutionContext) error {
+	switch typedMsg := msg.(type) {
+	case *authz.MsgExec:
+		innerMsgs, err := typedMsg.GetMessages()
+		if err != nil {
+			return err
+		}
+
+		for _, innerMsg := range innerMsgs {
+			// The granter is the signer of the executed message
+			addExecutionContextLayer(layers, depth, ExecutionContextAuthz, typedMsg.Grantee, innerMsg, "")
+			if err := resolveExecutionContext(cdc, innerMsg, depth+1, layers); err != nil {
+				return err
+			}
+		}
+	case *chanTypes.MsgRecvPacket:
+		if typedMsg.Packet.DestinationPort == icaTypes.HostPortID {
+			return resolveICAHostPacket(cdc, typedMsg.Packet, depth, layers)
+		}
+		resolveIBCHooksPacket(typedMsg.Packet, depth, layers)
+	}
+
+	return nil
+}
+
+// resolveICAHostPacket records the messages of an interchain account tx executed by the host, initiated by the owner of the
+// controller port on the counterparty chain
+func resolveICAHostPacket(cdc codec.BinaryCodec, packet chanTypes.Packet, depth int, layers *[]models.MessageExecutionContext) error {
+	var packetData icaTypes.InterchainAccountPacketData
+	if err := icaTypes.ModuleCdc.UnmarshalJSON(packet.GetData(), &packetData); err != nil {
+		return fmt.Errorf("error unmarshaling interchain account packet data: %w", err)
+	}
+
+	if packetData.Type != icaTypes.EXECUTE_TX {
+		return nil
+	}
+
+	// The host accepts both encodings, the channel version it was negotiated with is not part of the packet
+	innerMsgs, err := icaTypes.DeserializeCosmosTx(cdc, packetData.Data)
+	if err != nil {
+		var jsonErr error
+		innerMsgs, jsonErr = icaTypes.DeserializeCosmosTxWithEncoding(cdc, packetData.Data, icaTypes.EncodingProto3JSON)
+		if jsonErr != nil {
+			return fmt.Errorf("error deserializing interchain account tx: %w", err)
+		}
+	}
+
+	owner := strings.TrimPrefix(packet.SourcePort, icaTypes.ControllerPortPrefix)
+	for _, innerMsg := range innerMsgs {
+		// The interchain account is the signer of the executed message
+		addExecutionContextLayer(layers, depth, ExecutionContextICAHost, owner, innerMsg, packet.DestinationChannel)
+		if err := resolveExecutionContext(cdc, innerMsg, depth+1, layers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveIBCHooksPacket records the contract call of a transfer packet with an ibc-hooks wasm memo, initiated by the sender on the
+// counterparty chain and executed by the intermediary sender ibc-hooks derives from it
+func resolveIBCHooksPacket(packet chanTypes.Packet, depth int, layers *[]models.MessageExecutionContext) {
+	var packetData ibcTransferTypes.FungibleTokenPacketData
+	if err := ibcTransferTypes.ModuleCdc.UnmarshalJSON(packet.GetData(), &packetData); err != nil || packetData.Memo == "" {
+		return
+	}
+
+	var memo struct {
+		Wasm *struct {
+			Contract string `json:"contract"`
+		} `json:"wasm"`
+	}
+	if err := json.Unmarshal([]byte(packetData.Memo), &memo); err != nil || memo.Wasm == nil || memo.Wasm.Contract == "" {
+		return
+	}
+
+	layer := models.MessageExecutionContext{
+		Index:            len(*layers),
+		Depth:            depth,
+		Kind:             ExecutionContextIBCHooks,
+		Initiator:        packetData.Sender,
+		Channel:          packet.DestinationChannel,
+		InnerMessageType: ibcHooksExecuteTypeURL,
+	}
+
+	// The contract is the receiver of the transfer, the intermediary sender uses the chain's address prefix
+	if prefix, _, err := bech32.DecodeAndConvert(memo.Wasm.Contract); err == nil {
+		senderHash := address.Hash(ibcHooksSenderPrefix, []byte(fmt.Sprintf("%s/%s", packet.DestinationChannel, packetData.Sender)))
+		if intermediarySender, err := bech32.ConvertAndEncode(prefix, senderHash); err == nil {
+			layer.ExecutedAs = intermediarySender
+		}
+	}
+
+	*layers = append(*layers, layer)
+}
+
+func addExecutionContextLayer(layers *[]models.MessageExecutionContext, depth int, kind string, initiator string, innerMsg types.Msg, channel string) {
+	layer := models.MessageExecutionContext{
+		Index:            len(*layers),
+		Depth:            depth,
+		Kind:             kind,
+		Initiator:        initiator,
+		Channel:          channel,
+		InnerMessageType: types.MsgTypeURL(innerMsg),
+	}
+
+	if signers := innerMsg.GetSigners(); len(signers) != 0 {
+		layer.ExecutedAs = signers[0].String()
+	}
+
+	*layers = append(*layers, layer)
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	probeClient "github.com/DefiantLabs/probe/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/address"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	authz "github.com/cosmos/cosmos-sdk/x/authz"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/gogoproto/proto"
+	icaTypes "github.com/cosmos/ibc-go/v7/modules/apps/27-interchain-accounts/types"
+	ibcTransferTypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	chanTypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExecutionContextTestSuite struct {
+	suite.Suite
+	cdc codec.Codec
+}
+
+func (suite *ExecutionContextTestSuite) SetupTest() {
+	chainCodec, err := probeClient.MakeCodec(nil, map[string]sdk.Msg{
+		"/cosmos.bank.v1beta1.MsgSend":  &bankTypes.MsgSend{},
+		"/cosmos.authz.v1beta1.MsgExec": &authz.MsgExec{},
+	})
+	suite.Require().NoError(err)
+	suite.cdc = chainCodec.Marshaler
+}
+
+func testAddress(fill byte) sdk.AccAddress {
+	addressBytes := make([]byte, 20)
+	for i := range addressBytes {
+		addressBytes[i] = fill
+	}
+	return addressBytes
+}
+
+// icaHostRecvPacket is the packet relayed to the host chain to execute the messages as the owner's interchain account
+func (suite *ExecutionContextTestSuite) icaHostRecvPacket(owner string, msgs ...proto.Message) *chanTypes.MsgRecvPacket {
+	cosmosTx, err := icaTypes.SerializeCosmosTx(suite.cdc, msgs)
+	suite.Require().NoError(err)
+
+	packetData := icaTypes.InterchainAccountPacketData{Type: icaTypes.EXECUTE_TX, Data: cosmosTx}
+	return &chanTypes.MsgRecvPacket{
+		Packet: chanTypes.Packet{
+			SourcePort:         icaTypes.ControllerPortPrefix + owner,
+			SourceChannel:      "channel-141",
+			DestinationPort:    icaTypes.HostPortID,
+			DestinationChannel: "channel-7",
+			Data:               packetData.GetBytes(),
+		},
+		Signer: testAddress(9).String(),
+	}
+}
+
+func (suite *ExecutionContextTestSuite) TestICAWrappedBankSend() {
+	interchainAccount := testAddress(1)
+	send := &bankTypes.MsgSend{FromAddress: interchainAccount.String(), ToAddress: testAddress(2).String(), Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000))}
+
+	layers, err := ResolveExecutionContext(suite.cdc, suite.icaHostRecvPacket("osmo1controllerowner", send))
+	suite.Require().NoError(err)
+
+	suite.Equal([]models.MessageExecutionContext{{
+		Index:            0,
+		Depth:            0,
+		Kind:             ExecutionContextICAHost,
+		Initiator:        "osmo1controllerowner",
+		ExecutedAs:       interchainAccount.String(),
+		Channel:          "channel-7",
+		InnerMessageType: "/cosmos.bank.v1beta1.MsgSend",
+	}}, layers)
+}
+
+func (suite *ExecutionContextTestSuite) TestICAWrappedAuthzExec() {
+	interchainAccount := testAddress(1)
+	granter := testAddress(3)
+	send := &bankTypes.MsgSend{FromAddress: granter.String(), ToAddress: testAddress(2).String(), Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000))}
+	exec := authz.NewMsgExec(interchainAccount, []sdk.Msg{send})
+
+	layers, err := ResolveExecutionContext(suite.cdc, suite.icaHostRecvPacket("osmo1controllerowner", &exec))
+	suite.Require().NoError(err)
+	suite.Require().Len(layers, 2)
+
+	// The interchain account executes the authz exec, which runs the send as the granter
+	suite.Equal(ExecutionContextICAHost, layers[0].Kind)
+	suite.Equal("osmo1controllerowner", layers[0].Initiator)
+	suite.Equal(interchainAccount.String(), layers[0].ExecutedAs)
+	suite.Equal("/cosmos.authz.v1beta1.MsgExec", layers[0].InnerMessageType)
+
+	suite.Equal(models.MessageExecutionContext{
+		Index:            1,
+		Depth:            1,
+		Kind:             ExecutionContextAuthz,
+		Initiator:        interchainAccount.String(),
+		ExecutedAs:       granter.String(),
+		InnerMessageType: "/cosmos.bank.v1beta1.MsgSend",
+	}, layers[1])
+}
+
+func (suite *ExecutionContextTestSuite) TestIBCHooksContractCall() {
+	contract, err := bech32.ConvertAndEncode("osmo", testAddress(4))
+	suite.Require().NoError(err)
+
+	packetData := ibcTransferTypes.NewFungibleTokenPacketData("uatom", "1000", "cosmos1counterpartysender", contract, `{"wasm":{"contract":"`+contract+`","msg":{"swap":{}}}}`)
+	msg := &chanTypes.MsgRecvPacket{Packet: chanTypes.Packet{
+		SourcePort:         ibcTransferTypes.PortID,
+		DestinationPort:    ibcTransferTypes.PortID,
+		DestinationChannel: "channel-0",
+		Data:               packetData.GetBytes(),
+	}}
+
+	layers, err := ResolveExecutionContext(suite.cdc, msg)
+	suite.Require().NoError(err)
+	suite.Require().Len(layers, 1)
+
+	intermediarySender, err := bech32.ConvertAndEncode("osmo", address.Hash("ibc-wasm-hook-intermediary", []byte("channel-0/cosmos1counterpartysender")))
+	suite.Require().NoError(err)
+
+	suite.Equal(ExecutionContextIBCHooks, layers[0].Kind)
+	suite.Equal("cosmos1counterpartysender", layers[0].Initiator)
+	suite.Equal(intermediarySender, layers[0].ExecutedAs)
+	suite.Equal("channel-0", layers[0].Channel)
+	suite.Equal("/cosmwasm.wasm.v1.MsgExecuteContract", layers[0].InnerMessageType)
+
+	// Plain transfers have no execution context
+	packetData.Memo = ""
+	msg.Packet.Data = packetData.GetBytes()
+	layers, err = ResolveExecutionContext(suite.cdc, msg)
+	suite.Require().NoError(err)
+	suite.Empty(layers)
+}
+
+func (suite *ExecutionContextTestSuite) TestUnwrappedMessage() {
+	layers, err := ResolveExecutionContext(suite.cdc, &bankTypes.MsgSend{FromAddress: testAddress(1).String()})
+	suite.Require().NoError(err)
+	suite.Empty(layers)
+}
+
+func TestExecutionContextTestSuite(t *testing.T) {
+	suite.Run(t, new(ExecutionContextTestSuite))
+}
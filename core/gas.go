@@ -0,0 +1,49 @@
+package core
+
+import (
+	"strconv"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	cosmosTx "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// Attribute key chains exposing per-message execution info use for the gas consumed by the message
+const messageGasUsedAttributeKey = "gas_used"
+
+// MessageGasUsed returns the gas attributable to a single message, or nil when it cannot be determined.
+// A gas_used attribute in the message's own events is used when the chain provides one. Otherwise the gas of a single
+// message tx is attributed to its only message (including the tx overhead such as signature verification).
+// The gas of multi-message txs is not split between messages since any split would be a guess.
+func MessageGasUsed(messageLog *txtypes.LogMessage, messageCount int, txGasUsed int64) *int64 {
+	if messageLog != nil {
+		for _, event := range messageLog.Events {
+			for _, attribute := range event.Attributes {
+				if attribute.Key != messageGasUsedAttributeKey {
+					continue
+				}
+
+				gasUsed, err := strconv.ParseInt(attribute.Value, 10, 64)
+				if err == nil && gasUsed >= 0 {
+					return &gasUsed
+				}
+			}
+		}
+	}
+
+	if messageCount == 1 && txGasUsed > 0 {
+		return &txGasUsed
+	}
+
+	return nil
+}
+
+// GasUtilization returns the ratio of the gas used by the tx to the gas limit declared in its auth info fee,
+// or nil when the tx declares no gas limit
+func GasUtilization(authInfo cosmosTx.AuthInfo, txGasUsed int64) *float64 {
+	if authInfo.Fee == nil || authInfo.Fee.GasLimit == 0 {
+		return nil
+	}
+
+	utilization := float64(txGasUsed) / float64(authInfo.Fee.GasLimit)
+	return &utilization
+}
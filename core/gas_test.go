@@ -0,0 +1,51 @@
+package core
+
+import (
+	"testing"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/stretchr/testify/suite"
+)
+
+type GasTestSuite struct {
+	suite.Suite
+}
+
+func (suite *GasTestSuite) TestMessageGasFromEvents() {
+	messageLog := &txtypes.LogMessage{
+		MessageIndex: 1,
+		Events: []txtypes.LogMessageEvent{
+			{Type: "message", Attributes: []txtypes.Attribute{{Key: "action", Value: "/cosmos.bank.v1beta1.MsgSend"}}},
+			{Type: "message_execution", Attributes: []txtypes.Attribute{{Key: "msg_index", Value: "1"}, {Key: "gas_used", Value: "41250"}}},
+		},
+	}
+
+	gasUsed := MessageGasUsed(messageLog, 3, 150000)
+	suite.Require().NotNil(gasUsed)
+	suite.Equal(int64(41250), *gasUsed)
+}
+
+func (suite *GasTestSuite) TestSingleMessageTx() {
+	gasUsed := MessageGasUsed(&txtypes.LogMessage{}, 1, 85000)
+	suite.Require().NotNil(gasUsed)
+	suite.Equal(int64(85000), *gasUsed)
+}
+
+func (suite *GasTestSuite) TestUnavailable() {
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "message", Attributes: []txtypes.Attribute{{Key: "action", Value: "/cosmos.bank.v1beta1.MsgSend"}}},
+		},
+	}
+
+	// Multi-message tx without per-message gas events
+	suite.Nil(MessageGasUsed(messageLog, 2, 150000))
+	// No logs and no tx gas reported by the node
+	suite.Nil(MessageGasUsed(nil, 1, 0))
+	// Unparsable attribute values are ignored
+	suite.Nil(MessageGasUsed(&txtypes.LogMessage{Events: []txtypes.LogMessageEvent{{Type: "message_execution", Attributes: []txtypes.Attribute{{Key: "gas_used", Value: "n/a"}}}}}, 2, 0))
+}
+
+func TestGasTestSuite(t *testing.T) {
+	suite.Run(t, new(GasTestSuite))
+}
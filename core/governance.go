@@ -0,0 +1,109 @@
+package core
+
+import (
+	"strconv"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/cosmos/cosmos-sdk/types"
+	govTypesV1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	govTypesV1Beta1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	govVersionV1      = "v1"
+	govVersionV1Beta1 = "v1beta1"
+)
+
+// ExtractGovernanceMessages returns the structured governance rows for v1 and v1beta1 proposal submissions, deposits and votes.
+// Other message types return nil. Rows are returned without IDs, the address is identified by its Address field only.
+func ExtractGovernanceMessages(msg types.Msg, messageLog *txtypes.LogMessage) ([]models.GovernanceMessage, error) {
+	switch typedMsg := msg.(type) {
+	case *govTypesV1Beta1.MsgSubmitProposal:
+		return []models.GovernanceMessage{submittedProposal(govVersionV1Beta1, typedMsg.Proposer, typedMsg.InitialDeposit, messageLog)}, nil
+	case *govTypesV1.MsgSubmitProposal:
+		return []models.GovernanceMessage{submittedProposal(govVersionV1, typedMsg.Proposer, typedMsg.InitialDeposit, messageLog)}, nil
+	case *govTypesV1Beta1.MsgDeposit:
+		return []models.GovernanceMessage{deposit(govVersionV1Beta1, typedMsg.ProposalId, typedMsg.Depositor, typedMsg.Amount)}, nil
+	case *govTypesV1.MsgDeposit:
+		return []models.GovernanceMessage{deposit(govVersionV1, typedMsg.ProposalId, typedMsg.Depositor, typedMsg.Amount)}, nil
+	case *govTypesV1Beta1.MsgVote:
+		return []models.GovernanceMessage{vote(govVersionV1Beta1, typedMsg.ProposalId, typedMsg.Voter, typedMsg.Option.String(), decimal.NewFromInt(1))}, nil
+	case *govTypesV1.MsgVote:
+		return []models.GovernanceMessage{vote(govVersionV1, typedMsg.ProposalId, typedMsg.Voter, typedMsg.Option.String(), decimal.NewFromInt(1))}, nil
+	case *govTypesV1Beta1.MsgVoteWeighted:
+		var votes []models.GovernanceMessage
+		for _, option := range typedMsg.Options {
+			weight, err := decimal.NewFromString(option.Weight.String())
+			if err != nil {
+				return nil, err
+			}
+			votes = append(votes, vote(govVersionV1Beta1, typedMsg.ProposalId, typedMsg.Voter, option.Option.String(), weight))
+		}
+		return votes, nil
+	case *govTypesV1.MsgVoteWeighted:
+		var votes []models.GovernanceMessage
+		for _, option := range typedMsg.Options {
+			weight, err := decimal.NewFromString(option.Weight)
+			if err != nil {
+				return nil, err
+			}
+			votes = append(votes, vote(govVersionV1, typedMsg.ProposalId, typedMsg.Voter, option.Option.String(), weight))
+		}
+		return votes, nil
+	}
+
+	return nil, nil
+}
+
+func submittedProposal(govVersion string, proposer string, initialDeposit types.Coins, messageLog *txtypes.LogMessage) models.GovernanceMessage {
+	return models.GovernanceMessage{
+		GovVersion: govVersion,
+		Action:     models.GovernanceActionSubmitProposal,
+		ProposalID: submittedProposalID(messageLog),
+		Address:    models.Address{Address: proposer},
+		Amount:     initialDeposit.String(),
+	}
+}
+
+// The proposal ID is assigned on execution, it is only available from the submit_proposal event of the message
+func submittedProposalID(messageLog *txtypes.LogMessage) uint64 {
+	event := txtypes.GetEventWithType("submit_proposal", messageLog)
+	if event == nil {
+		return 0
+	}
+
+	value, err := txtypes.GetValueForAttribute("proposal_id", event)
+	if err != nil {
+		return 0
+	}
+
+	proposalID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return proposalID
+}
+
+func deposit(govVersion string, proposalID uint64, depositor string, amount types.Coins) models.GovernanceMessage {
+	return models.GovernanceMessage{
+		GovVersion: govVersion,
+		Action:     models.GovernanceActionDeposit,
+		ProposalID: proposalID,
+		Address:    models.Address{Address: depositor},
+		Amount:     amount.String(),
+	}
+}
+
+func vote(govVersion string, proposalID uint64, voter string, option string, weight decimal.Decimal) models.GovernanceMessage {
+	return models.GovernanceMessage{
+		GovVersion: govVersion,
+		Action:     models.GovernanceActionVote,
+		ProposalID: proposalID,
+		Address:    models.Address{Address: voter},
+		Option:     option,
+		Weight:     decimal.NullDecimal{Decimal: weight, Valid: true},
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"testing"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/cosmos/cosmos-sdk/types"
+	govTypesV1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	govTypesV1Beta1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	"github.com/stretchr/testify/suite"
+)
+
+type GovernanceTestSuite struct {
+	suite.Suite
+}
+
+const governanceVoter = "cosmos1voter"
+
+func (suite *GovernanceTestSuite) TestV1WeightedVote() {
+	msg := &govTypesV1.MsgVoteWeighted{
+		ProposalId: 42,
+		Voter:      governanceVoter,
+		Options: []*govTypesV1.WeightedVoteOption{
+			{Option: govTypesV1.OptionYes, Weight: "0.700000000000000000"},
+			{Option: govTypesV1.OptionAbstain, Weight: "0.300000000000000000"},
+		},
+	}
+
+	governanceMessages, err := ExtractGovernanceMessages(msg, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(governanceMessages, 2)
+
+	suite.Equal("v1", governanceMessages[0].GovVersion)
+	suite.Equal(models.GovernanceActionVote, governanceMessages[0].Action)
+	suite.Equal(uint64(42), governanceMessages[0].ProposalID)
+	suite.Equal(governanceVoter, governanceMessages[0].Address.Address)
+	suite.Equal("VOTE_OPTION_YES", governanceMessages[0].Option)
+	suite.Equal("0.7", governanceMessages[0].Weight.Decimal.String())
+
+	suite.Equal("VOTE_OPTION_ABSTAIN", governanceMessages[1].Option)
+	suite.Equal("0.3", governanceMessages[1].Weight.Decimal.String())
+}
+
+func (suite *GovernanceTestSuite) TestV1Beta1Vote() {
+	msg := &govTypesV1Beta1.MsgVote{
+		ProposalId: 7,
+		Voter:      governanceVoter,
+		Option:     govTypesV1Beta1.OptionNo,
+	}
+
+	governanceMessages, err := ExtractGovernanceMessages(msg, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(governanceMessages, 1)
+
+	suite.Equal("v1beta1", governanceMessages[0].GovVersion)
+	suite.Equal(models.GovernanceActionVote, governanceMessages[0].Action)
+	suite.Equal(uint64(7), governanceMessages[0].ProposalID)
+	suite.Equal("VOTE_OPTION_NO", governanceMessages[0].Option)
+	suite.True(governanceMessages[0].Weight.Valid)
+	suite.Equal("1", governanceMessages[0].Weight.Decimal.String())
+}
+
+func (suite *GovernanceTestSuite) TestSubmitProposalID() {
+	msg := &govTypesV1.MsgSubmitProposal{
+		Proposer:       governanceVoter,
+		InitialDeposit: types.NewCoins(types.NewInt64Coin("uatom", 1000)),
+	}
+
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "submit_proposal", Attributes: []txtypes.Attribute{{Key: "proposal_id", Value: "12"}}},
+		},
+	}
+
+	governanceMessages, err := ExtractGovernanceMessages(msg, messageLog)
+	suite.Require().NoError(err)
+	suite.Require().Len(governanceMessages, 1)
+
+	suite.Equal(models.GovernanceActionSubmitProposal, governanceMessages[0].Action)
+	suite.Equal(uint64(12), governanceMessages[0].ProposalID)
+	suite.Equal("1000uatom", governanceMessages[0].Amount)
+	suite.False(governanceMessages[0].Weight.Valid)
+}
+
+func TestGovernanceTestSuite(t *testing.T) {
+	suite.Run(t, new(GovernanceTestSuite))
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+)
+
+// HeightAnnotation tags the blocks of an inclusive height range, e.g. an upgrade height or an incident window
+type HeightAnnotation struct {
+	From int64             `json:"from"`
+	To   int64             `json:"to"`
+	Tags map[string]string `json:"tags"`
+}
+
+// HeightAnnotations are the annotated height ranges of base.height-annotations-file, in file order
+type HeightAnnotations []HeightAnnotation
+
+// LoadHeightAnnotations loads a JSON list of height ranges and their tags, e.g. [{"from": 100, "to": 200, "tags": {"incident": "halt"}}].
+// Ranges are inclusive and may overlap.
+func LoadHeightAnnotations(path string) (HeightAnnotations, error) {
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading height annotations file %s: %w", path, err)
+	}
+
+	var annotations HeightAnnotations
+	if err := json.Unmarshal(fileBytes, &annotations); err != nil {
+		return nil, fmt.Errorf("error parsing height annotations file %s, expected a JSON list of height ranges and tags: %w", path, err)
+	}
+
+	for _, annotation := range annotations {
+		if annotation.From <= 0 || annotation.To < annotation.From {
+			return nil, fmt.Errorf("height annotation range %d-%d must start at a positive height and cannot end before it starts", annotation.From, annotation.To)
+		}
+		if len(annotation.Tags) == 0 {
+			return nil, fmt.Errorf("height annotation range %d-%d has no tags", annotation.From, annotation.To)
+		}
+	}
+
+	return annotations, nil
+}
+
+// At returns the merged tags of every range containing the height, a tag set by several ranges takes the value of the range listed
+// last. Heights outside every range, and every height of nil annotations, return nil.
+func (annotations HeightAnnotations) At(height int64) models.RowTags {
+	var tags models.RowTags
+	for _, annotation := range annotations {
+		if height < annotation.From || height > annotation.To {
+			continue
+		}
+
+		if tags == nil {
+			tags = make(models.RowTags, len(annotation.Tags))
+		}
+		for key, value := range annotation.Tags {
+			tags[key] = value
+		}
+	}
+	return tags
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/stretchr/testify/suite"
+)
+
+type HeightAnnotationsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *HeightAnnotationsTestSuite) loadAnnotations(contents string) (HeightAnnotations, error) {
+	path := filepath.Join(suite.T().TempDir(), "annotations.json")
+	suite.Require().NoError(os.WriteFile(path, []byte(contents), 0o600))
+	return LoadHeightAnnotations(path)
+}
+
+func (suite *HeightAnnotationsTestSuite) TestOverlappingRangesMerge() {
+	annotations, err := suite.loadAnnotations(`[
+		{"from": 100, "to": 200, "tags": {"upgrade": "v2", "phase": "pre"}},
+		{"from": 150, "to": 300, "tags": {"incident": "halt", "phase": "outage"}}
+	]`)
+	suite.Require().NoError(err)
+
+	suite.Equal(models.RowTags{"upgrade": "v2", "phase": "pre"}, annotations.At(120))
+	// The later range wins the conflicting tag
+	suite.Equal(models.RowTags{"upgrade": "v2", "incident": "halt", "phase": "outage"}, annotations.At(175))
+	suite.Equal(models.RowTags{"incident": "halt", "phase": "outage"}, annotations.At(300))
+	suite.Nil(annotations.At(99))
+	suite.Nil(annotations.At(301))
+}
+
+func (suite *HeightAnnotationsTestSuite) TestInvalidRanges() {
+	_, err := suite.loadAnnotations(`[{"from": 200, "to": 100, "tags": {"upgrade": "v2"}}]`)
+	suite.Error(err)
+
+	_, err = suite.loadAnnotations(`[{"from": 100, "to": 200}]`)
+	suite.Error(err)
+}
+
+func (suite *HeightAnnotationsTestSuite) TestNoAnnotations() {
+	var annotations HeightAnnotations
+	suite.Nil(annotations.At(100))
+}
+
+func TestHeightAnnotationsTestSuite(t *testing.T) {
+	suite.Run(t, new(HeightAnnotationsTestSuite))
+}
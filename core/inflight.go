@@ -0,0 +1,135 @@
+package core
+
+import (
+	"sync/atomic"
+)
+
+// InflightLimiter caps the number of blocks held in memory across all pipeline stages (RPC fetch, processing and the DB write queue),
+// independent of the worker count and queue sizes. Blocks are admitted before their RPC data is fetched and leave the pipeline once every
+// stage holding data for them is done with it. The number of in-flight blocks is tracked even when no cap is set.
+type InflightLimiter struct {
+	slots    chan struct{}
+	inFlight atomic.Int64
+	peak     atomic.Int64
+	left     atomic.Int64
+	// Signalled after blocks leave the pipeline, a single signal may stand for several blocks
+	leaving chan struct{}
+}
+
+// InflightBlock is the admission of a single block. Each stage that keeps data for the block retains it and releases it when done,
+// the slot is freed on the final release.
+type InflightBlock struct {
+	limiter *InflightLimiter
+	refs    atomic.Int64
+}
+
+// NewInflightLimiter creates a limiter admitting at most maxInflight blocks at a time, 0 only tracks the in-flight count
+func NewInflightLimiter(maxInflight int64) *InflightLimiter {
+	limiter := &InflightLimiter{leaving: make(chan struct{}, 1)}
+	if maxInflight > 0 {
+		limiter.slots = make(chan struct{}, maxInflight)
+	}
+	return limiter
+}
+
+// Admit blocks until there is room for another block in the pipeline. The returned block is held by the caller.
+// A nil limiter admits immediately and returns a nil block, which is safe to retain and release.
+func (limiter *InflightLimiter) Admit() *InflightBlock {
+	if limiter == nil {
+		return nil
+	}
+
+	if limiter.slots != nil {
+		limiter.slots <- struct{}{}
+	}
+	return limiter.admitted()
+}
+
+// TryAdmit admits the block only if there is room in the pipeline, without waiting. A nil limiter always admits.
+func (limiter *InflightLimiter) TryAdmit() (*InflightBlock, bool) {
+	if limiter == nil {
+		return nil, true
+	}
+
+	if limiter.slots != nil {
+		select {
+		case limiter.slots <- struct{}{}:
+		default:
+			return nil, false
+		}
+	}
+	return limiter.admitted(), true
+}
+
+// admitted counts a block that was given a slot into the pipeline
+func (limiter *InflightLimiter) admitted() *InflightBlock {
+	inFlight := limiter.inFlight.Add(1)
+	for {
+		peak := limiter.peak.Load()
+		if inFlight <= peak || limiter.peak.CompareAndSwap(peak, inFlight) {
+			break
+		}
+	}
+
+	block := &InflightBlock{limiter: limiter}
+	block.refs.Store(1)
+	return block
+}
+
+// InFlight returns the number of blocks currently in the pipeline
+func (limiter *InflightLimiter) InFlight() int64 {
+	if limiter == nil {
+		return 0
+	}
+	return limiter.inFlight.Load()
+}
+
+// Peak returns the highest number of blocks that have been in the pipeline at once
+func (limiter *InflightLimiter) Peak() int64 {
+	if limiter == nil {
+		return 0
+	}
+	return limiter.peak.Load()
+}
+
+// Left returns the number of blocks that have left the pipeline
+func (limiter *InflightLimiter) Left() int64 {
+	if limiter == nil {
+		return 0
+	}
+	return limiter.left.Load()
+}
+
+// Leaving is signalled after blocks leave the pipeline, receivers check Left for how many have. A nil limiter is never signalled.
+func (limiter *InflightLimiter) Leaving() <-chan struct{} {
+	if limiter == nil {
+		return nil
+	}
+	return limiter.leaving
+}
+
+// Retain marks the block as held by one more pipeline stage
+func (block *InflightBlock) Retain() {
+	if block == nil {
+		return
+	}
+	block.refs.Add(1)
+}
+
+// Release marks a pipeline stage as done with the block, the final release frees its slot
+func (block *InflightBlock) Release() {
+	if block == nil || block.refs.Add(-1) != 0 {
+		return
+	}
+
+	block.limiter.inFlight.Add(-1)
+	if block.limiter.slots != nil {
+		<-block.limiter.slots
+	}
+
+	block.limiter.left.Add(1)
+	select {
+	case block.limiter.leaving <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InflightTestSuite struct {
+	suite.Suite
+}
+
+// Runs blocks through fetch, process and two DB queues like the index pipeline, with more workers and larger queues than the cap
+func (suite *InflightTestSuite) TestCapHoldsUnderLoad() {
+	const maxInflight = 3
+	limiter := NewInflightLimiter(maxInflight)
+
+	heights := make(chan int64)
+	fetched := make(chan *InflightBlock, 10)
+	txQueue := make(chan *InflightBlock, 10)
+	eventQueue := make(chan *InflightBlock, 10)
+
+	var mu sync.Mutex
+	var observedMax int64
+	observe := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if inFlight := limiter.InFlight(); inFlight > observedMax {
+			observedMax = inFlight
+		}
+	}
+
+	pause := func() {
+		time.Sleep(time.Duration(rand.Intn(300)) * time.Microsecond) //nolint:gosec
+	}
+
+	var fetchers sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		fetchers.Add(1)
+		go func() {
+			defer fetchers.Done()
+			for height := range heights {
+				block := limiter.Admit()
+				observe()
+				pause()
+				// Some blocks fail to fetch and leave the pipeline right away
+				if height%7 == 0 {
+					block.Release()
+					continue
+				}
+				fetched <- block
+			}
+		}()
+	}
+
+	go func() {
+		for height := int64(1); height <= 500; height++ {
+			heights <- height
+		}
+		close(heights)
+		fetchers.Wait()
+		close(fetched)
+	}()
+
+	go func() {
+		for block := range fetched {
+			pause()
+			block.Retain()
+			eventQueue <- block
+			block.Retain()
+			txQueue <- block
+			block.Release()
+			observe()
+		}
+		close(eventQueue)
+		close(txQueue)
+	}()
+
+	var writers sync.WaitGroup
+	for _, queue := range []chan *InflightBlock{eventQueue, txQueue} {
+		writers.Add(1)
+		go func(queue chan *InflightBlock) {
+			defer writers.Done()
+			for block := range queue {
+				observe()
+				pause()
+				block.Release()
+			}
+		}(queue)
+	}
+	writers.Wait()
+
+	suite.LessOrEqual(observedMax, int64(maxInflight))
+	suite.LessOrEqual(limiter.Peak(), int64(maxInflight))
+	suite.Equal(int64(maxInflight), limiter.Peak())
+	suite.Equal(int64(0), limiter.InFlight())
+}
+
+func (suite *InflightTestSuite) TestUnlimitedTracksCount() {
+	limiter := NewInflightLimiter(0)
+
+	first := limiter.Admit()
+	second := limiter.Admit()
+	suite.Equal(int64(2), limiter.InFlight())
+
+	first.Release()
+	second.Retain()
+	second.Release()
+	suite.Equal(int64(1), limiter.InFlight())
+
+	second.Release()
+	suite.Equal(int64(0), limiter.InFlight())
+	suite.Equal(int64(2), limiter.Peak())
+	suite.Equal(int64(2), limiter.Left())
+	suite.Len(limiter.Leaving(), 1)
+}
+
+func (suite *InflightTestSuite) TestTryAdmit() {
+	limiter := NewInflightLimiter(1)
+
+	block, ok := limiter.TryAdmit()
+	suite.Require().True(ok)
+	_, ok = limiter.TryAdmit()
+	suite.False(ok)
+	suite.Equal(int64(1), limiter.InFlight())
+
+	block.Release()
+	block, ok = limiter.TryAdmit()
+	suite.Require().True(ok)
+	block.Release()
+	suite.Equal(int64(0), limiter.InFlight())
+}
+
+func (suite *InflightTestSuite) TestNilLimiter() {
+	var limiter *InflightLimiter
+	block := limiter.Admit()
+	suite.Nil(block)
+	block.Retain()
+	block.Release()
+	suite.Equal(int64(0), limiter.InFlight())
+	suite.Equal(int64(0), limiter.Left())
+	suite.Nil(limiter.Leaving())
+}
+
+func TestInflightSuite(t *testing.T) {
+	suite.Run(t, new(InflightTestSuite))
+}
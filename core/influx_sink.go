@@ -0,0 +1,225 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	influxSinkQueueSize = 1000
+	// Most blocks queued while a write is in flight are written together in the next request
+	influxSinkMaxBatch = 500
+	influxSinkRetries  = 2
+	influxSinkTimeout  = 10 * time.Second
+)
+
+// Measurements of the points written to the InfluxDB sink
+const (
+	InfluxBlockMeasurement    = "block_aggregates"
+	InfluxBlockFeeMeasurement = "block_fees"
+)
+
+// BlockAggregate is the per-block summary written to the InfluxDB sink
+type BlockAggregate struct {
+	Height  int64
+	Time    time.Time
+	TxCount int
+	// Only known with base.index-block-gas
+	GasUsed *int64
+	// Fees paid by the indexed txs of the block per denom
+	Fees map[string]decimal.Decimal
+	// Indexer phase the block was written in, only set with base.report-phase
+	Phase string
+}
+
+// NewBlockAggregate sums the indexed txs of the block
+func NewBlockAggregate(block models.Block, txs []dbTypes.TxDBWrapper) BlockAggregate {
+	aggregate := BlockAggregate{
+		Height:  block.Height,
+		Time:    block.TimeStamp,
+		TxCount: len(txs),
+		GasUsed: block.GasUsed,
+		Fees:    make(map[string]decimal.Decimal),
+	}
+
+	for _, tx := range txs {
+		for _, fee := range tx.Tx.Fees {
+			aggregate.Fees[fee.Denomination.Base] = aggregate.Fees[fee.Denomination.Base].Add(fee.Amount)
+		}
+	}
+
+	return aggregate
+}
+
+// InfluxSink writes per-block aggregate points to an InfluxDB v2 bucket in the background, alongside the database. Like the error
+// webhook, delivery is best-effort: points are dropped when the queue is full and given up on after a few failed writes, so the
+// sink never holds up indexing. A nil sink writes nothing.
+type InfluxSink struct {
+	writeURL   string
+	token      string
+	chainID    string
+	client     *http.Client
+	retryDelay time.Duration
+	queue      chan BlockAggregate
+	done       chan struct{}
+}
+
+// NewInfluxSink creates a sink writing the points of the chain to the bucket configured in the sink section and starts its writer
+func NewInfluxSink(conf config.Sink, chainID string) (*InfluxSink, error) {
+	writeURL, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, err
+	}
+	writeURL = writeURL.JoinPath("api", "v2", "write")
+	writeURL.RawQuery = url.Values{"org": {conf.Org}, "bucket": {conf.Bucket}, "precision": {"ns"}}.Encode()
+
+	sink := &InfluxSink{
+		writeURL:   writeURL.String(),
+		token:      conf.Token,
+		chainID:    chainID,
+		client:     &http.Client{Timeout: influxSinkTimeout},
+		retryDelay: time.Second,
+		queue:      make(chan BlockAggregate, influxSinkQueueSize),
+		done:       make(chan struct{}),
+	}
+
+	go sink.run()
+	return sink, nil
+}
+
+// Write queues the points of a block without waiting for them to be written
+func (sink *InfluxSink) Write(aggregate BlockAggregate) {
+	if sink == nil {
+		return
+	}
+
+	select {
+	case sink.queue <- aggregate:
+	default:
+		config.Log.Warnf("InfluxDB sink queue is full, dropping the points of block %d", aggregate.Height)
+	}
+}
+
+// Close stops accepting points and waits up to the timeout for the queued points to be written
+func (sink *InfluxSink) Close(timeout time.Duration) {
+	if sink == nil {
+		return
+	}
+
+	close(sink.queue)
+	select {
+	case <-sink.done:
+	case <-time.After(timeout):
+		config.Log.Warn("Timed out writing the queued InfluxDB sink points")
+	}
+}
+
+func (sink *InfluxSink) run() {
+	defer close(sink.done)
+	for aggregate := range sink.queue {
+		batch := []BlockAggregate{aggregate}
+	drain:
+		for len(batch) < influxSinkMaxBatch {
+			select {
+			case next, ok := <-sink.queue:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+		sink.deliver(batch)
+	}
+}
+
+func (sink *InfluxSink) deliver(batch []BlockAggregate) {
+	var lines bytes.Buffer
+	for _, aggregate := range batch {
+		lines.WriteString(sink.lineProtocol(aggregate))
+	}
+
+	var err error
+	for try := 0; try <= influxSinkRetries; try++ {
+		if try > 0 {
+			time.Sleep(sink.retryDelay * time.Duration(try))
+		}
+
+		err = sink.post(lines.Bytes())
+		if err == nil {
+			return
+		}
+	}
+
+	config.Log.Warnf("Failed to write the InfluxDB sink points of blocks %d to %d, giving up. Err: %v", batch[0].Height, batch[len(batch)-1].Height, err)
+}
+
+func (sink *InfluxSink) post(lines []byte) error {
+	req, err := http.NewRequest(http.MethodPost, sink.writeURL, bytes.NewReader(lines))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if sink.token != "" {
+		req.Header.Set("Authorization", "Token "+sink.token)
+	}
+
+	resp, err := sink.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("InfluxDB responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// lineProtocol returns the block point and one fee point per denom in the InfluxDB line protocol, in a deterministic order
+func (sink *InfluxSink) lineProtocol(aggregate BlockAggregate) string {
+	timestamp := strconv.FormatInt(aggregate.Time.UnixNano(), 10)
+	chainTag := "chain_id=" + escapeInfluxTag(sink.chainID)
+	if aggregate.Phase != "" {
+		chainTag += ",phase=" + escapeInfluxTag(aggregate.Phase)
+	}
+
+	var lines strings.Builder
+	fmt.Fprintf(&lines, "%s,%s height=%di,tx_count=%di", InfluxBlockMeasurement, chainTag, aggregate.Height, aggregate.TxCount)
+	if aggregate.GasUsed != nil {
+		fmt.Fprintf(&lines, ",gas_used=%di", *aggregate.GasUsed)
+	}
+	lines.WriteString(" " + timestamp + "\n")
+
+	denoms := make([]string, 0, len(aggregate.Fees))
+	for denom := range aggregate.Fees {
+		denoms = append(denoms, denom)
+	}
+	sort.Strings(denoms)
+
+	for _, denom := range denoms {
+		// Fee amounts are uint256, beyond the range of InfluxDB integers
+		amount := strconv.FormatFloat(aggregate.Fees[denom].InexactFloat64(), 'f', -1, 64)
+		fmt.Fprintf(&lines, "%s,%s,denom=%s height=%di,amount=%s %s\n", InfluxBlockFeeMeasurement, chainTag, escapeInfluxTag(denom), aggregate.Height, amount, timestamp)
+	}
+
+	return lines.String()
+}
+
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+func escapeInfluxTag(value string) string {
+	return influxTagEscaper.Replace(value)
+}
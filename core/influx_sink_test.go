@@ -0,0 +1,113 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+type InfluxSinkTestSuite struct {
+	suite.Suite
+}
+
+// influxWriteServer is a mock InfluxDB write endpoint recording the lines and queries of the writes it receives
+func influxWriteServer(failures int) (*httptest.Server, func() ([]string, []url.Values, []string)) {
+	var lock sync.Mutex
+	var lines []string
+	var queries []url.Values
+	var tokens []string
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		requests++
+		if requests <= failures || r.URL.Path != "/api/v2/write" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")...)
+		queries = append(queries, r.URL.Query())
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	return server, func() ([]string, []url.Values, []string) {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string{}, lines...), append([]url.Values{}, queries...), append([]string{}, tokens...)
+	}
+}
+
+func (suite *InfluxSinkTestSuite) TestBlockAggregatePoints() {
+	// The first write fails and is retried
+	server, received := influxWriteServer(1)
+	defer server.Close()
+
+	sink, err := NewInfluxSink(config.Sink{URL: server.URL, Org: "defiant", Bucket: "chain metrics", Token: "secret"}, "cosmoshub-4")
+	suite.Require().NoError(err)
+	sink.retryDelay = time.Millisecond
+
+	gasUsed := int64(250000)
+	blockTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	block := models.Block{Height: 100, TimeStamp: blockTime, GasUsed: &gasUsed}
+	fee := func(denom string, amount int64) models.Fee {
+		return models.Fee{Amount: decimal.NewFromInt(amount), Denomination: models.Denom{Base: denom}}
+	}
+	txs := []dbTypes.TxDBWrapper{
+		{Tx: models.Tx{Hash: "A", Fees: []models.Fee{fee("uatom", 5000)}}},
+		{Tx: models.Tx{Hash: "B", Fees: []models.Fee{fee("uatom", 2500), fee("ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", 10)}}},
+		{Tx: models.Tx{Hash: "C"}},
+	}
+
+	sink.Write(NewBlockAggregate(block, txs))
+	tailing := NewBlockAggregate(models.Block{Height: 101, TimeStamp: blockTime.Add(6 * time.Second)}, nil)
+	tailing.Phase = "tailing"
+	sink.Write(tailing)
+	sink.Close(5 * time.Second)
+
+	lines, queries, tokens := received()
+	timestamp := "1772366400000000000"
+	suite.Equal([]string{
+		"block_aggregates,chain_id=cosmoshub-4 height=100i,tx_count=3i,gas_used=250000i " + timestamp,
+		"block_fees,chain_id=cosmoshub-4,denom=ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2 height=100i,amount=10 " + timestamp,
+		"block_fees,chain_id=cosmoshub-4,denom=uatom height=100i,amount=7500 " + timestamp,
+		"block_aggregates,chain_id=cosmoshub-4,phase=tailing height=101i,tx_count=0i 1772366406000000000",
+	}, lines)
+
+	suite.Require().NotEmpty(queries)
+	suite.Equal("defiant", queries[0].Get("org"))
+	suite.Equal("chain metrics", queries[0].Get("bucket"))
+	suite.Equal("ns", queries[0].Get("precision"))
+	suite.Equal("Token secret", tokens[0])
+}
+
+func (suite *InfluxSinkTestSuite) TestTagEscaping() {
+	suite.Equal(`a\ b\,c\=d`, escapeInfluxTag("a b,c=d"))
+}
+
+func (suite *InfluxSinkTestSuite) TestNilSink() {
+	var sink *InfluxSink
+	suite.NotPanics(func() {
+		sink.Write(BlockAggregate{Height: 1})
+		sink.Close(time.Second)
+	})
+}
+
+func TestInfluxSinkTestSuite(t *testing.T) {
+	suite.Run(t, new(InfluxSinkTestSuite))
+}
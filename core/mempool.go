@@ -0,0 +1,83 @@
+package core
+
+import (
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	probeClient "github.com/DefiantLabs/probe/client"
+	"gorm.io/gorm"
+)
+
+// The node caps the unconfirmed txs returned by a single request at 100
+const mempoolPollLimit = 100
+
+// getUnconfirmedTxs fetches the txs in the node's mempool, overridden in tests
+var getUnconfirmedTxs = rpc.GetUnconfirmedTxs
+
+// MempoolPoller polls the node's unconfirmed txs and stores the newly seen ones with the time they were first seen. Nodes do
+// not stream their mempool, so txs that enter and leave the mempool between two polls are not observed.
+type MempoolPoller struct {
+	chainClient *probeClient.ChainClient
+	store       func([]models.MempoolTx) error
+	now         func() time.Time
+	// Hashes in the mempool at the last poll, txs still pending are not stored again
+	pending map[string]struct{}
+}
+
+// NewMempoolPoller creates a poller storing the pending txs of the node in the database
+func NewMempoolPoller(chainClient *probeClient.ChainClient, db *gorm.DB) *MempoolPoller {
+	return &MempoolPoller{
+		chainClient: chainClient,
+		store: func(txs []models.MempoolTx) error {
+			return dbTypes.InsertMempoolTxs(db, txs)
+		},
+		now:     time.Now,
+		pending: make(map[string]struct{}),
+	}
+}
+
+// Run polls the mempool every interval. It does not return.
+func (poller *MempoolPoller) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := poller.poll(); err != nil {
+			config.Log.Warnf("Failed to poll the node's mempool. Err: %v", err)
+		}
+	}
+}
+
+// poll stores the txs that were not in the mempool at the last poll
+func (poller *MempoolPoller) poll() error {
+	resp, err := getUnconfirmedTxs(poller.chainClient, mempoolPollLimit)
+	if err != nil {
+		return err
+	}
+
+	seen := poller.now()
+	pending := make(map[string]struct{}, len(resp.Txs))
+	var newTxs []models.MempoolTx
+	for _, tx := range resp.Txs {
+		hash := tendermintHashToHex(tx.Hash())
+		if _, ok := pending[hash]; ok {
+			continue
+		}
+		pending[hash] = struct{}{}
+
+		if _, ok := poller.pending[hash]; !ok {
+			newTxs = append(newTxs, models.MempoolTx{Hash: hash, FirstSeen: seen, Size: len(tx)})
+		}
+	}
+
+	if err := poller.store(newTxs); err != nil {
+		// Keep the previous view, so the txs are stored on the next poll
+		return err
+	}
+
+	poller.pending = pending
+	return nil
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	probeClient "github.com/DefiantLabs/probe/client"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type MempoolTestSuite struct {
+	suite.Suite
+}
+
+// mockMempool replays a sequence of mempool snapshots, one per poll
+type mockMempool struct {
+	snapshots [][]cmtTypes.Tx
+	polls     int
+	failPoll  int
+}
+
+func (mempool *mockMempool) unconfirmedTxs(cl *probeClient.ChainClient, limit int) (*ctypes.ResultUnconfirmedTxs, error) {
+	mempool.polls++
+	if mempool.polls == mempool.failPoll {
+		return nil, errors.New("connection refused")
+	}
+
+	txs := mempool.snapshots[mempool.polls-1]
+	return &ctypes.ResultUnconfirmedTxs{Count: len(txs), Total: len(txs), Txs: txs}, nil
+}
+
+func (suite *MempoolTestSuite) TestFirstSeen() {
+	txA, txB, txC := cmtTypes.Tx("tx-a"), cmtTypes.Tx("tx-b"), cmtTypes.Tx("tx-c")
+	mempool := &mockMempool{snapshots: [][]cmtTypes.Tx{
+		{txA},
+		{txA, txB},
+		// Poll 3 fails
+		nil,
+		// txA was committed, txC arrived while the node was unreachable
+		{txB, txC},
+		// txA re-broadcast after being evicted
+		{txA, txC},
+	}, failPoll: 3}
+
+	originalGetUnconfirmedTxs := getUnconfirmedTxs
+	defer func() { getUnconfirmedTxs = originalGetUnconfirmedTxs }()
+	getUnconfirmedTxs = mempool.unconfirmedTxs
+
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	var stored []models.MempoolTx
+	poller := &MempoolPoller{
+		store: func(txs []models.MempoolTx) error {
+			stored = append(stored, txs...)
+			return nil
+		},
+		now:     func() time.Time { return start.Add(time.Duration(mempool.polls) * time.Second) },
+		pending: make(map[string]struct{}),
+	}
+
+	suite.Require().NoError(poller.poll())
+	suite.Require().NoError(poller.poll())
+	suite.Require().Error(poller.poll())
+	suite.Require().NoError(poller.poll())
+	suite.Require().NoError(poller.poll())
+
+	hashA, hashB, hashC := tendermintHashToHex(txA.Hash()), tendermintHashToHex(txB.Hash()), tendermintHashToHex(txC.Hash())
+	suite.Equal([]models.MempoolTx{
+		{Hash: hashA, FirstSeen: start.Add(time.Second), Size: 4},
+		{Hash: hashB, FirstSeen: start.Add(2 * time.Second), Size: 4},
+		{Hash: hashC, FirstSeen: start.Add(4 * time.Second), Size: 4},
+		// Stored again, the database keeps the first time it was seen
+		{Hash: hashA, FirstSeen: start.Add(5 * time.Second), Size: 4},
+	}, stored)
+}
+
+func (suite *MempoolTestSuite) TestStoreFailureRetried() {
+	tx := cmtTypes.Tx("tx-a")
+	mempool := &mockMempool{snapshots: [][]cmtTypes.Tx{{tx}, {tx}}}
+
+	originalGetUnconfirmedTxs := getUnconfirmedTxs
+	defer func() { getUnconfirmedTxs = originalGetUnconfirmedTxs }()
+	getUnconfirmedTxs = mempool.unconfirmedTxs
+
+	storeCalls := 0
+	var stored []models.MempoolTx
+	poller := &MempoolPoller{
+		store: func(txs []models.MempoolTx) error {
+			storeCalls++
+			if storeCalls == 1 {
+				return errors.New("database unavailable")
+			}
+			stored = append(stored, txs...)
+			return nil
+		},
+		now:     time.Now,
+		pending: make(map[string]struct{}),
+	}
+
+	suite.Require().Error(poller.poll())
+	suite.Require().NoError(poller.poll())
+	suite.Require().Len(stored, 1)
+	suite.Equal(tendermintHashToHex(tx.Hash()), stored[0].Hash)
+}
+
+func TestMempoolTestSuite(t *testing.T) {
+	suite.Run(t, new(MempoolTestSuite))
+}
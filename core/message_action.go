@@ -0,0 +1,97 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MessageActions maps message type URLs to the human readable action label stored in the action column of their messages
+type MessageActions map[string]string
+
+// defaultMessageActions labels the common SDK, IBC and CosmWasm message types
+var defaultMessageActions = MessageActions{
+	"/cosmos.bank.v1beta1.MsgSend":                                "send",
+	"/cosmos.bank.v1beta1.MsgMultiSend":                           "multi_send",
+	"/cosmos.staking.v1beta1.MsgDelegate":                         "delegate",
+	"/cosmos.staking.v1beta1.MsgUndelegate":                       "undelegate",
+	"/cosmos.staking.v1beta1.MsgBeginRedelegate":                  "redelegate",
+	"/cosmos.staking.v1beta1.MsgCancelUnbondingDelegation":        "cancel_unbonding",
+	"/cosmos.staking.v1beta1.MsgCreateValidator":                  "create_validator",
+	"/cosmos.staking.v1beta1.MsgEditValidator":                    "edit_validator",
+	"/cosmos.distribution.v1beta1.MsgWithdrawDelegatorReward":     "claim_rewards",
+	"/cosmos.distribution.v1beta1.MsgWithdrawValidatorCommission": "withdraw_commission",
+	"/cosmos.distribution.v1beta1.MsgSetWithdrawAddress":          "set_withdraw_address",
+	"/cosmos.distribution.v1beta1.MsgFundCommunityPool":           "fund_community_pool",
+	"/cosmos.gov.v1beta1.MsgSubmitProposal":                       "submit_proposal",
+	"/cosmos.gov.v1.MsgSubmitProposal":                            "submit_proposal",
+	"/cosmos.gov.v1beta1.MsgDeposit":                              "deposit",
+	"/cosmos.gov.v1.MsgDeposit":                                   "deposit",
+	"/cosmos.gov.v1beta1.MsgVote":                                 "vote",
+	"/cosmos.gov.v1.MsgVote":                                      "vote",
+	"/cosmos.gov.v1beta1.MsgVoteWeighted":                         "vote",
+	"/cosmos.gov.v1.MsgVoteWeighted":                              "vote",
+	"/cosmos.slashing.v1beta1.MsgUnjail":                          "unjail",
+	"/cosmos.authz.v1beta1.MsgGrant":                              "authz_grant",
+	"/cosmos.authz.v1beta1.MsgRevoke":                             "authz_revoke",
+	"/cosmos.authz.v1beta1.MsgExec":                               "authz_exec",
+	"/cosmos.feegrant.v1beta1.MsgGrantAllowance":                  "fee_grant",
+	"/cosmos.feegrant.v1beta1.MsgRevokeAllowance":                 "fee_revoke",
+	"/ibc.applications.transfer.v1.MsgTransfer":                   "ibc_transfer",
+	"/ibc.core.channel.v1.MsgRecvPacket":                          "ibc_receive",
+	"/ibc.core.channel.v1.MsgAcknowledgement":                     "ibc_acknowledgement",
+	"/ibc.core.channel.v1.MsgTimeout":                             "ibc_timeout",
+	"/ibc.core.client.v1.MsgUpdateClient":                         "ibc_update_client",
+	"/cosmwasm.wasm.v1.MsgStoreCode":                              "store_code",
+	"/cosmwasm.wasm.v1.MsgInstantiateContract":                    "instantiate_contract",
+	"/cosmwasm.wasm.v1.MsgInstantiateContract2":                   "instantiate_contract",
+	"/cosmwasm.wasm.v1.MsgExecuteContract":                        "execute_contract",
+	"/cosmwasm.wasm.v1.MsgMigrateContract":                        "migrate_contract",
+}
+
+// LoadMessageActions returns the built-in labels, extended and overridden by the JSON object of type URLs to labels in the file
+// at the path (e.g. {"/osmosis.gamm.v1beta1.MsgSwapExactAmountIn": "swap"}). An empty path returns the built-in labels only.
+func LoadMessageActions(path string) (MessageActions, error) {
+	actions := make(MessageActions, len(defaultMessageActions))
+	for typeURL, action := range defaultMessageActions {
+		actions[typeURL] = action
+	}
+
+	if path == "" {
+		return actions, nil
+	}
+
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading action map file %s: %w", path, err)
+	}
+
+	var custom map[string]string
+	if err := json.Unmarshal(fileBytes, &custom); err != nil {
+		return nil, fmt.Errorf("error parsing action map file %s, expected a JSON object of type URLs to action labels: %w", path, err)
+	}
+
+	for typeURL, action := range custom {
+		if action == "" {
+			return nil, fmt.Errorf("action label for message type %s must not be empty", typeURL)
+		}
+		actions["/"+strings.TrimPrefix(typeURL, "/")] = action
+	}
+
+	return actions, nil
+}
+
+// Action returns the label of the message type, falling back to the last segment of unmapped type URLs
+// (e.g. MsgSwapExactAmountIn). A nil map returns nil.
+func (actions MessageActions) Action(typeURL string) *string {
+	if actions == nil {
+		return nil
+	}
+
+	action, ok := actions[typeURL]
+	if !ok {
+		action = typeURL[strings.LastIndexAny(typeURL, "/.")+1:]
+	}
+	return &action
+}
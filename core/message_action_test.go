@@ -0,0 +1,55 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MessageActionTestSuite struct {
+	suite.Suite
+	actions MessageActions
+}
+
+func (suite *MessageActionTestSuite) SetupTest() {
+	path := filepath.Join(suite.T().TempDir(), "actions.json")
+	err := os.WriteFile(path, []byte(`{"osmosis.gamm.v1beta1.MsgSwapExactAmountIn": "swap", "/cosmos.bank.v1beta1.MsgMultiSend": "send"}`), 0o600)
+	suite.Require().NoError(err)
+
+	suite.actions, err = LoadMessageActions(path)
+	suite.Require().NoError(err)
+}
+
+func (suite *MessageActionTestSuite) TestBuiltInAction() {
+	suite.Equal("send", *suite.actions.Action("/cosmos.bank.v1beta1.MsgSend"))
+	suite.Equal("vote", *suite.actions.Action("/cosmos.gov.v1.MsgVoteWeighted"))
+}
+
+func (suite *MessageActionTestSuite) TestCustomMappedAction() {
+	suite.Equal("swap", *suite.actions.Action("/osmosis.gamm.v1beta1.MsgSwapExactAmountIn"))
+	// The file overrides built-in labels
+	suite.Equal("send", *suite.actions.Action("/cosmos.bank.v1beta1.MsgMultiSend"))
+}
+
+func (suite *MessageActionTestSuite) TestUnmappedTypeFallsBackToLastSegment() {
+	suite.Equal("MsgSubmitBid", *suite.actions.Action("/sdk.auction.v1.MsgSubmitBid"))
+}
+
+func (suite *MessageActionTestSuite) TestDisabled() {
+	var actions MessageActions
+	suite.Nil(actions.Action("/cosmos.bank.v1beta1.MsgSend"))
+}
+
+func (suite *MessageActionTestSuite) TestEmptyLabel() {
+	path := filepath.Join(suite.T().TempDir(), "actions.json")
+	suite.Require().NoError(os.WriteFile(path, []byte(`{"/sdk.auction.v1.MsgSubmitBid": ""}`), 0o600))
+
+	_, err := LoadMessageActions(path)
+	suite.ErrorContains(err, "must not be empty")
+}
+
+func TestMessageActionTestSuite(t *testing.T) {
+	suite.Run(t, new(MessageActionTestSuite))
+}
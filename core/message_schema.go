@@ -0,0 +1,76 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// MessageSchemas maps message type URLs to the JSON schema their decoded JSON form is validated against
+type MessageSchemas map[string]*gojsonschema.Schema
+
+// LoadMessageSchemas loads every .json file in the directory as a JSON schema. The file name, without the extension,
+// is the message type URL the schema applies to, with or without the leading slash (e.g. cosmos.bank.v1beta1.MsgSend.json).
+func LoadMessageSchemas(dir string) (MessageSchemas, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading message schema dir %s: %w", dir, err)
+	}
+
+	schemas := make(MessageSchemas)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		schemaBytes, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading message schema %s: %w", entry.Name(), err)
+		}
+
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaBytes))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing message schema %s: %w", entry.Name(), err)
+		}
+
+		typeURL := "/" + strings.TrimPrefix(strings.TrimSuffix(entry.Name(), ".json"), "/")
+		schemas[typeURL] = schema
+	}
+
+	return schemas, nil
+}
+
+// Validate checks the decoded JSON form of the message against the schema for its type URL. Messages without a schema pass.
+// The returned error describes every violation found.
+func (schemas MessageSchemas) Validate(messageTypeURL string, message types.Msg) error {
+	schema, ok := schemas[messageTypeURL]
+	if !ok {
+		return nil
+	}
+
+	messageJSON, err := codec.ProtoMarshalJSON(message, nil)
+	if err != nil {
+		return fmt.Errorf("error encoding message as JSON: %w", err)
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(messageJSON))
+	if err != nil {
+		return fmt.Errorf("error validating message: %w", err)
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	violations := make([]string, len(result.Errors()))
+	for i, violation := range result.Errors() {
+		violations[i] = violation.String()
+	}
+
+	return fmt.Errorf("message does not match schema: %s", strings.Join(violations, "; "))
+}
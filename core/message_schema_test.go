@@ -0,0 +1,66 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/suite"
+)
+
+const msgSendSchema = `{
+	"type": "object",
+	"required": ["from_address", "to_address", "amount"],
+	"properties": {
+		"from_address": {"type": "string", "pattern": "^cosmos1"},
+		"to_address": {"type": "string", "pattern": "^cosmos1"},
+		"amount": {"type": "array", "minItems": 1}
+	}
+}`
+
+type MessageSchemaTestSuite struct {
+	suite.Suite
+	schemas MessageSchemas
+}
+
+func (suite *MessageSchemaTestSuite) SetupTest() {
+	dir := suite.T().TempDir()
+	err := os.WriteFile(filepath.Join(dir, "cosmos.bank.v1beta1.MsgSend.json"), []byte(msgSendSchema), 0o600)
+	suite.Require().NoError(err)
+
+	suite.schemas, err = LoadMessageSchemas(dir)
+	suite.Require().NoError(err)
+	suite.Require().Contains(suite.schemas, "/cosmos.bank.v1beta1.MsgSend")
+}
+
+func (suite *MessageSchemaTestSuite) TestConformingMessage() {
+	msg := &bankTypes.MsgSend{
+		FromAddress: "cosmos1sender",
+		ToAddress:   "cosmos1recipient",
+		Amount:      types.NewCoins(types.NewInt64Coin("uatom", 100)),
+	}
+
+	suite.NoError(suite.schemas.Validate("/cosmos.bank.v1beta1.MsgSend", msg))
+}
+
+func (suite *MessageSchemaTestSuite) TestNonConformingMessage() {
+	msg := &bankTypes.MsgSend{
+		FromAddress: "osmo1sender",
+		ToAddress:   "cosmos1recipient",
+	}
+
+	err := suite.schemas.Validate("/cosmos.bank.v1beta1.MsgSend", msg)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "from_address")
+	suite.Contains(err.Error(), "amount")
+}
+
+func (suite *MessageSchemaTestSuite) TestMessageWithoutSchema() {
+	suite.NoError(suite.schemas.Validate("/cosmos.bank.v1beta1.MsgMultiSend", &bankTypes.MsgMultiSend{}))
+}
+
+func TestMessageSchemaSuite(t *testing.T) {
+	suite.Run(t, new(MessageSchemaTestSuite))
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	"github.com/DefiantLabs/probe/client"
+	"github.com/shopspring/decimal"
+)
+
+// getAllBalances queries the balances of an address at the height, overridden in tests
+var getAllBalances = rpc.GetAllBalances
+
+// SnapshotModuleBalances returns the balances of the base.module-balance-addresses accounts at the height, one row per denom.
+// Nothing is queried unless base.index-module-balances is enabled and the height is on the base.module-balance-stride.
+// Rows are returned without block IDs, these are filled in when the block is stored. The client must decode with the codec in effect at the height.
+func SnapshotModuleBalances(cfg *config.IndexConfig, cl *client.ChainClient, height int64) ([]models.ModuleBalance, error) {
+	if !cfg.Base.IndexModuleBalances || cfg.Base.ModuleBalanceStride <= 0 || height%cfg.Base.ModuleBalanceStride != 0 {
+		return nil, nil
+	}
+
+	var moduleBalances []models.ModuleBalance
+	for _, address := range cfg.Base.ModuleBalanceAddresses {
+		balances, err := getAllBalances(cl, address, height)
+		if err != nil {
+			return nil, fmt.Errorf("error querying balances of %s: %w", address, err)
+		}
+
+		for _, coin := range balances {
+			moduleBalances = append(moduleBalances, models.ModuleBalance{
+				Height:  height,
+				Address: address,
+				Denom:   coin.Denom,
+				Amount:  decimal.NewFromBigInt(coin.Amount.BigInt(), 0),
+			})
+		}
+	}
+
+	return moduleBalances, nil
+}
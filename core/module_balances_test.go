@@ -0,0 +1,79 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	probeClient "github.com/DefiantLabs/probe/client"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/suite"
+)
+
+const communityPoolAddress = "cosmos1jv65s3grqf6v6jl3dp4t6c9t9rk99cd88lyufl"
+
+type ModuleBalancesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ModuleBalancesTestSuite) SetupTest() {
+	originalGetAllBalances := getAllBalances
+	suite.T().Cleanup(func() { getAllBalances = originalGetAllBalances })
+}
+
+func (suite *ModuleBalancesTestSuite) TestSnapshotAtStride() {
+	var queriedHeights []int64
+	getAllBalances = func(cl *probeClient.ChainClient, address string, height int64) (types.Coins, error) {
+		suite.Equal(communityPoolAddress, address)
+		queriedHeights = append(queriedHeights, height)
+		return types.NewCoins(types.NewInt64Coin("uatom", 1500000), types.NewInt64Coin("ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", 42)), nil
+	}
+
+	cfg := &config.IndexConfig{}
+	cfg.Base.IndexModuleBalances = true
+	cfg.Base.ModuleBalanceAddresses = []string{communityPoolAddress}
+	cfg.Base.ModuleBalanceStride = 100
+
+	// Heights off the stride are not queried
+	balances, err := SnapshotModuleBalances(cfg, &probeClient.ChainClient{}, 150)
+	suite.Require().NoError(err)
+	suite.Empty(balances)
+
+	balances, err = SnapshotModuleBalances(cfg, &probeClient.ChainClient{}, 200)
+	suite.Require().NoError(err)
+	suite.Equal([]int64{200}, queriedHeights)
+	suite.Require().Len(balances, 2)
+
+	// Coins are sorted by denom
+	suite.Equal(int64(200), balances[0].Height)
+	suite.Equal(communityPoolAddress, balances[0].Address)
+	suite.Equal("ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", balances[0].Denom)
+	suite.Equal("42", balances[0].Amount.String())
+	suite.Equal("uatom", balances[1].Denom)
+	suite.Equal("1500000", balances[1].Amount.String())
+
+	// Nothing is queried when the option is disabled
+	cfg.Base.IndexModuleBalances = false
+	balances, err = SnapshotModuleBalances(cfg, &probeClient.ChainClient{}, 300)
+	suite.Require().NoError(err)
+	suite.Empty(balances)
+	suite.Len(queriedHeights, 1)
+}
+
+func (suite *ModuleBalancesTestSuite) TestSnapshotQueryError() {
+	getAllBalances = func(cl *probeClient.ChainClient, address string, height int64) (types.Coins, error) {
+		return nil, errors.New("state pruned")
+	}
+
+	cfg := &config.IndexConfig{}
+	cfg.Base.IndexModuleBalances = true
+	cfg.Base.ModuleBalanceAddresses = []string{communityPoolAddress}
+	cfg.Base.ModuleBalanceStride = 10
+
+	_, err := SnapshotModuleBalances(cfg, &probeClient.ChainClient{}, 20)
+	suite.ErrorContains(err, "state pruned")
+}
+
+func TestModuleBalancesTestSuite(t *testing.T) {
+	suite.Run(t, new(ModuleBalancesTestSuite))
+}
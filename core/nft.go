@@ -0,0 +1,148 @@
+package core
+
+import (
+	"encoding/json"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+)
+
+// Typed events of the cosmos nft module, their attribute values are JSON encoded (e.g. "\"kitty\"")
+const (
+	nftEventSend = "cosmos.nft.v1beta1.EventSend"
+	nftEventMint = "cosmos.nft.v1beta1.EventMint"
+	nftEventBurn = "cosmos.nft.v1beta1.EventBurn"
+)
+
+// Attribute keys of the nft module events
+const (
+	nftAttributeClassID  = "class_id"
+	nftAttributeID       = "id"
+	nftAttributeSender   = "sender"
+	nftAttributeReceiver = "receiver"
+	nftAttributeOwner    = "owner"
+)
+
+// cw721 contracts emit their activity as wasm events, identified by the action attribute
+const (
+	wasmEventType      = "wasm"
+	cw721TransferNFT   = "transfer_nft"
+	cw721SendNFT       = "send_nft"
+	cw721Mint          = "mint"
+	cw721Burn          = "burn"
+	cw721AttrContract  = "_contract_address"
+	cw721AttrAction    = "action"
+	cw721AttrTokenID   = "token_id"
+	cw721AttrSender    = "sender"
+	cw721AttrOwner     = "owner"
+	cw721AttrRecipient = "recipient"
+)
+
+var nftEventActions = map[string]string{
+	nftEventSend: models.NFTActionTransfer,
+	nftEventMint: models.NFTActionMint,
+	nftEventBurn: models.NFTActionBurn,
+}
+
+// ExtractMessageNFTActivities returns the nft module and cw721 mints, transfers and burns of the message events. Rows are
+// returned without block, tx or message IDs.
+func ExtractMessageNFTActivities(messageLog *txtypes.LogMessage) []models.NFTActivity {
+	if messageLog == nil {
+		return nil
+	}
+
+	var activities []models.NFTActivity
+	var eventIndex uint64
+	for _, event := range messageLog.Events {
+		if _, ok := nftEventActions[event.Type]; !ok && event.Type != wasmEventType {
+			continue
+		}
+
+		keys := make([]string, len(event.Attributes))
+		values := make([]string, len(event.Attributes))
+		for i, attribute := range event.Attributes {
+			keys[i] = attribute.Key
+			values[i] = attribute.Value
+		}
+
+		for _, attributes := range splitEventAttributes(keys, values) {
+			var activity *models.NFTActivity
+			if event.Type == wasmEventType {
+				activity = cw721Activity(attributes)
+			} else {
+				activity = nativeNFTActivity(nftEventActions[event.Type], attributes)
+			}
+
+			if activity != nil {
+				activity.EventIndex = eventIndex
+				activities = append(activities, *activity)
+				eventIndex++
+			}
+		}
+	}
+
+	return activities
+}
+
+// nativeNFTActivity returns the activity of an nft module event
+func nativeNFTActivity(action string, attributes eventAttributeSet) *models.NFTActivity {
+	activity := &models.NFTActivity{
+		Source:  models.NFTSourceNative,
+		Action:  action,
+		ClassID: nftAttributeValue(attributes[nftAttributeClassID]),
+		TokenID: nftAttributeValue(attributes[nftAttributeID]),
+	}
+
+	switch action {
+	case models.NFTActionTransfer:
+		activity.PreviousOwner = nftAttributeValue(attributes[nftAttributeSender])
+		activity.Owner = nftAttributeValue(attributes[nftAttributeReceiver])
+	case models.NFTActionMint:
+		activity.Owner = nftAttributeValue(attributes[nftAttributeOwner])
+	case models.NFTActionBurn:
+		activity.PreviousOwner = nftAttributeValue(attributes[nftAttributeOwner])
+	}
+
+	return activity
+}
+
+// cw721Activity returns the activity of a wasm event of a cw721 contract, nil for other contract events. cw20 contracts also emit
+// mint and burn actions, only events with a token_id are cw721 activity.
+func cw721Activity(attributes eventAttributeSet) *models.NFTActivity {
+	tokenID, ok := attributes[cw721AttrTokenID]
+	if !ok {
+		return nil
+	}
+
+	activity := &models.NFTActivity{
+		Source:  models.NFTSourceCW721,
+		ClassID: attributes[cw721AttrContract],
+		TokenID: tokenID,
+	}
+
+	switch attributes[cw721AttrAction] {
+	case cw721TransferNFT, cw721SendNFT:
+		activity.Action = models.NFTActionTransfer
+		activity.PreviousOwner = attributes[cw721AttrSender]
+		activity.Owner = attributes[cw721AttrRecipient]
+	case cw721Mint:
+		activity.Action = models.NFTActionMint
+		activity.Owner = attributes[cw721AttrOwner]
+	case cw721Burn:
+		activity.Action = models.NFTActionBurn
+		activity.PreviousOwner = attributes[cw721AttrSender]
+	default:
+		return nil
+	}
+
+	return activity
+}
+
+// nftAttributeValue decodes a JSON encoded typed event attribute value, values that are not JSON strings are kept as they are
+func nftAttributeValue(value string) string {
+	var decoded string
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"testing"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testNFTSender   = "cosmos1nftsender"
+	testNFTReceiver = "cosmos1nftreceiver"
+	testCW721       = "cosmos14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s4hmalr"
+)
+
+type NFTTestSuite struct {
+	suite.Suite
+}
+
+func (suite *NFTTestSuite) TestNativeTransfer() {
+	// Typed events have JSON encoded attribute values
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "message", Attributes: []txtypes.Attribute{{Key: "action", Value: "/cosmos.nft.v1beta1.MsgSend"}}},
+			{Type: "cosmos.nft.v1beta1.EventSend", Attributes: []txtypes.Attribute{
+				{Key: "class_id", Value: `"kitty"`},
+				{Key: "id", Value: `"kitty1"`},
+				{Key: "sender", Value: `"` + testNFTSender + `"`},
+				{Key: "receiver", Value: `"` + testNFTReceiver + `"`},
+			}},
+		},
+	}
+
+	activities := ExtractMessageNFTActivities(messageLog)
+	suite.Require().Len(activities, 1)
+	suite.Equal(models.NFTActivity{
+		Source:        models.NFTSourceNative,
+		Action:        models.NFTActionTransfer,
+		ClassID:       "kitty",
+		TokenID:       "kitty1",
+		PreviousOwner: testNFTSender,
+		Owner:         testNFTReceiver,
+	}, activities[0])
+}
+
+func (suite *NFTTestSuite) TestCW721Transfer() {
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "execute", Attributes: []txtypes.Attribute{{Key: "_contract_address", Value: testCW721}}},
+			{Type: "wasm", Attributes: []txtypes.Attribute{
+				{Key: "_contract_address", Value: testCW721},
+				{Key: "action", Value: "transfer_nft"},
+				{Key: "sender", Value: testNFTSender},
+				{Key: "recipient", Value: testNFTReceiver},
+				{Key: "token_id", Value: "42"},
+			}},
+		},
+	}
+
+	activities := ExtractMessageNFTActivities(messageLog)
+	suite.Require().Len(activities, 1)
+	suite.Equal(models.NFTActivity{
+		Source:        models.NFTSourceCW721,
+		Action:        models.NFTActionTransfer,
+		ClassID:       testCW721,
+		TokenID:       "42",
+		PreviousOwner: testNFTSender,
+		Owner:         testNFTReceiver,
+	}, activities[0])
+}
+
+func (suite *NFTTestSuite) TestCW721MergedWasmEvents() {
+	// The log merges the wasm events of a cw20 mint and a cw721 mint and burn into a single event
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "wasm", Attributes: []txtypes.Attribute{
+				{Key: "_contract_address", Value: "cosmos1cw20"},
+				{Key: "action", Value: "mint"},
+				{Key: "to", Value: testNFTReceiver},
+				{Key: "amount", Value: "100"},
+				{Key: "_contract_address", Value: testCW721},
+				{Key: "action", Value: "mint"},
+				{Key: "minter", Value: testNFTSender},
+				{Key: "owner", Value: testNFTReceiver},
+				{Key: "token_id", Value: "7"},
+				{Key: "_contract_address", Value: testCW721},
+				{Key: "action", Value: "burn"},
+				{Key: "sender", Value: testNFTReceiver},
+				{Key: "token_id", Value: "6"},
+			}},
+		},
+	}
+
+	activities := ExtractMessageNFTActivities(messageLog)
+	suite.Require().Len(activities, 2)
+	suite.Equal(models.NFTActionMint, activities[0].Action)
+	suite.Equal("7", activities[0].TokenID)
+	suite.Empty(activities[0].PreviousOwner)
+	suite.Equal(testNFTReceiver, activities[0].Owner)
+	suite.Equal(uint64(0), activities[0].EventIndex)
+
+	suite.Equal(models.NFTActionBurn, activities[1].Action)
+	suite.Equal("6", activities[1].TokenID)
+	suite.Equal(testNFTReceiver, activities[1].PreviousOwner)
+	suite.Empty(activities[1].Owner)
+	suite.Equal(uint64(1), activities[1].EventIndex)
+}
+
+func TestNFTTestSuite(t *testing.T) {
+	suite.Run(t, new(NFTTestSuite))
+}
@@ -20,12 +20,41 @@ const (
 	OsmosisNodeRewardIndexError
 	NodeMissingHistoryForBlock
 	FailedBlockEventHandling
+	BlockProcessingTimeout
 )
 
+// String returns the category name of the failure used in the error webhook payload
+func (code BlockProcessingFailure) String() string {
+	switch code {
+	case NodeMissingBlockTxs:
+		return "node_missing_block_txs"
+	case BlockQueryError:
+		return "block_query_error"
+	case UnprocessableTxError:
+		return "unprocessable_tx"
+	case OsmosisNodeRewardLookupError:
+		return "osmosis_reward_lookup_error"
+	case OsmosisNodeRewardIndexError:
+		return "osmosis_reward_index_error"
+	case NodeMissingHistoryForBlock:
+		return "node_missing_history_for_block"
+	case FailedBlockEventHandling:
+		return "failed_block_event_handling"
+	case BlockProcessingTimeout:
+		return "block_processing_timeout"
+	}
+	return "unknown"
+}
+
 type FailedBlockHandler func(height int64, code BlockProcessingFailure, err error)
 
 // Process RPC Block data into the model object used by the application.
+// Only header fields are read, so the commit-less first block of a chain (nil or empty LastCommit) is handled like any other height.
 func ProcessBlock(blockData *ctypes.ResultBlock, blockResultsData *rpc.CustomBlockResults, chainID uint) (models.Block, error) {
+	if blockData == nil || blockData.Block == nil {
+		return models.Block{}, fmt.Errorf("ProcessBlock: RPC block data is missing")
+	}
+
 	block := models.Block{
 		Height:  blockData.Block.Height,
 		ChainID: chainID,
@@ -38,6 +67,9 @@ func ProcessBlock(blockData *ctypes.ResultBlock, blockResultsData *rpc.CustomBlo
 
 	block.ProposerConsAddress = models.Address{Address: propAddressFromHex.String()}
 	block.TimeStamp = blockData.Block.Time
+	block.AppHash = blockData.Block.AppHash.String()
+	block.DataHash = blockData.Block.DataHash.String()
+	block.ConsensusHash = blockData.Block.ConsensusHash.String()
 
 	return block, nil
 }
@@ -58,6 +90,8 @@ func HandleFailedBlock(height int64, code BlockProcessingFailure, err error) {
 		reason = "Node has no TX history for block"
 	case FailedBlockEventHandling:
 		reason = "Failed to process block event"
+	case BlockProcessingTimeout:
+		reason = "Block processing exceeded the configured block timeout"
 	}
 
 	config.Log.Error(fmt.Sprintf("Block %v failed. Reason: %v", height, reason), err)
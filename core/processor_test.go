@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProcessorTestSuite struct {
+	suite.Suite
+}
+
+// Header fixture, hashes are the upper case hex encoding CometBFT uses when rendering HexBytes
+const (
+	fixtureAppHash         = "8B5E1F3A2C3E9A6F0D4B7C1E5A9F3D2B6C8E0A4F7D1B3C5E9A2F6D8B0C4E7A1F"
+	fixtureDataHash        = "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"
+	fixtureConsensusHash   = "80261DFBF66AFFF9F0E7B2F4FD2A4C7E9A5D6A1B0F0C6B3E4C8C0F9F2A7D5E11"
+	fixtureProposerAddress = "0D7A5F1E2C4B6A8D9E0F1A2B3C4D5E6F7A8B9C0D"
+)
+
+func mustDecodeHex(s string) []byte {
+	bytes, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
+
+func (suite *ProcessorTestSuite) TestProcessBlockHeaderHashes() {
+	blockData := &ctypes.ResultBlock{
+		Block: &cmtTypes.Block{
+			Header: cmtTypes.Header{
+				Height:          100,
+				Time:            time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				AppHash:         mustDecodeHex(fixtureAppHash),
+				DataHash:        mustDecodeHex(fixtureDataHash),
+				ConsensusHash:   mustDecodeHex(fixtureConsensusHash),
+				ProposerAddress: mustDecodeHex(fixtureProposerAddress),
+			},
+		},
+	}
+
+	block, err := ProcessBlock(blockData, nil, 1)
+	suite.Require().NoError(err)
+
+	suite.Equal(int64(100), block.Height)
+	suite.Equal(fixtureAppHash, block.AppHash)
+	suite.Equal(fixtureDataHash, block.DataHash)
+	suite.Equal(fixtureConsensusHash, block.ConsensusHash)
+}
+
+// earlyHeightFixture builds the blocks at the start of a chain. Height 1 carries no last commit, later heights commit to the previous block.
+func earlyHeightFixture(height int64) *ctypes.ResultBlock {
+	block := &cmtTypes.Block{
+		Header: cmtTypes.Header{
+			ChainID:         "testchain-1",
+			Height:          height,
+			Time:            time.Date(2024, 1, 1, 0, 0, int(height), 0, time.UTC),
+			AppHash:         mustDecodeHex(fixtureAppHash),
+			ProposerAddress: mustDecodeHex(fixtureProposerAddress),
+		},
+	}
+
+	if height > 1 {
+		block.LastBlockID = cmtTypes.BlockID{Hash: mustDecodeHex(fixtureDataHash)}
+		block.LastCommit = &cmtTypes.Commit{
+			Height:  height - 1,
+			BlockID: block.LastBlockID,
+			Signatures: []cmtTypes.CommitSig{
+				{
+					BlockIDFlag:      cmtTypes.BlockIDFlagCommit,
+					ValidatorAddress: mustDecodeHex(fixtureProposerAddress),
+				},
+			},
+		}
+	}
+
+	return &ctypes.ResultBlock{Block: block}
+}
+
+func (suite *ProcessorTestSuite) TestProcessEarlyHeights() {
+	conf := config.IndexConfig{}
+
+	for height := int64(1); height <= 3; height++ {
+		blockData := earlyHeightFixture(height)
+		if height == 1 {
+			suite.Nil(blockData.Block.LastCommit)
+		}
+
+		// Genesis-adjacent block results commonly come back with every field empty
+		blockResults := &rpc.CustomBlockResults{Height: height}
+
+		block, err := ProcessBlock(blockData, blockResults, 1)
+		suite.Require().NoError(err)
+		suite.Equal(height, block.Height)
+		suite.Equal(blockData.Block.Time, block.TimeStamp)
+		suite.NotEmpty(block.ProposerConsAddress.Address)
+
+		blockDBWrapper, err := ProcessRPCBlockResults(context.Background(), conf, block, blockResults, nil, nil)
+		suite.Require().NoError(err)
+		suite.Empty(blockDBWrapper.BeginBlockEvents)
+		suite.Empty(blockDBWrapper.EndBlockEvents)
+
+		txs, blockTime, err := ProcessRPCBlockByHeightTXs(context.Background(), &conf, nil, nil, nil, nil, blockData, blockResults, nil, TxLookups{})
+		suite.Require().NoError(err)
+		suite.Empty(txs)
+		suite.Equal(blockData.Block.Time, *blockTime)
+	}
+}
+
+func (suite *ProcessorTestSuite) TestProcessMissingBlockData() {
+	_, err := ProcessBlock(nil, nil, 1)
+	suite.Error(err)
+
+	_, err = ProcessBlock(&ctypes.ResultBlock{}, nil, 1)
+	suite.Error(err)
+
+	_, err = ProcessRPCBlockResults(context.Background(), config.IndexConfig{}, models.Block{Height: 1}, nil, nil, nil)
+	suite.Error(err)
+
+	_, _, err = ProcessRPCBlockByHeightTXs(context.Background(), &config.IndexConfig{}, nil, nil, nil, nil, earlyHeightFixture(1), nil, nil, TxLookups{})
+	suite.Error(err)
+}
+
+func TestProcessorTestSuite(t *testing.T) {
+	suite.Run(t, new(ProcessorTestSuite))
+}
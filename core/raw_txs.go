@@ -0,0 +1,23 @@
+package core
+
+import (
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+)
+
+// AttachRawTxs sets the raw bytes of each processed tx from the block the txs were included in (flags.index-tx-raw).
+// The block holds the txs exactly as they were signed and broadcast, whichever RPC response the txs were decoded from.
+func AttachRawTxs(txDBWrappers []dbTypes.TxDBWrapper, blockData *coretypes.ResultBlock) {
+	if blockData == nil || blockData.Block == nil {
+		return
+	}
+
+	rawTxs := make(map[string][]byte, len(blockData.Block.Txs))
+	for _, rawTx := range blockData.Block.Txs {
+		rawTxs[tendermintHashToHex(rawTx.Hash())] = rawTx
+	}
+
+	for i := range txDBWrappers {
+		txDBWrappers[i].Raw = rawTxs[txDBWrappers[i].Tx.Hash]
+	}
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	probeClient "github.com/DefiantLabs/probe/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/suite"
+)
+
+const msgSendTypeURL = "/cosmos.bank.v1beta1.MsgSend"
+
+type RawTxsTestSuite struct {
+	suite.Suite
+	codec probeClient.Codec
+}
+
+func (suite *RawTxsTestSuite) SetupTest() {
+	codec, err := probeClient.MakeCodec(nil, map[string]sdk.Msg{msgSendTypeURL: &bankTypes.MsgSend{}})
+	suite.Require().NoError(err)
+	suite.codec = codec
+}
+
+func (suite *RawTxsTestSuite) encodeTx(memo string, signature []byte) []byte {
+	msgBytes, err := (&bankTypes.MsgSend{FromAddress: "cosmos1sender", ToAddress: "cosmos1recipient", Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000))}).Marshal()
+	suite.Require().NoError(err)
+
+	bodyBytes, err := (&tx.TxBody{Messages: []*codectypes.Any{{TypeUrl: msgSendTypeURL, Value: msgBytes}}, Memo: memo, TimeoutHeight: 1500}).Marshal()
+	suite.Require().NoError(err)
+	authInfoBytes, err := (&tx.AuthInfo{Fee: &tx.Fee{GasLimit: 200000, Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", 500))}}).Marshal()
+	suite.Require().NoError(err)
+
+	txBytes, err := (&tx.TxRaw{BodyBytes: bodyBytes, AuthInfoBytes: authInfoBytes, Signatures: [][]byte{signature}}).Marshal()
+	suite.Require().NoError(err)
+	return txBytes
+}
+
+func txHash(txBytes []byte) string {
+	sum := sha256.Sum256(txBytes)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func (suite *RawTxsTestSuite) TestRawTxRoundTrip() {
+	first := suite.encodeTx("first", []byte("first signature"))
+	second := suite.encodeTx("second", []byte("second signature"))
+	blockData := &coretypes.ResultBlock{Block: &cmtTypes.Block{Data: cmtTypes.Data{Txs: cmtTypes.Txs{first, second}}}}
+
+	// Processed txs can be filtered and are matched to the block txs by hash
+	txDBWrappers := []dbTypes.TxDBWrapper{{Tx: models.Tx{Hash: txHash(second)}}, {Tx: models.Tx{Hash: txHash(first)}}}
+	AttachRawTxs(txDBWrappers, blockData)
+
+	for i, original := range [][]byte{second, first} {
+		suite.Require().Equal(original, txDBWrappers[i].Raw)
+
+		expected, err := InAppTxDecoder(suite.codec)(original)
+		suite.Require().NoError(err)
+		decoded, err := InAppTxDecoder(suite.codec)(txDBWrappers[i].Raw)
+		suite.Require().NoError(err)
+		suite.Equal(expected, decoded)
+	}
+
+	decoded, err := InAppTxDecoder(suite.codec)(txDBWrappers[1].Raw)
+	suite.Require().NoError(err)
+	decodedTx := decoded.(*tx.Tx)
+	suite.Equal("first", decodedTx.Body.Memo)
+	suite.Equal(uint64(1500), decodedTx.Body.TimeoutHeight)
+	suite.Equal(uint64(200000), decodedTx.AuthInfo.Fee.GasLimit)
+	suite.Equal([][]byte{[]byte("first signature")}, decodedTx.Signatures)
+	msgSend, ok := decodedTx.Body.Messages[0].GetCachedValue().(*bankTypes.MsgSend)
+	suite.Require().True(ok)
+	suite.Equal("cosmos1recipient", msgSend.ToAddress)
+}
+
+func (suite *RawTxsTestSuite) TestMissingBlockData() {
+	txDBWrappers := []dbTypes.TxDBWrapper{{Tx: models.Tx{Hash: "HASH"}}}
+	AttachRawTxs(txDBWrappers, nil)
+	suite.Nil(txDBWrappers[0].Raw)
+}
+
+func TestRawTxsTestSuite(t *testing.T) {
+	suite.Run(t, new(RawTxsTestSuite))
+}
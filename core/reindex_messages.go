@@ -0,0 +1,67 @@
+package core
+
+import (
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/parsers"
+	probeClient "github.com/DefiantLabs/probe/client"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewMessageRedecoder returns a redecoder for the reindex-messages command. Stored raw message bytes are decoded with the codec
+// in effect at the message height in the codec schedule, then the schema error, the addresses (with base.index-addresses) and the custom parser data
+// are derived again the same way as during indexing, the schema error against the schemas. The stored message events stand in
+// for the message log.
+func NewMessageRedecoder(cfg config.IndexConfig, cl *probeClient.ChainClient, codecs *CodecSchedule, customParsers map[string][]parsers.MessageParser, schemas MessageSchemas) dbTypes.MessageRedecoder {
+	return func(stored dbTypes.StoredMessage) (dbTypes.MessageDBWrapper, error) {
+		wrapper := dbTypes.MessageDBWrapper{Message: stored.Message}
+		messageType := stored.Message.MessageType.MessageType
+
+		var message types.Msg
+		err := codecs.ClientAtHeight(cl, stored.Height).Codec.InterfaceRegistry.UnpackAny(&codectypes.Any{TypeUrl: messageType, Value: stored.Message.MessageBytes}, &message)
+		if err != nil {
+			return wrapper, err
+		}
+
+		wrapper.Message.SchemaError = nil
+		if schemaErr := schemas.Validate(messageType, message); schemaErr != nil {
+			schemaError := schemaErr.Error()
+			wrapper.Message.SchemaError = &schemaError
+		}
+
+		messageLog := storedMessageLog(stored)
+		for index, customParser := range customParsers[messageType] {
+			parsedData, err := customParser.ParseMessage(message, messageLog, cfg)
+			wrapper.MessageParsedDatasets = append(wrapper.MessageParsedDatasets, parsers.MessageParsedData{
+				Data:   parsedData,
+				Error:  err,
+				Parser: &customParsers[messageType][index],
+			})
+		}
+
+		if cfg.Base.IndexAddresses {
+			wrapper.Addresses, err = ExtractMessageAddresses(message, customParsers[messageType], cfg)
+			if err != nil {
+				// As during indexing, the built-in addresses are still stored if a custom address parser fails
+				config.Log.Errorf("[Block: %v] [TX: %v] Error extracting addresses from msg of type '%v': %v", stored.Height, stored.TxHash, messageType, err)
+			}
+		}
+
+		return wrapper, nil
+	}
+}
+
+// storedMessageLog rebuilds the message log from the events stored for the message
+func storedMessageLog(stored dbTypes.StoredMessage) *txtypes.LogMessage {
+	messageLog := &txtypes.LogMessage{MessageIndex: stored.Message.MessageIndex}
+	for _, event := range stored.MessageEvents {
+		logEvent := txtypes.LogMessageEvent{Type: event.MessageEvent.MessageEventType.Type}
+		for _, attribute := range event.Attributes {
+			logEvent.Attributes = append(logEvent.Attributes, txtypes.Attribute{Key: attribute.MessageEventAttributeKey.Key, Value: attribute.Value})
+		}
+		messageLog.Events = append(messageLog.Events, logEvent)
+	}
+	return messageLog
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/parsers"
+	probeClient "github.com/DefiantLabs/probe/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReindexMessagesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ReindexMessagesTestSuite) TestRedecodeFromRawBytes() {
+	codec, err := probeClient.MakeCodec(nil, map[string]sdk.Msg{"/cosmos.bank.v1beta1.MsgSend": &bankTypes.MsgSend{}})
+	suite.Require().NoError(err)
+
+	sender := sdk.AccAddress(make([]byte, 20)).String()
+	msgBytes, err := (&bankTypes.MsgSend{FromAddress: sender, ToAddress: "cosmos1recipient"}).Marshal()
+	suite.Require().NoError(err)
+
+	staleSchemaError := "stale"
+	stored := dbTypes.StoredMessage{
+		Message: models.Message{
+			ID:           7,
+			MessageType:  models.MessageType{MessageType: "/cosmos.bank.v1beta1.MsgSend"},
+			MessageBytes: msgBytes,
+			SchemaError:  &staleSchemaError,
+		},
+		Height: 10,
+	}
+
+	conf := config.IndexConfig{}
+	conf.Base.IndexAddresses = true
+
+	wrapper, err := NewMessageRedecoder(conf, &probeClient.ChainClient{Codec: codec}, nil, nil, nil)(stored)
+	suite.Require().NoError(err)
+	suite.Equal(uint(7), wrapper.Message.ID)
+	// No schema is configured, so the stale violation is cleared
+	suite.Nil(wrapper.Message.SchemaError)
+	suite.Contains(wrapper.Addresses, parsers.MessageAddress{Address: sender, Role: AddressRoleSigner})
+	suite.Contains(wrapper.Addresses, parsers.MessageAddress{Address: "cosmos1recipient", Role: AddressRoleRecipient})
+
+	stored.Message.MessageBytes = []byte("not a message")
+	_, err = NewMessageRedecoder(conf, &probeClient.ChainClient{Codec: codec}, nil, nil, nil)(stored)
+	suite.Error(err)
+}
+
+func TestReindexMessagesTestSuite(t *testing.T) {
+	suite.Run(t, new(ReindexMessagesTestSuite))
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"fmt"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/cosmos/cosmos-sdk/types"
+	distTypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	stakingTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/shopspring/decimal"
+)
+
+// Attribute keys of the distribution module events
+const (
+	rewardAttributeAmount    = "amount"
+	rewardAttributeValidator = "validator"
+	rewardAttributeDelegator = "delegator"
+)
+
+var messageRewardEventTypes = map[string]bool{
+	models.RewardEventTypeWithdrawRewards:    true,
+	models.RewardEventTypeWithdrawCommission: true,
+}
+
+var blockRewardEventTypes = map[string]bool{
+	models.RewardEventTypeRewards:        true,
+	models.RewardEventTypeCommission:     true,
+	models.RewardEventTypeProposerReward: true,
+}
+
+type eventAttributeSet map[string]string
+
+// splitEventAttributes splits the attributes of a message log event into one set per original event. Message logs merge events of the
+// same type into a single event, so a message withdrawing from several validators has a single event with the attributes repeated per validator.
+func splitEventAttributes(keys []string, values []string) []eventAttributeSet {
+	var sets []eventAttributeSet
+	var current eventAttributeSet
+	for i, key := range keys {
+		if _, repeated := current[key]; current == nil || repeated {
+			current = make(eventAttributeSet)
+			sets = append(sets, current)
+		}
+		current[key] = values[i]
+	}
+	return sets
+}
+
+// ExtractMessageRewardEvents returns the reward withdrawal rows (withdraw_rewards and withdraw_commission events) of the message, one per denom.
+// Rows are returned without block, tx or message IDs. SDK versions that do not emit the delegator or validator with the event get it from the message.
+func ExtractMessageRewardEvents(msg types.Msg, messageLog *txtypes.LogMessage) ([]models.RewardEvent, error) {
+	if messageLog == nil {
+		return nil, nil
+	}
+
+	var rewardEvents []models.RewardEvent
+	var rewardIndex uint64
+	for _, event := range messageLog.Events {
+		if !messageRewardEventTypes[event.Type] {
+			continue
+		}
+
+		keys := make([]string, len(event.Attributes))
+		values := make([]string, len(event.Attributes))
+		for i, attribute := range event.Attributes {
+			keys[i] = attribute.Key
+			values[i] = attribute.Value
+		}
+
+		for _, reward := range splitEventAttributes(keys, values) {
+			if reward[rewardAttributeDelegator] == "" && event.Type == models.RewardEventTypeWithdrawRewards {
+				reward[rewardAttributeDelegator] = messageDelegator(msg)
+			}
+			if reward[rewardAttributeValidator] == "" {
+				reward[rewardAttributeValidator] = messageValidator(msg)
+			}
+
+			coinRows, err := rewardRows(event.Type, reward, rewardIndex)
+			if err != nil {
+				return nil, err
+			}
+			rewardEvents = append(rewardEvents, coinRows...)
+			rewardIndex++
+		}
+	}
+
+	return rewardEvents, nil
+}
+
+// ExtractBlockRewardEvents returns the reward and commission allocation rows (rewards, commission and proposer_reward events) of the block events, one per denom.
+// Rows are returned without block IDs.
+func ExtractBlockRewardEvents(height int64, blockEvents []db.BlockEventDBWrapper) ([]models.RewardEvent, error) {
+	var rewardEvents []models.RewardEvent
+	for _, blockEvent := range blockEvents {
+		eventType := blockEvent.BlockEvent.BlockEventType.Type
+		if !blockRewardEventTypes[eventType] {
+			continue
+		}
+
+		keys := make([]string, len(blockEvent.Attributes))
+		values := make([]string, len(blockEvent.Attributes))
+		for i, attribute := range blockEvent.Attributes {
+			keys[i] = attribute.BlockEventAttributeKey.Key
+			values[i] = attribute.Value
+		}
+
+		for _, reward := range splitEventAttributes(keys, values) {
+			coinRows, err := rewardRows(eventType, reward, blockEvent.BlockEvent.Index)
+			if err != nil {
+				return nil, err
+			}
+
+			lifecyclePosition := blockEvent.BlockEvent.LifecyclePosition
+			for i := range coinRows {
+				coinRows[i].Height = height
+				coinRows[i].LifecyclePosition = &lifecyclePosition
+			}
+			rewardEvents = append(rewardEvents, coinRows...)
+		}
+	}
+
+	return rewardEvents, nil
+}
+
+// rewardRows returns one row per coin of the reward amount. Allocations are decimal coins, withdrawals are integer coins, both parse as decimal coins.
+func rewardRows(eventType string, reward eventAttributeSet, eventIndex uint64) ([]models.RewardEvent, error) {
+	coins, err := types.ParseDecCoins(reward[rewardAttributeAmount])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s amount %q: %w", eventType, reward[rewardAttributeAmount], err)
+	}
+
+	var rows []models.RewardEvent
+	for _, coin := range coins {
+		amount, err := decimal.NewFromString(coin.Amount.String())
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, models.RewardEvent{
+			EventIndex:       eventIndex,
+			Type:             eventType,
+			DelegatorAddress: reward[rewardAttributeDelegator],
+			ValidatorAddress: reward[rewardAttributeValidator],
+			Amount:           amount,
+			Denom:            coin.Denom,
+		})
+	}
+
+	return rows, nil
+}
+
+// messageDelegator returns the delegator of the messages that withdraw delegation rewards
+func messageDelegator(msg types.Msg) string {
+	switch typedMsg := msg.(type) {
+	case *distTypes.MsgWithdrawDelegatorReward:
+		return typedMsg.DelegatorAddress
+	case *stakingTypes.MsgDelegate:
+		return typedMsg.DelegatorAddress
+	case *stakingTypes.MsgUndelegate:
+		return typedMsg.DelegatorAddress
+	case *stakingTypes.MsgBeginRedelegate:
+		return typedMsg.DelegatorAddress
+	}
+	return ""
+}
+
+// messageValidator returns the validator of the messages that withdraw from a single validator
+func messageValidator(msg types.Msg) string {
+	switch typedMsg := msg.(type) {
+	case *distTypes.MsgWithdrawDelegatorReward:
+		return typedMsg.ValidatorAddress
+	case *distTypes.MsgWithdrawValidatorCommission:
+		return typedMsg.ValidatorAddress
+	case *stakingTypes.MsgDelegate:
+		return typedMsg.ValidatorAddress
+	case *stakingTypes.MsgUndelegate:
+		return typedMsg.ValidatorAddress
+	}
+	return ""
+}
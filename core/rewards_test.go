@@ -0,0 +1,117 @@
+package core
+
+import (
+	"testing"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	distTypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testDelegator  = "cosmos1delegator"
+	testValidatorA = "cosmosvaloper1validatora"
+	testValidatorB = "cosmosvaloper1validatorb"
+)
+
+type RewardsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *RewardsTestSuite) TestWithdrawRewards() {
+	// Withdrawing from two validators in one message, the log merges both withdrawals into a single event
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "message", Attributes: []txtypes.Attribute{{Key: "action", Value: "/cosmos.distribution.v1beta1.MsgWithdrawDelegatorReward"}}},
+			{Type: "withdraw_rewards", Attributes: []txtypes.Attribute{
+				{Key: "amount", Value: "1500uatom,20ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"},
+				{Key: "validator", Value: testValidatorA},
+				{Key: "delegator", Value: testDelegator},
+				{Key: "amount", Value: "300uatom"},
+				{Key: "validator", Value: testValidatorB},
+				{Key: "delegator", Value: testDelegator},
+			}},
+		},
+	}
+
+	rewardEvents, err := ExtractMessageRewardEvents(&distTypes.MsgWithdrawDelegatorReward{DelegatorAddress: testDelegator, ValidatorAddress: testValidatorA}, messageLog)
+	suite.Require().NoError(err)
+	suite.Require().Len(rewardEvents, 3)
+
+	// Coins of a single withdrawal are sorted by denom
+	suite.Equal(models.RewardEventTypeWithdrawRewards, rewardEvents[0].Type)
+	suite.Equal(testDelegator, rewardEvents[0].DelegatorAddress)
+	suite.Equal(testValidatorA, rewardEvents[0].ValidatorAddress)
+	suite.True(decimal.NewFromInt(20).Equal(rewardEvents[0].Amount))
+	suite.Equal("ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", rewardEvents[0].Denom)
+	suite.Equal(uint64(0), rewardEvents[0].EventIndex)
+
+	suite.Equal(testValidatorA, rewardEvents[1].ValidatorAddress)
+	suite.True(decimal.NewFromInt(1500).Equal(rewardEvents[1].Amount))
+	suite.Equal("uatom", rewardEvents[1].Denom)
+	suite.Equal(uint64(0), rewardEvents[1].EventIndex)
+
+	suite.Equal(testValidatorB, rewardEvents[2].ValidatorAddress)
+	suite.Equal(testDelegator, rewardEvents[2].DelegatorAddress)
+	suite.True(decimal.NewFromInt(300).Equal(rewardEvents[2].Amount))
+	suite.Equal(uint64(1), rewardEvents[2].EventIndex)
+}
+
+func (suite *RewardsTestSuite) TestWithdrawRewardsWithoutDelegatorAttribute() {
+	// Older SDK versions only emit the amount and validator
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "withdraw_rewards", Attributes: []txtypes.Attribute{{Key: "amount", Value: "42uatom"}, {Key: "validator", Value: testValidatorA}}},
+		},
+	}
+
+	rewardEvents, err := ExtractMessageRewardEvents(&distTypes.MsgWithdrawDelegatorReward{DelegatorAddress: testDelegator, ValidatorAddress: testValidatorA}, messageLog)
+	suite.Require().NoError(err)
+	suite.Require().Len(rewardEvents, 1)
+	suite.Equal(testDelegator, rewardEvents[0].DelegatorAddress)
+	suite.Equal(testValidatorA, rewardEvents[0].ValidatorAddress)
+}
+
+func (suite *RewardsTestSuite) TestWithdrawCommission() {
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "withdraw_commission", Attributes: []txtypes.Attribute{{Key: "amount", Value: "900uatom"}}},
+		},
+	}
+
+	rewardEvents, err := ExtractMessageRewardEvents(&distTypes.MsgWithdrawValidatorCommission{ValidatorAddress: testValidatorB}, messageLog)
+	suite.Require().NoError(err)
+	suite.Require().Len(rewardEvents, 1)
+	suite.Equal(models.RewardEventTypeWithdrawCommission, rewardEvents[0].Type)
+	suite.Equal(testValidatorB, rewardEvents[0].ValidatorAddress)
+	suite.Empty(rewardEvents[0].DelegatorAddress)
+}
+
+func (suite *RewardsTestSuite) TestBlockRewardAllocations() {
+	blockEvents := []db.BlockEventDBWrapper{
+		blockEvent(0, "commission", "amount", "12.500000000000000000uatom", "validator", testValidatorA),
+		blockEvent(1, "rewards", "amount", "112.500000000000000000uatom", "validator", testValidatorA),
+		blockEvent(2, "transfer", "amount", "100uatom"),
+	}
+
+	rewardEvents, err := ExtractBlockRewardEvents(10, blockEvents)
+	suite.Require().NoError(err)
+	suite.Require().Len(rewardEvents, 2)
+
+	suite.Equal(models.RewardEventTypeCommission, rewardEvents[0].Type)
+	suite.True(decimal.RequireFromString("12.5").Equal(rewardEvents[0].Amount))
+	suite.Equal(int64(10), rewardEvents[0].Height)
+	suite.Require().NotNil(rewardEvents[0].LifecyclePosition)
+	suite.Equal(models.BeginBlockEvent, *rewardEvents[0].LifecyclePosition)
+
+	suite.Equal(models.RewardEventTypeRewards, rewardEvents[1].Type)
+	suite.Equal(uint64(1), rewardEvents[1].EventIndex)
+	suite.True(decimal.RequireFromString("112.5").Equal(rewardEvents[1].Amount))
+}
+
+func TestRewardsSuite(t *testing.T) {
+	suite.Run(t, new(RewardsTestSuite))
+}
@@ -1,12 +1,16 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"net/url"
 	"sync"
 
 	"github.com/DefiantLabs/cosmos-indexer/config"
 	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/probe"
 	"github.com/DefiantLabs/cosmos-indexer/rpc"
 	"github.com/DefiantLabs/probe/client"
 	abci "github.com/cometbft/cometbft/abci/types"
@@ -24,23 +28,80 @@ type IndexerBlockEventData struct {
 	TxRequestsFailed         bool
 	IndexBlockEvents         bool
 	IndexTransactions        bool
+	// Set on the blocks of the base.background-reindex range
+	BackgroundReindex bool
+	// Admission of the block into the pipeline, released by the last stage holding block data
+	Inflight *InflightBlock
+	// RPC endpoint the block data was fetched from, only set when base.record-source-endpoint is enabled
+	SourceEndpoint string
+	// Context carrying the block's fetch span, later pipeline stages start their spans as its children
+	TraceContext context.Context
+	// Balances of the base.module-balance-addresses accounts, only set at heights on the base.module-balance-stride
+	ModuleBalances []models.ModuleBalance
+	// Total gas used by the txs of the block and the block max gas consensus param, only set when base.index-block-gas is enabled
+	GasUsed *int64
+	MaxGas  *int64
+	// Number of txs of the block and of its failed txs, only set when base.index-block-tx-stats is enabled
+	TxCount       *int64
+	FailedTxCount *int64
+}
+
+// getBlock fetches the block at the height, overridden in tests
+var getBlock = rpc.GetBlock
+
+// sourceEndpoint returns the RPC address with any credentials removed, so it is safe to store alongside the block
+func sourceEndpoint(rpcAddr string) string {
+	endpoint, err := url.Parse(rpcAddr)
+	if err != nil || endpoint.User == nil {
+		return rpcAddr
+	}
+	endpoint.User = nil
+	return endpoint.String()
 }
 
 // This function is responsible for making all RPC requests to the chain needed for later processing.
 // The indexer relies on a number of RPC endpoints for full block data, including block event and transaction searches.
-func BlockRPCWorker(wg *sync.WaitGroup, blockEnqueueChan chan *EnqueueData, chainID uint, chainStringID string, cfg *config.IndexConfig, chainClient *client.ChainClient, db *gorm.DB, outputChannel chan IndexerBlockEventData) {
+func BlockRPCWorker(wg *sync.WaitGroup, blockEnqueueChan chan *EnqueueData, chainID uint, chainStringID string, cfg *config.IndexConfig, chainClient *client.ChainClient, codecs *CodecSchedule, db *gorm.DB, failedBlocks *dbTypes.FailedBlockCounter, inflightLimiter *InflightLimiter, fetchThrottle *AdaptiveThrottle, commitOrder *CommitOrder, errorWebhook *ErrorWebhook, outputChannel chan IndexerBlockEventData) {
 	defer wg.Done()
+	httpClient, err := probe.GetHTTPClient(cfg.Probe, 0)
+	if err != nil {
+		config.Log.Fatal("Failed to create RPC worker HTTP client", err)
+	}
 	rpcClient := rpc.URIClient{
 		Address: chainClient.Config.RPCAddr,
-		Client:  &http.Client{},
+		Client:  httpClient,
 	}
 
+	batcher := &blockBatcher{size: int(cfg.Base.RPCBatchSize), client: rpcClient}
+	var pending []*EnqueueData
+	var admitted []*InflightBlock
+	var prefetched *rpc.BlockBatch
+
 	for {
-		// Get the next block to process
-		block, open := <-blockEnqueueChan
-		if !open {
-			config.Log.Debugf("Block enqueue channel closed. Exiting RPC worker.")
-			break
+		// Get the next blocks to process, prefetching their data in a single batch request where possible
+		if len(pending) == 0 {
+			pending = nextBlocks(blockEnqueueChan, batcher.size)
+			if len(pending) == 0 {
+				config.Log.Debugf("Block enqueue channel closed. Exiting RPC worker.")
+				break
+			}
+
+			// Back off while the database is slow to commit and wait for room in the pipeline before fetching any block data.
+			// Only the blocks admitted into the pipeline are prefetched, the rest wait for room one at a time.
+			fetchThrottle.Wait()
+			admitted = admitBlocks(inflightLimiter, len(pending))
+			prefetched = batcher.prefetch(pending[:len(admitted)])
+		}
+		block := pending[0]
+		pending = pending[1:]
+
+		var inflightBlock *InflightBlock
+		if len(admitted) != 0 {
+			inflightBlock = admitted[0]
+			admitted = admitted[1:]
+		} else {
+			fetchThrottle.Wait()
+			inflightBlock = inflightLimiter.Admit()
 		}
 
 		currentHeightIndexerData := IndexerBlockEventData{
@@ -48,32 +109,55 @@ func BlockRPCWorker(wg *sync.WaitGroup, blockEnqueueChan chan *EnqueueData, chai
 			TxRequestsFailed:         false,
 			IndexBlockEvents:         block.IndexBlockEvents,
 			IndexTransactions:        block.IndexTransactions,
+			BackgroundReindex:        block.BackgroundReindex,
+			Inflight:                 inflightBlock,
 		}
 
+		if cfg.Base.RecordSourceEndpoint {
+			currentHeightIndexerData.SourceEndpoint = sourceEndpoint(chainClient.Config.RPCAddr)
+		}
+
+		traceContext, fetchSpan := StartBlockSpan(context.Background(), SpanBlockFetch, block.Height)
+		currentHeightIndexerData.TraceContext = traceContext
+
 		// Get the block from the RPC
-		blockData, err := rpc.GetBlock(chainClient, block.Height)
+		blockData := prefetched.Block(block.Height)
+		var err error
+		if blockData == nil {
+			blockData, err = getBlock(chainClient, block.Height)
+		}
 		if err != nil {
+			fetchSpan.RecordError(err)
+			fetchSpan.End()
 			// This is the only response we continue on. If we can't get the block, we can't index anything.
 			config.Log.Errorf("Error getting block %v from RPC. Err: %v", block, err)
-			err := dbTypes.UpsertFailedEventBlock(db, block.Height, chainStringID, cfg.Probe.ChainName)
+			errorWebhook.Notify(block.Height, BlockQueryError, err)
+			err := dbTypes.UpsertFailedEventBlock(db, failedBlocks, block.Height, chainStringID, cfg.Probe.ChainName)
 			if err != nil {
 				config.Log.Fatal("Failed to insert failed block event", err)
 			}
-			err = dbTypes.UpsertFailedBlock(db, block.Height, chainStringID, cfg.Probe.ChainName)
+			err = dbTypes.UpsertFailedBlock(db, failedBlocks, block.Height, chainStringID, cfg.Probe.ChainName)
 			if err != nil {
 				config.Log.Fatal("Failed to insert failed block", err)
 			}
+			inflightBlock.Release()
+			commitOrder.Drop(block.Height)
 			continue
 		}
 
 		currentHeightIndexerData.BlockData = blockData
 
 		if block.IndexBlockEvents {
-			bresults, err := rpc.GetBlockResultWithRetry(rpcClient, block.Height, cfg.Base.RequestRetryAttempts, cfg.Base.RequestRetryMaxWait)
+			var bresults *rpc.CustomBlockResults
+			var err error
+			if bresults = prefetched.BlockResult(block.Height); bresults == nil {
+				bresults, err = rpc.GetBlockResultWithRetry(rpcClient, block.Height, cfg.Base.RequestRetryAttempts, cfg.Base.RequestRetryMaxWait)
+			}
 
 			if err != nil {
 				config.Log.Errorf("Error getting block results for block %v from RPC. Err: %v", block, err)
-				err := dbTypes.UpsertFailedEventBlock(db, block.Height, chainStringID, cfg.Probe.ChainName)
+				errorWebhook.Notify(block.Height, BlockQueryError, err)
+				err := dbTypes.UpsertFailedEventBlock(db, failedBlocks, block.Height, chainStringID, cfg.Probe.ChainName)
 				if err != nil {
 					config.Log.Fatal("Failed to insert failed block event", err)
 				}
@@ -83,7 +167,8 @@ func BlockRPCWorker(wg *sync.WaitGroup, blockEnqueueChan chan *EnqueueData, chai
 				bresults, err = NormalizeCustomBlockResults(bresults)
 				if err != nil {
 					config.Log.Errorf("Error normalizing block results for block %v from RPC. Err: %v", block, err)
-					err := dbTypes.UpsertFailedEventBlock(db, block.Height, chainStringID, cfg.Probe.ChainName)
+					errorWebhook.Notify(block.Height, FailedBlockEventHandling, err)
+					err := dbTypes.UpsertFailedEventBlock(db, failedBlocks, block.Height, chainStringID, cfg.Probe.ChainName)
 					if err != nil {
 						config.Log.Fatal("Failed to insert failed block event", err)
 					}
@@ -91,13 +176,28 @@ func BlockRPCWorker(wg *sync.WaitGroup, blockEnqueueChan chan *EnqueueData, chai
 					currentHeightIndexerData.BlockResultsData = bresults
 				}
 			}
+
+			if !currentHeightIndexerData.BlockEventRequestsFailed {
+				moduleBalances, err := SnapshotModuleBalances(cfg, codecs.ClientAtHeight(chainClient, block.Height), block.Height)
+				if err != nil {
+					config.Log.Errorf("Error getting module balances for block %v from RPC. Err: %v", block, err)
+					errorWebhook.Notify(block.Height, BlockQueryError, err)
+					err := dbTypes.UpsertFailedEventBlock(db, failedBlocks, block.Height, chainStringID, cfg.Probe.ChainName)
+					if err != nil {
+						config.Log.Fatal("Failed to insert failed block event", err)
+					}
+					currentHeightIndexerData.BlockEventRequestsFailed = true
+				} else {
+					currentHeightIndexerData.ModuleBalances = moduleBalances
+				}
+			}
 		}
 
 		if block.IndexTransactions {
 			var txsEventResp *txTypes.GetTxsEventResponse
 			var err error
 			if !cfg.Base.SkipBlockByHeightRPCRequest {
-				txsEventResp, err = rpc.GetTxsByBlockHeight(chainClient, block.Height)
+				txsEventResp, err = rpc.GetTxsByBlockHeight(codecs.ClientAtHeight(chainClient, block.Height), block.Height)
 			}
 
 			if err != nil || cfg.Base.SkipBlockByHeightRPCRequest {
@@ -108,7 +208,8 @@ func BlockRPCWorker(wg *sync.WaitGroup, blockEnqueueChan chan *EnqueueData, chai
 
 					if err != nil {
 						config.Log.Errorf("Error getting txs for block %v from RPC. Err: %v", block, err)
-						err := dbTypes.UpsertFailedBlock(db, block.Height, chainStringID, cfg.Probe.ChainName)
+						errorWebhook.Notify(block.Height, BlockQueryError, err)
+						err := dbTypes.UpsertFailedBlock(db, failedBlocks, block.Height, chainStringID, cfg.Probe.ChainName)
 						if err != nil {
 							config.Log.Fatal("Failed to insert failed block", err)
 						}
@@ -120,7 +221,8 @@ func BlockRPCWorker(wg *sync.WaitGroup, blockEnqueueChan chan *EnqueueData, chai
 						bresults, err = NormalizeCustomBlockResults(bresults)
 						if err != nil {
 							config.Log.Errorf("Error normalizing block results for block %v from RPC. Err: %v", block, err)
-							err := dbTypes.UpsertFailedBlock(db, block.Height, chainStringID, cfg.Probe.ChainName)
+							errorWebhook.Notify(block.Height, FailedBlockEventHandling, err)
+							err := dbTypes.UpsertFailedBlock(db, failedBlocks, block.Height, chainStringID, cfg.Probe.ChainName)
 							if err != nil {
 								config.Log.Fatal("Failed to insert failed block", err)
 							}
@@ -135,10 +237,105 @@ func BlockRPCWorker(wg *sync.WaitGroup, blockEnqueueChan chan *EnqueueData, chai
 			}
 		}
 
+		if cfg.Base.IndexBlockTxStats {
+			if txCount, failedTxCount, ok := BlockTxStats(currentHeightIndexerData.BlockResultsData, currentHeightIndexerData.GetTxsResponse); ok {
+				currentHeightIndexerData.TxCount = &txCount
+				currentHeightIndexerData.FailedTxCount = &failedTxCount
+			}
+		}
+
+		if cfg.Base.IndexBlockGas {
+			if gasUsed, ok := BlockGasUsed(currentHeightIndexerData.BlockResultsData, currentHeightIndexerData.GetTxsResponse); ok {
+				currentHeightIndexerData.GasUsed = &gasUsed
+			}
+
+			maxGas, err := getBlockMaxGas(chainClient, block.Height)
+			if err != nil {
+				// The block is still indexed, without the max gas
+				config.Log.Errorf("Error getting consensus params for block %v from RPC. Err: %v", block, err)
+			} else {
+				currentHeightIndexerData.MaxGas = &maxGas
+			}
+		}
+
+		fetchSpan.End()
 		outputChannel <- currentHeightIndexerData
 	}
 }
 
+// nextBlocks waits for the next block to process and takes up to size-1 more blocks that are already enqueued, so they can be
+// fetched in a single batch request. Returns no blocks once the enqueue channel is closed.
+func nextBlocks(blockEnqueueChan chan *EnqueueData, size int) []*EnqueueData {
+	block, open := <-blockEnqueueChan
+	if !open {
+		return nil
+	}
+
+	blocks := []*EnqueueData{block}
+	for len(blocks) < size {
+		select {
+		case block, open := <-blockEnqueueChan:
+			if !open {
+				return blocks
+			}
+			blocks = append(blocks, block)
+		default:
+			return blocks
+		}
+	}
+	return blocks
+}
+
+// admitBlocks waits for room in the pipeline for one block, then admits up to count blocks in total while there is room left
+func admitBlocks(inflightLimiter *InflightLimiter, count int) []*InflightBlock {
+	admitted := []*InflightBlock{inflightLimiter.Admit()}
+	for len(admitted) < count {
+		inflightBlock, ok := inflightLimiter.TryAdmit()
+		if !ok {
+			break
+		}
+		admitted = append(admitted, inflightBlock)
+	}
+	return admitted
+}
+
+// blockBatcher fetches the blocks and block results of several heights in a single JSON-RPC batch request (base.rpc-batch-size).
+// Batching is turned off for the worker once the endpoint rejects a batch, the blocks are then fetched with individual requests.
+type blockBatcher struct {
+	size        int
+	client      rpc.URIClient
+	unsupported bool
+}
+
+// prefetch returns the batched data of the blocks, nil if nothing was batched
+func (batcher *blockBatcher) prefetch(blocks []*EnqueueData) *rpc.BlockBatch {
+	if batcher.unsupported || len(blocks) < 2 {
+		return nil
+	}
+
+	heights := make([]int64, len(blocks))
+	var resultHeights []int64
+	for i, block := range blocks {
+		heights[i] = block.Height
+		if block.IndexBlockEvents {
+			resultHeights = append(resultHeights, block.Height)
+		}
+	}
+
+	batch, err := rpc.GetBlockBatch(batcher.client, heights, resultHeights)
+	if errors.Is(err, rpc.ErrBatchUnsupported) {
+		config.Log.Warnf("RPC endpoint rejected a batch request, fetching blocks with individual requests")
+		batcher.unsupported = true
+		return nil
+	} else if err != nil {
+		// The blocks are fetched with individual requests, which retry on their own
+		config.Log.Errorf("Error getting batch of %d blocks from RPC, fetching them individually. Err: %v", len(blocks), err)
+		return nil
+	}
+
+	return batch
+}
+
 func NormalizeCustomBlockResults(blockResults *rpc.CustomBlockResults) (*rpc.CustomBlockResults, error) {
 	if len(blockResults.FinalizeBlockEvents) != 0 {
 		beginBlockEvents := []abci.Event{}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/cosmos/cosmos-sdk/types"
+)
+
+// SenderWhitelist is the set of addresses whose transactions are indexed
+type SenderWhitelist map[string]bool
+
+// LoadSenderWhitelist loads a JSON list of bech32 addresses, every address must parse under the bech32 prefix
+func LoadSenderWhitelist(path string, prefix string) (SenderWhitelist, error) {
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sender whitelist file %s: %w", path, err)
+	}
+
+	var addresses []string
+	if err := json.Unmarshal(fileBytes, &addresses); err != nil {
+		return nil, fmt.Errorf("error parsing sender whitelist file %s, expected a JSON list of addresses: %w", path, err)
+	}
+
+	whitelist := make(SenderWhitelist, len(addresses))
+	for _, address := range addresses {
+		if _, err := types.GetFromBech32(address, prefix); err != nil {
+			return nil, fmt.Errorf("sender whitelist address %s is not a valid %s address: %w", address, prefix, err)
+		}
+		whitelist[address] = true
+	}
+
+	return whitelist, nil
+}
+
+// Allows reports whether a transaction with the signers should be indexed. Every transaction is allowed by a nil whitelist.
+func (whitelist SenderWhitelist) Allows(signers []models.Address) bool {
+	if whitelist == nil {
+		return true
+	}
+
+	for _, signer := range signers {
+		if whitelist[signer.Address] {
+			return true
+		}
+	}
+	return false
+}
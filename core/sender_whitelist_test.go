@@ -0,0 +1,57 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type SenderWhitelistTestSuite struct {
+	suite.Suite
+	whitelisted string
+	other       string
+}
+
+func (suite *SenderWhitelistTestSuite) SetupTest() {
+	suite.whitelisted = types.AccAddress([]byte("whitelisted_sender__")).String()
+	suite.other = types.AccAddress([]byte("other_sender________")).String()
+}
+
+func (suite *SenderWhitelistTestSuite) writeWhitelist(contents string) string {
+	path := filepath.Join(suite.T().TempDir(), "whitelist.json")
+	suite.Require().NoError(os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func (suite *SenderWhitelistTestSuite) TestMatchingAndNonMatchingSenders() {
+	whitelist, err := LoadSenderWhitelist(suite.writeWhitelist(`["`+suite.whitelisted+`"]`), "cosmos")
+	suite.Require().NoError(err)
+
+	suite.True(whitelist.Allows([]models.Address{{Address: suite.whitelisted}}))
+	// A multi-signer tx is indexed when any of its signers is whitelisted
+	suite.True(whitelist.Allows([]models.Address{{Address: suite.other}, {Address: suite.whitelisted}}))
+	suite.False(whitelist.Allows([]models.Address{{Address: suite.other}}))
+	suite.False(whitelist.Allows(nil))
+
+	var noWhitelist SenderWhitelist
+	suite.True(noWhitelist.Allows([]models.Address{{Address: suite.other}}))
+}
+
+func (suite *SenderWhitelistTestSuite) TestAddressesMustMatchPrefix() {
+	_, err := LoadSenderWhitelist(suite.writeWhitelist(`["`+suite.whitelisted+`"]`), "osmo")
+	suite.Error(err)
+
+	_, err = LoadSenderWhitelist(suite.writeWhitelist(`["cosmos1notanaddress"]`), "cosmos")
+	suite.Error(err)
+
+	_, err = LoadSenderWhitelist(suite.writeWhitelist(`{"address": "`+suite.whitelisted+`"}`), "cosmos")
+	suite.Error(err)
+}
+
+func TestSenderWhitelistSuite(t *testing.T) {
+	suite.Run(t, new(SenderWhitelistTestSuite))
+}
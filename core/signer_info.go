@@ -0,0 +1,122 @@
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/probe/client"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	cryptoTypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	cosmosTx "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// ExtractSignerInfos returns the public key, sequence and sign mode of each signer of the tx. Multisig signers are expanded
+// to one row per member key. Signers without a public key (e.g. an account's first tx on some chains) are skipped.
+func ExtractSignerInfos(cl *client.ChainClient, authInfo *cosmosTx.AuthInfo) ([]models.TxSignerInfo, error) {
+	var signerInfos []models.TxSignerInfo
+
+	for signerIndex, signerInfo := range authInfo.SignerInfos {
+		if signerInfo.PublicKey == nil {
+			continue
+		}
+
+		pubKey, err := unpackSignerPubKey(cl, signerInfo.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		multisigKey, ok := pubKey.(*multisig.LegacyAminoPubKey)
+		if !ok {
+			signerInfos = append(signerInfos, models.TxSignerInfo{
+				SignerIndex: signerIndex,
+				Address:     types.AccAddress(pubKey.Address().Bytes()).String(),
+				PubKeyType:  signerInfo.PublicKey.TypeUrl,
+				PubKey:      base64.StdEncoding.EncodeToString(pubKey.Bytes()),
+				Sequence:    signerInfo.Sequence,
+				SignMode:    signModeString(signerInfo.ModeInfo),
+			})
+			continue
+		}
+
+		// The multi mode info only lists the members that signed, in key order, flagged in its bit array
+		memberModeInfos := signerInfo.ModeInfo.GetMulti()
+		signedIndex := 0
+		threshold := multisigKey.Threshold
+		for keyIndex, memberKey := range multisigKey.GetPubKeys() {
+			member := models.TxSignerInfo{
+				SignerIndex:       signerIndex,
+				KeyIndex:          keyIndex,
+				Address:           types.AccAddress(memberKey.Address().Bytes()).String(),
+				PubKeyType:        multisigKey.PubKeys[keyIndex].TypeUrl,
+				PubKey:            base64.StdEncoding.EncodeToString(memberKey.Bytes()),
+				Sequence:          signerInfo.Sequence,
+				MultisigThreshold: &threshold,
+			}
+
+			if memberModeInfos != nil && memberModeInfos.Bitarray.GetIndex(keyIndex) && signedIndex < len(memberModeInfos.ModeInfos) {
+				member.SignMode = signModeString(memberModeInfos.ModeInfos[signedIndex])
+				signedIndex++
+			}
+
+			signerInfos = append(signerInfos, member)
+		}
+	}
+
+	return signerInfos, nil
+}
+
+func unpackSignerPubKey(cl *client.ChainClient, pubKeyAny *codectypes.Any) (cryptoTypes.PubKey, error) {
+	if pubKey, ok := pubKeyAny.GetCachedValue().(cryptoTypes.PubKey); ok {
+		return pubKey, nil
+	}
+
+	var pubKey cryptoTypes.PubKey
+	if err := cl.Codec.InterfaceRegistry.UnpackAny(pubKeyAny, &pubKey); err != nil {
+		return nil, err
+	}
+
+	if pubKey == nil {
+		return nil, fmt.Errorf("signer public key of type %s could not be decoded", pubKeyAny.TypeUrl)
+	}
+
+	return pubKey, nil
+}
+
+// signModeString returns the name of a single signer's sign mode (e.g. SIGN_MODE_DIRECT), or "multi" for a nested multisig
+func signModeString(modeInfo *cosmosTx.ModeInfo) string {
+	if single := modeInfo.GetSingle(); single != nil {
+		return single.Mode.String()
+	}
+	if modeInfo.GetMulti() != nil {
+		return "multi"
+	}
+	return ""
+}
+
+// SignerCount returns the number of signatures of the tx (base.index-signer-count), counting every member that signed for a multisig
+// signer, including the members of nested multisigs
+func SignerCount(authInfo cosmosTx.AuthInfo) int {
+	count := 0
+	for _, signerInfo := range authInfo.SignerInfos {
+		count += modeInfoSignatures(signerInfo.ModeInfo)
+	}
+	return count
+}
+
+// modeInfoSignatures returns the number of signatures behind a signer's mode info, the multi mode info lists one mode info per
+// member that signed
+func modeInfoSignatures(modeInfo *cosmosTx.ModeInfo) int {
+	multi := modeInfo.GetMulti()
+	if multi == nil {
+		return 1
+	}
+
+	count := 0
+	for _, memberModeInfo := range multi.ModeInfos {
+		count += modeInfoSignatures(memberModeInfo)
+	}
+	return count
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"encoding/base64"
+	"testing"
+
+	probeClient "github.com/DefiantLabs/probe/client"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptoTypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	cosmosTx "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/stretchr/testify/suite"
+)
+
+type SignerInfoTestSuite struct {
+	suite.Suite
+	cl *probeClient.ChainClient
+}
+
+func (suite *SignerInfoTestSuite) SetupTest() {
+	registry := codectypes.NewInterfaceRegistry()
+	cryptocodec.RegisterInterfaces(registry)
+	suite.cl = &probeClient.ChainClient{Codec: probeClient.Codec{InterfaceRegistry: registry}}
+}
+
+// packPubKey packs the key the way it arrives in a decoded tx, without the cached value
+func (suite *SignerInfoTestSuite) packPubKey(pubKey cryptoTypes.PubKey) *codectypes.Any {
+	packed, err := codectypes.NewAnyWithValue(pubKey)
+	suite.Require().NoError(err)
+	return &codectypes.Any{TypeUrl: packed.TypeUrl, Value: packed.Value}
+}
+
+func singleModeInfo(mode signing.SignMode) *cosmosTx.ModeInfo {
+	return &cosmosTx.ModeInfo{Sum: &cosmosTx.ModeInfo_Single_{Single: &cosmosTx.ModeInfo_Single{Mode: mode}}}
+}
+
+func (suite *SignerInfoTestSuite) TestSingleSig() {
+	pubKey := secp256k1.GenPrivKeyFromSecret([]byte("single")).PubKey()
+	authInfo := &cosmosTx.AuthInfo{SignerInfos: []*cosmosTx.SignerInfo{{
+		PublicKey: suite.packPubKey(pubKey),
+		ModeInfo:  singleModeInfo(signing.SignMode_SIGN_MODE_DIRECT),
+		Sequence:  42,
+	}}}
+
+	signerInfos, err := ExtractSignerInfos(suite.cl, authInfo)
+	suite.Require().NoError(err)
+	suite.Require().Len(signerInfos, 1)
+
+	signerInfo := signerInfos[0]
+	suite.Equal(0, signerInfo.SignerIndex)
+	suite.Equal(0, signerInfo.KeyIndex)
+	suite.Equal(types.AccAddress(pubKey.Address()).String(), signerInfo.Address)
+	suite.Equal("/cosmos.crypto.secp256k1.PubKey", signerInfo.PubKeyType)
+	suite.Equal(base64.StdEncoding.EncodeToString(pubKey.Bytes()), signerInfo.PubKey)
+	suite.Equal(uint64(42), signerInfo.Sequence)
+	suite.Equal("SIGN_MODE_DIRECT", signerInfo.SignMode)
+	suite.Nil(signerInfo.MultisigThreshold)
+}
+
+func (suite *SignerInfoTestSuite) TestMultisigExpandsToMembers() {
+	members := []cryptoTypes.PubKey{
+		secp256k1.GenPrivKeyFromSecret([]byte("member-a")).PubKey(),
+		secp256k1.GenPrivKeyFromSecret([]byte("member-b")).PubKey(),
+		secp256k1.GenPrivKeyFromSecret([]byte("member-c")).PubKey(),
+	}
+	multisigKey := multisig.NewLegacyAminoPubKey(2, members)
+
+	// Members a and c signed
+	bitArray := cryptoTypes.NewCompactBitArray(len(members))
+	bitArray.SetIndex(0, true)
+	bitArray.SetIndex(2, true)
+
+	feePayer := secp256k1.GenPrivKeyFromSecret([]byte("fee-payer")).PubKey()
+	authInfo := &cosmosTx.AuthInfo{SignerInfos: []*cosmosTx.SignerInfo{
+		{
+			PublicKey: suite.packPubKey(multisigKey),
+			ModeInfo: &cosmosTx.ModeInfo{Sum: &cosmosTx.ModeInfo_Multi_{Multi: &cosmosTx.ModeInfo_Multi{
+				Bitarray: bitArray,
+				ModeInfos: []*cosmosTx.ModeInfo{
+					singleModeInfo(signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON),
+					singleModeInfo(signing.SignMode_SIGN_MODE_DIRECT),
+				},
+			}}},
+			Sequence: 7,
+		},
+		{
+			PublicKey: suite.packPubKey(feePayer),
+			ModeInfo:  singleModeInfo(signing.SignMode_SIGN_MODE_DIRECT),
+			Sequence:  3,
+		},
+	}}
+
+	signerInfos, err := ExtractSignerInfos(suite.cl, authInfo)
+	suite.Require().NoError(err)
+	suite.Require().Len(signerInfos, 4)
+
+	expectedModes := []string{"SIGN_MODE_LEGACY_AMINO_JSON", "", "SIGN_MODE_DIRECT"}
+	for keyIndex, member := range members {
+		signerInfo := signerInfos[keyIndex]
+		suite.Equal(0, signerInfo.SignerIndex)
+		suite.Equal(keyIndex, signerInfo.KeyIndex)
+		suite.Equal(types.AccAddress(member.Address()).String(), signerInfo.Address)
+		suite.Equal(base64.StdEncoding.EncodeToString(member.Bytes()), signerInfo.PubKey)
+		suite.Equal("/cosmos.crypto.secp256k1.PubKey", signerInfo.PubKeyType)
+		suite.Equal(uint64(7), signerInfo.Sequence)
+		suite.Equal(expectedModes[keyIndex], signerInfo.SignMode)
+		suite.Require().NotNil(signerInfo.MultisigThreshold)
+		suite.Equal(uint32(2), *signerInfo.MultisigThreshold)
+	}
+
+	suite.Equal(1, signerInfos[3].SignerIndex)
+	suite.Equal(types.AccAddress(feePayer.Address()).String(), signerInfos[3].Address)
+	suite.Equal(uint64(3), signerInfos[3].Sequence)
+	suite.Nil(signerInfos[3].MultisigThreshold)
+}
+
+func (suite *SignerInfoTestSuite) TestSignerCount() {
+	singleSig := cosmosTx.AuthInfo{SignerInfos: []*cosmosTx.SignerInfo{{ModeInfo: singleModeInfo(signing.SignMode_SIGN_MODE_DIRECT)}}}
+	suite.Equal(1, SignerCount(singleSig))
+
+	// Two of the three members of the multisig signed, next to a single-sig fee payer
+	multiModeInfo := &cosmosTx.ModeInfo{Sum: &cosmosTx.ModeInfo_Multi_{Multi: &cosmosTx.ModeInfo_Multi{
+		Bitarray: cryptoTypes.NewCompactBitArray(3),
+		ModeInfos: []*cosmosTx.ModeInfo{
+			singleModeInfo(signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON),
+			singleModeInfo(signing.SignMode_SIGN_MODE_DIRECT),
+		},
+	}}}
+	multisigAuthInfo := cosmosTx.AuthInfo{SignerInfos: []*cosmosTx.SignerInfo{
+		{ModeInfo: multiModeInfo},
+		{ModeInfo: singleModeInfo(signing.SignMode_SIGN_MODE_DIRECT)},
+	}}
+	suite.Equal(3, SignerCount(multisigAuthInfo))
+
+	// A nested multisig member counts the members that signed for it
+	nested := cosmosTx.AuthInfo{SignerInfos: []*cosmosTx.SignerInfo{{ModeInfo: &cosmosTx.ModeInfo{Sum: &cosmosTx.ModeInfo_Multi_{Multi: &cosmosTx.ModeInfo_Multi{
+		ModeInfos: []*cosmosTx.ModeInfo{multiModeInfo, singleModeInfo(signing.SignMode_SIGN_MODE_DIRECT)},
+	}}}}}}
+	suite.Equal(3, SignerCount(nested))
+
+	suite.Zero(SignerCount(cosmosTx.AuthInfo{}))
+}
+
+func TestSignerInfoSuite(t *testing.T) {
+	suite.Run(t, new(SignerInfoTestSuite))
+}
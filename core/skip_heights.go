@@ -0,0 +1,58 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+)
+
+// SkipHeights is the set of block heights that are never enqueued for indexing
+type SkipHeights map[int64]bool
+
+// LoadSkipHeights loads a JSON list of block heights, every height must be positive
+func LoadSkipHeights(path string) (SkipHeights, error) {
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading skip heights file %s: %w", path, err)
+	}
+
+	var heights []int64
+	if err := json.Unmarshal(fileBytes, &heights); err != nil {
+		return nil, fmt.Errorf("error parsing skip heights file %s, expected a JSON list of heights: %w", path, err)
+	}
+
+	skip := make(SkipHeights, len(heights))
+	for _, height := range heights {
+		if height <= 0 {
+			return nil, fmt.Errorf("skip height %d must be a positive number", height)
+		}
+		skip[height] = true
+	}
+
+	return skip, nil
+}
+
+// Skips reports whether the height is never indexed. A nil set skips no heights.
+func (heights SkipHeights) Skips(height int64) bool {
+	return heights[height]
+}
+
+// Heights returns the skipped heights in ascending order
+func (heights SkipHeights) Heights() []int64 {
+	sorted := make([]int64, 0, len(heights))
+	for height := range heights {
+		sorted = append(sorted, height)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// RecordSkipHeights records the heights as manually skipped for the chain
+func RecordSkipHeights(db *gorm.DB, chainID uint, heights SkipHeights) error {
+	return dbTypes.RecordSkippedBlocks(db, chainID, heights.Heights(), models.SkippedBlockReasonManual)
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+)
+
+// Attribute keys of the x/slashing slash and liveness events
+const (
+	slashingAttributeAddress      = "address"
+	slashingAttributePower        = "power"
+	slashingAttributeReason       = "reason"
+	slashingAttributeJailed       = "jailed"
+	slashingAttributeBurnedCoins  = "burned_coins"
+	slashingAttributeMissedBlocks = "missed_blocks"
+)
+
+// ExtractSlashingEvents returns the slashing rows for the slash and liveness events in the block events.
+// Rows are returned without block IDs or operator addresses, these are filled in by the caller.
+func ExtractSlashingEvents(height int64, blockEvents []db.BlockEventDBWrapper) []models.SlashingEvent {
+	var slashingEvents []models.SlashingEvent
+	for _, blockEvent := range blockEvents {
+		eventType := blockEvent.BlockEvent.BlockEventType.Type
+		if eventType != models.SlashingEventTypeSlash && eventType != models.SlashingEventTypeLiveness {
+			continue
+		}
+
+		slashingEvent := models.SlashingEvent{
+			LifecyclePosition: blockEvent.BlockEvent.LifecyclePosition,
+			EventIndex:        blockEvent.BlockEvent.Index,
+			Height:            height,
+			Type:              eventType,
+		}
+
+		for _, attribute := range blockEvent.Attributes {
+			value := attribute.Value
+			switch attribute.BlockEventAttributeKey.Key {
+			case slashingAttributeAddress:
+				slashingEvent.ConsensusAddress = value
+			case slashingAttributeJailed:
+				// Jailing is emitted as its own slash event with the jailed validator as the only attribute
+				slashingEvent.Jailed = true
+				if slashingEvent.ConsensusAddress == "" {
+					slashingEvent.ConsensusAddress = value
+				}
+			case slashingAttributePower:
+				slashingEvent.Power = value
+			case slashingAttributeReason:
+				slashingEvent.Reason = value
+			case slashingAttributeBurnedCoins:
+				slashingEvent.Amount = value
+			case slashingAttributeMissedBlocks:
+				missedBlocks, err := strconv.ParseInt(value, 10, 64)
+				if err == nil {
+					slashingEvent.MissedBlocks = missedBlocks
+				}
+			}
+		}
+
+		if slashingEvent.ConsensusAddress != "" {
+			slashingEvents = append(slashingEvents, slashingEvent)
+		}
+	}
+
+	return slashingEvents
+}
+
+// ValidatorOperatorResolver maps validator consensus addresses to operator addresses. The mapping is cached and refreshed
+// from the validator set whenever an unknown consensus address is resolved, so validators created after startup are picked up.
+type ValidatorOperatorResolver struct {
+	mu                 sync.Mutex
+	operatorAddresses  map[string]string
+	fetchValidatorSets func() (map[string]string, error)
+}
+
+// NewValidatorOperatorResolver creates a resolver that loads the consensus to operator address mapping with the fetch function
+func NewValidatorOperatorResolver(fetch func() (map[string]string, error)) *ValidatorOperatorResolver {
+	return &ValidatorOperatorResolver{
+		operatorAddresses:  make(map[string]string),
+		fetchValidatorSets: fetch,
+	}
+}
+
+// Resolve returns the operator address of the consensus address, or an empty string if no validator has that consensus address.
+func (resolver *ValidatorOperatorResolver) Resolve(consensusAddress string) (string, error) {
+	resolver.mu.Lock()
+	defer resolver.mu.Unlock()
+
+	if operatorAddress, ok := resolver.operatorAddresses[consensusAddress]; ok {
+		return operatorAddress, nil
+	}
+
+	operatorAddresses, err := resolver.fetchValidatorSets()
+	if err != nil {
+		return "", err
+	}
+
+	for consAddress, operatorAddress := range operatorAddresses {
+		resolver.operatorAddresses[consAddress] = operatorAddress
+	}
+
+	// Validators that are no longer in the validator set are cached as unresolvable to avoid refetching on every event
+	if _, ok := resolver.operatorAddresses[consensusAddress]; !ok {
+		resolver.operatorAddresses[consensusAddress] = ""
+	}
+
+	return resolver.operatorAddresses[consensusAddress], nil
+}
+
+// ResolveOperatorAddresses fills in the operator address of every slashing event the resolver knows the validator of
+func (resolver *ValidatorOperatorResolver) ResolveOperatorAddresses(slashingEvents []models.SlashingEvent) error {
+	for index := range slashingEvents {
+		operatorAddress, err := resolver.Resolve(slashingEvents[index].ConsensusAddress)
+		if err != nil {
+			return err
+		}
+		slashingEvents[index].OperatorAddress = operatorAddress
+	}
+
+	return nil
+}
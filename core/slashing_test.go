@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testConsensusAddress = "cosmosvalcons1slashedvalidator"
+	testOperatorAddress  = "cosmosvaloper1slashedvalidator"
+)
+
+type SlashingTestSuite struct {
+	suite.Suite
+}
+
+func blockEvent(index uint64, eventType string, attributes ...string) db.BlockEventDBWrapper {
+	wrapper := db.BlockEventDBWrapper{
+		BlockEvent: models.BlockEvent{
+			Index:             index,
+			LifecyclePosition: models.BeginBlockEvent,
+			BlockEventType:    models.BlockEventType{Type: eventType},
+		},
+	}
+
+	for i := 0; i+1 < len(attributes); i += 2 {
+		wrapper.Attributes = append(wrapper.Attributes, models.BlockEventAttribute{
+			BlockEventAttributeKey: models.BlockEventAttributeKey{Key: attributes[i]},
+			Value:                  attributes[i+1],
+			Index:                  uint64(i / 2),
+		})
+	}
+
+	return wrapper
+}
+
+func (suite *SlashingTestSuite) TestExtractSlashingEvents() {
+	blockEvents := []db.BlockEventDBWrapper{
+		blockEvent(0, "transfer", "recipient", "cosmos1recipient", "amount", "100uatom"),
+		blockEvent(1, "slash", "address", testConsensusAddress, "power", "1500", "reason", "missing_signature", "burned_coins", "150uatom"),
+		blockEvent(2, "slash", "jailed", testConsensusAddress),
+		blockEvent(3, "liveness", "address", testConsensusAddress, "missed_blocks", "501", "height", "100"),
+	}
+
+	slashingEvents := ExtractSlashingEvents(100, blockEvents)
+	suite.Require().Len(slashingEvents, 3)
+
+	slash := slashingEvents[0]
+	suite.Equal(models.SlashingEventTypeSlash, slash.Type)
+	suite.Equal(int64(100), slash.Height)
+	suite.Equal(uint64(1), slash.EventIndex)
+	suite.Equal(testConsensusAddress, slash.ConsensusAddress)
+	suite.Equal("1500", slash.Power)
+	suite.Equal("missing_signature", slash.Reason)
+	suite.Equal("150uatom", slash.Amount)
+	suite.False(slash.Jailed)
+
+	jail := slashingEvents[1]
+	suite.Equal(models.SlashingEventTypeSlash, jail.Type)
+	suite.Equal(testConsensusAddress, jail.ConsensusAddress)
+	suite.True(jail.Jailed)
+
+	liveness := slashingEvents[2]
+	suite.Equal(models.SlashingEventTypeLiveness, liveness.Type)
+	suite.Equal(int64(501), liveness.MissedBlocks)
+}
+
+func (suite *SlashingTestSuite) TestResolveOperatorAddresses() {
+	fetches := 0
+	resolver := NewValidatorOperatorResolver(func() (map[string]string, error) {
+		fetches++
+		return map[string]string{testConsensusAddress: testOperatorAddress}, nil
+	})
+
+	slashingEvents := []models.SlashingEvent{
+		{ConsensusAddress: testConsensusAddress},
+		{ConsensusAddress: testConsensusAddress},
+		{ConsensusAddress: "cosmosvalcons1unknown"},
+	}
+
+	err := resolver.ResolveOperatorAddresses(slashingEvents)
+	suite.Require().NoError(err)
+	suite.Equal(testOperatorAddress, slashingEvents[0].OperatorAddress)
+	suite.Equal(testOperatorAddress, slashingEvents[1].OperatorAddress)
+	suite.Empty(slashingEvents[2].OperatorAddress)
+
+	// Known and unresolvable addresses are cached
+	suite.Equal(2, fetches)
+	_, err = resolver.Resolve("cosmosvalcons1unknown")
+	suite.Require().NoError(err)
+	suite.Equal(2, fetches)
+}
+
+func (suite *SlashingTestSuite) TestResolveError() {
+	resolver := NewValidatorOperatorResolver(func() (map[string]string, error) {
+		return nil, errors.New("node unavailable")
+	})
+
+	err := resolver.ResolveOperatorAddresses([]models.SlashingEvent{{ConsensusAddress: testConsensusAddress}})
+	suite.Error(err)
+}
+
+func TestSlashingSuite(t *testing.T) {
+	suite.Run(t, new(SlashingTestSuite))
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+// supplyDeltaSet sums the supply changes of a part of a block per denom
+type supplyDeltaSet struct {
+	// Only these denoms are tracked, all denoms if empty
+	denoms map[string]bool
+	deltas map[string]*models.SupplyDelta
+}
+
+func newSupplyDeltaSet(denoms []string) *supplyDeltaSet {
+	set := &supplyDeltaSet{denoms: make(map[string]bool), deltas: make(map[string]*models.SupplyDelta)}
+	for _, denom := range denoms {
+		set.denoms[denom] = true
+	}
+	return set
+}
+
+// add sums the coins of a coinbase or burn event amount into the set
+func (set *supplyDeltaSet) add(eventType string, amount string) error {
+	coins, err := types.ParseCoinsNormalized(amount)
+	if err != nil {
+		return fmt.Errorf("error parsing %s amount %q: %w", eventType, amount, err)
+	}
+
+	for _, coin := range coins {
+		if len(set.denoms) != 0 && !set.denoms[coin.Denom] {
+			continue
+		}
+
+		change := models.SupplyDelta{Denom: coin.Denom, Minted: decimal.Zero, Burned: decimal.Zero}
+		if eventType == models.SupplyDeltaEventTypeCoinbase {
+			change.Minted = decimal.NewFromBigInt(coin.Amount.BigInt(), 0)
+		} else {
+			change.Burned = decimal.NewFromBigInt(coin.Amount.BigInt(), 0)
+		}
+		change.Net = change.Minted.Sub(change.Burned)
+
+		if delta, ok := set.deltas[coin.Denom]; ok {
+			delta.Add(change)
+		} else {
+			set.deltas[coin.Denom] = &change
+		}
+	}
+
+	return nil
+}
+
+// rows returns the summed deltas sorted by denom
+func (set *supplyDeltaSet) rows() []models.SupplyDelta {
+	rows := make([]models.SupplyDelta, 0, len(set.deltas))
+	for _, delta := range set.deltas {
+		rows = append(rows, *delta)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Denom < rows[j].Denom })
+	return rows
+}
+
+func isSupplyDeltaEvent(eventType string) bool {
+	return eventType == models.SupplyDeltaEventTypeCoinbase || eventType == models.SupplyDeltaEventTypeBurn
+}
+
+// ExtractMessageSupplyDeltas returns the supply changes (bank module coinbase and burn events) of the message, one row per denom with
+// the mints and burns of the denom summed. Only the given denoms are returned, all denoms if empty. Rows are returned without block IDs.
+func ExtractMessageSupplyDeltas(messageLog *txtypes.LogMessage, denoms []string) ([]models.SupplyDelta, error) {
+	if messageLog == nil {
+		return nil, nil
+	}
+
+	set := newSupplyDeltaSet(denoms)
+	for _, event := range messageLog.Events {
+		if !isSupplyDeltaEvent(event.Type) {
+			continue
+		}
+
+		// Message logs merge the events of a message, each merged event repeats the amount attribute
+		for _, attribute := range event.Attributes {
+			if attribute.Key != rewardAttributeAmount {
+				continue
+			}
+			if err := set.add(event.Type, attribute.Value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return set.rows(), nil
+}
+
+// ExtractBlockSupplyDeltas returns the supply changes (bank module coinbase and burn events) of the block events of a lifecycle
+// position, one row per denom with the mints and burns of the denom summed. Only the given denoms are returned, all denoms if empty.
+// Rows are returned without block IDs.
+func ExtractBlockSupplyDeltas(height int64, source string, blockEvents []db.BlockEventDBWrapper, denoms []string) ([]models.SupplyDelta, error) {
+	set := newSupplyDeltaSet(denoms)
+	for _, blockEvent := range blockEvents {
+		eventType := blockEvent.BlockEvent.BlockEventType.Type
+		if !isSupplyDeltaEvent(eventType) {
+			continue
+		}
+
+		for _, attribute := range blockEvent.Attributes {
+			if attribute.BlockEventAttributeKey.Key != rewardAttributeAmount {
+				continue
+			}
+			if err := set.add(eventType, attribute.Value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	rows := set.rows()
+	for i := range rows {
+		rows[i].Height = height
+		rows[i].Source = source
+	}
+	return rows, nil
+}
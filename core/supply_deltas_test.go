@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	"github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+type SupplyDeltasTestSuite struct {
+	suite.Suite
+}
+
+func (suite *SupplyDeltasTestSuite) TestMintAndBurnNetOut() {
+	// A message minting and burning the same denom, the log merges both burn events into a single event
+	messageLog := &txtypes.LogMessage{
+		Events: []txtypes.LogMessageEvent{
+			{Type: "coinbase", Attributes: []txtypes.Attribute{
+				{Key: "minter", Value: "cosmos1tokenfactory"},
+				{Key: "amount", Value: "1000factory/cosmos1creator/token,50uatom"},
+			}},
+			{Type: "burn", Attributes: []txtypes.Attribute{
+				{Key: "burner", Value: "cosmos1tokenfactory"},
+				{Key: "amount", Value: "300factory/cosmos1creator/token"},
+				{Key: "burner", Value: "cosmos1tokenfactory"},
+				{Key: "amount", Value: "100factory/cosmos1creator/token"},
+			}},
+		},
+	}
+
+	deltas, err := ExtractMessageSupplyDeltas(messageLog, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(deltas, 2)
+
+	suite.Equal("factory/cosmos1creator/token", deltas[0].Denom)
+	suite.True(decimal.NewFromInt(1000).Equal(deltas[0].Minted))
+	suite.True(decimal.NewFromInt(400).Equal(deltas[0].Burned))
+	suite.True(decimal.NewFromInt(600).Equal(deltas[0].Net), "net %s", deltas[0].Net)
+
+	suite.Equal("uatom", deltas[1].Denom)
+	suite.True(decimal.NewFromInt(50).Equal(deltas[1].Net))
+
+	// Only the configured denoms are tracked
+	deltas, err = ExtractMessageSupplyDeltas(messageLog, []string{"uatom"})
+	suite.Require().NoError(err)
+	suite.Require().Len(deltas, 1)
+	suite.Equal("uatom", deltas[0].Denom)
+}
+
+func (suite *SupplyDeltasTestSuite) TestBlockSupplyDeltas() {
+	blockEvents := []db.BlockEventDBWrapper{
+		blockEvent(0, "coinbase", "minter", "cosmos1mintmodule", "amount", "5000uatom"),
+		blockEvent(1, "coin_received", "receiver", "cosmos1mintmodule", "amount", "5000uatom"),
+		blockEvent(2, "burn", "burner", "cosmos1feeburner", "amount", "7000uatom"),
+	}
+
+	deltas, err := ExtractBlockSupplyDeltas(100, models.SupplyDeltaSourceBeginBlock, blockEvents, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(deltas, 1)
+
+	suite.Equal(int64(100), deltas[0].Height)
+	suite.Equal(models.SupplyDeltaSourceBeginBlock, deltas[0].Source)
+	suite.True(decimal.NewFromInt(5000).Equal(deltas[0].Minted))
+	suite.True(decimal.NewFromInt(7000).Equal(deltas[0].Burned))
+	suite.True(decimal.NewFromInt(-2000).Equal(deltas[0].Net), "net %s", deltas[0].Net)
+}
+
+func TestSupplyDeltasSuite(t *testing.T) {
+	suite.Run(t, new(SupplyDeltasTestSuite))
+}
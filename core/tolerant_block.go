@@ -0,0 +1,38 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+)
+
+// UndecodableTxsError is returned by the tx processors in base.tolerant-block mode together with the txs that did decode, the
+// block is committed without the txs that failed to decode
+type UndecodableTxsError struct {
+	Height   int64
+	Failures []models.TxDecodeFailure
+}
+
+func (err *UndecodableTxsError) Error() string {
+	return fmt.Sprintf("%d txs of block %d could not be decoded", len(err.Failures), err.Height)
+}
+
+// tolerateDecodeFailure records a tx that failed to decode when base.tolerant-block is enabled, otherwise the error fails the block
+func tolerateDecodeFailure(cfg *config.IndexConfig, failures *[]models.TxDecodeFailure, height int64, hash string, err error) bool {
+	if !cfg.Base.TolerantBlock {
+		return false
+	}
+
+	config.Log.Warnf("[Block: %v] [TX: %v] Skipping tx that could not be decoded, the block will be committed without it. Err: %v", height, hash, err)
+	*failures = append(*failures, models.TxDecodeFailure{Height: height, Hash: hash, Error: err.Error()})
+	return true
+}
+
+// undecodableTxsError returns the error reporting the txs that failed to decode, nil when every tx decoded
+func undecodableTxsError(height int64, failures []models.TxDecodeFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return &UndecodableTxsError{Height: height, Failures: failures}
+}
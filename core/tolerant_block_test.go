@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	probeClient "github.com/DefiantLabs/probe/client"
+	abci "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type TolerantBlockTestSuite struct {
+	suite.Suite
+	client *probeClient.ChainClient
+}
+
+func (suite *TolerantBlockTestSuite) SetupTest() {
+	codec, err := probeClient.MakeCodec(nil, map[string]sdk.Msg{msgSendTypeURL: &bankTypes.MsgSend{}})
+	suite.Require().NoError(err)
+	suite.client = &probeClient.ChainClient{Codec: codec}
+}
+
+func (suite *TolerantBlockTestSuite) encodeSend(memo string) []byte {
+	sender := sdk.AccAddress(make([]byte, 20)).String()
+	msgBytes, err := (&bankTypes.MsgSend{FromAddress: sender, ToAddress: sender, Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000))}).Marshal()
+	suite.Require().NoError(err)
+
+	bodyBytes, err := (&tx.TxBody{Messages: []*codectypes.Any{{TypeUrl: msgSendTypeURL, Value: msgBytes}}, Memo: memo}).Marshal()
+	suite.Require().NoError(err)
+	authInfoBytes, err := (&tx.AuthInfo{Fee: &tx.Fee{GasLimit: 200000}}).Marshal()
+	suite.Require().NoError(err)
+
+	txBytes, err := (&tx.TxRaw{BodyBytes: bodyBytes, AuthInfoBytes: authInfoBytes, Signatures: [][]byte{[]byte("signature")}}).Marshal()
+	suite.Require().NoError(err)
+	return txBytes
+}
+
+// blockWithUndecodableTx returns a block with an undecodable tx between two valid txs
+func (suite *TolerantBlockTestSuite) blockWithUndecodableTx() (*coretypes.ResultBlock, *rpc.CustomBlockResults, cmtTypes.Tx) {
+	undecodable := cmtTypes.Tx("not a protobuf encoded tx")
+	txs := cmtTypes.Txs{suite.encodeSend("first"), undecodable, suite.encodeSend("third")}
+
+	blockData := &coretypes.ResultBlock{Block: &cmtTypes.Block{
+		Header: cmtTypes.Header{Height: 120, Time: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		Data:   cmtTypes.Data{Txs: txs},
+	}}
+
+	blockResults := &rpc.CustomBlockResults{Height: 120}
+	for range txs {
+		blockResults.TxsResults = append(blockResults.TxsResults, &abci.ResponseDeliverTx{Log: `[{"msg_index":0,"events":[]}]`})
+	}
+
+	return blockData, blockResults, undecodable
+}
+
+func (suite *TolerantBlockTestSuite) TestUndecodableTxFailsBlock() {
+	blockData, blockResults, _ := suite.blockWithUndecodableTx()
+
+	_, _, err := ProcessRPCBlockByHeightTXs(context.Background(), &config.IndexConfig{}, nil, suite.client, nil, nil, blockData, blockResults, nil, TxLookups{})
+	suite.Require().Error(err)
+
+	var undecodable *UndecodableTxsError
+	suite.False(errors.As(err, &undecodable))
+}
+
+func (suite *TolerantBlockTestSuite) TestTolerantBlockKeepsDecodedTxs() {
+	blockData, blockResults, undecodableTx := suite.blockWithUndecodableTx()
+	cfg := &config.IndexConfig{}
+	cfg.Base.TolerantBlock = true
+
+	txs, _, err := ProcessRPCBlockByHeightTXs(context.Background(), cfg, nil, suite.client, nil, nil, blockData, blockResults, nil, TxLookups{})
+
+	var undecodable *UndecodableTxsError
+	suite.Require().True(errors.As(err, &undecodable))
+	suite.Equal(int64(120), undecodable.Height)
+	suite.Require().Len(undecodable.Failures, 1)
+	suite.Equal(tendermintHashToHex(undecodableTx.Hash()), undecodable.Failures[0].Hash)
+	suite.Equal(int64(120), undecodable.Failures[0].Height)
+	suite.NotEmpty(undecodable.Failures[0].Error)
+
+	suite.Require().Len(txs, 2)
+	suite.Equal("first", txs[0].Tx.Memo)
+	suite.Equal("third", txs[1].Tx.Memo)
+	suite.Equal(tendermintHashToHex(blockData.Block.Txs[2].Hash()), txs[1].Tx.Hash)
+}
+
+func (suite *TolerantBlockTestSuite) TestTolerantBlockWithoutFailures() {
+	blockData, blockResults, _ := suite.blockWithUndecodableTx()
+	blockData.Block.Txs = cmtTypes.Txs{blockData.Block.Txs[0]}
+	blockResults.TxsResults = blockResults.TxsResults[:1]
+	cfg := &config.IndexConfig{}
+	cfg.Base.TolerantBlock = true
+
+	txs, _, err := ProcessRPCBlockByHeightTXs(context.Background(), cfg, nil, suite.client, nil, nil, blockData, blockResults, nil, TxLookups{})
+	suite.Require().NoError(err)
+	suite.Len(txs, 1)
+}
+
+func TestTolerantBlockTestSuite(t *testing.T) {
+	suite.Run(t, new(TolerantBlockTestSuite))
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/DefiantLabs/cosmos-indexer"
+
+// Names of the spans wrapping the pipeline stages of a block. The decode span is a child of the fetch span and the commit
+// spans are children of the decode span, so the stages of a block form a single trace.
+const (
+	SpanBlockFetch        = "block.fetch"
+	SpanBlockDecode       = "block.decode"
+	SpanBlockCommitTxs    = "block.commit.txs"
+	SpanBlockCommitEvents = "block.commit.events"
+)
+
+// SetupTracing exports the pipeline spans to the OTLP/HTTP collector endpoint (e.g. http://localhost:4318) and returns a
+// function that flushes and stops the exporter. Without it the spans are no-ops.
+func SetupTracing(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %s, expected a URL such as http://localhost:4318", endpoint)
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpointURL.Host)}
+	if endpointURL.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if endpointURL.Path != "" && endpointURL.Path != "/" {
+		options = append(options, otlptracehttp.WithURLPath(endpointURL.Path))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "cosmos-indexer"))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// StartBlockSpan starts the span of a pipeline stage for the block at the height, as a child of the span in the context.
+// A nil context starts a new trace.
+func StartBlockSpan(ctx context.Context, name string, height int64) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attribute.Int64("block.height", height)))
+}
@@ -1,12 +1,14 @@
 package core
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"math/big"
 	"reflect"
 	"strings"
 	"time"
+	"unicode/utf8"
 	"unsafe"
 
 	"github.com/DefiantLabs/cosmos-indexer/config"
@@ -32,7 +34,19 @@ func getUnexportedField(field reflect.Value) interface{} {
 	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Interface()
 }
 
-func ProcessRPCBlockByHeightTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client.ChainClient, messageTypeFilters []filter.MessageTypeFilter, messageFilters []filter.MessageFilter, blockResults *coretypes.ResultBlock, resultBlockRes *rpc.CustomBlockResults, customParsers map[string][]parsers.MessageParser) ([]dbTypes.TxDBWrapper, *time.Time, error) {
+// TxLookups are the lookup tables loaded from files during setup that transaction processing consults. The zero value indexes every
+// sender, validates no messages against schemas and leaves the message action column empty.
+type TxLookups struct {
+	SenderWhitelist SenderWhitelist // base.sender-whitelist-file
+	MessageSchemas  MessageSchemas  // base.message-schema-dir
+	MessageActions  MessageActions  // base.index-message-action
+}
+
+func ProcessRPCBlockByHeightTXs(ctx context.Context, cfg *config.IndexConfig, db *gorm.DB, cl *client.ChainClient, messageTypeFilters []filter.MessageTypeFilter, messageFilters []filter.MessageFilter, blockResults *coretypes.ResultBlock, resultBlockRes *rpc.CustomBlockResults, customParsers map[string][]parsers.MessageParser, lookups TxLookups) ([]dbTypes.TxDBWrapper, *time.Time, error) {
+	if blockResults == nil || blockResults.Block == nil || resultBlockRes == nil {
+		return nil, nil, fmt.Errorf("ProcessRPCBlockByHeightTXs: block or block results data is missing")
+	}
+
 	if len(blockResults.Block.Txs) != len(resultBlockRes.TxsResults) {
 		config.Log.Fatalf("blockResults & resultBlockRes: different length")
 	}
@@ -40,8 +54,14 @@ func ProcessRPCBlockByHeightTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client
 	blockTime := &blockResults.Block.Time
 	blockTimeStr := blockTime.Format(time.RFC3339)
 	var currTxDbWrappers []dbTypes.TxDBWrapper
+	var decodeFailures []models.TxDecodeFailure
 
+txs:
 	for txIdx, tendermintTx := range blockResults.Block.Txs {
+		// Parsers are not interrupted, a cancelled context stops processing before the next tx
+		if err := ctx.Err(); err != nil {
+			return nil, blockTime, err
+		}
 		txResult := resultBlockRes.TxsResults[txIdx]
 
 		// Indexer types only used by the indexer app (similar to the cosmos types)
@@ -58,7 +78,11 @@ func ProcessRPCBlockByHeightTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client
 		if err != nil {
 			txBasic, err = InAppTxDecoder(cl.Codec)(tendermintTx)
 			if err != nil {
-				return nil, blockTime, fmt.Errorf("ProcessRPCBlockByHeightTXs: TX cannot be parsed from block %v. This is usually a proto definition error. Err: %v", blockResults.Block.Height, err)
+				err = fmt.Errorf("ProcessRPCBlockByHeightTXs: TX cannot be parsed from block %v. This is usually a proto definition error. Err: %v", blockResults.Block.Height, err)
+				if tolerateDecodeFailure(cfg, &decodeFailures, blockResults.Block.Height, tendermintHashToHex(tendermintTx.Hash()), err) {
+					continue
+				}
+				return nil, blockTime, err
 			}
 			txFull = txBasic.(*cosmosTx.Tx)
 		} else {
@@ -149,6 +173,10 @@ func ProcessRPCBlockByHeightTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client
 				currMessages = append(currMessages, msg)
 				currLogMsgs = append(currLogMsgs, currTxLog)
 			} else {
+				err := fmt.Errorf("tx message could not be processed. Msg type: %s, Msg index: %d", txFull.Body.Messages[msgIdx].TypeUrl, msgIdx)
+				if tolerateDecodeFailure(cfg, &decodeFailures, blockResults.Block.Height, tendermintHashToHex(txHash), err) {
+					continue txs
+				}
 				return nil, blockTime, fmt.Errorf("tx message could not be processed")
 			}
 		}
@@ -156,6 +184,8 @@ func ProcessRPCBlockByHeightTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client
 		hexTxHash := tendermintHashToHex(txHash)
 
 		txBody.Messages = currMessages
+		txBody.Memo = txFull.Body.Memo
+		txBody.TimeoutHeight = txFull.Body.TimeoutHeight
 		indexerTx.Body = txBody
 		indexerTxResp := txtypes.Response{
 			TxHash:    hexTxHash,
@@ -164,6 +194,8 @@ func ProcessRPCBlockByHeightTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client
 			RawLog:    txResult.Log,
 			Log:       currLogMsgs,
 			Code:      txResult.Code,
+			Codespace: txResult.Codespace,
+			GasUsed:   txResult.GasUsed,
 		}
 
 		indexerTx.AuthInfo = *txFull.AuthInfo
@@ -171,12 +203,13 @@ func ProcessRPCBlockByHeightTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client
 		indexerMergedTx.Tx = indexerTx
 		indexerMergedTx.Tx.AuthInfo = *txFull.AuthInfo
 
-		processedTx, _, err := ProcessTx(cfg, db, indexerMergedTx, messagesRaw, messageTypeURLs, customParsers)
+		processedTx, _, err := ProcessTx(cfg, db, indexerMergedTx, messagesRaw, messageTypeURLs, customParsers, lookups)
 		if err != nil {
 			return currTxDbWrappers, blockTime, err
 		}
 
-		if len(processedTx.Messages) == 0 && !cfg.Flags.IndexEmptyTransactions {
+		// Failed txs with captured logs are kept so the log is stored even when the tx itself is skipped
+		if len(processedTx.Messages) == 0 && !cfg.Flags.IndexEmptyTransactions && processedTx.FailedTxLog == nil {
 			config.Log.Debug(fmt.Sprintf("[Block: %v] [TX: %v] Skipping empty transaction.", blockResults.Block.Height, hexTxHash))
 			continue
 		}
@@ -195,6 +228,12 @@ func ProcessRPCBlockByHeightTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client
 			return currTxDbWrappers, blockTime, err
 		}
 
+		// The sender whitelist applies on top of the message type filters, both must pass for the tx to be indexed
+		if !lookups.SenderWhitelist.Allows(signers) {
+			config.Log.Debug(fmt.Sprintf("[Block: %v] [TX: %v] Skipping transaction with no whitelisted signer.", blockResults.Block.Height, hexTxHash))
+			continue
+		}
+
 		processedTx.Tx.SignerAddresses = signers
 
 		fees, err := ProcessFees(db, indexerTx.AuthInfo, signers)
@@ -203,12 +242,40 @@ func ProcessRPCBlockByHeightTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client
 		}
 
 		processedTx.Tx.Fees = fees
-		processedTx.Tx.Memo = txFull.Body.Memo
+
+		if cfg.Base.IndexSignerInfo {
+			processedTx.SignerInfos, err = ExtractSignerInfos(cl, txFull.AuthInfo)
+			if err != nil {
+				return currTxDbWrappers, blockTime, err
+			}
+		}
+
+		if cfg.Base.ResolveExecutionContext {
+			resolveTxExecutionContexts(cl, &processedTx, txBody.Messages)
+		}
 
 		currTxDbWrappers = append(currTxDbWrappers, processedTx)
 	}
 
-	return currTxDbWrappers, blockTime, nil
+	return currTxDbWrappers, blockTime, undecodableTxsError(blockResults.Block.Height, decodeFailures)
+}
+
+// resolveTxExecutionContexts sets the execution context of each processed message, the messages are the tx messages by index
+func resolveTxExecutionContexts(cl *client.ChainClient, txDBWrapper *dbTypes.TxDBWrapper, messages []types.Msg) {
+	for i := range txDBWrapper.Messages {
+		message := &txDBWrapper.Messages[i]
+		messageIndex := message.Message.MessageIndex
+		if messageIndex >= len(messages) || messages[messageIndex] == nil {
+			continue
+		}
+
+		var err error
+		message.ExecutionContexts, err = ResolveExecutionContext(cl.Codec.Marshaler, messages[messageIndex])
+		if err != nil {
+			// As with address extraction, the message is still indexed with the layers resolved so far
+			config.Log.Errorf("[TX: %v] Error resolving the execution context of msg of type '%v': %v", txDBWrapper.Tx.Hash, message.Message.MessageType.MessageType, err)
+		}
+	}
 }
 
 func tendermintHashToHex(hash []byte) string {
@@ -216,11 +283,19 @@ func tendermintHashToHex(hash []byte) string {
 }
 
 // ProcessRPCTXs - Given an RPC response, build out the more specific data used by the parser.
-func ProcessRPCTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client.ChainClient, messageTypeFilters []filter.MessageTypeFilter, messageFilters []filter.MessageFilter, txEventResp *cosmosTx.GetTxsEventResponse, customParsers map[string][]parsers.MessageParser) ([]dbTypes.TxDBWrapper, *time.Time, error) {
+func ProcessRPCTXs(ctx context.Context, cfg *config.IndexConfig, db *gorm.DB, cl *client.ChainClient, messageTypeFilters []filter.MessageTypeFilter, messageFilters []filter.MessageFilter, txEventResp *cosmosTx.GetTxsEventResponse, customParsers map[string][]parsers.MessageParser, lookups TxLookups) ([]dbTypes.TxDBWrapper, *time.Time, error) {
 	var currTxDbWrappers []dbTypes.TxDBWrapper
 	var blockTime *time.Time
+	var decodeFailures []models.TxDecodeFailure
+	var blockHeight int64
 
+txs:
 	for txIdx := range txEventResp.Txs {
+		// Parsers are not interrupted, a cancelled context stops processing before the next tx
+		if err := ctx.Err(); err != nil {
+			return nil, blockTime, err
+		}
+
 		// Indexer types only used by the indexer app (similar to the cosmos types)
 		var indexerMergedTx txtypes.MergedTx
 		var indexerTx txtypes.IndexerTx
@@ -273,6 +348,13 @@ func ProcessRPCTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client.ChainClient,
 				var currMsgUnpack types.Msg
 				err := cl.Codec.InterfaceRegistry.UnpackAny(currTx.Body.Messages[msgIdx], &currMsgUnpack)
 				if err != nil || currMsgUnpack == nil {
+					if err == nil {
+						err = fmt.Errorf("unpacking protos failed and CachedValue is not present. Msg type: %s, Msg index: %d", currTx.Body.Messages[msgIdx].TypeUrl, msgIdx)
+					}
+					if tolerateDecodeFailure(cfg, &decodeFailures, currTxResp.Height, currTxResp.TxHash, err) {
+						blockHeight = currTxResp.Height
+						continue txs
+					}
 					return nil, blockTime, fmt.Errorf("tx message could not be processed. Unpacking protos failed and CachedValue is not present. TX Hash: %s, Msg type: %s, Msg index: %d, Code: %d",
 						currTxResp.TxHash,
 						currTx.Body.Messages[msgIdx].TypeUrl,
@@ -316,6 +398,8 @@ func ProcessRPCTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client.ChainClient,
 		}
 
 		txBody.Messages = currMessages
+		txBody.Memo = currTx.Body.Memo
+		txBody.TimeoutHeight = currTx.Body.TimeoutHeight
 		indexerTx.Body = txBody
 
 		indexerTxResp := txtypes.Response{
@@ -325,6 +409,8 @@ func ProcessRPCTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client.ChainClient,
 			RawLog:    currTxResp.RawLog,
 			Log:       currLogMsgs,
 			Code:      currTxResp.Code,
+			Codespace: currTxResp.Codespace,
+			GasUsed:   currTxResp.GasUsed,
 		}
 
 		indexerTx.AuthInfo = *currTx.AuthInfo
@@ -332,12 +418,13 @@ func ProcessRPCTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client.ChainClient,
 		indexerMergedTx.Tx = indexerTx
 		indexerMergedTx.Tx.AuthInfo = *currTx.AuthInfo
 
-		processedTx, txTime, err := ProcessTx(cfg, db, indexerMergedTx, messagesRaw, messageTypeURLs, customParsers)
+		processedTx, txTime, err := ProcessTx(cfg, db, indexerMergedTx, messagesRaw, messageTypeURLs, customParsers, lookups)
 		if err != nil {
 			return currTxDbWrappers, blockTime, err
 		}
 
-		if len(processedTx.Messages) == 0 && !cfg.Flags.IndexEmptyTransactions {
+		// Failed txs with captured logs are kept so the log is stored even when the tx itself is skipped
+		if len(processedTx.Messages) == 0 && !cfg.Flags.IndexEmptyTransactions && processedTx.FailedTxLog == nil {
 			config.Log.Debug(fmt.Sprintf("[Block: %v] [TX: %v] Skipping empty transaction.", currTxResp.Height, currTxResp.TxHash))
 			continue
 		}
@@ -364,6 +451,12 @@ func ProcessRPCTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client.ChainClient,
 		if err != nil {
 			return currTxDbWrappers, blockTime, err
 		}
+
+		// The sender whitelist applies on top of the message type filters, both must pass for the tx to be indexed
+		if !lookups.SenderWhitelist.Allows(signers) {
+			config.Log.Debug(fmt.Sprintf("[Block: %v] [TX: %v] Skipping transaction with no whitelisted signer.", currTxResp.Height, currTxResp.TxHash))
+			continue
+		}
 		processedTx.Tx.SignerAddresses = signers
 
 		fees, err := ProcessFees(db, indexerTx.AuthInfo, signers)
@@ -372,12 +465,22 @@ func ProcessRPCTXs(cfg *config.IndexConfig, db *gorm.DB, cl *client.ChainClient,
 		}
 
 		processedTx.Tx.Fees = fees
-		processedTx.Tx.Memo = currTx.Body.Memo
+
+		if cfg.Base.IndexSignerInfo {
+			processedTx.SignerInfos, err = ExtractSignerInfos(cl, currTx.AuthInfo)
+			if err != nil {
+				return currTxDbWrappers, blockTime, err
+			}
+		}
+
+		if cfg.Base.ResolveExecutionContext {
+			resolveTxExecutionContexts(cl, &processedTx, txBody.Messages)
+		}
 
 		currTxDbWrappers = append(currTxDbWrappers, processedTx)
 	}
 
-	return currTxDbWrappers, blockTime, nil
+	return currTxDbWrappers, blockTime, undecodableTxsError(blockHeight, decodeFailures)
 }
 
 func messageTypeShouldIndex(messageType string, filters []filter.MessageTypeFilter, customParsers map[string][]parsers.MessageParser) (bool, error) {
@@ -414,7 +517,7 @@ func messageTypeShouldIndex(messageType string, filters []filter.MessageTypeFilt
 	return true, nil
 }
 
-func ProcessTx(cfg *config.IndexConfig, db *gorm.DB, tx txtypes.MergedTx, messagesRaw [][]byte, messageTypeURLs []string, customParsers map[string][]parsers.MessageParser) (txDBWapper dbTypes.TxDBWrapper, txTime time.Time, err error) {
+func ProcessTx(cfg *config.IndexConfig, db *gorm.DB, tx txtypes.MergedTx, messagesRaw [][]byte, messageTypeURLs []string, customParsers map[string][]parsers.MessageParser, lookups TxLookups) (txDBWapper dbTypes.TxDBWrapper, txTime time.Time, err error) {
 	txTime, err = time.Parse(time.RFC3339, tx.TxResponse.TimeStamp)
 	if err != nil {
 		config.Log.Error("Error parsing tx timestamp.", err)
@@ -435,9 +538,18 @@ func ProcessTx(cfg *config.IndexConfig, db *gorm.DB, tx txtypes.MergedTx, messag
 				messageLog := txtypes.GetMessageLogForIndex(tx.TxResponse.Log, messageIndex)
 				messageType, currMessageDBWrapper := ProcessMessage(messageIndex, message, messageTypeURLs[messageIndex], messageLog, uniqueEventTypes, uniqueEventAttributeKeys)
 				currMessageDBWrapper.Message.MessageBytes = messagesRaw[messageIndex]
+				currMessageDBWrapper.Message.GasUsed = MessageGasUsed(messageLog, len(tx.Tx.Body.Messages), tx.TxResponse.GasUsed)
+				currMessageDBWrapper.Message.Action = lookups.MessageActions.Action(messageType)
 				uniqueMessageTypes[messageType] = currMessageDBWrapper.Message.MessageType
 				config.Log.Debug(fmt.Sprintf("[Block: %v] [TX: %v] Found msg of type '%v'.", tx.TxResponse.Height, tx.TxResponse.TxHash, messageType))
 
+				// Non-conforming messages are still indexed, the violation is stored on the message row for data-quality checks
+				if schemaErr := lookups.MessageSchemas.Validate(messageType, message); schemaErr != nil {
+					config.Log.Warnf("[Block: %v] [TX: %v] Msg of type '%v' failed schema validation: %v", tx.TxResponse.Height, tx.TxResponse.TxHash, messageType, schemaErr)
+					schemaError := schemaErr.Error()
+					currMessageDBWrapper.Message.SchemaError = &schemaError
+				}
+
 				if customParsers != nil {
 					if customMessageParsers, ok := customParsers[messageType]; ok {
 						for index, customParser := range customMessageParsers {
@@ -453,12 +565,82 @@ func ProcessTx(cfg *config.IndexConfig, db *gorm.DB, tx txtypes.MergedTx, messag
 					}
 				}
 
+				if cfg.Base.IndexAddresses {
+					currMessageDBWrapper.Addresses, err = ExtractMessageAddresses(message, customParsers[messageType], *cfg)
+					if err != nil {
+						// As with custom message parsing, the message is still indexed if address extraction fails
+						config.Log.Errorf("[Block: %v] [TX: %v] Error extracting addresses from msg of type '%v': %v", tx.TxResponse.Height, tx.TxResponse.TxHash, messageType, err)
+						err = nil
+					}
+				}
+
+				if cfg.Base.IndexGovernance {
+					currMessageDBWrapper.GovernanceMessages, err = ExtractGovernanceMessages(message, messageLog)
+					if err != nil {
+						config.Log.Errorf("[Block: %v] [TX: %v] Error extracting governance data from msg of type '%v': %v", tx.TxResponse.Height, tx.TxResponse.TxHash, messageType, err)
+						err = nil
+					}
+				}
+
+				if cfg.Base.IndexRewards {
+					currMessageDBWrapper.RewardEvents, err = ExtractMessageRewardEvents(message, messageLog)
+					if err != nil {
+						config.Log.Errorf("[Block: %v] [TX: %v] Error extracting reward events from msg of type '%v': %v", tx.TxResponse.Height, tx.TxResponse.TxHash, messageType, err)
+						err = nil
+					}
+				}
+
+				if cfg.Base.IndexBalanceDeltas {
+					currMessageDBWrapper.BalanceDeltas, err = ExtractMessageBalanceDeltas(messageLog)
+					if err != nil {
+						config.Log.Errorf("[Block: %v] [TX: %v] Error extracting balance deltas from msg of type '%v': %v", tx.TxResponse.Height, tx.TxResponse.TxHash, messageType, err)
+						err = nil
+					}
+				}
+
+				if cfg.Base.IndexSupplyDeltas {
+					currMessageDBWrapper.SupplyDeltas, err = ExtractMessageSupplyDeltas(messageLog, cfg.Base.SupplyDeltaDenoms)
+					if err != nil {
+						config.Log.Errorf("[Block: %v] [TX: %v] Error extracting supply deltas from msg of type '%v': %v", tx.TxResponse.Height, tx.TxResponse.TxHash, messageType, err)
+						err = nil
+					}
+				}
+
+				if cfg.Base.IndexNFT {
+					currMessageDBWrapper.NFTActivities = ExtractMessageNFTActivities(messageLog)
+				}
+
 				messages = append(messages, currMessageDBWrapper)
 			}
 		}
 	}
 
-	txDBWapper.Tx = models.Tx{Hash: tx.TxResponse.TxHash, Code: code}
+	txDBWapper.Tx = models.Tx{
+		Hash:           tx.TxResponse.TxHash,
+		Code:           code,
+		Memo:           TruncateMemo(tx.Tx.Body.Memo, cfg.Base.MaxMemoBytes),
+		GasUtilization: GasUtilization(tx.Tx.AuthInfo, tx.TxResponse.GasUsed),
+		Tags:           cfg.Base.RowTags,
+	}
+
+	if cfg.Base.IndexSignerCount {
+		numSigners := SignerCount(tx.Tx.AuthInfo)
+		txDBWapper.Tx.NumSigners = &numSigners
+	}
+
+	if tx.Tx.Body.TimeoutHeight != 0 {
+		timeoutHeight := tx.Tx.Body.TimeoutHeight
+		txDBWapper.Tx.TimeoutHeight = &timeoutHeight
+	}
+
+	if code != 0 && cfg.Base.CaptureFailedTxLogs {
+		txDBWapper.FailedTxLog = &models.FailedTxLog{
+			Hash:      tx.TxResponse.TxHash,
+			Code:      code,
+			Codespace: tx.TxResponse.Codespace,
+			RawLog:    tx.TxResponse.RawLog,
+		}
+	}
 	txDBWapper.Messages = messages
 	txDBWapper.UniqueMessageTypes = uniqueMessageTypes
 	txDBWapper.UniqueMessageAttributeKeys = uniqueEventAttributeKeys
@@ -573,6 +755,11 @@ func ProcessMessage(messageIndex int, message types.Msg, messageTypeURL string,
 	currMessage.MessageType = currMessageType
 	currMessageDBWrapper.Message = currMessage
 
+	// Chains that return no logs for the message have no message events to index
+	if messageLog == nil {
+		return currMessageType.MessageType, currMessageDBWrapper
+	}
+
 	for eventIndex, event := range messageLog.Events {
 		uniqueEventTypes[event.Type] = models.MessageEventType{Type: event.Type}
 
@@ -597,3 +784,16 @@ func ProcessMessage(messageIndex int, message types.Msg, messageTypeURL string,
 	}
 	return currMessageType.MessageType, currMessageDBWrapper
 }
+
+// TruncateMemo cuts the memo down to at most maxBytes bytes without splitting a UTF-8 character, 0 keeps the full memo
+func TruncateMemo(memo string, maxBytes int64) string {
+	if maxBytes <= 0 || int64(len(memo)) <= maxBytes {
+		return memo
+	}
+
+	cut := int(maxBytes)
+	for cut > 0 && !utf8.RuneStart(memo[cut]) {
+		cut--
+	}
+	return memo[:cut]
+}
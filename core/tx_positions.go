@@ -0,0 +1,26 @@
+package core
+
+import (
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+)
+
+// AttachTxIndexes sets the position of each processed tx in the tx list of the block the txs were included in (base.index-tx-position).
+// Processed txs can be filtered or returned out of block order by tx search, so they are matched to the block txs by hash.
+func AttachTxIndexes(txDBWrappers []dbTypes.TxDBWrapper, blockData *coretypes.ResultBlock) {
+	if blockData == nil || blockData.Block == nil {
+		return
+	}
+
+	positions := make(map[string]int, len(blockData.Block.Txs))
+	for i, rawTx := range blockData.Block.Txs {
+		positions[tendermintHashToHex(rawTx.Hash())] = i
+	}
+
+	for i := range txDBWrappers {
+		if position, ok := positions[txDBWrappers[i].Tx.Hash]; ok {
+			txIndex := position
+			txDBWrappers[i].Tx.TxIndex = &txIndex
+		}
+	}
+}
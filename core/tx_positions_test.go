@@ -0,0 +1,45 @@
+package core
+
+import (
+	"testing"
+
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type TxPositionsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TxPositionsTestSuite) TestTxIndexesMatchBlockOrder() {
+	blockTxs := cmtTypes.Txs{[]byte("first tx"), []byte("second tx"), []byte("third tx"), []byte("fourth tx")}
+	blockData := &coretypes.ResultBlock{Block: &cmtTypes.Block{Data: cmtTypes.Data{Txs: blockTxs}}}
+
+	// Tx search returned the txs out of block order and the second tx was filtered out
+	txDBWrappers := []dbTypes.TxDBWrapper{
+		{Tx: models.Tx{Hash: txHash(blockTxs[3])}},
+		{Tx: models.Tx{Hash: txHash(blockTxs[0])}},
+		{Tx: models.Tx{Hash: txHash(blockTxs[2])}},
+		{Tx: models.Tx{Hash: "NOT IN BLOCK"}},
+	}
+	AttachTxIndexes(txDBWrappers, blockData)
+
+	for i, expected := range []int{3, 0, 2} {
+		suite.Require().NotNil(txDBWrappers[i].Tx.TxIndex)
+		suite.Equal(expected, *txDBWrappers[i].Tx.TxIndex)
+	}
+	suite.Nil(txDBWrappers[3].Tx.TxIndex)
+}
+
+func (suite *TxPositionsTestSuite) TestMissingBlockData() {
+	txDBWrappers := []dbTypes.TxDBWrapper{{Tx: models.Tx{Hash: "HASH"}}}
+	AttachTxIndexes(txDBWrappers, nil)
+	suite.Nil(txDBWrappers[0].Tx.TxIndex)
+}
+
+func TestTxPositionsTestSuite(t *testing.T) {
+	suite.Run(t, new(TxPositionsTestSuite))
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	txtypes "github.com/DefiantLabs/cosmos-indexer/cosmos/modules/tx"
+	cosmosTx "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/stretchr/testify/suite"
+)
+
+type TxTestSuite struct {
+	suite.Suite
+}
+
+func failedMergedTx() txtypes.MergedTx {
+	return txtypes.MergedTx{
+		TxResponse: txtypes.Response{
+			TxHash:    "FAILEDTXHASH",
+			Height:    "10",
+			TimeStamp: "2024-01-01T00:00:00Z",
+			Code:      5,
+			Codespace: "sdk",
+			RawLog:    "failed to execute message; message index: 0: 100uatom is smaller than 200uatom: insufficient funds",
+		},
+	}
+}
+
+func (suite *TxTestSuite) TestCaptureFailedTxLog() {
+	cfg := config.IndexConfig{}
+	cfg.Base.CaptureFailedTxLogs = true
+
+	txDBWrapper, _, err := ProcessTx(&cfg, nil, failedMergedTx(), nil, nil, nil, TxLookups{})
+	suite.Require().NoError(err)
+	suite.Require().NotNil(txDBWrapper.FailedTxLog)
+
+	suite.Empty(txDBWrapper.Messages)
+	suite.Equal("FAILEDTXHASH", txDBWrapper.FailedTxLog.Hash)
+	suite.Equal(uint32(5), txDBWrapper.FailedTxLog.Code)
+	suite.Equal("sdk", txDBWrapper.FailedTxLog.Codespace)
+	suite.Equal(failedMergedTx().TxResponse.RawLog, txDBWrapper.FailedTxLog.RawLog)
+}
+
+func (suite *TxTestSuite) TestFailedTxLogDisabled() {
+	cfg := config.IndexConfig{}
+
+	txDBWrapper, _, err := ProcessTx(&cfg, nil, failedMergedTx(), nil, nil, nil, TxLookups{})
+	suite.Require().NoError(err)
+	suite.Nil(txDBWrapper.FailedTxLog)
+}
+
+func (suite *TxTestSuite) TestMemo() {
+	cfg := config.IndexConfig{}
+
+	memoTx := failedMergedTx()
+	memoTx.Tx.Body.Memo = "exchange deposit 104729 ✓"
+
+	txDBWrapper, _, err := ProcessTx(&cfg, nil, memoTx, nil, nil, nil, TxLookups{})
+	suite.Require().NoError(err)
+	suite.Equal("exchange deposit 104729 ✓", txDBWrapper.Tx.Memo)
+
+	// Truncation never splits the 3 byte check mark
+	cfg.Base.MaxMemoBytes = 25
+	txDBWrapper, _, err = ProcessTx(&cfg, nil, memoTx, nil, nil, nil, TxLookups{})
+	suite.Require().NoError(err)
+	suite.Equal("exchange deposit 104729 ", txDBWrapper.Tx.Memo)
+
+	txDBWrapper, _, err = ProcessTx(&cfg, nil, failedMergedTx(), nil, nil, nil, TxLookups{})
+	suite.Require().NoError(err)
+	suite.Equal("", txDBWrapper.Tx.Memo)
+}
+
+func (suite *TxTestSuite) TestRowTags() {
+	cfg := config.IndexConfig{}
+	cfg.Base.RowTags = map[string]string{"env": "testnet"}
+
+	txDBWrapper, _, err := ProcessTx(&cfg, nil, failedMergedTx(), nil, nil, nil, TxLookups{})
+	suite.Require().NoError(err)
+	suite.Equal("testnet", txDBWrapper.Tx.Tags["env"])
+}
+
+func (suite *TxTestSuite) TestTimeoutHeightAndGasUtilization() {
+	cfg := config.IndexConfig{}
+
+	timeoutTx := failedMergedTx()
+	timeoutTx.Tx.Body.TimeoutHeight = 1500
+	timeoutTx.Tx.AuthInfo = cosmosTx.AuthInfo{Fee: &cosmosTx.Fee{GasLimit: 200000}}
+	timeoutTx.TxResponse.GasUsed = 150000
+
+	txDBWrapper, _, err := ProcessTx(&cfg, nil, timeoutTx, nil, nil, nil, TxLookups{})
+	suite.Require().NoError(err)
+	suite.Require().NotNil(txDBWrapper.Tx.TimeoutHeight)
+	suite.Equal(uint64(1500), *txDBWrapper.Tx.TimeoutHeight)
+	suite.Require().NotNil(txDBWrapper.Tx.GasUtilization)
+	suite.InDelta(0.75, *txDBWrapper.Tx.GasUtilization, 1e-9)
+
+	// Without a timeout or a declared gas limit both are stored as null
+	txDBWrapper, _, err = ProcessTx(&cfg, nil, failedMergedTx(), nil, nil, nil, TxLookups{})
+	suite.Require().NoError(err)
+	suite.Nil(txDBWrapper.Tx.TimeoutHeight)
+	suite.Nil(txDBWrapper.Tx.GasUtilization)
+}
+
+func TestTxTestSuite(t *testing.T) {
+	suite.Run(t, new(TxTestSuite))
+}
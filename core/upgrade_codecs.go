@@ -0,0 +1,94 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/DefiantLabs/probe/client"
+)
+
+// CodecUpgrade switches decoding to the codec of a registered variant from the upgrade height on
+type CodecUpgrade struct {
+	Height  int64  `json:"height"`
+	Variant string `json:"variant"`
+	codec   client.Codec
+}
+
+// CodecSchedule holds the codecs used to decode blocks across chain upgrades that changed message encodings.
+// Blocks below the first upgrade height are decoded with the chain client's own codec.
+type CodecSchedule struct {
+	upgrades []CodecUpgrade
+}
+
+// LoadCodecSchedule reads a JSON list of upgrades (e.g. [{"height": 1200000, "variant": "v2"}]) and resolves each variant to its codec
+func LoadCodecSchedule(path string, variants map[string]client.Codec) (*CodecSchedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var upgrades []CodecUpgrade
+	if err := json.Unmarshal(data, &upgrades); err != nil {
+		return nil, fmt.Errorf("error parsing upgrade heights file %s: %w", path, err)
+	}
+
+	return NewCodecSchedule(upgrades, variants)
+}
+
+// NewCodecSchedule resolves the variant of each upgrade to its codec, each upgrade height must be positive and unique
+func NewCodecSchedule(upgrades []CodecUpgrade, variants map[string]client.Codec) (*CodecSchedule, error) {
+	schedule := &CodecSchedule{}
+	heights := make(map[int64]bool)
+	for _, upgrade := range upgrades {
+		if upgrade.Height <= 0 {
+			return nil, fmt.Errorf("upgrade height %d must be a positive number", upgrade.Height)
+		}
+		if heights[upgrade.Height] {
+			return nil, fmt.Errorf("duplicate upgrade height %d", upgrade.Height)
+		}
+		heights[upgrade.Height] = true
+
+		codec, ok := variants[upgrade.Variant]
+		if !ok {
+			return nil, fmt.Errorf("upgrade at height %d uses codec variant \"%s\", which is not registered", upgrade.Height, upgrade.Variant)
+		}
+		upgrade.codec = codec
+		schedule.upgrades = append(schedule.upgrades, upgrade)
+	}
+
+	sort.Slice(schedule.upgrades, func(i, j int) bool {
+		return schedule.upgrades[i].Height < schedule.upgrades[j].Height
+	})
+
+	return schedule, nil
+}
+
+// codecAt returns the codec of the latest upgrade at or below the height, false when the height is before the first upgrade
+func (schedule *CodecSchedule) codecAt(height int64) (client.Codec, bool) {
+	if schedule == nil {
+		return client.Codec{}, false
+	}
+
+	for i := len(schedule.upgrades) - 1; i >= 0; i-- {
+		if height >= schedule.upgrades[i].Height {
+			return schedule.upgrades[i].codec, true
+		}
+	}
+
+	return client.Codec{}, false
+}
+
+// ClientAtHeight returns a chain client that decodes with the codec in effect at the height. The client is shared by
+// everything but its codec, if no upgrade applies, or the schedule is nil, the client itself is returned.
+func (schedule *CodecSchedule) ClientAtHeight(cl *client.ChainClient, height int64) *client.ChainClient {
+	codec, ok := schedule.codecAt(height)
+	if !ok {
+		return cl
+	}
+
+	heightClient := *cl
+	heightClient.Codec = codec
+	return &heightClient
+}
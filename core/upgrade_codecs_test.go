@@ -0,0 +1,99 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	probeClient "github.com/DefiantLabs/probe/client"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/suite"
+)
+
+// The chain kept the type URL across the upgrade but changed the message encoding behind it
+const upgradedTypeURL = "/upgrade.test.v1.MsgTransfer"
+
+const upgradeHeight = 100
+
+type UpgradeCodecsTestSuite struct {
+	suite.Suite
+	cl       *probeClient.ChainClient
+	schedule *CodecSchedule
+}
+
+func (suite *UpgradeCodecsTestSuite) SetupTest() {
+	preUpgradeCodec, err := probeClient.MakeCodec(nil, map[string]sdk.Msg{upgradedTypeURL: &bankTypes.MsgSend{}})
+	suite.Require().NoError(err)
+	postUpgradeCodec, err := probeClient.MakeCodec(nil, map[string]sdk.Msg{upgradedTypeURL: &bankTypes.MsgMultiSend{}})
+	suite.Require().NoError(err)
+
+	path := filepath.Join(suite.T().TempDir(), "upgrades.json")
+	suite.Require().NoError(os.WriteFile(path, []byte(`[{"height": 100, "variant": "v2"}]`), 0o600))
+
+	suite.schedule, err = LoadCodecSchedule(path, map[string]probeClient.Codec{"v2": postUpgradeCodec})
+	suite.Require().NoError(err)
+
+	suite.cl = &probeClient.ChainClient{Codec: preUpgradeCodec}
+}
+
+// encodeTx encodes a tx with a single message under the upgraded type URL
+func (suite *UpgradeCodecsTestSuite) encodeTx(msg interface{ Marshal() ([]byte, error) }) []byte {
+	msgBytes, err := msg.Marshal()
+	suite.Require().NoError(err)
+
+	body := tx.TxBody{Messages: []*codectypes.Any{{TypeUrl: upgradedTypeURL, Value: msgBytes}}}
+	bodyBytes, err := body.Marshal()
+	suite.Require().NoError(err)
+	authInfoBytes, err := (&tx.AuthInfo{}).Marshal()
+	suite.Require().NoError(err)
+
+	txBytes, err := (&tx.TxRaw{BodyBytes: bodyBytes, AuthInfoBytes: authInfoBytes}).Marshal()
+	suite.Require().NoError(err)
+	return txBytes
+}
+
+// decodeMessage decodes the single message of the tx with the codec in effect at the height
+func (suite *UpgradeCodecsTestSuite) decodeMessage(txBytes []byte, height int64) any {
+	decoded, err := InAppTxDecoder(suite.schedule.ClientAtHeight(suite.cl, height).Codec)(txBytes)
+	suite.Require().NoError(err)
+	return decoded.(*tx.Tx).Body.Messages[0].GetCachedValue()
+}
+
+func (suite *UpgradeCodecsTestSuite) TestDecodingAcrossUpgradeBoundary() {
+	preUpgradeTx := suite.encodeTx(&bankTypes.MsgSend{FromAddress: "cosmos1sender", ToAddress: "cosmos1recipient"})
+	postUpgradeTx := suite.encodeTx(&bankTypes.MsgMultiSend{Inputs: []bankTypes.Input{{Address: "cosmos1sender"}}})
+
+	// The last block before the upgrade decodes with the old types
+	msgSend, ok := suite.decodeMessage(preUpgradeTx, upgradeHeight-1).(*bankTypes.MsgSend)
+	suite.Require().True(ok)
+	suite.Equal("cosmos1recipient", msgSend.ToAddress)
+
+	// From the upgrade height on the new types are used
+	for _, height := range []int64{upgradeHeight, upgradeHeight + 1} {
+		msgMultiSend, ok := suite.decodeMessage(postUpgradeTx, height).(*bankTypes.MsgMultiSend)
+		suite.Require().True(ok, "height %d", height)
+		suite.Require().Len(msgMultiSend.Inputs, 1)
+		suite.Equal("cosmos1sender", msgMultiSend.Inputs[0].Address)
+	}
+
+	// The chain client itself is left decoding with the old types
+	suite.Same(suite.cl, suite.schedule.ClientAtHeight(suite.cl, upgradeHeight-1))
+
+	// Without a schedule every height decodes with the chain client's codec
+	var noSchedule *CodecSchedule
+	suite.Same(suite.cl, noSchedule.ClientAtHeight(suite.cl, upgradeHeight))
+	_, ok = suite.decodeMessage(postUpgradeTx, upgradeHeight-1).(*bankTypes.MsgMultiSend)
+	suite.False(ok)
+}
+
+func (suite *UpgradeCodecsTestSuite) TestUnknownVariant() {
+	_, err := NewCodecSchedule([]CodecUpgrade{{Height: 10, Variant: "missing"}}, map[string]probeClient.Codec{})
+	suite.ErrorContains(err, "not registered")
+}
+
+func TestUpgradeCodecsSuite(t *testing.T) {
+	suite.Run(t, new(UpgradeCodecsTestSuite))
+}
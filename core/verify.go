@@ -0,0 +1,34 @@
+package core
+
+import (
+	"context"
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	"github.com/DefiantLabs/probe/client"
+)
+
+// getBlockResults fetches the block results at the height, overridden in tests
+var getBlockResults = rpc.GetBlockResultWithRetry
+
+// FetchBlockTxs fetches the block and its results from the node again and decodes its txs the way the indexer does, without
+// filters or custom parsers. The block results are always used, so the decode does not depend on the node's tx index.
+func FetchBlockTxs(cfg *config.IndexConfig, chainClient *client.ChainClient, codecs *CodecSchedule, rpcClient rpc.URIClient, height int64) ([]dbTypes.TxDBWrapper, error) {
+	blockData, err := getBlock(chainClient, height)
+	if err != nil {
+		return nil, err
+	}
+
+	blockResults, err := getBlockResults(rpcClient, height, cfg.Base.RequestRetryAttempts, cfg.Base.RequestRetryMaxWait)
+	if err != nil {
+		return nil, err
+	}
+
+	blockResults, err = NormalizeCustomBlockResults(blockResults)
+	if err != nil {
+		return nil, err
+	}
+
+	txs, _, err := ProcessRPCBlockByHeightTXs(context.Background(), cfg, nil, codecs.ClientAtHeight(chainClient, height), nil, nil, blockData, blockResults, nil, TxLookups{})
+	return txs, err
+}
@@ -16,6 +16,8 @@ type Response struct {
 	Height    string       `json:"height"`
 	TimeStamp string       `json:"timestamp"`
 	Code      uint32       `json:"code"`
+	Codespace string       `json:"codespace"`
+	GasUsed   int64        `json:"gas_used"`
 	RawLog    string       `json:"raw_log"`
 	Log       []LogMessage `json:"logs"`
 }
@@ -60,7 +62,9 @@ type LogMessageEvent struct {
 }
 
 type Body struct {
-	Messages []sdk.Msg `json:"messages"`
+	Messages      []sdk.Msg `json:"messages"`
+	Memo          string    `json:"memo"`
+	TimeoutHeight uint64    `json:"timeout_height"`
 }
 
 type AuthInfo struct {
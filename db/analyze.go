@@ -0,0 +1,36 @@
+package db
+
+import (
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"gorm.io/gorm"
+)
+
+// AnalyzeBackfilledTables refreshes the query planner statistics of the per-block tables after a back-fill, which leaves
+// them stale until autovacuum catches up. With vacuum set the tables are also vacuumed. It is a no-op on non-Postgres backends.
+func AnalyzeBackfilledTables(db *gorm.DB, vacuum bool) error {
+	if db.Dialector.Name() != "postgres" {
+		config.Log.Infof("Skipping ANALYZE after back-fill, not supported by the %s backend", db.Dialector.Name())
+		return nil
+	}
+
+	command := "ANALYZE"
+	if vacuum {
+		command = "VACUUM ANALYZE"
+	}
+
+	for _, model := range deferrableIndexModels() {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return err
+		}
+
+		config.Log.Infof("Running %s on %s", command, statement.Schema.Table)
+		// VACUUM cannot run inside a transaction block, each statement runs on its own
+		if err := db.Exec(command + " " + statement.Quote(statement.Schema.Table)).Error; err != nil {
+			config.Log.Errorf("Error running %s on %s. Err: %v", command, statement.Schema.Table, err)
+			return err
+		}
+	}
+
+	return nil
+}
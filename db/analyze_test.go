@@ -0,0 +1,53 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+type AnalyzeTestSuite struct {
+	suite.Suite
+}
+
+// spyOnStatements records the raw statements issued through the DB. The DB runs in dry run mode, so nothing is executed.
+func (suite *AnalyzeTestSuite) spyOnStatements(dialector gorm.Dialector) (*gorm.DB, *[]string) {
+	db, err := gorm.Open(dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	suite.Require().NoError(err)
+
+	var statements []string
+	err = db.Callback().Raw().After("gorm:raw").Register("analyze_test:spy", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	})
+	suite.Require().NoError(err)
+
+	return db, &statements
+}
+
+func (suite *AnalyzeTestSuite) TestAnalyzeAfterBackfill() {
+	// The DSN is never connected to in dry run mode
+	db, statements := suite.spyOnStatements(postgres.Open("host=localhost port=5432 user=test dbname=test"))
+
+	suite.Require().NoError(AnalyzeBackfilledTables(db, false))
+	suite.Require().Len(*statements, len(deferrableIndexModels()))
+	suite.Contains(*statements, `ANALYZE "blocks"`)
+	suite.Contains(*statements, `ANALYZE "txes"`)
+
+	*statements = nil
+	suite.Require().NoError(AnalyzeBackfilledTables(db, true))
+	suite.Contains(*statements, `VACUUM ANALYZE "messages"`)
+}
+
+func (suite *AnalyzeTestSuite) TestNoOpOnOtherBackends() {
+	db, statements := suite.spyOnStatements(tests.DummyDialector{})
+
+	suite.Require().NoError(AnalyzeBackfilledTables(db, true))
+	suite.Empty(*statements)
+}
+
+func TestAnalyzeTestSuite(t *testing.T) {
+	suite.Run(t, new(AnalyzeTestSuite))
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArchiveFilteredBlockEvents stores the begin and end block events dropped by the block event filters in the compressed cold table
+// of base.archive-filtered-events when the block events are indexed. Archived rows of the chain more than retention blocks below
+// the block are removed in the same transaction.
+func ArchiveFilteredBlockEvents(blockDBWrapper *BlockDBWrapper, beginBlockEvents []BlockEventDBWrapper, endBlockEvents []BlockEventDBWrapper, retention int64) error {
+	blockDBWrapper.archiveRetention = retention
+	if len(beginBlockEvents) == 0 && len(endBlockEvents) == 0 {
+		return nil
+	}
+
+	events := make(models.BlockEventsJSON, 0, len(beginBlockEvents)+len(endBlockEvents))
+	events = appendBlockEventsJSON(events, models.JSONBeginBlockEvent, beginBlockEvents)
+	events = appendBlockEventsJSON(events, models.JSONEndBlockEvent, endBlockEvents)
+
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if err := json.NewEncoder(gzipWriter).Encode(events); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+
+	blockDBWrapper.ArchivedEvents = &models.ArchivedBlockEvents{
+		Height: blockDBWrapper.Block.Height,
+		Events: compressed.Bytes(),
+	}
+	return nil
+}
+
+// DecodeArchivedBlockEvents decompresses the block events of an archived row
+func DecodeArchivedBlockEvents(archived models.ArchivedBlockEvents) (models.BlockEventsJSON, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archived.Events))
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	decompressed, err := io.ReadAll(gzipReader)
+	if err != nil {
+		return nil, err
+	}
+
+	var events models.BlockEventsJSON
+	if err := json.Unmarshal(decompressed, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// indexArchivedBlockEvents writes the archived events of the block and removes the rows of the chain that fell out of retention
+func indexArchivedBlockEvents(db *gorm.DB, blockDBWrapper *BlockDBWrapper) error {
+	if blockDBWrapper.ArchivedEvents != nil {
+		blockDBWrapper.ArchivedEvents.BlockID = blockDBWrapper.Block.ID
+		if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "block_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"height", "events"}),
+		}).Create(blockDBWrapper.ArchivedEvents).Error; err != nil {
+			return err
+		}
+	}
+
+	if blockDBWrapper.archiveRetention > 0 {
+		chainBlockIDs := db.Model(&models.Block{}).Select("id").Where("chain_id = ?", blockDBWrapper.Block.ChainID)
+		return db.Where("height <= ? AND block_id IN (?)", blockDBWrapper.Block.Height-blockDBWrapper.archiveRetention, chainBlockIDs).
+			Delete(&models.ArchivedBlockEvents{}).Error
+	}
+
+	return nil
+}
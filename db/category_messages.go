@@ -0,0 +1,58 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// messageCategory returns the category of the message type from the module prefix of its type URL, e.g. bank for
+// /cosmos.bank.v1beta1.MsgSend. Types of modules without a category table are in the other category.
+func messageCategory(typeURL string) string {
+	segments := strings.Split(strings.TrimPrefix(typeURL, "/"), ".")
+
+	module := segments[0]
+	if module == "cosmos" && len(segments) > 1 {
+		module = segments[1]
+	}
+
+	switch module {
+	case models.MessageCategoryBank, models.MessageCategoryStaking, models.MessageCategoryDistribution, models.MessageCategoryGov, models.MessageCategoryIBC:
+		return module
+	case "cosmwasm":
+		return models.MessageCategoryWasm
+	default:
+		return models.MessageCategoryOther
+	}
+}
+
+// indexCategoryMessages copies the messages of the tx into the tables of their categories
+func indexCategoryMessages(db *gorm.DB, block models.Block, tx TxDBWrapper) error {
+	categoryMessages := make(map[string][]models.CategoryMessage)
+	for _, message := range tx.Messages {
+		category := messageCategory(message.Message.MessageType.MessageType)
+		categoryMessages[category] = append(categoryMessages[category], models.CategoryMessage{
+			Height:        block.Height,
+			TxID:          tx.Tx.ID,
+			MessageID:     message.Message.ID,
+			MessageTypeID: message.Message.MessageTypeID,
+			MessageIndex:  message.Message.MessageIndex,
+			MessageBytes:  message.Message.MessageBytes,
+		})
+	}
+
+	for category, messagesSlice := range categoryMessages {
+		if err := db.Table(models.CategoryMessageTable(category)).Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "message_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"height", "tx_id", "message_type_id", "message_index", "message_bytes"}),
+		}).Create(&messagesSlice).Error; err != nil {
+			config.Log.Errorf("Error creating %s category messages. Err: %v", category, err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/stretchr/testify/suite"
+)
+
+type CategoryMessagesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *CategoryMessagesTestSuite) TestMessageCategory() {
+	suite.Equal(models.MessageCategoryBank, messageCategory("/cosmos.bank.v1beta1.MsgSend"))
+	suite.Equal(models.MessageCategoryStaking, messageCategory("/cosmos.staking.v1beta1.MsgDelegate"))
+	suite.Equal(models.MessageCategoryDistribution, messageCategory("/cosmos.distribution.v1beta1.MsgWithdrawDelegatorReward"))
+	suite.Equal(models.MessageCategoryGov, messageCategory("/cosmos.gov.v1.MsgVote"))
+	suite.Equal(models.MessageCategoryIBC, messageCategory("/ibc.applications.transfer.v1.MsgTransfer"))
+	suite.Equal(models.MessageCategoryWasm, messageCategory("/cosmwasm.wasm.v1.MsgExecuteContract"))
+	suite.Equal(models.MessageCategoryOther, messageCategory("/cosmos.authz.v1beta1.MsgExec"))
+	suite.Equal(models.MessageCategoryOther, messageCategory("/osmosis.gamm.v1beta1.MsgSwapExactAmountIn"))
+}
+
+func TestCategoryMessagesTestSuite(t *testing.T) {
+	suite.Run(t, new(CategoryMessagesTestSuite))
+}
@@ -0,0 +1,343 @@
+package db
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+)
+
+// blockChecksumContent is the canonical form of the normalized tx and event set of a block that its checksum is computed over.
+// Database IDs are left out, so the same data yields the same checksum in any database and across reindexes.
+type blockChecksumContent struct {
+	Txs         []checksumTx           `json:"txs"`
+	BlockEvents []checksumEvent        `json:"block_events"`
+	EventsJSON  models.BlockEventsJSON `json:"events_json,omitempty"`
+}
+
+type checksumTx struct {
+	Hash     string            `json:"hash"`
+	Code     uint32            `json:"code"`
+	Memo     string            `json:"memo"`
+	Fees     []checksumFee     `json:"fees"`
+	Messages []checksumMessage `json:"messages"`
+}
+
+type checksumFee struct {
+	txID   uint
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+type checksumMessage struct {
+	txID   uint
+	Index  int             `json:"index"`
+	Type   string          `json:"type"`
+	Events []checksumEvent `json:"events"`
+}
+
+type checksumEvent struct {
+	parentID          uint
+	LifecyclePosition int                 `json:"lifecycle_position"`
+	Index             uint64              `json:"index"`
+	Type              string              `json:"type"`
+	Attributes        []checksumAttribute `json:"attributes"`
+}
+
+type checksumAttribute struct {
+	Index uint64 `json:"index"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// checksum sorts the content into its canonical order and returns the hex encoded SHA-256 of its JSON serialization
+func (content blockChecksumContent) checksum() (string, error) {
+	sortChecksumTxs(content.Txs)
+	sortChecksumEvents(content.BlockEvents)
+
+	serialized, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(serialized)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func sortChecksumTxs(txs []checksumTx) {
+	for _, tx := range txs {
+		slices.SortFunc(tx.Fees, func(a, b checksumFee) int {
+			return cmp.Or(cmp.Compare(a.Denom, b.Denom), cmp.Compare(a.Amount, b.Amount))
+		})
+		slices.SortFunc(tx.Messages, func(a, b checksumMessage) int { return cmp.Compare(a.Index, b.Index) })
+		for _, message := range tx.Messages {
+			sortChecksumEvents(message.Events)
+		}
+	}
+	slices.SortFunc(txs, func(a, b checksumTx) int { return cmp.Compare(a.Hash, b.Hash) })
+}
+
+func sortChecksumEvents(events []checksumEvent) {
+	slices.SortFunc(events, func(a, b checksumEvent) int {
+		return cmp.Or(cmp.Compare(a.LifecyclePosition, b.LifecyclePosition), cmp.Compare(a.Index, b.Index))
+	})
+	for _, event := range events {
+		slices.SortFunc(event.Attributes, func(a, b checksumAttribute) int { return cmp.Compare(a.Index, b.Index) })
+	}
+}
+
+// ComputeBlockChecksum returns the checksum of the txs (with their fees, messages, message events and attributes) and block events
+// (normalized or jsonb) stored for the block
+func ComputeBlockChecksum(db *gorm.DB, blockID uint) (string, error) {
+	content, err := loadBlockChecksumContent(db, blockID)
+	if err != nil {
+		return "", err
+	}
+	return content.checksum()
+}
+
+// UpdateBlockChecksum recomputes the checksum of the block from its stored rows and stores it (base.index-block-checksums).
+// It is run after each write of the block's txs or block events, so the checksum covers whatever has been indexed for the block.
+func UpdateBlockChecksum(db *gorm.DB, blockID uint) error {
+	checksum, err := ComputeBlockChecksum(db, blockID)
+	if err != nil {
+		config.Log.Error("Error computing block checksum.", err)
+		return err
+	}
+
+	if err := db.Model(&models.Block{}).Where("id = ?", blockID).Update("checksum", checksum).Error; err != nil {
+		config.Log.Error("Error updating block checksum.", err)
+		return err
+	}
+
+	return nil
+}
+
+func loadBlockChecksumContent(db *gorm.DB, blockID uint) (blockChecksumContent, error) {
+	content := blockChecksumContent{Txs: []checksumTx{}, BlockEvents: []checksumEvent{}}
+
+	var block models.Block
+	if err := db.Select("id", "events").First(&block, blockID).Error; err != nil {
+		return content, err
+	}
+	content.EventsJSON = block.Events
+
+	var txRows []struct {
+		ID   uint
+		Hash string
+		Code uint32
+		Memo string
+	}
+	if err := db.Raw("SELECT id, hash, code, memo FROM txes WHERE block_id = ?", blockID).Scan(&txRows).Error; err != nil {
+		return content, err
+	}
+
+	txIDs := make([]uint, len(txRows))
+	txIndexes := make(map[uint]int, len(txRows))
+	for i, row := range txRows {
+		txIDs[i] = row.ID
+		txIndexes[row.ID] = i
+		content.Txs = append(content.Txs, checksumTx{Hash: row.Hash, Code: row.Code, Memo: row.Memo, Fees: []checksumFee{}, Messages: []checksumMessage{}})
+	}
+
+	if len(txIDs) != 0 {
+		fees, err := loadChecksumFees(db, txIDs)
+		if err != nil {
+			return content, err
+		}
+		for _, fee := range fees {
+			tx := &content.Txs[txIndexes[fee.txID]]
+			tx.Fees = append(tx.Fees, fee)
+		}
+
+		messages, err := loadChecksumMessages(db, txIDs)
+		if err != nil {
+			return content, err
+		}
+		for _, message := range messages {
+			tx := &content.Txs[txIndexes[message.txID]]
+			tx.Messages = append(tx.Messages, message)
+		}
+	}
+
+	blockEvents, err := loadChecksumEvents(db,
+		`SELECT block_events.id, block_events.block_id AS parent_id, block_events.lifecycle_position, block_events."index", block_event_types.type
+		FROM block_events JOIN block_event_types ON block_event_types.id = block_events.block_event_type_id
+		WHERE block_events.block_id IN ?`,
+		`SELECT block_event_attributes.block_event_id AS event_id, block_event_attributes."index", block_event_attribute_keys.key, block_event_attributes.value
+		FROM block_event_attributes JOIN block_event_attribute_keys ON block_event_attribute_keys.id = block_event_attributes.block_event_attribute_key_id
+		WHERE block_event_attributes.block_event_id IN ?`,
+		[]uint{blockID})
+	if err != nil {
+		return content, err
+	}
+	content.BlockEvents = append(content.BlockEvents, blockEvents...)
+
+	return content, nil
+}
+
+func loadChecksumFees(db *gorm.DB, txIDs []uint) ([]checksumFee, error) {
+	var rows []struct {
+		TxID   uint
+		Denom  string
+		Amount string
+	}
+	err := db.Raw("SELECT fees.tx_id, denoms.base AS denom, fees.amount::text AS amount FROM fees JOIN denoms ON denoms.id = fees.denomination_id WHERE fees.tx_id IN ?", txIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	fees := make([]checksumFee, len(rows))
+	for i, row := range rows {
+		fees[i] = checksumFee{txID: row.TxID, Denom: row.Denom, Amount: row.Amount}
+	}
+	return fees, nil
+}
+
+func loadChecksumMessages(db *gorm.DB, txIDs []uint) ([]checksumMessage, error) {
+	var rows []struct {
+		ID           uint
+		TxID         uint
+		MessageIndex int
+		MessageType  string
+	}
+	err := db.Raw("SELECT messages.id, messages.tx_id, messages.message_index, message_types.message_type FROM messages JOIN message_types ON message_types.id = messages.message_type_id WHERE messages.tx_id IN ?", txIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	messageIDs := make([]uint, len(rows))
+	messages := make([]checksumMessage, len(rows))
+	messageIndexes := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		messageIDs[i] = row.ID
+		messageIndexes[row.ID] = i
+		messages[i] = checksumMessage{txID: row.TxID, Index: row.MessageIndex, Type: row.MessageType, Events: []checksumEvent{}}
+	}
+
+	if len(messageIDs) == 0 {
+		return messages, nil
+	}
+
+	events, err := loadChecksumEvents(db,
+		`SELECT message_events.id, message_events.message_id AS parent_id, 0 AS lifecycle_position, message_events."index", message_event_types.type
+		FROM message_events JOIN message_event_types ON message_event_types.id = message_events.message_event_type_id
+		WHERE message_events.message_id IN ?`,
+		`SELECT message_event_attributes.message_event_id AS event_id, message_event_attributes."index", message_event_attribute_keys.key, message_event_attributes.value
+		FROM message_event_attributes JOIN message_event_attribute_keys ON message_event_attribute_keys.id = message_event_attributes.message_event_attribute_key_id
+		WHERE message_event_attributes.message_event_id IN ?`,
+		messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		message := &messages[messageIndexes[event.parentID]]
+		message.Events = append(message.Events, event)
+	}
+
+	return messages, nil
+}
+
+// loadChecksumEvents loads the events of the parents with their attributes, the queries must select the columns of checksumEvent
+// and checksumAttribute under their snake case names
+func loadChecksumEvents(db *gorm.DB, eventsQuery string, attributesQuery string, parentIDs []uint) ([]checksumEvent, error) {
+	var eventRows []struct {
+		ID                uint
+		ParentID          uint
+		LifecyclePosition int
+		Index             uint64
+		Type              string
+	}
+	if err := db.Raw(eventsQuery, parentIDs).Scan(&eventRows).Error; err != nil {
+		return nil, err
+	}
+
+	eventIDs := make([]uint, len(eventRows))
+	events := make([]checksumEvent, len(eventRows))
+	eventIndexes := make(map[uint]int, len(eventRows))
+	for i, row := range eventRows {
+		eventIDs[i] = row.ID
+		eventIndexes[row.ID] = i
+		events[i] = checksumEvent{parentID: row.ParentID, LifecyclePosition: row.LifecyclePosition, Index: row.Index, Type: row.Type, Attributes: []checksumAttribute{}}
+	}
+
+	if len(eventIDs) == 0 {
+		return events, nil
+	}
+
+	var attributeRows []struct {
+		EventID uint
+		Index   uint64
+		Key     string
+		Value   string
+	}
+	if err := db.Raw(attributesQuery, eventIDs).Scan(&attributeRows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range attributeRows {
+		event := &events[eventIndexes[row.EventID]]
+		event.Attributes = append(event.Attributes, checksumAttribute{Index: row.Index, Key: row.Key, Value: row.Value})
+	}
+
+	return events, nil
+}
+
+// BlockChecksumMismatch is a block whose stored checksum no longer matches the checksum of its stored rows
+type BlockChecksumMismatch struct {
+	Height   int64
+	Stored   string
+	Computed string
+}
+
+// VerifyBlockChecksums recomputes the checksum of every block of the chain that has one stored, in a read-only transaction,
+// calling report for every mismatch. It returns the number of blocks verified and the number of mismatches.
+func VerifyBlockChecksums(db *gorm.DB, chainID uint, batchSize int, report func(BlockChecksumMismatch)) (int64, int64, error) {
+	var verified, mismatches int64
+
+	err := db.Transaction(func(dbTransaction *gorm.DB) error {
+		if err := dbTransaction.Exec("SET TRANSACTION READ ONLY").Error; err != nil {
+			return err
+		}
+
+		var lastID uint
+		for {
+			var blocks []models.Block
+			err := dbTransaction.Select("id", "height", "checksum").
+				Where("chain_id = ? AND checksum IS NOT NULL AND id > ?", chainID, lastID).
+				Order("id").
+				Limit(batchSize).
+				Find(&blocks).Error
+			if err != nil {
+				return err
+			}
+
+			if len(blocks) == 0 {
+				return nil
+			}
+
+			for _, block := range blocks {
+				computed, err := ComputeBlockChecksum(dbTransaction, block.ID)
+				if err != nil {
+					return err
+				}
+
+				verified++
+				if computed != *block.Checksum {
+					mismatches++
+					report(BlockChecksumMismatch{Height: block.Height, Stored: *block.Checksum, Computed: computed})
+				}
+			}
+
+			lastID = blocks[len(blocks)-1].ID
+		}
+	})
+
+	return verified, mismatches, err
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ChecksumsTestSuite struct {
+	suite.Suite
+}
+
+// checksumBlock returns the content of a block with two txs and a block event, in the order rows come back from the database
+func checksumBlock(reversed bool) blockChecksumContent {
+	transfer := checksumEvent{Index: 0, Type: "transfer", Attributes: []checksumAttribute{{Index: 0, Key: "recipient", Value: "cosmos1recipient"}, {Index: 1, Key: "amount", Value: "10uatom"}}}
+	message := checksumEvent{Index: 1, Type: "message", Attributes: []checksumAttribute{{Index: 0, Key: "action", Value: "/cosmos.bank.v1beta1.MsgSend"}}}
+
+	content := blockChecksumContent{
+		Txs: []checksumTx{
+			{
+				Hash:     "AAAA",
+				Fees:     []checksumFee{{Denom: "uatom", Amount: "5000"}, {Denom: "uosmo", Amount: "10"}},
+				Messages: []checksumMessage{{Index: 0, Type: "/cosmos.bank.v1beta1.MsgSend", Events: []checksumEvent{transfer, message}}},
+			},
+			{Hash: "BBBB", Code: 5, Memo: "failed", Fees: []checksumFee{}, Messages: []checksumMessage{}},
+		},
+		BlockEvents: []checksumEvent{
+			{LifecyclePosition: 0, Index: 0, Type: "mint", Attributes: []checksumAttribute{{Index: 0, Key: "amount", Value: "100"}}},
+			{LifecyclePosition: 1, Index: 0, Type: "complete_unbonding", Attributes: []checksumAttribute{}},
+		},
+	}
+
+	if reversed {
+		tx := &content.Txs[0]
+		tx.Fees[0], tx.Fees[1] = tx.Fees[1], tx.Fees[0]
+		events := tx.Messages[0].Events
+		events[0], events[1] = events[1], events[0]
+		attributes := events[1].Attributes
+		attributes[0], attributes[1] = attributes[1], attributes[0]
+		content.Txs[0], content.Txs[1] = content.Txs[1], content.Txs[0]
+		content.BlockEvents[0], content.BlockEvents[1] = content.BlockEvents[1], content.BlockEvents[0]
+	}
+
+	return content
+}
+
+func (suite *ChecksumsTestSuite) TestChecksumIsIndependentOfRowOrder() {
+	checksum, err := checksumBlock(false).checksum()
+	suite.Require().NoError(err)
+	suite.Len(checksum, 64)
+
+	reversedChecksum, err := checksumBlock(true).checksum()
+	suite.Require().NoError(err)
+	suite.Equal(checksum, reversedChecksum)
+}
+
+func (suite *ChecksumsTestSuite) TestChecksumChangesWithContent() {
+	checksum, err := checksumBlock(false).checksum()
+	suite.Require().NoError(err)
+
+	changed := checksumBlock(false)
+	changed.Txs[0].Messages[0].Events[0].Attributes[1].Value = "11uatom"
+	changedChecksum, err := changed.checksum()
+	suite.Require().NoError(err)
+	suite.NotEqual(checksum, changedChecksum)
+}
+
+func TestChecksumsTestSuite(t *testing.T) {
+	suite.Run(t, new(ChecksumsTestSuite))
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/DefiantLabs/cosmos-indexer/config"
 	"github.com/DefiantLabs/cosmos-indexer/db/models"
@@ -64,6 +65,8 @@ func migrateBlockModels(db *gorm.DB) error {
 		&models.BlockEventAttributeKey{},
 		&models.FailedBlock{},
 		&models.FailedEventBlock{},
+		&models.SkippedBlock{},
+		&models.SlashingEvent{},
 	)
 }
 
@@ -81,11 +84,34 @@ func migrateTXModels(db *gorm.DB) error {
 		&models.MessageType{},
 		&models.Message{},
 		&models.FailedTx{},
+		&models.FailedTxLog{},
+		&models.TxDecodeFailure{},
 		&models.FailedMessage{},
 		&models.MessageEvent{},
 		&models.MessageEventType{},
 		&models.MessageEventAttribute{},
 		&models.MessageEventAttributeKey{},
+		&models.MessageAddress{},
+		&models.GovernanceMessage{},
+		&models.RewardEvent{},
+		&models.NFTActivity{},
+		&models.BalanceDelta{},
+		&models.TxSignerInfo{},
+		&models.ModuleBalance{},
+		&models.TxRaw{},
+		&models.MessageExecutionContext{},
+		&models.BankMessage{},
+		&models.StakingMessage{},
+		&models.DistributionMessage{},
+		&models.GovMessage{},
+		&models.IBCMessage{},
+		&models.WasmMessage{},
+		&models.OtherMessage{},
+		&models.StaticBlockEventRun{},
+		&models.ArchivedBlockEvents{},
+		&models.SupplyDelta{},
+		&models.BlockEvidence{},
+		&models.MempoolTx{},
 	)
 }
 
@@ -133,6 +159,35 @@ func GetBlocksFromStart(db *gorm.DB, chainID uint, startHeight int64, endHeight
 	return blocks, nil
 }
 
+// GetBlockHeightsWithMessageType returns the distinct, ascending heights of indexed blocks with transactions containing the message type.
+// An endHeight of -1 leaves the range unbounded. The message type is resolved first so the scan can use the message type ID index.
+func GetBlockHeightsWithMessageType(db *gorm.DB, chainID uint, msgType string, startHeight int64, endHeight int64) ([]int64, error) {
+	var messageType models.MessageType
+	err := db.Where("message_type = ?", msgType).First(&messageType).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	query := db.Table("blocks").
+		Distinct("blocks.height").
+		Joins("JOIN txes ON txes.block_id = blocks.id").
+		Joins("JOIN messages ON messages.tx_id = txes.id").
+		Where("messages.message_type_id = ? AND blocks.chain_id = ?::int AND blocks.height >= ?", messageType.ID, chainID, startHeight)
+
+	if endHeight != -1 {
+		query = query.Where("blocks.height <= ?", endHeight)
+	}
+
+	var heights []int64
+	if err := query.Order("blocks.height asc").Pluck("blocks.height", &heights).Error; err != nil {
+		return nil, err
+	}
+
+	return heights, nil
+}
+
 func GetHighestEventIndexedBlock(db *gorm.DB, chainID uint) (models.Block, error) {
 	var block models.Block
 	// this can potentially be optimized by getting max first and selecting it (this gets translated into a select * limit 1)
@@ -145,7 +200,28 @@ func GetHighestEventIndexedBlock(db *gorm.DB, chainID uint) (models.Block, error
 	return block, err
 }
 
-func UpsertFailedBlock(db *gorm.DB, blockHeight int64, chainID string, chainName string) error {
+// FailedBlockCounter counts the failed blocks and failed event blocks recorded through it. A nil counter counts nothing.
+type FailedBlockCounter struct {
+	count atomic.Int64
+}
+
+// Add counts a recorded failed block
+func (counter *FailedBlockCounter) Add() {
+	if counter != nil {
+		counter.count.Add(1)
+	}
+}
+
+// Count returns the number of failed blocks and failed event blocks recorded through the counter
+func (counter *FailedBlockCounter) Count() int64 {
+	if counter == nil {
+		return 0
+	}
+	return counter.count.Load()
+}
+
+func UpsertFailedBlock(db *gorm.DB, failedBlocks *FailedBlockCounter, blockHeight int64, chainID string, chainName string) error {
+	failedBlocks.Add()
 	return db.Transaction(func(dbTransaction *gorm.DB) error {
 		failedBlock := models.FailedBlock{Height: blockHeight, Chain: models.Chain{ChainID: chainID, Name: chainName}}
 
@@ -162,7 +238,43 @@ func UpsertFailedBlock(db *gorm.DB, blockHeight int64, chainID string, chainName
 	})
 }
 
-func UpsertFailedEventBlock(db *gorm.DB, blockHeight int64, chainID string, chainName string) error {
+// RecordSkippedBlocks records the heights as deliberately skipped for the reason. Failed block records of the heights are
+// removed since the heights are no longer expected to be indexed.
+func RecordSkippedBlocks(db *gorm.DB, chainID uint, heights []int64, reason string) error {
+	if len(heights) == 0 {
+		return nil
+	}
+
+	skippedBlocks := make([]models.SkippedBlock, len(heights))
+	for i, height := range heights {
+		skippedBlocks[i] = models.SkippedBlock{Height: height, BlockchainID: chainID, Reason: reason}
+	}
+
+	return db.Transaction(func(dbTransaction *gorm.DB) error {
+		if err := dbTransaction.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "height"}, {Name: "blockchain_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reason"}),
+		}).Create(&skippedBlocks).Error; err != nil {
+			config.Log.Error("Error recording skipped blocks.", err)
+			return err
+		}
+
+		if err := dbTransaction.Where("blockchain_id = ? AND height IN ?", chainID, heights).Delete(&models.FailedBlock{}).Error; err != nil {
+			config.Log.Error("Error clearing failed blocks of skipped heights.", err)
+			return err
+		}
+
+		if err := dbTransaction.Where("blockchain_id = ? AND height IN ?", chainID, heights).Delete(&models.FailedEventBlock{}).Error; err != nil {
+			config.Log.Error("Error clearing failed event blocks of skipped heights.", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func UpsertFailedEventBlock(db *gorm.DB, failedBlocks *FailedBlockCounter, blockHeight int64, chainID string, chainName string) error {
+	failedBlocks.Add()
 	return db.Transaction(func(dbTransaction *gorm.DB) error {
 		failedEventBlock := models.FailedEventBlock{Height: blockHeight, Chain: models.Chain{ChainID: chainID, Name: chainName}}
 
@@ -179,11 +291,62 @@ func UpsertFailedEventBlock(db *gorm.DB, blockHeight int64, chainID string, chai
 	})
 }
 
-func IndexNewBlock(db *gorm.DB, block models.Block, txs []TxDBWrapper, indexerConfig config.IndexConfig) (models.Block, []TxDBWrapper, error) {
+// ReplacesExisting reports whether reindexed blocks replace their existing data, base.reindex in base.reindex-mode replace.
+// Blocks are only written over when reindexing, so without base.reindex there is no existing data to delete.
+func ReplacesExisting(indexerConfig config.IndexConfig) bool {
+	return indexerConfig.Base.ReIndex && indexerConfig.Base.ReIndexMode == config.ReIndexModeReplace
+}
+
+// txUpsertAssignments returns the txes columns overwritten when a stored tx is indexed again. The columns of disabled features keep
+// the value stored by a run that had them enabled, and the columns derived from the tx itself are only overwritten with a value.
+func txUpsertAssignments(indexerConfig config.IndexConfig) clause.Set {
+	columns := []string{"code", "block_id", "memo"}
+	if len(indexerConfig.Base.RowTags) != 0 {
+		columns = append(columns, "tags")
+	}
+	if indexerConfig.Base.DenormalizeBlockTime {
+		columns = append(columns, "block_time")
+	}
+	if indexerConfig.Base.IndexSignerCount {
+		columns = append(columns, "num_signers")
+	}
+	if indexerConfig.Base.IndexTxPosition {
+		columns = append(columns, "tx_index")
+	}
+
+	return append(clause.AssignmentColumns(columns), keepStoredWhenNull("txes", "timeout_height"), keepStoredWhenNull("txes", "gas_utilization"))
+}
+
+// messageUpsertAssignments returns the messages columns overwritten when a stored message is indexed again, see txUpsertAssignments
+func messageUpsertAssignments(indexerConfig config.IndexConfig) clause.Set {
+	columns := []string{"message_type_id", "message_bytes"}
+	// A message that passes validation clears the schema error of a previous run
+	if indexerConfig.Base.MessageSchemaDir != "" {
+		columns = append(columns, "schema_error")
+	}
+	if indexerConfig.Database.IndexMessageType {
+		columns = append(columns, "type_url")
+	}
+	if indexerConfig.Base.IndexMessageAction {
+		columns = append(columns, "action")
+	}
+
+	return append(clause.AssignmentColumns(columns), keepStoredWhenNull("messages", "gas_used"))
+}
+
+// keepStoredWhenNull assigns the column the inserted value, or keeps the stored value if the inserted value is NULL
+func keepStoredWhenNull(table string, column string) clause.Assignment {
+	return clause.Assignment{
+		Column: clause.Column{Name: column},
+		Value:  gorm.Expr(fmt.Sprintf("COALESCE(excluded.%s, %s.%s)", column, table, column)),
+	}
+}
+
+func IndexNewBlock(db *gorm.DB, txnLimiter *TransactionLimiter, txColumns []TxColumn, block models.Block, txs []TxDBWrapper, indexerConfig config.IndexConfig) (models.Block, []TxDBWrapper, error) {
 	// consider optimizing the transaction, but how? Ordering matters due to foreign key constraints
 	// Order required: Block -> (For each Tx: Signer Address -> Tx -> (For each Message: Message -> Taxable Events))
 	// Also, foreign key relations are struct value based so create needs to be called first to get right foreign key ID
-	err := db.Transaction(func(dbTransaction *gorm.DB) error {
+	err := blockTransaction(db, txnLimiter, func(dbTransaction *gorm.DB) error {
 		// remove from failed blocks if exists
 		if err := dbTransaction.
 			Exec("DELETE FROM failed_blocks WHERE height = ? AND blockchain_id = ?", block.Height, block.ChainID).
@@ -203,28 +366,64 @@ func IndexNewBlock(db *gorm.DB, block models.Block, txs []TxDBWrapper, indexerCo
 		block.ProposerConsAddressID = consAddress.ID
 		block.ProposerConsAddress = consAddress
 		block.TxIndexed = true
+		evidence := block.Evidence
+		decodeFailures := block.TxDecodeFailures
+		// The map assigns a false partial flag too, so a fully indexed block clears the flag of an earlier partial commit
 		if err := dbTransaction.
 			Preload("Chain").
 			Where(models.Block{Height: block.Height, ChainID: block.ChainID}).
-			Assign(models.Block{TxIndexed: true, TimeStamp: block.TimeStamp}).
+			Assign(models.Block{TxIndexed: true, TimeStamp: block.TimeStamp, Tags: block.Tags, Annotations: block.Annotations, AppHash: block.AppHash, DataHash: block.DataHash, ConsensusHash: block.ConsensusHash, SourceEndpoint: block.SourceEndpoint, GasUsed: block.GasUsed, MaxGas: block.MaxGas, TxCount: block.TxCount, FailedTxCount: block.FailedTxCount}, map[string]any{"partial": block.Partial}).
 			FirstOrCreate(&block).Error; err != nil {
 			config.Log.Error("Error getting/creating block DB object.", err)
 			return err
 		}
 
+		// When reindexing in replace mode the block's existing tx data is removed first so the new tx set fully replaces it without
+		// stale rows. This runs before any of the block's tx rows are written, the delete covers the tx decode failures too.
+		if ReplacesExisting(indexerConfig) {
+			blockIDs := dbTransaction.Model(&models.Block{}).Select("id").Where("id = ?", block.ID)
+			if err := deleteBlockTxData(dbTransaction, blockIDs); err != nil {
+				config.Log.Error("Error deleting existing tx data for reindexed block.", err)
+				return err
+			}
+		}
+
+		if err := indexBlockEvidence(dbTransaction, block, evidence); err != nil {
+			return err
+		}
+
+		if indexerConfig.Base.TolerantBlock {
+			if err := replaceTxDecodeFailures(dbTransaction, block, decodeFailures); err != nil {
+				return err
+			}
+		}
+
 		// pull txes and insert them
 		uniqueTxes := make(map[string]models.Tx)
 		uniqueAddress := make(map[string]models.Address)
 
 		denomMap := make(map[string]models.Denom)
 
+		var failedTxLogs []models.FailedTxLog
+
 		for _, tx := range txs {
+			if tx.FailedTxLog != nil {
+				failedTxLog := *tx.FailedTxLog
+				failedTxLog.BlockID = block.ID
+				failedTxLog.Height = block.Height
+				failedTxLogs = append(failedTxLogs, failedTxLog)
+			}
+
 			if !indexerConfig.Flags.IndexEmptyTransactions && len(tx.Messages) == 0 {
 				continue
 			}
 
 			tx.Tx.BlockID = block.ID
 			tx.Tx.Block = block
+			if indexerConfig.Base.DenormalizeBlockTime {
+				blockTime := block.TimeStamp
+				tx.Tx.BlockTime = &blockTime
+			}
 			uniqueTxes[tx.Tx.Hash] = tx.Tx
 			if len(tx.Tx.SignerAddresses) != 0 {
 				for _, signerAddress := range tx.Tx.SignerAddresses {
@@ -252,6 +451,16 @@ func IndexNewBlock(db *gorm.DB, block models.Block, txs []TxDBWrapper, indexerCo
 			}
 		}
 
+		if len(failedTxLogs) != 0 {
+			if err := dbTransaction.Omit(clause.Associations).Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "hash"}},
+				DoUpdates: clause.AssignmentColumns([]string{"block_id", "height", "code", "codespace", "raw_log"}),
+			}).Create(failedTxLogs).Error; err != nil {
+				config.Log.Error("Error creating failed tx logs.", err)
+				return err
+			}
+		}
+
 		var addressesSlice []models.Address
 		for _, address := range uniqueAddress {
 			addressesSlice = append(addressesSlice, address)
@@ -294,7 +503,7 @@ func IndexNewBlock(db *gorm.DB, block models.Block, txs []TxDBWrapper, indexerCo
 		if len(txesSlice) != 0 {
 			if err := dbTransaction.Clauses(clause.OnConflict{
 				Columns:   []clause.Column{{Name: "hash"}},
-				DoUpdates: clause.AssignmentColumns([]string{"code", "block_id"}),
+				DoUpdates: txUpsertAssignments(indexerConfig),
 			}).Create(txesSlice).Error; err != nil {
 				config.Log.Error("Error getting/creating txes.", err)
 				return err
@@ -305,6 +514,10 @@ func IndexNewBlock(db *gorm.DB, block models.Block, txs []TxDBWrapper, indexerCo
 			uniqueTxes[tx.Hash] = tx
 		}
 
+		if err := indexTxColumns(dbTransaction, txColumns, txs, uniqueTxes); err != nil {
+			return err
+		}
+
 		// Create unique message types and post-process them into the messages
 		fullUniqueBlockMessageTypes, err := indexMessageTypes(dbTransaction, txs)
 		if err != nil {
@@ -339,6 +552,10 @@ func IndexNewBlock(db *gorm.DB, block models.Block, txs []TxDBWrapper, indexerCo
 				tx.Messages[messageIndex].Message.MessageTypeID = fullUniqueBlockMessageTypes[tx.Messages[messageIndex].Message.MessageType.MessageType].ID
 
 				tx.Messages[messageIndex].Message.MessageType = fullUniqueBlockMessageTypes[tx.Messages[messageIndex].Message.MessageType.MessageType]
+				if indexerConfig.Database.IndexMessageType {
+					typeURL := tx.Messages[messageIndex].Message.MessageType.MessageType
+					tx.Messages[messageIndex].Message.TypeURL = &typeURL
+				}
 
 				if indexerConfig.Flags.IndexMessageEvents {
 					for eventIndex := range tx.Messages[messageIndex].MessageEvents {
@@ -362,7 +579,7 @@ func IndexNewBlock(db *gorm.DB, block models.Block, txs []TxDBWrapper, indexerCo
 			if len(messagesSlice) != 0 {
 				if err := dbTransaction.Clauses(clause.OnConflict{
 					Columns:   []clause.Column{{Name: "tx_id"}, {Name: "message_index"}},
-					DoUpdates: clause.AssignmentColumns([]string{"message_type_id", "message_bytes"}),
+					DoUpdates: messageUpsertAssignments(indexerConfig),
 				}).Create(messagesSlice).Error; err != nil {
 					config.Log.Error("Error getting/creating messages.", err)
 					return err
@@ -412,6 +629,72 @@ func IndexNewBlock(db *gorm.DB, block models.Block, txs []TxDBWrapper, indexerCo
 					}
 				}
 			}
+
+			if indexerConfig.Base.IndexAddresses {
+				if err := indexMessageAddresses(dbTransaction, block, tx); err != nil {
+					return err
+				}
+			}
+
+			if indexerConfig.Base.IndexGovernance {
+				if err := indexGovernanceMessages(dbTransaction, block, tx); err != nil {
+					return err
+				}
+			}
+
+			if indexerConfig.Base.CategoryTables {
+				if err := indexCategoryMessages(dbTransaction, block, tx); err != nil {
+					return err
+				}
+			}
+
+			if indexerConfig.Base.ResolveExecutionContext {
+				if err := indexMessageExecutionContexts(dbTransaction, block, tx); err != nil {
+					return err
+				}
+			}
+
+			if indexerConfig.Base.IndexRewards {
+				if err := indexMessageRewardEvents(dbTransaction, block, tx); err != nil {
+					return err
+				}
+			}
+
+			if indexerConfig.Base.IndexNFT {
+				if err := indexMessageNFTActivities(dbTransaction, block, tx); err != nil {
+					return err
+				}
+			}
+
+			if indexerConfig.Base.IndexBalanceDeltas {
+				if err := indexMessageBalanceDeltas(dbTransaction, block, tx); err != nil {
+					return err
+				}
+			}
+
+			if indexerConfig.Base.IndexSignerInfo {
+				if err := indexTxSignerInfos(dbTransaction, block, tx); err != nil {
+					return err
+				}
+			}
+
+			if indexerConfig.Flags.IndexTxRaw {
+				if err := indexTxRaw(dbTransaction, block, tx); err != nil {
+					return err
+				}
+			}
+		}
+
+		if indexerConfig.Base.IndexSupplyDeltas {
+			if err := indexTxSupplyDeltas(dbTransaction, block, txs); err != nil {
+				return err
+			}
+		}
+
+		if indexerConfig.Base.IndexMempool {
+			if err := reconcileMempoolTxs(dbTransaction, block, txs); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -513,8 +796,115 @@ func indexMessageEventAttributeKeys(db *gorm.DB, txs []TxDBWrapper) (map[string]
 	return fullUniqueMessageEventAttributeKeys, nil
 }
 
-func IndexCustomMessages(conf config.IndexConfig, db *gorm.DB, dryRun bool, blockDBWrapper []TxDBWrapper, messageParserTrackers map[string]models.MessageParser) error {
-	return db.Transaction(func(dbTransaction *gorm.DB) error {
+// indexMessageAddresses stores the addresses extracted from each message of the tx in the message address index
+func indexMessageAddresses(db *gorm.DB, block models.Block, tx TxDBWrapper) error {
+	uniqueAddresses := make(map[string]models.Address)
+	for _, message := range tx.Messages {
+		for _, messageAddress := range message.Addresses {
+			uniqueAddresses[messageAddress.Address] = models.Address{Address: messageAddress.Address}
+		}
+	}
+
+	if len(uniqueAddresses) == 0 {
+		return nil
+	}
+
+	var addressesSlice []models.Address
+	for _, address := range uniqueAddresses {
+		addressesSlice = append(addressesSlice, address)
+	}
+
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"address"}),
+	}).Create(addressesSlice).Error; err != nil {
+		config.Log.Error("Error getting/creating message addresses.", err)
+		return err
+	}
+
+	for _, address := range addressesSlice {
+		uniqueAddresses[address.Address] = address
+	}
+
+	var messageAddressesSlice []models.MessageAddress
+	for _, message := range tx.Messages {
+		for _, messageAddress := range message.Addresses {
+			messageAddressesSlice = append(messageAddressesSlice, models.MessageAddress{
+				Height:    block.Height,
+				TxID:      tx.Tx.ID,
+				MessageID: message.Message.ID,
+				AddressID: uniqueAddresses[messageAddress.Address].ID,
+				Role:      messageAddress.Role,
+			})
+		}
+	}
+
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_id"}, {Name: "address_id"}, {Name: "role"}},
+		DoNothing: true,
+	}).Create(messageAddressesSlice).Error; err != nil {
+		config.Log.Error("Error creating message address index.", err)
+		return err
+	}
+
+	return nil
+}
+
+// indexGovernanceMessages stores the structured governance data extracted from each message of the tx
+func indexGovernanceMessages(db *gorm.DB, block models.Block, tx TxDBWrapper) error {
+	uniqueAddresses := make(map[string]models.Address)
+	for _, message := range tx.Messages {
+		for _, governanceMessage := range message.GovernanceMessages {
+			uniqueAddresses[governanceMessage.Address.Address] = governanceMessage.Address
+		}
+	}
+
+	if len(uniqueAddresses) == 0 {
+		return nil
+	}
+
+	var addressesSlice []models.Address
+	for _, address := range uniqueAddresses {
+		addressesSlice = append(addressesSlice, address)
+	}
+
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"address"}),
+	}).Create(addressesSlice).Error; err != nil {
+		config.Log.Error("Error getting/creating governance addresses.", err)
+		return err
+	}
+
+	for _, address := range addressesSlice {
+		uniqueAddresses[address.Address] = address
+	}
+
+	var governanceMessagesSlice []models.GovernanceMessage
+	for _, message := range tx.Messages {
+		for _, governanceMessage := range message.GovernanceMessages {
+			governanceMessage.Height = block.Height
+			governanceMessage.TxID = tx.Tx.ID
+			governanceMessage.MessageID = message.Message.ID
+			governanceMessage.Address = uniqueAddresses[governanceMessage.Address.Address]
+			governanceMessage.AddressID = governanceMessage.Address.ID
+			governanceMessagesSlice = append(governanceMessagesSlice, governanceMessage)
+		}
+	}
+
+	if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_id"}, {Name: "option"}},
+		DoUpdates: clause.AssignmentColumns([]string{"proposal_id", "address_id", "weight", "amount"}),
+	}).Create(governanceMessagesSlice).Error; err != nil {
+		config.Log.Error("Error creating governance messages.", err)
+		return err
+	}
+
+	return nil
+}
+
+func IndexCustomMessages(conf config.IndexConfig, db *gorm.DB, txnLimiter *TransactionLimiter, dryRun bool, blockDBWrapper []TxDBWrapper, messageParserTrackers map[string]models.MessageParser) error {
+	return blockTransaction(db, txnLimiter, func(dbTransaction *gorm.DB) error {
 		for _, tx := range blockDBWrapper {
 			for _, message := range tx.Messages {
 				if len(message.MessageParsedDatasets) != 0 {
@@ -557,3 +947,259 @@ func IndexCustomMessages(conf config.IndexConfig, db *gorm.DB, dryRun bool, bloc
 		return nil
 	})
 }
+
+// indexMessageRewardEvents stores the reward withdrawals extracted from each message of the tx
+func indexMessageRewardEvents(db *gorm.DB, block models.Block, tx TxDBWrapper) error {
+	txID := tx.Tx.ID
+
+	var rewardEventsSlice []models.RewardEvent
+	for _, message := range tx.Messages {
+		messageID := message.Message.ID
+		for _, rewardEvent := range message.RewardEvents {
+			rewardEvent.BlockID = block.ID
+			rewardEvent.Height = block.Height
+			rewardEvent.TxID = &txID
+			rewardEvent.MessageID = &messageID
+			rewardEventsSlice = append(rewardEventsSlice, rewardEvent)
+		}
+	}
+
+	if len(rewardEventsSlice) == 0 {
+		return nil
+	}
+
+	if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "message_id"}, {Name: "event_index"}, {Name: "denom"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "message_id IS NOT NULL"}}},
+		DoUpdates:   clause.AssignmentColumns([]string{"block_id", "height", "tx_id", "type", "delegator_address", "validator_address", "amount"}),
+	}).Create(rewardEventsSlice).Error; err != nil {
+		config.Log.Error("Error creating message reward events.", err)
+		return err
+	}
+
+	return nil
+}
+
+// indexMessageNFTActivities stores the NFT mints, transfers and burns extracted from each message of the tx
+func indexMessageNFTActivities(db *gorm.DB, block models.Block, tx TxDBWrapper) error {
+	var nftActivitiesSlice []models.NFTActivity
+	for _, message := range tx.Messages {
+		for _, nftActivity := range message.NFTActivities {
+			nftActivity.Height = block.Height
+			nftActivity.TxID = tx.Tx.ID
+			nftActivity.MessageID = message.Message.ID
+			nftActivitiesSlice = append(nftActivitiesSlice, nftActivity)
+		}
+	}
+
+	if len(nftActivitiesSlice) == 0 {
+		return nil
+	}
+
+	if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_id"}, {Name: "event_index"}},
+		DoUpdates: clause.AssignmentColumns([]string{"height", "tx_id", "source", "action", "class_id", "token_id", "previous_owner", "owner"}),
+	}).Create(nftActivitiesSlice).Error; err != nil {
+		config.Log.Error("Error creating NFT activities.", err)
+		return err
+	}
+
+	return nil
+}
+
+// indexMessageBalanceDeltas stores the balance deltas extracted from each message of the tx
+func indexMessageBalanceDeltas(db *gorm.DB, block models.Block, tx TxDBWrapper) error {
+	txID := tx.Tx.ID
+
+	var balanceDeltasSlice []models.BalanceDelta
+	for _, message := range tx.Messages {
+		messageID := message.Message.ID
+		for _, balanceDelta := range message.BalanceDeltas {
+			balanceDelta.BlockID = block.ID
+			balanceDelta.Height = block.Height
+			balanceDelta.TxID = &txID
+			balanceDelta.MessageID = &messageID
+			balanceDeltasSlice = append(balanceDeltasSlice, balanceDelta)
+		}
+	}
+
+	if len(balanceDeltasSlice) == 0 {
+		return nil
+	}
+
+	if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "message_id"}, {Name: "event_index"}, {Name: "denom"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "message_id IS NOT NULL"}}},
+		DoUpdates:   clause.AssignmentColumns([]string{"block_id", "height", "tx_id", "type", "address", "amount"}),
+	}).Create(balanceDeltasSlice).Error; err != nil {
+		config.Log.Error("Error creating message balance deltas.", err)
+		return err
+	}
+
+	return nil
+}
+
+// indexMessageExecutionContexts stores the execution context chain of each message of the tx
+func indexMessageExecutionContexts(db *gorm.DB, block models.Block, tx TxDBWrapper) error {
+	var executionContextsSlice []models.MessageExecutionContext
+	for _, message := range tx.Messages {
+		for _, executionContext := range message.ExecutionContexts {
+			executionContext.Height = block.Height
+			executionContext.TxID = tx.Tx.ID
+			executionContext.MessageID = message.Message.ID
+			executionContextsSlice = append(executionContextsSlice, executionContext)
+		}
+	}
+
+	if len(executionContextsSlice) == 0 {
+		return nil
+	}
+
+	if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_id"}, {Name: "index"}},
+		DoUpdates: clause.AssignmentColumns([]string{"height", "depth", "kind", "initiator", "executed_as", "channel", "inner_message_type"}),
+	}).Create(executionContextsSlice).Error; err != nil {
+		config.Log.Error("Error creating message execution contexts.", err)
+		return err
+	}
+
+	return nil
+}
+
+// indexTxSignerInfos stores the signer public keys of the tx
+func indexTxSignerInfos(db *gorm.DB, block models.Block, tx TxDBWrapper) error {
+	if len(tx.SignerInfos) == 0 {
+		return nil
+	}
+
+	signerInfosSlice := make([]models.TxSignerInfo, len(tx.SignerInfos))
+	for i, signerInfo := range tx.SignerInfos {
+		signerInfo.TxID = tx.Tx.ID
+		signerInfo.Height = block.Height
+		signerInfosSlice[i] = signerInfo
+	}
+
+	if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tx_id"}, {Name: "signer_index"}, {Name: "key_index"}},
+		DoUpdates: clause.AssignmentColumns([]string{"height", "address", "pub_key_type", "pub_key", "sequence", "sign_mode", "multisig_threshold"}),
+	}).Create(signerInfosSlice).Error; err != nil {
+		config.Log.Error("Error creating tx signer infos.", err)
+		return err
+	}
+
+	return nil
+}
+
+// indexBlockEvidence stores the misbehavior evidence of the block. Both the tx and block event writers store it, whichever writes
+// the block first creates the rows. Nothing is written for the usual block without evidence.
+func indexBlockEvidence(db *gorm.DB, block models.Block, evidence []models.BlockEvidence) error {
+	if len(evidence) == 0 {
+		return nil
+	}
+
+	for index := range evidence {
+		evidence[index].BlockID = block.ID
+	}
+
+	if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "block_id"}, {Name: "evidence_index"}, {Name: "validator_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"height", "type", "hash", "infraction_height", "vote_a_round", "vote_b_round", "validator_power", "total_voting_power", "time"}),
+	}).Create(&evidence).Error; err != nil {
+		config.Log.Error("Error creating block evidence.", err)
+		return err
+	}
+
+	return nil
+}
+
+// replaceTxDecodeFailures replaces the undecodable tx rows of the block, so a block whose txs decode when it is reindexed has none left
+func replaceTxDecodeFailures(db *gorm.DB, block models.Block, decodeFailures []models.TxDecodeFailure) error {
+	if err := db.Where("block_id = ?", block.ID).Delete(&models.TxDecodeFailure{}).Error; err != nil {
+		config.Log.Error("Error removing previous tx decode failures.", err)
+		return err
+	}
+
+	if len(decodeFailures) == 0 {
+		return nil
+	}
+
+	for index := range decodeFailures {
+		decodeFailures[index].BlockID = block.ID
+	}
+
+	if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"block_id", "height", "error"}),
+	}).Create(&decodeFailures).Error; err != nil {
+		config.Log.Error("Error creating tx decode failures.", err)
+		return err
+	}
+
+	return nil
+}
+
+// indexTxSupplyDeltas stores the supply changes of the messages of all txs of the block, summed per denom. The previous rows of the
+// block are replaced, so a denom whose supply no longer changed when the block is reindexed has no row left.
+func indexTxSupplyDeltas(db *gorm.DB, block models.Block, txs []TxDBWrapper) error {
+	deltas := make(map[string]*models.SupplyDelta)
+	var denoms []string
+	for _, tx := range txs {
+		for _, message := range tx.Messages {
+			for _, supplyDelta := range message.SupplyDeltas {
+				if delta, ok := deltas[supplyDelta.Denom]; ok {
+					delta.Add(supplyDelta)
+					continue
+				}
+				supplyDelta.BlockID = block.ID
+				supplyDelta.Height = block.Height
+				supplyDelta.Source = models.SupplyDeltaSourceTxs
+				deltas[supplyDelta.Denom] = &supplyDelta
+				denoms = append(denoms, supplyDelta.Denom)
+			}
+		}
+	}
+
+	supplyDeltasSlice := make([]models.SupplyDelta, len(denoms))
+	for i, denom := range denoms {
+		supplyDeltasSlice[i] = *deltas[denom]
+	}
+
+	return replaceSupplyDeltas(db, block.ID, []string{models.SupplyDeltaSourceTxs}, supplyDeltasSlice)
+}
+
+// replaceSupplyDeltas replaces the supply delta rows of the sources of the block
+func replaceSupplyDeltas(db *gorm.DB, blockID uint, sources []string, supplyDeltasSlice []models.SupplyDelta) error {
+	if err := db.Where("block_id = ? AND source IN ?", blockID, sources).Delete(&models.SupplyDelta{}).Error; err != nil {
+		config.Log.Error("Error removing previous supply deltas.", err)
+		return err
+	}
+
+	if len(supplyDeltasSlice) == 0 {
+		return nil
+	}
+
+	if err := db.Omit(clause.Associations).Create(supplyDeltasSlice).Error; err != nil {
+		config.Log.Error("Error creating supply deltas.", err)
+		return err
+	}
+
+	return nil
+}
+
+// indexTxRaw stores the raw bytes of the tx
+func indexTxRaw(db *gorm.DB, block models.Block, tx TxDBWrapper) error {
+	if tx.Raw == nil {
+		return nil
+	}
+
+	txRaw := models.TxRaw{Hash: tx.Tx.Hash, TxID: tx.Tx.ID, Height: block.Height, Raw: tx.Raw}
+	if err := db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"tx_id", "height", "raw"}),
+	}).Create(&txRaw).Error; err != nil {
+		config.Log.Error("Error creating raw tx.", err)
+		return err
+	}
+
+	return nil
+}
@@ -1,12 +1,24 @@
 package db
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/DefiantLabs/cosmos-indexer/config"
 	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/parsers"
+	sdkTypes "github.com/cosmos/cosmos-sdk/types"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/ory/dockertest/v3"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
 )
@@ -158,6 +170,1285 @@ func (suite *DBTestSuite) TestGetHighestBlockFunctions() {
 	suite.Assert().Equal(block3.Height, eventBlock.Height)
 }
 
+func (suite *DBTestSuite) TestIndexNewBlockRowTags() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{
+		ChainID: "testchain-1",
+	}
+
+	err = suite.db.Create(&initChain).Error
+	suite.Require().NoError(err)
+
+	conf := config.IndexConfig{}
+	conf.Base.RowTags = map[string]string{"env": "testnet"}
+
+	block := models.Block{
+		Height:              1,
+		ChainID:             initChain.ID,
+		TimeStamp:           time.Now(),
+		ProposerConsAddress: models.Address{Address: "testchainaddress"},
+		Tags:                conf.Base.RowTags,
+	}
+
+	txs := []TxDBWrapper{
+		{
+			Tx: models.Tx{Hash: "TESTHASH", Tags: conf.Base.RowTags},
+		},
+	}
+
+	conf.Flags.IndexEmptyTransactions = true
+
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, txs, conf)
+	suite.Require().NoError(err)
+
+	var storedBlock models.Block
+	err = suite.db.Where("height = ? AND chain_id = ?", 1, initChain.ID).First(&storedBlock).Error
+	suite.Require().NoError(err)
+	suite.Assert().Equal("testnet", storedBlock.Tags["env"])
+
+	var storedTx models.Tx
+	err = suite.db.Where("hash = ?", "TESTHASH").First(&storedTx).Error
+	suite.Require().NoError(err)
+	suite.Assert().Equal("testnet", storedTx.Tags["env"])
+}
+
+func (suite *DBTestSuite) TestIndexNewBlockDenormalizeBlockTime() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	conf := config.IndexConfig{}
+	conf.Base.DenormalizeBlockTime = true
+	conf.Flags.IndexEmptyTransactions = true
+
+	block := models.Block{
+		Height:              1,
+		ChainID:             initChain.ID,
+		TimeStamp:           time.Date(2024, 3, 1, 12, 30, 15, 0, time.UTC),
+		ProposerConsAddress: models.Address{Address: "testchainaddress"},
+	}
+
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{{Tx: models.Tx{Hash: "TESTHASH"}}}, conf)
+	suite.Require().NoError(err)
+
+	var storedBlock models.Block
+	suite.Require().NoError(suite.db.Where("height = ? AND chain_id = ?", 1, initChain.ID).First(&storedBlock).Error)
+
+	var storedTx models.Tx
+	suite.Require().NoError(suite.db.Where("hash = ?", "TESTHASH").First(&storedTx).Error)
+	suite.Require().NotNil(storedTx.BlockTime)
+	suite.Assert().True(storedBlock.TimeStamp.Equal(*storedTx.BlockTime))
+	suite.Assert().Equal(storedBlock.ID, storedTx.BlockID)
+}
+
+func (suite *DBTestSuite) TestIndexNewBlockUpdatesMemo() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	conf := config.IndexConfig{}
+	block := models.Block{
+		Height:              1,
+		ChainID:             initChain.ID,
+		ProposerConsAddress: models.Address{Address: "testchainaddress"},
+	}
+
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{{Tx: models.Tx{Hash: "TESTHASH", Memo: "full memo"}}}, conf)
+	suite.Require().NoError(err)
+
+	// Re-indexing with a lower base.max-memo-bytes replaces the stored memo
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{{Tx: models.Tx{Hash: "TESTHASH", Memo: "full"}}}, conf)
+	suite.Require().NoError(err)
+
+	var storedTx models.Tx
+	suite.Require().NoError(suite.db.Where("hash = ?", "TESTHASH").First(&storedTx).Error)
+	suite.Assert().Equal("full", storedTx.Memo)
+}
+
+func (suite *DBTestSuite) TestIndexNewBlockKeepsDisabledFeatureColumns() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	block := models.Block{
+		Height:              1,
+		ChainID:             initChain.ID,
+		TimeStamp:           time.Now(),
+		ProposerConsAddress: models.Address{Address: "testchainaddress"},
+	}
+
+	numSigners := 2
+	timeoutHeight := uint64(100)
+	conf := config.IndexConfig{}
+	conf.Flags.IndexEmptyTransactions = true
+	conf.Base.IndexSignerCount = true
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{{Tx: models.Tx{Hash: "TESTHASH", NumSigners: &numSigners, TimeoutHeight: &timeoutHeight}}}, conf)
+	suite.Require().NoError(err)
+
+	// Re-indexing without base.index-signer-count and without the derived timeout keeps the stored values
+	conf.Base.IndexSignerCount = false
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{{Tx: models.Tx{Hash: "TESTHASH"}}}, conf)
+	suite.Require().NoError(err)
+
+	var storedTx models.Tx
+	suite.Require().NoError(suite.db.Where("hash = ?", "TESTHASH").First(&storedTx).Error)
+	suite.Require().NotNil(storedTx.NumSigners)
+	suite.Equal(2, *storedTx.NumSigners)
+	suite.Require().NotNil(storedTx.TimeoutHeight)
+	suite.Equal(uint64(100), *storedTx.TimeoutHeight)
+}
+
+func (suite *DBTestSuite) TestRedactors() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	digits := regexp.MustCompile(`[0-9]`)
+	var secondSaw []string
+	err = UseRedactors(suite.db, []Redactor{
+		func(record any) any {
+			if tx, ok := record.(*models.Tx); ok {
+				tx.Memo = digits.ReplaceAllString(tx.Memo, "#")
+			}
+			return record
+		},
+		// Redactors are chained, the second one receives the output of the first
+		func(record any) any {
+			if tx, ok := record.(*models.Tx); ok {
+				secondSaw = append(secondSaw, tx.Memo)
+				redacted := *tx
+				redacted.Memo = "memo: " + tx.Memo
+				return redacted
+			}
+			return record
+		},
+	})
+	suite.Require().NoError(err)
+
+	conf := config.IndexConfig{}
+	conf.Flags.IndexEmptyTransactions = true
+
+	block := models.Block{
+		Height:              1,
+		ChainID:             initChain.ID,
+		TimeStamp:           time.Now(),
+		ProposerConsAddress: models.Address{Address: "testchainaddress"},
+	}
+
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{{Tx: models.Tx{Hash: "TESTHASH", Memo: "ssn 123-45-6789"}}}, conf)
+	suite.Require().NoError(err)
+
+	var storedTx models.Tx
+	suite.Require().NoError(suite.db.Where("hash = ?", "TESTHASH").First(&storedTx).Error)
+	suite.Assert().Equal("memo: ssn ###-##-####", storedTx.Memo)
+	suite.Assert().Equal([]string{"ssn ###-##-####"}, secondSaw)
+
+	// Columns updated in place are redacted too
+	suite.Require().NoError(suite.db.Model(&models.Tx{}).Where("hash = ?", "TESTHASH").Update("memo", "call 555").Error)
+	suite.Require().NoError(suite.db.Where("hash = ?", "TESTHASH").First(&storedTx).Error)
+	suite.Assert().Equal("memo: call ###", storedTx.Memo)
+}
+
+func (suite *DBTestSuite) TestBlockChecksums() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	conf := config.IndexConfig{}
+	conf.Flags.IndexEmptyTransactions = true
+
+	// indexRun indexes the txs and block events of the block as the DB worker does and returns the stored checksum
+	indexRun := func() string {
+		block := models.Block{
+			Height:              1,
+			ChainID:             initChain.ID,
+			TimeStamp:           time.Now(),
+			ProposerConsAddress: models.Address{Address: "testchainaddress"},
+		}
+		txs := []TxDBWrapper{
+			{Tx: models.Tx{Hash: "TESTHASH1", Memo: "first"}},
+			{Tx: models.Tx{Hash: "TESTHASH2", Code: 5}},
+		}
+
+		indexedBlock, _, err := IndexNewBlock(suite.db, nil, nil, block, txs, conf)
+		suite.Require().NoError(err)
+		suite.Require().NoError(UpdateBlockChecksum(suite.db, indexedBlock.ID))
+
+		indexedEvents, err := IndexBlockEvents(suite.db, nil, false, false, mockBlockEventsWrapper(initChain, 1), "block 1")
+		suite.Require().NoError(err)
+		suite.Require().NoError(UpdateBlockChecksum(suite.db, indexedEvents.Block.ID))
+
+		var storedBlock models.Block
+		suite.Require().NoError(suite.db.First(&storedBlock, indexedBlock.ID).Error)
+		suite.Require().NotNil(storedBlock.Checksum)
+		return *storedBlock.Checksum
+	}
+
+	firstRun := indexRun()
+	secondRun := indexRun()
+	suite.Assert().Equal(firstRun, secondRun)
+
+	verified, mismatches, err := VerifyBlockChecksums(suite.db, initChain.ID, 100, func(BlockChecksumMismatch) {})
+	suite.Require().NoError(err)
+	suite.Assert().Equal(int64(1), verified)
+	suite.Assert().Zero(mismatches)
+
+	// Changing a stored row is detected
+	suite.Require().NoError(suite.db.Model(&models.Tx{}).Where("hash = ?", "TESTHASH1").Update("memo", "tampered").Error)
+
+	var reported []BlockChecksumMismatch
+	_, mismatches, err = VerifyBlockChecksums(suite.db, initChain.ID, 100, func(mismatch BlockChecksumMismatch) {
+		reported = append(reported, mismatch)
+	})
+	suite.Require().NoError(err)
+	suite.Assert().Equal(int64(1), mismatches)
+	suite.Require().Len(reported, 1)
+	suite.Assert().Equal(int64(1), reported[0].Height)
+	suite.Assert().Equal(firstRun, reported[0].Stored)
+}
+
+func (suite *DBTestSuite) TestVerifyBlockTxs() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	conf := config.IndexConfig{}
+	conf.Flags.IndexEmptyTransactions = true
+
+	sendType := models.MessageType{MessageType: "/cosmos.bank.v1beta1.MsgSend"}
+	// decoded returns the txs of the block as freshly decoded from the node
+	decoded := func() []TxDBWrapper {
+		return []TxDBWrapper{
+			{
+				Tx:                 models.Tx{Hash: "TESTHASH1", Memo: "first", Fees: []models.Fee{{Amount: decimal.NewFromInt(5000), Denomination: models.Denom{Base: "uatom"}, PayerAddress: models.Address{Address: "cosmos1payer"}}}},
+				Messages:           []MessageDBWrapper{{Message: models.Message{MessageType: sendType}}},
+				UniqueMessageTypes: map[string]models.MessageType{sendType.MessageType: sendType},
+			},
+			{Tx: models.Tx{Hash: "TESTHASH2", Code: 5}},
+		}
+	}
+
+	block := models.Block{Height: 1, ChainID: initChain.ID, TimeStamp: time.Now(), ProposerConsAddress: models.Address{Address: "testchainaddress"}}
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, decoded(), conf)
+	suite.Require().NoError(err)
+
+	mismatches, err := VerifyBlockTxs(suite.db, initChain.ID, 1, decoded(), true)
+	suite.Require().NoError(err)
+	suite.Assert().Empty(mismatches)
+
+	// A deliberately wrong row is flagged
+	suite.Require().NoError(suite.db.Model(&models.Tx{}).Where("hash = ?", "TESTHASH2").Update("code", 0).Error)
+
+	mismatches, err = VerifyBlockTxs(suite.db, initChain.ID, 1, decoded(), true)
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]TxMismatch{{Height: 1, TxHash: "TESTHASH2", Field: "code", Stored: "0", Fetched: "5"}}, mismatches)
+
+	// Blocks that were never indexed are reported
+	mismatches, err = VerifyBlockTxs(suite.db, initChain.ID, 2, nil, true)
+	suite.Require().NoError(err)
+	suite.Require().Len(mismatches, 1)
+	suite.Assert().Equal("block", mismatches[0].Field)
+}
+
 func TestDBSuite(t *testing.T) {
 	suite.Run(t, new(DBTestSuite))
 }
+
+func (suite *DBTestSuite) TestPruneBelow() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{
+		ChainID: "testchain-1",
+	}
+
+	err = suite.db.Create(&initChain).Error
+	suite.Require().NoError(err)
+
+	initConsAddress := models.Address{
+		Address: "testchainaddress",
+	}
+
+	err = suite.db.Create(&initConsAddress).Error
+	suite.Require().NoError(err)
+
+	var blocks []models.Block
+	for height := int64(1); height <= 3; height++ {
+		block, err := createMockBlock(suite.db, initChain, initConsAddress, height, true, true)
+		suite.Require().NoError(err)
+		blocks = append(blocks, block)
+	}
+
+	for _, block := range blocks {
+		tx := models.Tx{Hash: fmt.Sprintf("TESTHASH%d", block.Height), BlockID: block.ID, SignerAddresses: []models.Address{initConsAddress}}
+		err = suite.db.Create(&tx).Error
+		suite.Require().NoError(err)
+
+		message := models.Message{TxID: tx.ID, MessageType: models.MessageType{MessageType: fmt.Sprintf("/test.Msg%d", block.Height)}}
+		err = suite.db.Create(&message).Error
+		suite.Require().NoError(err)
+
+		blockEvent := models.BlockEvent{BlockID: block.ID, BlockEventType: models.BlockEventType{Type: fmt.Sprintf("test%d", block.Height)}}
+		err = suite.db.Create(&blockEvent).Error
+		suite.Require().NoError(err)
+	}
+
+	// Pruning above the resume cursor must be forced
+	_, err = PruneBelow(suite.db, initChain.ID, 4, false)
+	suite.Require().Error(err)
+
+	prunedBlocks, err := PruneBelow(suite.db, initChain.ID, 3, false)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(int64(2), prunedBlocks)
+
+	resumeBlock := GetHighestIndexedBlock(suite.db, initChain.ID)
+	suite.Assert().Equal(int64(3), resumeBlock.Height)
+
+	var remainingBlocks, remainingTxs, remainingMessages, remainingBlockEvents int64
+	suite.Require().NoError(suite.db.Model(&models.Block{}).Count(&remainingBlocks).Error)
+	suite.Require().NoError(suite.db.Model(&models.Tx{}).Count(&remainingTxs).Error)
+	suite.Require().NoError(suite.db.Model(&models.Message{}).Count(&remainingMessages).Error)
+	suite.Require().NoError(suite.db.Model(&models.BlockEvent{}).Count(&remainingBlockEvents).Error)
+
+	suite.Assert().Equal(int64(1), remainingBlocks)
+	suite.Assert().Equal(int64(1), remainingTxs)
+	suite.Assert().Equal(int64(1), remainingMessages)
+	suite.Assert().Equal(int64(1), remainingBlockEvents)
+}
+
+func (suite *DBTestSuite) TestGetBlockHeightsWithMessageType() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{
+		ChainID: "testchain-1",
+	}
+
+	err = suite.db.Create(&initChain).Error
+	suite.Require().NoError(err)
+
+	initConsAddress := models.Address{
+		Address: "testchainaddress",
+	}
+
+	err = suite.db.Create(&initConsAddress).Error
+	suite.Require().NoError(err)
+
+	sendType := models.MessageType{MessageType: "/cosmos.bank.v1beta1.MsgSend"}
+	voteType := models.MessageType{MessageType: "/cosmos.gov.v1beta1.MsgVote"}
+	suite.Require().NoError(suite.db.Create(&sendType).Error)
+	suite.Require().NoError(suite.db.Create(&voteType).Error)
+
+	messageTypesByHeight := map[int64][]models.MessageType{
+		1: {sendType},
+		2: {voteType},
+		3: {sendType, sendType},
+	}
+
+	for height := int64(1); height <= 3; height++ {
+		block, err := createMockBlock(suite.db, initChain, initConsAddress, height, true, true)
+		suite.Require().NoError(err)
+
+		tx := models.Tx{Hash: fmt.Sprintf("TESTHASH%d", height), BlockID: block.ID}
+		suite.Require().NoError(suite.db.Create(&tx).Error)
+
+		for messageIndex, messageType := range messageTypesByHeight[height] {
+			message := models.Message{TxID: tx.ID, MessageTypeID: messageType.ID, MessageIndex: messageIndex}
+			suite.Require().NoError(suite.db.Create(&message).Error)
+		}
+	}
+
+	// Open-ended range scans to the highest block, each height is returned once
+	heights, err := GetBlockHeightsWithMessageType(suite.db, initChain.ID, sendType.MessageType, 1, -1)
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]int64{1, 3}, heights)
+
+	heights, err = GetBlockHeightsWithMessageType(suite.db, initChain.ID, sendType.MessageType, 2, -1)
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]int64{3}, heights)
+
+	heights, err = GetBlockHeightsWithMessageType(suite.db, initChain.ID, sendType.MessageType, 1, 2)
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]int64{1}, heights)
+
+	heights, err = GetBlockHeightsWithMessageType(suite.db, initChain.ID, "/unknown.Msg", 1, -1)
+	suite.Require().NoError(err)
+	suite.Assert().Empty(heights)
+}
+
+func (suite *DBTestSuite) TestCreateViews() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	err = CreateViews(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{
+		ChainID: "testchain-1",
+	}
+
+	err = suite.db.Create(&initChain).Error
+	suite.Require().NoError(err)
+
+	payer := models.Address{
+		Address: "testchainaddress",
+	}
+
+	err = suite.db.Create(&payer).Error
+	suite.Require().NoError(err)
+
+	block, err := createMockBlock(suite.db, initChain, payer, 1, true, true)
+	suite.Require().NoError(err)
+
+	tx := models.Tx{
+		Hash:    "TESTHASH",
+		BlockID: block.ID,
+		Fees: []models.Fee{
+			{Amount: decimal.NewFromInt(5000), Denomination: models.Denom{Base: "uatom"}, PayerAddressID: payer.ID},
+		},
+	}
+	suite.Require().NoError(suite.db.Create(&tx).Error)
+
+	message := models.Message{TxID: tx.ID, MessageType: models.MessageType{MessageType: "/cosmos.bank.v1beta1.MsgSend"}}
+	suite.Require().NoError(suite.db.Create(&message).Error)
+
+	event := models.MessageEvent{MessageID: message.ID, MessageEventType: models.MessageEventType{Type: "transfer"}}
+	suite.Require().NoError(suite.db.Create(&event).Error)
+
+	for index, attribute := range [][2]string{{"recipient", "recipientaddress"}, {"sender", "senderaddress"}, {"amount", "100uatom"}} {
+		eventAttribute := models.MessageEventAttribute{
+			MessageEventID:           event.ID,
+			Index:                    uint64(index),
+			Value:                    attribute[1],
+			MessageEventAttributeKey: models.MessageEventAttributeKey{Key: attribute[0]},
+		}
+		suite.Require().NoError(suite.db.Create(&eventAttribute).Error)
+	}
+
+	var feeRows []struct {
+		TxHash    string
+		Height    int64
+		FeeAmount decimal.Decimal
+		FeeDenom  string
+		FeePayer  string
+	}
+	err = suite.db.Raw("SELECT tx_hash, height, fee_amount, fee_denom, fee_payer FROM v_transactions_with_fees").Scan(&feeRows).Error
+	suite.Require().NoError(err)
+	suite.Require().Len(feeRows, 1)
+	suite.Assert().Equal("TESTHASH", feeRows[0].TxHash)
+	suite.Assert().Equal(int64(1), feeRows[0].Height)
+	suite.Assert().True(decimal.NewFromInt(5000).Equal(feeRows[0].FeeAmount))
+	suite.Assert().Equal("uatom", feeRows[0].FeeDenom)
+	suite.Assert().Equal(payer.Address, feeRows[0].FeePayer)
+
+	var transferRows []struct {
+		TxHash    string
+		Sender    string
+		Recipient string
+		Amount    string
+	}
+	err = suite.db.Raw("SELECT tx_hash, sender, recipient, amount FROM v_transfers").Scan(&transferRows).Error
+	suite.Require().NoError(err)
+	suite.Require().Len(transferRows, 1)
+	suite.Assert().Equal("TESTHASH", transferRows[0].TxHash)
+	suite.Assert().Equal("senderaddress", transferRows[0].Sender)
+	suite.Assert().Equal("recipientaddress", transferRows[0].Recipient)
+	suite.Assert().Equal("100uatom", transferRows[0].Amount)
+}
+
+func (suite *DBTestSuite) TestIndexNewBlockReplaceReIndex() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{
+		ChainID: "testchain-1",
+	}
+
+	err = suite.db.Create(&initChain).Error
+	suite.Require().NoError(err)
+
+	conf := config.IndexConfig{}
+	conf.Base.ReIndex = true
+	conf.Base.ReIndexMode = config.ReIndexModeReplace
+	conf.Flags.IndexEmptyTransactions = true
+	conf.Flags.IndexMessageEvents = true
+
+	block := models.Block{
+		Height:              1,
+		ChainID:             initChain.ID,
+		TimeStamp:           time.Now(),
+		ProposerConsAddress: models.Address{Address: "testchainaddress"},
+	}
+
+	mockTx := func(hash string) TxDBWrapper {
+		return TxDBWrapper{
+			Tx: models.Tx{Hash: hash},
+			Messages: []MessageDBWrapper{
+				{
+					Message: models.Message{MessageIndex: 0, MessageType: models.MessageType{MessageType: "/cosmos.bank.v1beta1.MsgSend"}},
+					MessageEvents: []MessageEventDBWrapper{
+						{MessageEvent: models.MessageEvent{Index: 0, MessageEventType: models.MessageEventType{Type: "transfer"}}},
+					},
+				},
+			},
+		}
+	}
+
+	_, indexedTxs, err := IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{mockTx("TESTHASH1"), mockTx("TESTHASH2")}, conf)
+	suite.Require().NoError(err)
+
+	// A custom parser table referencing the messages must not block the replace
+	suite.Require().NoError(suite.db.Exec("CREATE TABLE custom_parsed_messages (id SERIAL PRIMARY KEY, message_id BIGINT NOT NULL REFERENCES messages(id))").Error)
+	suite.Require().NoError(suite.db.Exec("INSERT INTO custom_parsed_messages (message_id) VALUES (?)", indexedTxs[0].Messages[0].Message.ID).Error)
+
+	// The reindexed block has a different tx set
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{mockTx("TESTHASH3")}, conf)
+	suite.Require().NoError(err)
+
+	var customRows int64
+	suite.Require().NoError(suite.db.Table("custom_parsed_messages").Count(&customRows).Error)
+	suite.Assert().Zero(customRows)
+
+	var hashes []string
+	err = suite.db.Model(&models.Tx{}).Order("hash").Pluck("hash", &hashes).Error
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]string{"TESTHASH3"}, hashes)
+
+	var messageCount, messageEventCount, blockCount int64
+	suite.Require().NoError(suite.db.Model(&models.Message{}).Count(&messageCount).Error)
+	suite.Require().NoError(suite.db.Model(&models.MessageEvent{}).Count(&messageEventCount).Error)
+	suite.Require().NoError(suite.db.Model(&models.Block{}).Count(&blockCount).Error)
+	suite.Assert().Equal(int64(1), messageCount)
+	suite.Assert().Equal(int64(1), messageEventCount)
+	suite.Assert().Equal(int64(1), blockCount)
+
+	// Without base.reindex nothing is replaced, the block only gets new rows
+	conf.Base.ReIndex = false
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{mockTx("TESTHASH4")}, conf)
+	suite.Require().NoError(err)
+
+	err = suite.db.Model(&models.Tx{}).Order("hash").Pluck("hash", &hashes).Error
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]string{"TESTHASH3", "TESTHASH4"}, hashes)
+}
+
+func (suite *DBTestSuite) TestIndexBlockEventsReplace() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	indexed, err := IndexBlockEvents(suite.db, nil, false, true, mockBlockEventsWrapper(initChain, 1), "block 1")
+	suite.Require().NoError(err)
+
+	// A custom parser table referencing the block events must not block the replace
+	suite.Require().NoError(suite.db.Exec("CREATE TABLE custom_parsed_block_events (id SERIAL PRIMARY KEY, block_event_id BIGINT NOT NULL REFERENCES block_events(id))").Error)
+	suite.Require().NoError(suite.db.Exec("INSERT INTO custom_parsed_block_events (block_event_id) VALUES (?)", indexed.BeginBlockEvents[1].BlockEvent.ID).Error)
+
+	// The reindexed block has a single begin block event
+	wrapper := mockBlockEventsWrapper(initChain, 1)
+	wrapper.BeginBlockEvents = wrapper.BeginBlockEvents[:1]
+	wrapper.EndBlockEvents = nil
+	_, err = IndexBlockEvents(suite.db, nil, false, true, wrapper, "block 1")
+	suite.Require().NoError(err)
+
+	var eventCount, attributeCount, customRows int64
+	suite.Require().NoError(suite.db.Model(&models.BlockEvent{}).Count(&eventCount).Error)
+	suite.Require().NoError(suite.db.Model(&models.BlockEventAttribute{}).Count(&attributeCount).Error)
+	suite.Require().NoError(suite.db.Table("custom_parsed_block_events").Count(&customRows).Error)
+	suite.Assert().Equal(int64(1), eventCount)
+	suite.Assert().Equal(int64(2), attributeCount)
+	suite.Assert().Zero(customRows)
+}
+
+func (suite *DBTestSuite) TestIndexNewBlockTolerantBlock() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{
+		ChainID: "testchain-1",
+	}
+
+	err = suite.db.Create(&initChain).Error
+	suite.Require().NoError(err)
+
+	conf := config.IndexConfig{}
+	conf.Base.TolerantBlock = true
+	conf.Flags.IndexEmptyTransactions = true
+
+	block := models.Block{
+		Height:              1,
+		ChainID:             initChain.ID,
+		TimeStamp:           time.Now(),
+		ProposerConsAddress: models.Address{Address: "testchainaddress"},
+		Partial:             true,
+		TxDecodeFailures:    []models.TxDecodeFailure{{Height: 1, Hash: "UNDECODABLE", Error: "unable to resolve type URL"}},
+	}
+
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{{Tx: models.Tx{Hash: "TESTHASH1"}}, {Tx: models.Tx{Hash: "TESTHASH3"}}}, conf)
+	suite.Require().NoError(err)
+
+	var storedBlock models.Block
+	suite.Require().NoError(suite.db.Where("height = ?", 1).First(&storedBlock).Error)
+	suite.Assert().True(storedBlock.Partial)
+
+	var failures []models.TxDecodeFailure
+	suite.Require().NoError(suite.db.Find(&failures).Error)
+	suite.Require().Len(failures, 1)
+	suite.Assert().Equal("UNDECODABLE", failures[0].Hash)
+	suite.Assert().Equal(storedBlock.ID, failures[0].BlockID)
+
+	var txCount int64
+	suite.Require().NoError(suite.db.Model(&models.Tx{}).Count(&txCount).Error)
+	suite.Assert().Equal(int64(2), txCount)
+
+	// Reindexed once every tx decodes, the block is no longer partial
+	block.Partial = false
+	block.TxDecodeFailures = nil
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{{Tx: models.Tx{Hash: "TESTHASH1"}}, {Tx: models.Tx{Hash: "UNDECODABLE"}}, {Tx: models.Tx{Hash: "TESTHASH3"}}}, conf)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.db.Where("height = ?", 1).First(&storedBlock).Error)
+	suite.Assert().False(storedBlock.Partial)
+
+	var failureCount int64
+	suite.Require().NoError(suite.db.Model(&models.TxDecodeFailure{}).Count(&failureCount).Error)
+	suite.Assert().Zero(failureCount)
+}
+
+func (suite *DBTestSuite) TestSnapshotRestore() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{
+		ChainID: "testchain-1",
+	}
+
+	err = suite.db.Create(&initChain).Error
+	suite.Require().NoError(err)
+
+	initConsAddress := models.Address{
+		Address: "testchainaddress",
+	}
+
+	err = suite.db.Create(&initConsAddress).Error
+	suite.Require().NoError(err)
+
+	for height := int64(1); height <= 3; height++ {
+		block, err := createMockBlock(suite.db, initChain, initConsAddress, height, true, true)
+		suite.Require().NoError(err)
+
+		tx := models.Tx{Hash: fmt.Sprintf("TESTHASH%d", block.Height), BlockID: block.ID, SignerAddresses: []models.Address{initConsAddress}}
+		err = suite.db.Create(&tx).Error
+		suite.Require().NoError(err)
+
+		message := models.Message{TxID: tx.ID, MessageType: models.MessageType{MessageType: fmt.Sprintf("/test.Msg%d", block.Height)}}
+		err = suite.db.Create(&message).Error
+		suite.Require().NoError(err)
+	}
+
+	var snapshot bytes.Buffer
+	written, err := Snapshot(suite.db, &snapshot)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(int64(3), written["blocks"])
+
+	cleanRestore, restoreDB, err := SetupTestDatabase()
+	suite.Require().NoError(err)
+	defer cleanRestore()
+
+	err = MigrateModels(restoreDB)
+	suite.Require().NoError(err)
+
+	restored, err := Restore(restoreDB, &snapshot)
+	suite.Require().NoError(err)
+
+	for table, count := range written {
+		var restoredCount int64
+		suite.Require().NoError(restoreDB.Table(table).Count(&restoredCount).Error)
+		suite.Assert().Equal(count, restoredCount, table)
+		suite.Assert().Equal(count, restored[table], table)
+	}
+
+	// Sequences must be moved past the restored rows
+	block, err := createMockBlock(restoreDB, initChain, initConsAddress, 4, true, true)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(uint(4), block.ID)
+}
+
+func (suite *DBTestSuite) TestIndexMessageType() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+	suite.Require().NoError(CreateMessageTypeIndex(suite.db))
+	suite.Require().True(suite.db.Migrator().HasIndex(&models.Message{}, messageTypeURLIndex))
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	conf := config.IndexConfig{}
+	conf.Database.IndexMessageType = true
+
+	sendType := models.MessageType{MessageType: "/cosmos.bank.v1beta1.MsgSend"}
+	block := models.Block{Height: 1, ChainID: initChain.ID, TimeStamp: time.Now(), ProposerConsAddress: models.Address{Address: "testchainaddress"}}
+	tx := TxDBWrapper{
+		Tx:                 models.Tx{Hash: "TESTHASH1"},
+		Messages:           []MessageDBWrapper{{Message: models.Message{MessageType: sendType}}},
+		UniqueMessageTypes: map[string]models.MessageType{sendType.MessageType: sendType},
+	}
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{tx}, conf)
+	suite.Require().NoError(err)
+
+	var message models.Message
+	suite.Require().NoError(suite.db.First(&message).Error)
+	suite.Require().NotNil(message.TypeURL)
+	suite.Assert().Equal(sendType.MessageType, *message.TypeURL)
+
+	// The table is tiny, sequential scans are disabled so the planner picks the index whenever it can be used
+	var plan []string
+	err = suite.db.Transaction(func(dbTransaction *gorm.DB) error {
+		if err := dbTransaction.Exec("SET LOCAL enable_seqscan = off").Error; err != nil {
+			return err
+		}
+		return dbTransaction.Raw("EXPLAIN SELECT messages.id FROM messages JOIN txes ON txes.id = messages.tx_id WHERE messages.type_url = ?", sendType.MessageType).Scan(&plan).Error
+	})
+	suite.Require().NoError(err)
+	suite.Assert().Contains(strings.Join(plan, "\n"), messageTypeURLIndex)
+}
+
+func (suite *DBTestSuite) TestCategoryTables() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	conf := config.IndexConfig{}
+	conf.Base.CategoryTables = true
+
+	sendType := models.MessageType{MessageType: "/cosmos.bank.v1beta1.MsgSend"}
+	delegateType := models.MessageType{MessageType: "/cosmos.staking.v1beta1.MsgDelegate"}
+	block := models.Block{Height: 1, ChainID: initChain.ID, TimeStamp: time.Now(), ProposerConsAddress: models.Address{Address: "testchainaddress"}}
+	tx := TxDBWrapper{
+		Tx: models.Tx{Hash: "TESTHASH1"},
+		Messages: []MessageDBWrapper{
+			{Message: models.Message{MessageIndex: 0, MessageType: sendType}},
+			{Message: models.Message{MessageIndex: 1, MessageType: delegateType}},
+		},
+		UniqueMessageTypes: map[string]models.MessageType{sendType.MessageType: sendType, delegateType.MessageType: delegateType},
+	}
+	_, _, err = IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{tx}, conf)
+	suite.Require().NoError(err)
+
+	var bankMessages []models.BankMessage
+	suite.Require().NoError(suite.db.Find(&bankMessages).Error)
+	suite.Require().Len(bankMessages, 1)
+	suite.Assert().Equal(0, bankMessages[0].MessageIndex)
+	suite.Assert().Equal(int64(1), bankMessages[0].Height)
+
+	var stakingMessages []models.StakingMessage
+	suite.Require().NoError(suite.db.Find(&stakingMessages).Error)
+	suite.Require().Len(stakingMessages, 1)
+	suite.Assert().Equal(1, stakingMessages[0].MessageIndex)
+	suite.Assert().NotEqual(bankMessages[0].MessageID, stakingMessages[0].MessageID)
+
+	var otherMessages int64
+	suite.Require().NoError(suite.db.Model(&models.OtherMessage{}).Count(&otherMessages).Error)
+	suite.Assert().Zero(otherMessages)
+
+	// Every message is still in the messages table
+	var messages int64
+	suite.Require().NoError(suite.db.Model(&models.Message{}).Count(&messages).Error)
+	suite.Assert().Equal(int64(2), messages)
+}
+
+func (suite *DBTestSuite) TestRetryCommitAfterDroppedConnection() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	originalSleep := connectSleep
+	defer func() { connectSleep = originalSleep }()
+	connectSleep = func(time.Duration) {}
+
+	// A single pooled connection, so the server drops the connection the commit runs on
+	sqlDB, err := suite.db.DB()
+	suite.Require().NoError(err)
+	sqlDB.SetMaxOpenConns(1)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	conf := config.IndexConfig{}
+	conf.Database.ReconnectRetries = 2
+	block := models.Block{Height: 1, ChainID: initChain.ID, TimeStamp: time.Now(), ProposerConsAddress: models.Address{Address: "testchainaddress"}}
+
+	attempts := 0
+	err = RetryCommit(conf.Database, func() error {
+		attempts++
+		if attempts == 1 {
+			// As in a failover, the server terminates the connection mid-run
+			return suite.db.Exec("SELECT pg_terminate_backend(pg_backend_pid())").Error
+		}
+		_, _, err := IndexNewBlock(suite.db, nil, nil, block, []TxDBWrapper{}, conf)
+		return err
+	})
+	suite.Require().NoError(err)
+	suite.Assert().Equal(2, attempts)
+
+	var blocks int64
+	suite.Require().NoError(suite.db.Model(&models.Block{}).Where("height = ?", 1).Count(&blocks).Error)
+	suite.Assert().Equal(int64(1), blocks)
+}
+
+func (suite *DBTestSuite) TestDeferIndexes() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	suite.Require().True(suite.db.Migrator().HasIndex(&models.Message{}, "idx_txid_typeid"))
+
+	dropped, err := DropDeferrableIndexes(suite.db)
+	suite.Require().NoError(err)
+	suite.Assert().Contains(dropped, "idx_txid_typeid")
+
+	// Mid-run, secondary indexes are gone but the unique indexes the upserts depend on are kept
+	suite.Assert().False(suite.db.Migrator().HasIndex(&models.Message{}, "idx_txid_typeid"))
+	suite.Assert().False(suite.db.Migrator().HasIndex(&models.MessageAddress{}, "idx_message_address_height"))
+	suite.Assert().True(suite.db.Migrator().HasIndex(&models.Tx{}, "idx_txes_hash"))
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	initConsAddress := models.Address{Address: "testchainaddress"}
+	suite.Require().NoError(suite.db.Create(&initConsAddress).Error)
+
+	_, err = createMockBlock(suite.db, initChain, initConsAddress, 1, true, true)
+	suite.Require().NoError(err)
+
+	err = RecreateDeferredIndexes(suite.db)
+	suite.Require().NoError(err)
+
+	suite.Assert().True(suite.db.Migrator().HasIndex(&models.Message{}, "idx_txid_typeid"))
+	suite.Assert().True(suite.db.Migrator().HasIndex(&models.MessageAddress{}, "idx_message_address_height"))
+
+	// An interrupted back-fill leaves the indexes dropped, the migrations on the next start recreate them
+	_, err = DropDeferrableIndexes(suite.db)
+	suite.Require().NoError(err)
+
+	err = MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	suite.Assert().True(suite.db.Migrator().HasIndex(&models.Message{}, "idx_txid_typeid"))
+}
+
+func (suite *DBTestSuite) TestApplyPostMigrateSQL() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	dir := suite.T().TempDir()
+	suite.Require().NoError(os.WriteFile(filepath.Join(dir, "001_block_time_index.sql"), []byte("CREATE INDEX idx_analytics_block_time ON blocks (time_stamp);"), 0o600))
+	suite.Require().NoError(os.WriteFile(filepath.Join(dir, "002_block_counts.sql"), []byte("CREATE VIEW v_analytics_block_counts AS SELECT chain_id, COUNT(*) AS blocks FROM blocks GROUP BY chain_id;"), 0o600))
+	suite.Require().NoError(os.WriteFile(filepath.Join(dir, "README.md"), []byte("not SQL"), 0o600))
+
+	applied, err := ApplyPostMigrateSQL(suite.db, dir)
+	suite.Require().NoError(err)
+	suite.Assert().Equal([]string{"001_block_time_index.sql", "002_block_counts.sql"}, applied)
+
+	suite.Assert().True(suite.db.Migrator().HasIndex(&models.Block{}, "idx_analytics_block_time"))
+
+	var viewCount int64
+	err = suite.db.Table("v_analytics_block_counts").Count(&viewCount).Error
+	suite.Require().NoError(err)
+
+	// Applied files are not run again, which would fail on the existing index
+	applied, err = ApplyPostMigrateSQL(suite.db, dir)
+	suite.Require().NoError(err)
+	suite.Assert().Empty(applied)
+
+	// A failing file is named in the error and rolled back
+	suite.Require().NoError(os.WriteFile(filepath.Join(dir, "003_broken.sql"), []byte("CREATE INDEX idx_analytics_broken ON missing_table (id);"), 0o600))
+	_, err = ApplyPostMigrateSQL(suite.db, dir)
+	suite.Require().Error(err)
+	suite.Assert().Contains(err.Error(), "003_broken.sql")
+
+	var appliedCount int64
+	suite.Require().NoError(suite.db.Model(&models.PostMigrateSQLFile{}).Count(&appliedCount).Error)
+	suite.Assert().Equal(int64(2), appliedCount)
+}
+
+func (suite *DBTestSuite) TestLeaderElectionFailover() {
+	ctx := context.Background()
+	leader := NewLeaderElector(suite.db, "testchain-1")
+	follower := NewLeaderElector(suite.db, "testchain-1")
+
+	acquired, err := leader.TryAcquire(ctx)
+	suite.Require().NoError(err)
+	suite.Require().True(acquired)
+	suite.Require().NoError(leader.Heartbeat(ctx))
+
+	acquired, err = follower.TryAcquire(ctx)
+	suite.Require().NoError(err)
+	suite.Assert().False(acquired)
+
+	promoted := make(chan error, 1)
+	go func() {
+		promoted <- follower.WaitForLeadership(ctx, 50*time.Millisecond)
+	}()
+
+	// Killing the leader's session releases the lock
+	suite.Require().NoError(suite.db.Exec("SELECT pg_terminate_backend(?)", leader.pid).Error)
+
+	select {
+	case err := <-promoted:
+		suite.Require().NoError(err)
+	case <-time.After(10 * time.Second):
+		suite.FailNow("follower was not promoted after the leader was killed")
+	}
+
+	suite.Assert().Error(leader.Heartbeat(ctx))
+	suite.Assert().NoError(follower.Heartbeat(ctx))
+	suite.Assert().NoError(follower.Release())
+}
+
+func (suite *DBTestSuite) TestValidateData() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	block := models.Block{
+		Height:              10,
+		ChainID:             initChain.ID,
+		TimeStamp:           time.Now(),
+		ProposerConsAddress: models.Address{Address: "cosmosvalcons1proposer"},
+	}
+	suite.Require().NoError(suite.db.Create(&block).Error)
+
+	tx := models.Tx{
+		Hash:    "BADSIGNERHASH",
+		BlockID: block.ID,
+		SignerAddresses: []models.Address{
+			{Address: sdkTypes.AccAddress(make([]byte, 20)).String()},
+			{Address: "not-an-address"},
+		},
+	}
+	suite.Require().NoError(suite.db.Create(&tx).Error)
+
+	var violations []DataViolation
+	count, err := ValidateData(suite.db, initChain.ID, DataValidators(), 0, func(violation DataViolation) {
+		violations = append(violations, violation)
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(int64(1), count)
+	suite.Require().Len(violations, 1)
+
+	suite.Assert().Equal("signer-address-format", violations[0].Validator)
+	suite.Assert().Equal(int64(10), violations[0].Height)
+	suite.Assert().Equal("BADSIGNERHASH", violations[0].TxHash)
+	suite.Assert().Contains(violations[0].Reason, "not-an-address")
+
+	// The pass is read-only
+	var txCount int64
+	suite.Require().NoError(suite.db.Model(&models.Tx{}).Count(&txCount).Error)
+	suite.Assert().Equal(int64(1), txCount)
+}
+
+func (suite *DBTestSuite) TestReindexMessages() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	block := models.Block{
+		Height:              10,
+		ChainID:             initChain.ID,
+		TimeStamp:           time.Now(),
+		ProposerConsAddress: models.Address{Address: "cosmosvalcons1proposer"},
+	}
+	suite.Require().NoError(suite.db.Create(&block).Error)
+
+	tx := models.Tx{Hash: "REINDEXHASH", BlockID: block.ID}
+	suite.Require().NoError(suite.db.Create(&tx).Error)
+
+	sendBytes, err := (&bankTypes.MsgSend{FromAddress: "cosmos1sender", ToAddress: "cosmos1recipient"}).Marshal()
+	suite.Require().NoError(err)
+
+	staleSchemaError := "stale"
+	sendType := models.MessageType{MessageType: "/cosmos.bank.v1beta1.MsgSend"}
+	otherType := models.MessageType{MessageType: "/cosmos.bank.v1beta1.MsgMultiSend"}
+	messages := []models.Message{
+		{TxID: tx.ID, MessageIndex: 0, MessageType: sendType, MessageBytes: sendBytes, SchemaError: &staleSchemaError},
+		// Left untouched, it is not of the reindexed type
+		{TxID: tx.ID, MessageIndex: 1, MessageType: otherType, MessageBytes: []byte("other"), SchemaError: &staleSchemaError},
+	}
+	suite.Require().NoError(suite.db.Create(&messages).Error)
+
+	conf := config.IndexConfig{}
+	conf.Base.IndexAddresses = true
+
+	// The redecoder stands in for the codec of the chain and re-derives the addresses from the raw bytes
+	redecode := func(stored StoredMessage) (MessageDBWrapper, error) {
+		var msgSend bankTypes.MsgSend
+		if err := msgSend.Unmarshal(stored.Message.MessageBytes); err != nil {
+			return MessageDBWrapper{}, err
+		}
+		return MessageDBWrapper{Addresses: []parsers.MessageAddress{{Address: msgSend.ToAddress, Role: "recipient"}}}, nil
+	}
+
+	reindexed, err := ReindexMessages(suite.db, conf, initChain.ID, sendType.MessageType, 1, redecode, nil)
+	suite.Require().NoError(err)
+	suite.Assert().Equal(int64(1), reindexed)
+
+	var storedMessages []models.Message
+	suite.Require().NoError(suite.db.Order("message_index").Find(&storedMessages).Error)
+	suite.Require().Len(storedMessages, 2)
+	suite.Assert().Nil(storedMessages[0].SchemaError)
+	suite.Assert().Equal(sendBytes, storedMessages[0].MessageBytes)
+	suite.Require().NotNil(storedMessages[1].SchemaError)
+	suite.Assert().Equal(staleSchemaError, *storedMessages[1].SchemaError)
+
+	var messageAddresses []models.MessageAddress
+	suite.Require().NoError(suite.db.Preload("Address").Find(&messageAddresses).Error)
+	suite.Require().Len(messageAddresses, 1)
+	suite.Assert().Equal(storedMessages[0].ID, messageAddresses[0].MessageID)
+	suite.Assert().Equal("cosmos1recipient", messageAddresses[0].Address.Address)
+	suite.Assert().Equal(int64(10), messageAddresses[0].Height)
+
+	// Messages stored without their raw bytes cannot be reindexed
+	suite.Require().NoError(suite.db.Model(&models.Message{}).Where("id = ?", storedMessages[0].ID).Update("message_bytes", nil).Error)
+	_, err = ReindexMessages(suite.db, conf, initChain.ID, sendType.MessageType, 1, redecode, nil)
+	suite.Assert().ErrorIs(err, ErrMissingMessageBytes)
+}
+
+func (suite *DBTestSuite) TestRecordSkippedBlocks() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	// A known-bad height failed before it was added to the skip heights
+	suite.Require().NoError(UpsertFailedBlock(suite.db, nil, 5, initChain.ChainID, initChain.Name))
+	suite.Require().NoError(UpsertFailedBlock(suite.db, nil, 6, initChain.ChainID, initChain.Name))
+
+	suite.Require().NoError(RecordSkippedBlocks(suite.db, initChain.ID, []int64{3, 5}, models.SkippedBlockReasonManual))
+	// Recording again on the next start is a no-op
+	suite.Require().NoError(RecordSkippedBlocks(suite.db, initChain.ID, []int64{3, 5}, models.SkippedBlockReasonManual))
+
+	var skippedBlocks []models.SkippedBlock
+	suite.Require().NoError(suite.db.Order("height").Find(&skippedBlocks).Error)
+	suite.Require().Len(skippedBlocks, 2)
+	suite.Assert().Equal(int64(3), skippedBlocks[0].Height)
+	suite.Assert().Equal(models.SkippedBlockReasonManual, skippedBlocks[1].Reason)
+
+	// The skipped height is no longer a failure to reattempt, other failures are kept
+	var failedBlocks []models.FailedBlock
+	suite.Require().NoError(suite.db.Find(&failedBlocks).Error)
+	suite.Require().Len(failedBlocks, 1)
+	suite.Assert().Equal(int64(6), failedBlocks[0].Height)
+}
+
+// mockBlockEventsWrapper returns a block event wrapper holding the same begin and end block events at every height
+func mockBlockEventsWrapper(chain models.Chain, height int64) *BlockDBWrapper {
+	wrapper := &BlockDBWrapper{
+		Block:                         &models.Block{ChainID: chain.ID, Height: height, TimeStamp: time.Now(), ProposerConsAddress: models.Address{Address: "testchainaddress"}},
+		UniqueBlockEventTypes:         map[string]models.BlockEventType{},
+		UniqueBlockEventAttributeKeys: map[string]models.BlockEventAttributeKey{},
+	}
+
+	mockEvent := func(lifecyclePosition models.BlockLifecyclePosition, index uint64, eventType string, attributes ...string) BlockEventDBWrapper {
+		wrapper.UniqueBlockEventTypes[eventType] = models.BlockEventType{Type: eventType}
+		event := BlockEventDBWrapper{BlockEvent: models.BlockEvent{Index: index, LifecyclePosition: lifecyclePosition, BlockEventType: models.BlockEventType{Type: eventType}}}
+		for i := 0; i < len(attributes); i += 2 {
+			wrapper.UniqueBlockEventAttributeKeys[attributes[i]] = models.BlockEventAttributeKey{Key: attributes[i]}
+			event.Attributes = append(event.Attributes, models.BlockEventAttribute{Index: uint64(i / 2), Value: attributes[i+1], BlockEventAttributeKey: models.BlockEventAttributeKey{Key: attributes[i]}})
+		}
+		return event
+	}
+
+	wrapper.BeginBlockEvents = []BlockEventDBWrapper{
+		mockEvent(models.BeginBlockEvent, 0, "coin_spent", "spender", "cosmos1spender", "amount", "10uatom"),
+		mockEvent(models.BeginBlockEvent, 1, "mint", "amount", "5uatom"),
+	}
+	wrapper.EndBlockEvents = []BlockEventDBWrapper{
+		mockEvent(models.EndBlockEvent, 0, "complete_unbonding", "validator", "cosmosvaloper1validator", "delegator", "cosmos1delegator"),
+	}
+
+	return wrapper
+}
+
+func (suite *DBTestSuite) TestEventsStorageModes() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	normalized, err := IndexBlockEvents(suite.db, nil, false, false, mockBlockEventsWrapper(initChain, 1), "block 1")
+	suite.Require().NoError(err)
+
+	jsonbWrapper := mockBlockEventsWrapper(initChain, 2)
+	MoveBlockEventsToJSON(jsonbWrapper)
+	jsonb, err := IndexBlockEvents(suite.db, nil, false, false, jsonbWrapper, "block 2")
+	suite.Require().NoError(err)
+
+	// Rebuild the logical events of the normalized block from its rows
+	var blockEvents []models.BlockEvent
+	suite.Require().NoError(suite.db.Preload("BlockEventType").Where("block_id = ?", normalized.Block.ID).Order(`lifecycle_position, "index"`).Find(&blockEvents).Error)
+	normalizedEvents := models.BlockEventsJSON{}
+	for _, blockEvent := range blockEvents {
+		var attributes []models.BlockEventAttribute
+		suite.Require().NoError(suite.db.Preload("BlockEventAttributeKey").Where("block_event_id = ?", blockEvent.ID).Order(`"index"`).Find(&attributes).Error)
+
+		lifecyclePosition := models.JSONBeginBlockEvent
+		if blockEvent.LifecyclePosition == models.EndBlockEvent {
+			lifecyclePosition = models.JSONEndBlockEvent
+		}
+		event := models.BlockEventJSON{LifecyclePosition: lifecyclePosition, Index: blockEvent.Index, Type: blockEvent.BlockEventType.Type, Attributes: []models.BlockEventAttributeJSON{}}
+		for _, attribute := range attributes {
+			event.Attributes = append(event.Attributes, models.BlockEventAttributeJSON{Key: attribute.BlockEventAttributeKey.Key, Value: attribute.Value, ValueBase64: attribute.ValueBase64})
+		}
+		normalizedEvents = append(normalizedEvents, event)
+	}
+
+	var storedJSONBBlock models.Block
+	suite.Require().NoError(suite.db.First(&storedJSONBBlock, jsonb.Block.ID).Error)
+	suite.Require().Len(storedJSONBBlock.Events, 3)
+	suite.Equal(normalizedEvents, storedJSONBBlock.Events)
+
+	// Each mode only stores the events one way
+	var storedNormalizedBlock models.Block
+	suite.Require().NoError(suite.db.First(&storedNormalizedBlock, normalized.Block.ID).Error)
+	suite.Nil(storedNormalizedBlock.Events)
+
+	var jsonbBlockEvents int64
+	suite.Require().NoError(suite.db.Model(&models.BlockEvent{}).Where("block_id = ?", jsonb.Block.ID).Count(&jsonbBlockEvents).Error)
+	suite.Zero(jsonbBlockEvents)
+}
+
+func (suite *DBTestSuite) TestArchiveFilteredEvents() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	for height := int64(1); height <= 3; height++ {
+		// The filters only let the mint event through
+		wrapper := mockBlockEventsWrapper(initChain, height)
+		droppedBegin, droppedEnd := wrapper.BeginBlockEvents[:1], wrapper.EndBlockEvents
+		wrapper.BeginBlockEvents, wrapper.EndBlockEvents = wrapper.BeginBlockEvents[1:], nil
+		suite.Require().NoError(ArchiveFilteredBlockEvents(wrapper, droppedBegin, droppedEnd, 1))
+
+		_, err := IndexBlockEvents(suite.db, nil, false, false, wrapper, fmt.Sprintf("block %d", height))
+		suite.Require().NoError(err)
+	}
+
+	var mainTypes []string
+	suite.Require().NoError(suite.db.Model(&models.BlockEvent{}).
+		Joins("JOIN block_event_types ON block_event_types.id = block_events.block_event_type_id").
+		Distinct().Pluck("block_event_types.type", &mainTypes).Error)
+	suite.Equal([]string{"mint"}, mainTypes)
+
+	// Only the latest block is within the retention of 1 block
+	var archived []models.ArchivedBlockEvents
+	suite.Require().NoError(suite.db.Find(&archived).Error)
+	suite.Require().Len(archived, 1)
+	suite.Equal(int64(3), archived[0].Height)
+
+	events, err := DecodeArchivedBlockEvents(archived[0])
+	suite.Require().NoError(err)
+	suite.Equal(models.BlockEventsJSON{
+		{LifecyclePosition: models.JSONBeginBlockEvent, Index: 0, Type: "coin_spent", Attributes: []models.BlockEventAttributeJSON{{Key: "spender", Value: "cosmos1spender"}, {Key: "amount", Value: "10uatom"}}},
+		{LifecyclePosition: models.JSONEndBlockEvent, Index: 0, Type: "complete_unbonding", Attributes: []models.BlockEventAttributeJSON{{Key: "validator", Value: "cosmosvaloper1validator"}, {Key: "delegator", Value: "cosmos1delegator"}}},
+	}, events)
+}
+
+func (suite *DBTestSuite) TestDedupeStaticEvents() {
+	err := MigrateModels(suite.db)
+	suite.Require().NoError(err)
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	deduper := NewStaticEventDeduper()
+	for height := int64(1); height <= 100; height++ {
+		wrapper := mockBlockEventsWrapper(initChain, height)
+		deduper.Dedupe(wrapper)
+		indexed, err := IndexBlockEvents(suite.db, nil, false, false, wrapper, fmt.Sprintf("block %d", height))
+		suite.Require().NoError(err)
+		deduper.Record(indexed)
+	}
+
+	// The three events of the mock block are stored once, at the first height
+	var blockEvents int64
+	suite.Require().NoError(suite.db.Model(&models.BlockEvent{}).Count(&blockEvents).Error)
+	suite.Equal(int64(3), blockEvents)
+
+	var runs []models.StaticBlockEventRun
+	suite.Require().NoError(suite.db.Order("block_event_id").Find(&runs).Error)
+	suite.Require().Len(runs, 3)
+	for _, run := range runs {
+		suite.Equal(int64(1), run.FirstHeight)
+		suite.Equal(int64(100), run.LastHeight)
+		suite.Equal(int64(100), run.Count)
+	}
+
+	// Whether the mint event was emitted at a height, from its own row or a run covering the height
+	activeAt := func(height int64) bool {
+		var count int64
+		suite.Require().NoError(suite.db.Raw(`SELECT COUNT(*) FROM block_events
+			JOIN block_event_types ON block_event_types.id = block_events.block_event_type_id
+			JOIN blocks ON blocks.id = block_events.block_id
+			LEFT JOIN static_block_event_runs ON static_block_event_runs.block_event_id = block_events.id
+			WHERE block_event_types.type = 'mint' AND (blocks.height = ? OR (static_block_event_runs.first_height <= ? AND static_block_event_runs.last_height >= ?))`,
+			height, height, height).Scan(&count).Error)
+		return count != 0
+	}
+	suite.True(activeAt(1))
+	suite.True(activeAt(50))
+	suite.True(activeAt(100))
+	suite.False(activeAt(101))
+}
+
+func (suite *DBTestSuite) TestRegisterTxColumn() {
+	txColumns := []TxColumn{{Name: "memo_length", SQLType: "bigint", Extractor: func(tx TxDBWrapper) any {
+		return len(tx.Tx.Memo)
+	}}}
+	suite.Require().NoError(MigrateModels(suite.db))
+	suite.Require().NoError(MigrateTxColumns(suite.db, txColumns))
+	suite.True(suite.db.Migrator().HasColumn(&models.Tx{}, "memo_length"))
+
+	initChain := models.Chain{ChainID: "testchain-1"}
+	suite.Require().NoError(suite.db.Create(&initChain).Error)
+
+	conf := config.IndexConfig{}
+	conf.Flags.IndexEmptyTransactions = true
+
+	block := models.Block{
+		Height:              1,
+		ChainID:             initChain.ID,
+		TimeStamp:           time.Now(),
+		ProposerConsAddress: models.Address{Address: "testchainaddress"},
+	}
+	_, _, err := IndexNewBlock(suite.db, nil, txColumns, block, []TxDBWrapper{{Tx: models.Tx{Hash: "TESTHASH", Memo: "hello"}}}, conf)
+	suite.Require().NoError(err)
+
+	var memoLength int64
+	suite.Require().NoError(suite.db.Raw("SELECT memo_length FROM txes WHERE hash = ?", "TESTHASH").Scan(&memoLength).Error)
+	suite.Equal(int64(5), memoLength)
+}
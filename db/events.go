@@ -7,8 +7,10 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-func IndexBlockEvents(db *gorm.DB, dryRun bool, blockDBWrapper *BlockDBWrapper, identifierLoggingString string) (*BlockDBWrapper, error) {
-	err := db.Transaction(func(dbTransaction *gorm.DB) error {
+// IndexBlockEvents writes the block events of the block. With replace set the block's existing block events, along with their
+// attributes, custom parser rows and the block level rows extracted from them, are deleted first so a reindexed block has no stale rows.
+func IndexBlockEvents(db *gorm.DB, txnLimiter *TransactionLimiter, dryRun bool, replace bool, blockDBWrapper *BlockDBWrapper, identifierLoggingString string) (*BlockDBWrapper, error) {
+	err := blockTransaction(db, txnLimiter, func(dbTransaction *gorm.DB) error {
 		if err := dbTransaction.
 			Exec("DELETE FROM failed_event_blocks WHERE height = ? AND blockchain_id = ?", blockDBWrapper.Block.Height, blockDBWrapper.Block.ChainID).
 			Error; err != nil {
@@ -29,15 +31,28 @@ func IndexBlockEvents(db *gorm.DB, dryRun bool, blockDBWrapper *BlockDBWrapper,
 
 		// create block if it doesn't exist
 		blockDBWrapper.Block.BlockEventsIndexed = true
+		evidence := blockDBWrapper.Block.Evidence
 
 		if err := dbTransaction.
 			Where(models.Block{Height: blockDBWrapper.Block.Height, ChainID: blockDBWrapper.Block.ChainID}).
-			Assign(models.Block{BlockEventsIndexed: true, TimeStamp: blockDBWrapper.Block.TimeStamp, ProposerConsAddress: blockDBWrapper.Block.ProposerConsAddress}).
+			Assign(models.Block{BlockEventsIndexed: true, TimeStamp: blockDBWrapper.Block.TimeStamp, ProposerConsAddress: blockDBWrapper.Block.ProposerConsAddress, Tags: blockDBWrapper.Block.Tags, Annotations: blockDBWrapper.Block.Annotations, AppHash: blockDBWrapper.Block.AppHash, DataHash: blockDBWrapper.Block.DataHash, ConsensusHash: blockDBWrapper.Block.ConsensusHash, SourceEndpoint: blockDBWrapper.Block.SourceEndpoint, GasUsed: blockDBWrapper.Block.GasUsed, MaxGas: blockDBWrapper.Block.MaxGas, TxCount: blockDBWrapper.Block.TxCount, FailedTxCount: blockDBWrapper.Block.FailedTxCount, Events: blockDBWrapper.Block.Events}).
 			FirstOrCreate(&blockDBWrapper.Block).Error; err != nil {
 			config.Log.Error("Error getting/creating block DB object.", err)
 			return err
 		}
 
+		if replace {
+			blockIDs := dbTransaction.Model(&models.Block{}).Select("id").Where("id = ?", blockDBWrapper.Block.ID)
+			if err := deleteBlockEventData(dbTransaction, blockIDs); err != nil {
+				config.Log.Error("Error deleting existing block events for reindexed block.", err)
+				return err
+			}
+		}
+
+		if err := indexBlockEvidence(dbTransaction, *blockDBWrapper.Block, evidence); err != nil {
+			return err
+		}
+
 		var uniqueBlockEventTypes []models.BlockEventType
 
 		for _, value := range blockDBWrapper.UniqueBlockEventTypes {
@@ -160,7 +175,7 @@ func IndexBlockEvents(db *gorm.DB, dryRun bool, blockDBWrapper *BlockDBWrapper,
 				if err := dbTransaction.Clauses(clause.OnConflict{
 					Columns: []clause.Column{{Name: "block_event_id"}, {Name: "index"}},
 					// Force update of value
-					DoUpdates: clause.AssignmentColumns([]string{"value"}),
+					DoUpdates: clause.AssignmentColumns([]string{"value", "value_base64"}),
 				}).Create(&allAttributes).Error; err != nil {
 					config.Log.Error("Error creating begin block event attributes.", err)
 					return err
@@ -168,6 +183,89 @@ func IndexBlockEvents(db *gorm.DB, dryRun bool, blockDBWrapper *BlockDBWrapper,
 			}
 		}
 
+		if len(blockDBWrapper.StaticEventRuns) != 0 {
+			if err := dbTransaction.Omit(clause.Associations).Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "block_event_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"last_height", "count"}),
+			}).Create(&blockDBWrapper.StaticEventRuns).Error; err != nil {
+				config.Log.Error("Error creating static block event runs.", err)
+				return err
+			}
+		}
+
+		if err := indexArchivedBlockEvents(dbTransaction, blockDBWrapper); err != nil {
+			config.Log.Error("Error creating archived block events.", err)
+			return err
+		}
+
+		if len(blockDBWrapper.SlashingEvents) != 0 {
+			for index := range blockDBWrapper.SlashingEvents {
+				blockDBWrapper.SlashingEvents[index].BlockID = blockDBWrapper.Block.ID
+			}
+
+			if err := dbTransaction.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "block_id"}, {Name: "lifecycle_position"}, {Name: "event_index"}},
+				DoUpdates: clause.AssignmentColumns([]string{"height", "type", "consensus_address", "operator_address", "reason", "power", "amount", "jailed", "missed_blocks"}),
+			}).Omit("Block").Create(&blockDBWrapper.SlashingEvents).Error; err != nil {
+				config.Log.Error("Error creating slashing events.", err)
+				return err
+			}
+		}
+
+		if len(blockDBWrapper.RewardEvents) != 0 {
+			for index := range blockDBWrapper.RewardEvents {
+				blockDBWrapper.RewardEvents[index].BlockID = blockDBWrapper.Block.ID
+			}
+
+			if err := dbTransaction.Omit(clause.Associations).Clauses(clause.OnConflict{
+				Columns:     []clause.Column{{Name: "block_id"}, {Name: "lifecycle_position"}, {Name: "event_index"}, {Name: "denom"}},
+				TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "message_id IS NULL"}}},
+				DoUpdates:   clause.AssignmentColumns([]string{"height", "type", "delegator_address", "validator_address", "amount"}),
+			}).Create(&blockDBWrapper.RewardEvents).Error; err != nil {
+				config.Log.Error("Error creating block reward events.", err)
+				return err
+			}
+		}
+
+		if blockDBWrapper.SupplyDeltas != nil {
+			for index := range blockDBWrapper.SupplyDeltas {
+				blockDBWrapper.SupplyDeltas[index].BlockID = blockDBWrapper.Block.ID
+			}
+
+			if err := replaceSupplyDeltas(dbTransaction, blockDBWrapper.Block.ID, []string{models.SupplyDeltaSourceBeginBlock, models.SupplyDeltaSourceEndBlock}, blockDBWrapper.SupplyDeltas); err != nil {
+				return err
+			}
+		}
+
+		if len(blockDBWrapper.BalanceDeltas) != 0 {
+			for index := range blockDBWrapper.BalanceDeltas {
+				blockDBWrapper.BalanceDeltas[index].BlockID = blockDBWrapper.Block.ID
+			}
+
+			if err := dbTransaction.Omit(clause.Associations).Clauses(clause.OnConflict{
+				Columns:     []clause.Column{{Name: "block_id"}, {Name: "lifecycle_position"}, {Name: "event_index"}, {Name: "denom"}},
+				TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "message_id IS NULL"}}},
+				DoUpdates:   clause.AssignmentColumns([]string{"height", "type", "address", "amount"}),
+			}).Create(&blockDBWrapper.BalanceDeltas).Error; err != nil {
+				config.Log.Error("Error creating block balance deltas.", err)
+				return err
+			}
+		}
+
+		if len(blockDBWrapper.ModuleBalances) != 0 {
+			for index := range blockDBWrapper.ModuleBalances {
+				blockDBWrapper.ModuleBalances[index].BlockID = blockDBWrapper.Block.ID
+			}
+
+			if err := dbTransaction.Omit(clause.Associations).Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "block_id"}, {Name: "address"}, {Name: "denom"}},
+				DoUpdates: clause.AssignmentColumns([]string{"height", "amount"}),
+			}).Create(&blockDBWrapper.ModuleBalances).Error; err != nil {
+				config.Log.Error("Error creating module balances.", err)
+				return err
+			}
+		}
+
 		return nil
 	})
 
@@ -175,8 +273,8 @@ func IndexBlockEvents(db *gorm.DB, dryRun bool, blockDBWrapper *BlockDBWrapper,
 	return blockDBWrapper, err
 }
 
-func IndexCustomBlockEvents(conf config.IndexConfig, db *gorm.DB, dryRun bool, blockDBWrapper *BlockDBWrapper, identifierLoggingString string, beginBlockParserTrackers map[string]models.BlockEventParser, endBlockParserTrackers map[string]models.BlockEventParser) error {
-	return db.Transaction(func(dbTransaction *gorm.DB) error {
+func IndexCustomBlockEvents(conf config.IndexConfig, db *gorm.DB, txnLimiter *TransactionLimiter, dryRun bool, blockDBWrapper *BlockDBWrapper, identifierLoggingString string, beginBlockParserTrackers map[string]models.BlockEventParser, endBlockParserTrackers map[string]models.BlockEventParser) error {
+	return blockTransaction(db, txnLimiter, func(dbTransaction *gorm.DB) error {
 		// call generic function below
 		err := indexLifecycleCustomBlockEvents(dbTransaction, conf, blockDBWrapper, blockDBWrapper.BeginBlockEvents, beginBlockParserTrackers)
 		if err != nil {
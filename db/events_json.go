@@ -0,0 +1,39 @@
+package db
+
+import "github.com/DefiantLabs/cosmos-indexer/db/models"
+
+// MoveBlockEventsToJSON moves the begin and end block events of the wrapper into the jsonb events document of the block
+// for base.events-storage-mode jsonb. The normalized events are cleared, so IndexBlockEvents only writes the block row.
+// A block without events gets an empty document, telling it apart from blocks indexed in normalized mode.
+func MoveBlockEventsToJSON(blockDBWrapper *BlockDBWrapper) {
+	events := make(models.BlockEventsJSON, 0, len(blockDBWrapper.BeginBlockEvents)+len(blockDBWrapper.EndBlockEvents))
+	events = appendBlockEventsJSON(events, models.JSONBeginBlockEvent, blockDBWrapper.BeginBlockEvents)
+	events = appendBlockEventsJSON(events, models.JSONEndBlockEvent, blockDBWrapper.EndBlockEvents)
+
+	blockDBWrapper.Block.Events = events
+	blockDBWrapper.BeginBlockEvents = nil
+	blockDBWrapper.EndBlockEvents = nil
+	blockDBWrapper.UniqueBlockEventTypes = map[string]models.BlockEventType{}
+	blockDBWrapper.UniqueBlockEventAttributeKeys = map[string]models.BlockEventAttributeKey{}
+}
+
+func appendBlockEventsJSON(events models.BlockEventsJSON, lifecyclePosition string, blockEvents []BlockEventDBWrapper) models.BlockEventsJSON {
+	for _, blockEvent := range blockEvents {
+		attributes := make([]models.BlockEventAttributeJSON, len(blockEvent.Attributes))
+		for index, attribute := range blockEvent.Attributes {
+			attributes[index] = models.BlockEventAttributeJSON{
+				Key:         attribute.BlockEventAttributeKey.Key,
+				Value:       attribute.Value,
+				ValueBase64: attribute.ValueBase64,
+			}
+		}
+
+		events = append(events, models.BlockEventJSON{
+			LifecyclePosition: lifecyclePosition,
+			Index:             blockEvent.BlockEvent.Index,
+			Type:              blockEvent.BlockEvent.BlockEventType.Type,
+			Attributes:        attributes,
+		})
+	}
+	return events
+}
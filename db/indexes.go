@@ -0,0 +1,116 @@
+package db
+
+import (
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+)
+
+// Models written on every block. Their non-unique indexes only serve queries and can be deferred during a back-fill.
+func deferrableIndexModels() []any {
+	return []any{
+		&models.Block{},
+		&models.BlockEvent{},
+		&models.BlockEventAttribute{},
+		&models.SlashingEvent{},
+		&models.Tx{},
+		&models.FailedTxLog{},
+		&models.TxDecodeFailure{},
+		&models.Fee{},
+		&models.Message{},
+		&models.MessageEvent{},
+		&models.MessageEventAttribute{},
+		&models.MessageAddress{},
+		&models.GovernanceMessage{},
+		&models.RewardEvent{},
+		&models.NFTActivity{},
+		&models.BalanceDelta{},
+		&models.TxSignerInfo{},
+		&models.ModuleBalance{},
+		&models.TxRaw{},
+		&models.MessageExecutionContext{},
+		&models.BankMessage{},
+		&models.StakingMessage{},
+		&models.DistributionMessage{},
+		&models.GovMessage{},
+		&models.IBCMessage{},
+		&models.WasmMessage{},
+		&models.OtherMessage{},
+		&models.StaticBlockEventRun{},
+		&models.ArchivedBlockEvents{},
+		&models.SupplyDelta{},
+		&models.BlockEvidence{},
+	}
+}
+
+// DropDeferrableIndexes drops the non-unique secondary indexes of the per-block tables to speed up bulk inserts,
+// returning the names of the dropped indexes. Unique indexes are kept since the upserts rely on them.
+func DropDeferrableIndexes(db *gorm.DB) ([]string, error) {
+	var dropped []string
+	err := forEachDeferrableIndex(db, func(model any, name string) error {
+		if !db.Migrator().HasIndex(model, name) {
+			return nil
+		}
+
+		if err := db.Migrator().DropIndex(model, name); err != nil {
+			config.Log.Errorf("Error dropping index %s. Err: %v", name, err)
+			return err
+		}
+		dropped = append(dropped, name)
+		return nil
+	})
+
+	return dropped, err
+}
+
+// RecreateDeferredIndexes creates any missing non-unique secondary indexes of the per-block tables.
+// Migrations also create missing indexes, so a back-fill interrupted before this runs is repaired on the next start.
+func RecreateDeferredIndexes(db *gorm.DB) error {
+	return forEachDeferrableIndex(db, func(model any, name string) error {
+		if db.Migrator().HasIndex(model, name) {
+			return nil
+		}
+
+		config.Log.Infof("Recreating index %s", name)
+		if err := db.Migrator().CreateIndex(model, name); err != nil {
+			config.Log.Errorf("Error recreating index %s. Err: %v", name, err)
+			return err
+		}
+		return nil
+	})
+}
+
+func forEachDeferrableIndex(db *gorm.DB, fn func(model any, name string) error) error {
+	for _, model := range deferrableIndexModels() {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return err
+		}
+
+		for name, index := range statement.Schema.ParseIndexes() {
+			if index.Class == "UNIQUE" {
+				continue
+			}
+
+			if err := fn(model, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Index on the optional messages.type_url column (database.index-message-type)
+const messageTypeURLIndex = "idx_message_type_url"
+
+// CreateMessageTypeIndex creates the index on the type URL of the messages if it is missing. It is kept out of the model tags so
+// deployments that do not enable database.index-message-type do not pay for maintaining it. Messages stored before the option was
+// enabled keep a null type URL until their block is reindexed.
+func CreateMessageTypeIndex(db *gorm.DB) error {
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS " + messageTypeURLIndex + " ON messages (type_url, tx_id)").Error; err != nil {
+		config.Log.Errorf("Error creating index %s. Err: %v", messageTypeURLIndex, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"gorm.io/gorm"
+)
+
+// LeaderElector elects a single writing instance among indexers sharing a database through a Postgres session advisory lock.
+// The lock is held on a dedicated connection, so it is released by Postgres as soon as the leader's session ends, whether the
+// leader exits cleanly, crashes or loses its connection.
+type LeaderElector struct {
+	db     *gorm.DB
+	lockID int64
+	conn   *sql.Conn
+	// Backend PID of the session holding the lock, used to check the lock is still held
+	pid int64
+}
+
+// NewLeaderElector creates an elector for the chain, instances indexing the same chain into the same database compete for the same lock
+func NewLeaderElector(db *gorm.DB, chainID string) *LeaderElector {
+	hash := fnv.New64a()
+	hash.Write([]byte("cosmos-indexer:" + chainID))
+	// The lock key only needs to be stable, wrapping into the signed range is fine
+	return &LeaderElector{db: db, lockID: int64(hash.Sum64())} //nolint:gosec
+}
+
+// TryAcquire attempts to take the leader lock without waiting, it returns true when this instance is the leader
+func (elector *LeaderElector) TryAcquire(ctx context.Context) (bool, error) {
+	if elector.conn != nil {
+		return true, nil
+	}
+
+	sqlDB, err := elector.db.DB()
+	if err != nil {
+		return false, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	var pid int64
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1), pg_backend_pid()", elector.lockID).Scan(&acquired, &pid)
+	if err != nil || !acquired {
+		conn.Close()
+		return false, err
+	}
+
+	elector.conn = conn
+	elector.pid = pid
+	return true, nil
+}
+
+// WaitForLeadership blocks until this instance holds the leader lock, retrying every pollInterval. A follower keeps its
+// database connection and chain client set up while it waits, so it resumes indexing as soon as the leader goes away.
+func (elector *LeaderElector) WaitForLeadership(ctx context.Context, pollInterval time.Duration) error {
+	for {
+		acquired, err := elector.TryAcquire(ctx)
+		if err != nil {
+			config.Log.Warnf("Error checking the HA leader lock, retrying. Err: %v", err)
+		} else if acquired {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Heartbeat checks the leader's session, and with it the lock, is still alive. An error means leadership may have passed
+// to another instance and this instance must stop writing.
+func (elector *LeaderElector) Heartbeat(ctx context.Context) error {
+	if elector.conn == nil {
+		return errors.New("not the HA leader")
+	}
+
+	var held bool
+	err := elector.conn.QueryRowContext(ctx, `SELECT EXISTS (
+		SELECT 1 FROM pg_locks
+		WHERE locktype = 'advisory' AND objsubid = 1 AND granted AND pid = $1
+		AND ((classid::bigint << 32) | objid::bigint) = $2
+	)`, elector.pid, elector.lockID).Scan(&held)
+	if err != nil {
+		return fmt.Errorf("HA leader session lost: %w", err)
+	}
+
+	if !held {
+		return errors.New("HA leader lock is no longer held")
+	}
+
+	return nil
+}
+
+// RunHeartbeat checks the lease every interval until the context is done, calling onLost once if leadership is lost
+func (elector *LeaderElector) RunHeartbeat(ctx context.Context, interval time.Duration, onLost func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			heartbeatCtx, cancel := context.WithTimeout(ctx, interval)
+			err := elector.Heartbeat(heartbeatCtx)
+			cancel()
+			if err != nil && ctx.Err() == nil {
+				onLost(err)
+				return
+			}
+		}
+	}
+}
+
+// Release gives up the leader lock so a follower can take over without waiting for the session to end
+func (elector *LeaderElector) Release() error {
+	if elector.conn == nil {
+		return nil
+	}
+
+	_, err := elector.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", elector.lockID)
+	closeErr := elector.conn.Close()
+	elector.conn = nil
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
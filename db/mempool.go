@@ -0,0 +1,45 @@
+package db
+
+import (
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InsertMempoolTxs stores the observed pending txs. A tx seen again, for instance after being evicted and re-broadcast, keeps
+// the time it was first seen.
+func InsertMempoolTxs(db *gorm.DB, txs []models.MempoolTx) error {
+	if len(txs) == 0 {
+		return nil
+	}
+
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}},
+		DoNothing: true,
+	}).Create(&txs).Error; err != nil {
+		config.Log.Error("Error creating mempool txs.", err)
+		return err
+	}
+
+	return nil
+}
+
+// reconcileMempoolTxs sets the committed height of the observed pending txs included in the block
+func reconcileMempoolTxs(db *gorm.DB, block models.Block, txs []TxDBWrapper) error {
+	if len(txs) == 0 {
+		return nil
+	}
+
+	hashes := make([]string, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Tx.Hash
+	}
+
+	if err := db.Model(&models.MempoolTx{}).Where("hash IN ?", hashes).Update("committed_height", block.Height).Error; err != nil {
+		config.Log.Error("Error reconciling mempool txs with their block.", err)
+		return err
+	}
+
+	return nil
+}
@@ -24,6 +24,17 @@ type BlockDBWrapper struct {
 	EndBlockEvents                []BlockEventDBWrapper
 	UniqueBlockEventTypes         map[string]models.BlockEventType
 	UniqueBlockEventAttributeKeys map[string]models.BlockEventAttributeKey
+	SlashingEvents                []models.SlashingEvent
+	RewardEvents                  []models.RewardEvent
+	BalanceDeltas                 []models.BalanceDelta
+	ModuleBalances                []models.ModuleBalance
+	SupplyDeltas                  []models.SupplyDelta
+	// Runs extended by the unchanged events removed from the block, only set with base.dedupe-static-events
+	StaticEventRuns    []models.StaticBlockEventRun
+	staticEventRunKeys []string
+	// Events dropped by the block event filters, only set with base.archive-filtered-events
+	ArchivedEvents   *models.ArchivedBlockEvents
+	archiveRetention int64
 }
 
 type BlockEventDBWrapper struct {
@@ -39,12 +50,23 @@ type TxDBWrapper struct {
 	UniqueMessageTypes         map[string]models.MessageType
 	UniqueMessageEventTypes    map[string]models.MessageEventType
 	UniqueMessageAttributeKeys map[string]models.MessageEventAttributeKey
+	FailedTxLog                *models.FailedTxLog
+	SignerInfos                []models.TxSignerInfo
+	// Raw tx bytes from the block, only set with flags.index-tx-raw
+	Raw []byte
 }
 
 type MessageDBWrapper struct {
 	Message               models.Message
 	MessageEvents         []MessageEventDBWrapper
 	MessageParsedDatasets []parsers.MessageParsedData
+	Addresses             []parsers.MessageAddress
+	GovernanceMessages    []models.GovernanceMessage
+	RewardEvents          []models.RewardEvent
+	BalanceDeltas         []models.BalanceDelta
+	SupplyDeltas          []models.SupplyDelta
+	ExecutionContexts     []models.MessageExecutionContext
+	NFTActivities         []models.NFTActivity
 }
 
 type MessageEventDBWrapper struct {
@@ -0,0 +1,12 @@
+package models
+
+// ArchivedBlockEvents holds the block events of a block that were dropped by the block event filters (base.archive-filtered-events),
+// so they can be recovered without re-indexing the block from the node. Events is the gzip compressed JSON encoding of the events,
+// in the BlockEventsJSON format. Rows are removed once they fall out of the configured retention window.
+type ArchivedBlockEvents struct {
+	ID      uint
+	BlockID uint `gorm:"uniqueIndex"`
+	Block   Block
+	Height  int64 `gorm:"index"`
+	Events  []byte
+}
@@ -0,0 +1,30 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// Bank module event types stored in the balance deltas table
+const (
+	BalanceDeltaEventTypeCoinSpent    = "coin_spent"
+	BalanceDeltaEventTypeCoinReceived = "coin_received"
+)
+
+// BalanceDelta is a single coin of a bank module coin_spent or coin_received event, one row per denom. The amount is signed,
+// negative for coins spent and positive for coins received, so the sum of the deltas of an address up to a height is its balance
+// change up to that height. Deltas from message events are linked to their message, deltas from block events (e.g. minting and
+// reward distribution) have no message and are identified by their lifecycle position in the block instead.
+type BalanceDelta struct {
+	ID                uint
+	BlockID           uint `gorm:"uniqueIndex:idx_balance_delta_block_position,priority:1,where:message_id IS NULL"`
+	Block             Block
+	Height            int64                   `gorm:"index:idx_balance_delta_height"`
+	TxID              *uint                   `gorm:"index:idx_balance_delta_tx"`
+	Tx                *Tx                     `gorm:"foreignKey:TxID"`
+	MessageID         *uint                   `gorm:"uniqueIndex:idx_balance_delta_message_position,priority:1,where:message_id IS NOT NULL"`
+	Message           *Message                `gorm:"foreignKey:MessageID"`
+	LifecyclePosition *BlockLifecyclePosition `gorm:"uniqueIndex:idx_balance_delta_block_position,priority:2,where:message_id IS NULL"`
+	EventIndex        uint64                  `gorm:"uniqueIndex:idx_balance_delta_block_position,priority:3,where:message_id IS NULL;uniqueIndex:idx_balance_delta_message_position,priority:2,where:message_id IS NOT NULL"`
+	Type              string
+	Address           string          `gorm:"index:idx_balance_delta_address_denom,priority:1"`
+	Amount            decimal.Decimal `gorm:"type:decimal(78,0);"`
+	Denom             string          `gorm:"index:idx_balance_delta_address_denom,priority:2;uniqueIndex:idx_balance_delta_block_position,priority:4,where:message_id IS NULL;uniqueIndex:idx_balance_delta_message_position,priority:3,where:message_id IS NOT NULL"`
+}
@@ -15,6 +15,35 @@ type Block struct {
 	TxIndexed             bool
 	// TODO: Should block event indexing be split out or rolled up?
 	BlockEventsIndexed bool
+	Tags               RowTags `gorm:"type:jsonb"`
+	// Tags of the base.height-annotations-file ranges containing the block height
+	Annotations RowTags `gorm:"type:jsonb"`
+	// Header hashes, hex encoded, for light-client verification
+	AppHash       string
+	DataHash      string
+	ConsensusHash string
+	// RPC endpoint that served the block, only set when base.record-source-endpoint is enabled
+	SourceEndpoint *string
+	// Total gas used by the txs of the block and the block max gas consensus param (-1 when unlimited),
+	// only set when base.index-block-gas is enabled
+	GasUsed *int64
+	MaxGas  *int64
+	// Number of txs in the block and of its failed txs, the success rate is 1 - failed_tx_count / tx_count. Only set when
+	// base.index-block-tx-stats is enabled.
+	TxCount       *int64
+	FailedTxCount *int64
+	// Hex encoded SHA-256 of the canonical tx and event set of the block, only set when base.index-block-checksums is enabled
+	Checksum *string
+	// Filtered block events of the block, only set when base.events-storage-mode is jsonb
+	Events BlockEventsJSON `gorm:"type:jsonb"`
+	// Misbehavior evidence committed in the block, stored in its own table by both the tx and block event writers,
+	// only set when base.index-evidence is enabled
+	Evidence []BlockEvidence `gorm:"-"`
+	// Set when the block was committed in base.tolerant-block mode without the txs that failed to decode, reindexing the block
+	// once its txs decode clears the flag
+	Partial bool
+	// Txs of the block that failed to decode in base.tolerant-block mode, stored in their own table by the tx writer
+	TxDecodeFailures []TxDecodeFailure `gorm:"-"`
 }
 
 // Used to keep track of BeginBlock and EndBlock events
@@ -48,7 +77,9 @@ type BlockEventAttribute struct {
 	BlockEvent   BlockEvent
 	BlockEventID uint `gorm:"uniqueIndex:eventAttributeIndex,priority:1"`
 	Value        string
-	Index        uint64 `gorm:"uniqueIndex:eventAttributeIndex,priority:2"`
+	// Set when the decoded value was not valid UTF-8 and is stored base64 encoded instead
+	ValueBase64 bool
+	Index       uint64 `gorm:"uniqueIndex:eventAttributeIndex,priority:2"`
 	// Keys are limited to a smallish subset of string values set by the Cosmos SDK and external modules
 	// Save DB space by storing the key as a foreign key
 	BlockEventAttributeKeyID uint
@@ -73,3 +104,17 @@ type FailedEventBlock struct {
 	BlockchainID uint  `gorm:"uniqueIndex:failedchaineventheight"`
 	Chain        Chain `gorm:"foreignKey:BlockchainID"`
 }
+
+// Reasons a block height is deliberately left unindexed
+const (
+	SkippedBlockReasonManual = "manual"
+)
+
+// SkippedBlock is a height that is deliberately never indexed, it is accounted for rather than failed or missing
+type SkippedBlock struct {
+	ID           uint
+	Height       int64 `gorm:"uniqueIndex:skippedchainheight"`
+	BlockchainID uint  `gorm:"uniqueIndex:skippedchainheight"`
+	Chain        Chain `gorm:"foreignKey:BlockchainID"`
+	Reason       string
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Lifecycle positions of block events stored as jsonb
+const (
+	JSONBeginBlockEvent = "begin_block"
+	JSONEndBlockEvent   = "end_block"
+)
+
+// BlockEventsJSON are the block events of a block stored as a single jsonb document (base.events-storage-mode jsonb),
+// in the same order they are indexed into the normalized block event tables
+type BlockEventsJSON []BlockEventJSON
+
+type BlockEventJSON struct {
+	LifecyclePosition string                    `json:"lifecycle_position"`
+	Index             uint64                    `json:"index"`
+	Type              string                    `json:"type"`
+	Attributes        []BlockEventAttributeJSON `json:"attributes"`
+}
+
+type BlockEventAttributeJSON struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Set when the decoded value was not valid UTF-8 and is stored base64 encoded instead
+	ValueBase64 bool `json:"value_base64,omitempty"`
+}
+
+func (e BlockEventsJSON) Value() (driver.Value, error) {
+	if e == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(e)
+}
+
+func (e *BlockEventsJSON) Scan(value any) error {
+	if value == nil {
+		*e = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T for block events", value)
+	}
+
+	return json.Unmarshal(bytes, e)
+}
@@ -0,0 +1,48 @@
+package models
+
+// Message categories of base.category-tables, derived from the module prefix of the message type URL
+const (
+	MessageCategoryBank         = "bank"
+	MessageCategoryStaking      = "staking"
+	MessageCategoryDistribution = "distribution"
+	MessageCategoryGov          = "gov"
+	MessageCategoryIBC          = "ibc"
+	MessageCategoryWasm         = "wasm"
+	MessageCategoryOther        = "other"
+)
+
+// CategoryMessage holds the columns of the per-category message tables of base.category-tables. Each row copies a message of
+// the category; the messages table still holds every message, since the event, address and other message tables reference it.
+// Every category has its own model so gorm names the tables and their indexes after the category.
+type CategoryMessage struct {
+	ID            uint
+	Height        int64 `gorm:"index"`
+	TxID          uint  `gorm:"index"`
+	Tx            Tx
+	MessageID     uint `gorm:"uniqueIndex"`
+	Message       Message
+	MessageTypeID uint `gorm:"index"`
+	MessageType   MessageType
+	MessageIndex  int
+	// Only set with flags.index-tx-message-raw, like the messages table
+	MessageBytes []byte
+}
+
+type BankMessage struct{ CategoryMessage }
+
+type StakingMessage struct{ CategoryMessage }
+
+type DistributionMessage struct{ CategoryMessage }
+
+type GovMessage struct{ CategoryMessage }
+
+type IBCMessage struct{ CategoryMessage }
+
+type WasmMessage struct{ CategoryMessage }
+
+type OtherMessage struct{ CategoryMessage }
+
+// CategoryMessageTable returns the name of the table gorm migrates the model of the category to
+func CategoryMessageTable(category string) string {
+	return category + "_messages"
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Evidence types stored in the block evidence table
+const (
+	EvidenceTypeDuplicateVote     = "duplicate_vote"
+	EvidenceTypeLightClientAttack = "light_client_attack"
+)
+
+// BlockEvidence is validator misbehavior evidence committed in a block (base.index-evidence). Duplicate vote (double-sign) evidence
+// has a single row for the validator that signed both votes, light client attack evidence has one row per byzantine validator.
+type BlockEvidence struct {
+	ID               uint
+	BlockID          uint `gorm:"uniqueIndex:idx_block_evidence_position,priority:1"`
+	Block            Block
+	Height           int64 `gorm:"index:idx_block_evidence_height"`
+	EvidenceIndex    int   `gorm:"uniqueIndex:idx_block_evidence_position,priority:2"`
+	Type             string
+	Hash             string
+	ValidatorAddress string `gorm:"uniqueIndex:idx_block_evidence_position,priority:3;index:idx_block_evidence_validator"`
+	// Height the misbehavior happened at, the height of the votes or the common height of a light client attack
+	InfractionHeight int64
+	// Rounds of the conflicting votes, only set for duplicate vote evidence
+	VoteARound       *int32
+	VoteBRound       *int32
+	ValidatorPower   int64
+	TotalVotingPower int64
+	Time             time.Time
+}
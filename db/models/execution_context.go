@@ -0,0 +1,26 @@
+package models
+
+// MessageExecutionContext is one layer of wrapping around the action a message executes (base.resolve-execution-context), e.g. an
+// authz execution, an interchain account tx run by the ICA host or an ibc-hooks contract call. The layers of a message are stored
+// outermost first, the initiator of the outermost layer is the ultimate initiator of the action. Initiators can be addresses or
+// port owners on a counterparty chain, so they are stored as strings rather than in the address table.
+type MessageExecutionContext struct {
+	ID        uint
+	Height    int64 `gorm:"index:idx_message_execution_context_height"`
+	TxID      uint  `gorm:"index:idx_message_execution_context_tx"`
+	Tx        Tx
+	MessageID uint `gorm:"uniqueIndex:messageExecutionContextIndex,priority:1"`
+	Message   Message
+	// Position of the layer in the message's context chain, nested layers follow the layer wrapping them
+	Index int `gorm:"uniqueIndex:messageExecutionContextIndex,priority:2"`
+	// Number of layers wrapping this one, 0 for the message itself
+	Depth int
+	Kind  string
+	// Who initiated the layer: the authz grantee, the interchain account owner or the ibc-hooks packet sender
+	Initiator string `gorm:"index:idx_message_execution_context_initiator"`
+	// The address the wrapped message runs as: the authz granter, the interchain account or the ibc-hooks intermediary sender
+	ExecutedAs string
+	// Destination channel of the packet for the IBC layers
+	Channel          string
+	InnerMessageType string
+}
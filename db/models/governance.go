@@ -0,0 +1,32 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// Governance actions stored in the governance messages table
+const (
+	GovernanceActionSubmitProposal = "submit_proposal"
+	GovernanceActionDeposit        = "deposit"
+	GovernanceActionVote           = "vote"
+)
+
+// GovernanceMessage is a structured proposal submission, deposit or vote from the v1 or v1beta1 gov module.
+// Weighted votes are stored as one row per option, with the weight of the option.
+type GovernanceMessage struct {
+	ID         uint
+	Height     int64 `gorm:"index:idx_governance_message_height"`
+	TxID       uint  `gorm:"index:idx_governance_message_tx"`
+	Tx         Tx
+	MessageID  uint `gorm:"uniqueIndex:governanceMessageOption,priority:1"`
+	Message    Message
+	GovVersion string
+	Action     string `gorm:"index:idx_governance_message_proposal_action,priority:2"`
+	ProposalID uint64 `gorm:"index:idx_governance_message_proposal_action,priority:1"`
+	// The proposer, depositor or voter
+	AddressID uint `gorm:"index:idx_governance_message_address"`
+	Address   Address
+	// Vote option and weight, only set for votes
+	Option string              `gorm:"uniqueIndex:governanceMessageOption,priority:2"`
+	Weight decimal.NullDecimal `gorm:"type:decimal(38,18);"`
+	// Deposited coins (the initial deposit for proposal submissions)
+	Amount string
+}
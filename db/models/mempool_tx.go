@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// MempoolTx is a pending tx observed in the node's mempool (base.index-mempool), with the time it was first seen. The committed
+// height is set when a block including the tx is indexed, and stays empty for txs that never made it into an indexed block.
+type MempoolTx struct {
+	ID              uint
+	Hash            string    `gorm:"uniqueIndex"`
+	FirstSeen       time.Time `gorm:"index:idx_mempool_tx_first_seen"`
+	Size            int
+	CommittedHeight *int64 `gorm:"index:idx_mempool_tx_committed_height"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// ModuleBalance is the balance of one denom held by a configured account (e.g. the community pool) as of the end of a block,
+// snapshotted every base.module-balance-stride blocks, one row per denom
+type ModuleBalance struct {
+	ID      uint
+	BlockID uint `gorm:"uniqueIndex:idx_module_balance_block_address_denom,priority:1"`
+	Block   Block
+	Height  int64           `gorm:"index:idx_module_balance_height"`
+	Address string          `gorm:"uniqueIndex:idx_module_balance_block_address_denom,priority:2;index:idx_module_balance_address_denom,priority:1"`
+	Denom   string          `gorm:"uniqueIndex:idx_module_balance_block_address_denom,priority:3;index:idx_module_balance_address_denom,priority:2"`
+	Amount  decimal.Decimal `gorm:"type:decimal(78,0);"`
+}
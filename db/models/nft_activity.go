@@ -0,0 +1,29 @@
+package models
+
+// NFT activity sources and actions stored in the nft activities table
+const (
+	NFTSourceNative = "nft"
+	NFTSourceCW721  = "cw721"
+
+	NFTActionMint     = "mint"
+	NFTActionTransfer = "transfer"
+	NFTActionBurn     = "burn"
+)
+
+// NFTActivity is a mint, transfer or burn of a token of the cosmos nft module or of a cw721 contract. The class ID of cw721
+// tokens is the contract address. PreviousOwner is empty for mints and Owner is empty for burns.
+type NFTActivity struct {
+	ID            uint
+	Height        int64 `gorm:"index:idx_nft_activity_height"`
+	TxID          uint  `gorm:"index:idx_nft_activity_tx"`
+	Tx            Tx
+	MessageID     uint `gorm:"uniqueIndex:nftActivityEvent,priority:1"`
+	Message       Message
+	EventIndex    uint64 `gorm:"uniqueIndex:nftActivityEvent,priority:2"`
+	Source        string
+	Action        string
+	ClassID       string `gorm:"index:idx_nft_activity_token,priority:1"`
+	TokenID       string `gorm:"index:idx_nft_activity_token,priority:2"`
+	PreviousOwner string `gorm:"index:idx_nft_activity_previous_owner"`
+	Owner         string `gorm:"index:idx_nft_activity_owner"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// PostMigrateSQLFile tracks the custom SQL files from database.post-migrate-sql-dir that have been applied
+type PostMigrateSQLFile struct {
+	ID        uint
+	Name      string `gorm:"uniqueIndex"`
+	AppliedAt time.Time
+}
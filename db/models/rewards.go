@@ -0,0 +1,34 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// Distribution module event types stored in the reward events table
+const (
+	RewardEventTypeWithdrawRewards    = "withdraw_rewards"
+	RewardEventTypeWithdrawCommission = "withdraw_commission"
+	RewardEventTypeRewards            = "rewards"
+	RewardEventTypeCommission         = "commission"
+	RewardEventTypeProposerReward     = "proposer_reward"
+)
+
+// RewardEvent is a single coin of a distribution module reward or commission event, one row per denom.
+// Withdrawals come from message events and are linked to their message, allocations to validators come from block events
+// and have no message, they are identified by their lifecycle position in the block instead.
+type RewardEvent struct {
+	ID                uint
+	BlockID           uint `gorm:"uniqueIndex:idx_reward_event_block_position,priority:1,where:message_id IS NULL"`
+	Block             Block
+	Height            int64                   `gorm:"index:idx_reward_event_height"`
+	TxID              *uint                   `gorm:"index:idx_reward_event_tx"`
+	Tx                *Tx                     `gorm:"foreignKey:TxID"`
+	MessageID         *uint                   `gorm:"uniqueIndex:idx_reward_event_message_position,priority:1,where:message_id IS NOT NULL"`
+	Message           *Message                `gorm:"foreignKey:MessageID"`
+	LifecyclePosition *BlockLifecyclePosition `gorm:"uniqueIndex:idx_reward_event_block_position,priority:2,where:message_id IS NULL"`
+	EventIndex        uint64                  `gorm:"uniqueIndex:idx_reward_event_block_position,priority:3,where:message_id IS NULL;uniqueIndex:idx_reward_event_message_position,priority:2,where:message_id IS NOT NULL"`
+	Type              string
+	// Empty for commission and validator allocation events
+	DelegatorAddress string          `gorm:"index:idx_reward_event_delegator"`
+	ValidatorAddress string          `gorm:"index:idx_reward_event_validator"`
+	Amount           decimal.Decimal `gorm:"type:decimal(78,18);"`
+	Denom            string          `gorm:"uniqueIndex:idx_reward_event_block_position,priority:4,where:message_id IS NULL;uniqueIndex:idx_reward_event_message_position,priority:3,where:message_id IS NOT NULL"`
+}
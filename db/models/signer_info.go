@@ -0,0 +1,23 @@
+package models
+
+// TxSignerInfo is a public key that signed, or could sign, a transaction, taken from the signer infos of its auth info.
+// A multisig signer is expanded to one row per member key, all sharing the signer's sequence and threshold, members that did
+// not sign have no sign mode. Account numbers are not part of a transaction and are not stored.
+type TxSignerInfo struct {
+	ID          uint
+	TxID        uint `gorm:"uniqueIndex:idx_tx_signer_info_key,priority:1"`
+	Tx          Tx
+	Height      int64 `gorm:"index:idx_tx_signer_info_height"`
+	SignerIndex int   `gorm:"uniqueIndex:idx_tx_signer_info_key,priority:2"`
+	// Position of the key in the multisig, 0 for single keys
+	KeyIndex int    `gorm:"uniqueIndex:idx_tx_signer_info_key,priority:3"`
+	Address  string `gorm:"index:idx_tx_signer_info_address"`
+	// Type URL and base64 encoded bytes of the public key
+	PubKeyType string
+	PubKey     string
+	Sequence   uint64
+	// Empty for multisig members that did not sign
+	SignMode string
+	// Set for the member keys of a multisig
+	MultisigThreshold *uint32
+}
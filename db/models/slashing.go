@@ -0,0 +1,29 @@
+package models
+
+// Slashing event types stored in the slashing events table
+const (
+	SlashingEventTypeSlash    = "slash"
+	SlashingEventTypeLiveness = "liveness"
+)
+
+// SlashingEvent is a slash, jail or liveness (missed blocks) block event of a validator.
+// The operator address is correlated from the consensus address through the staking module and is empty when it could not be resolved.
+type SlashingEvent struct {
+	ID uint
+	// The block event the row was extracted from
+	BlockID           uint `gorm:"uniqueIndex:slashingEventPositionIndex,priority:1"`
+	Block             Block
+	LifecyclePosition BlockLifecyclePosition `gorm:"uniqueIndex:slashingEventPositionIndex,priority:2"`
+	EventIndex        uint64                 `gorm:"uniqueIndex:slashingEventPositionIndex,priority:3"`
+	Height            int64                  `gorm:"index:idx_slashing_event_height"`
+	Type              string
+	ConsensusAddress  string `gorm:"index:idx_slashing_event_consensus_address"`
+	OperatorAddress   string `gorm:"index:idx_slashing_event_operator_address"`
+	// Reason for the slash (e.g. double_sign, missing_signature), empty for liveness and jail-only events
+	Reason string
+	Power  string
+	// Coins burned by the slash, as reported by the chain
+	Amount       string
+	Jailed       bool
+	MissedBlocks int64
+}
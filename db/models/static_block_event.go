@@ -0,0 +1,14 @@
+package models
+
+// StaticBlockEventRun records the consecutive blocks an unchanged block event repeated in (base.dedupe-static-events). The event is
+// stored once, at the first height of the run, and the blocks after it that emitted the same event (lifecycle position, type and
+// attributes) only extend the run instead of storing their own copy. The event was emitted at every height from FirstHeight to
+// LastHeight, Count is the number of those blocks.
+type StaticBlockEventRun struct {
+	ID           uint
+	BlockEventID uint `gorm:"uniqueIndex"`
+	BlockEvent   BlockEvent
+	FirstHeight  int64 `gorm:"index:idx_static_block_event_run_heights,priority:1"`
+	LastHeight   int64 `gorm:"index:idx_static_block_event_run_heights,priority:2"`
+	Count        int64
+}
@@ -0,0 +1,38 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// Bank module event types that change the supply of a denom
+const (
+	SupplyDeltaEventTypeCoinbase = "coinbase"
+	SupplyDeltaEventTypeBurn     = "burn"
+)
+
+// Parts of a block the supply changes are summed over
+const (
+	SupplyDeltaSourceBeginBlock = "begin_block"
+	SupplyDeltaSourceEndBlock   = "end_block"
+	SupplyDeltaSourceTxs        = "txs"
+)
+
+// SupplyDelta is the change of the supply of a denom in a part of a block, summed over the bank module coinbase (minted) and burn
+// events of the begin blocker, the end blocker or all transactions of the block. Net is Minted minus Burned, the net supply change of
+// a denom at a height is the sum of the net deltas of the block's sources.
+type SupplyDelta struct {
+	ID      uint
+	BlockID uint `gorm:"uniqueIndex:idx_supply_delta_block_source_denom,priority:1"`
+	Block   Block
+	Height  int64           `gorm:"index:idx_supply_delta_denom_height,priority:2"`
+	Source  string          `gorm:"uniqueIndex:idx_supply_delta_block_source_denom,priority:2"`
+	Denom   string          `gorm:"uniqueIndex:idx_supply_delta_block_source_denom,priority:3;index:idx_supply_delta_denom_height,priority:1"`
+	Minted  decimal.Decimal `gorm:"type:decimal(78,0);"`
+	Burned  decimal.Decimal `gorm:"type:decimal(78,0);"`
+	Net     decimal.Decimal `gorm:"type:decimal(78,0);"`
+}
+
+// Add sums the change of another delta of the same denom into the delta
+func (delta *SupplyDelta) Add(other SupplyDelta) {
+	delta.Minted = delta.Minted.Add(other.Minted)
+	delta.Burned = delta.Burned.Add(other.Burned)
+	delta.Net = delta.Minted.Sub(delta.Burned)
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// RowTags are constant key/value labels (configured by base.row-tags) stored as jsonb on indexed rows
+type RowTags map[string]string
+
+func (t RowTags) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(t)
+}
+
+func (t *RowTags) Scan(value any) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T for row tags", value)
+	}
+
+	return json.Unmarshal(bytes, t)
+}
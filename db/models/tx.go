@@ -1,20 +1,33 @@
 package models
 
 import (
+	"time"
+
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type Tx struct {
-	ID              uint
-	Hash            string `gorm:"uniqueIndex"`
-	Code            uint32
-	BlockID         uint
-	Block           Block
-	Memo            string
+	ID      uint
+	Hash    string `gorm:"uniqueIndex"`
+	Code    uint32
+	BlockID uint
+	Block   Block
+	Memo    string
+	// Timestamp of the block, copied from it so txs can be queried by time without a join, only set when base.denormalize-block-time is enabled
+	BlockTime *time.Time `gorm:"index:idx_tx_block_time"`
+	// Height after which the tx is no longer valid, null when the tx has no timeout
+	TimeoutHeight *uint64
+	// Ratio of the gas used to the gas limit declared in the auth info fee, null when no gas limit is declared
+	GasUtilization *float64
+	// Number of signatures of the tx, counting each multisig member that signed, only set when base.index-signer-count is enabled
+	NumSigners *int
+	// Position of the tx in the block's tx list, only set when base.index-tx-position is enabled
+	TxIndex         *int
 	SignerAddresses []Address `gorm:"many2many:tx_signer_addresses;"`
 	Fees            []Fee
+	Tags            RowTags `gorm:"type:jsonb"`
 }
 
 type FailedTx struct {
@@ -24,6 +37,28 @@ type FailedTx struct {
 	Block   Block
 }
 
+// FailedTxLog holds the error diagnostics of a failed tx, stored whether or not the tx itself is indexed
+type FailedTxLog struct {
+	ID        uint
+	BlockID   uint `gorm:"index:idx_failed_tx_log_block"`
+	Block     Block
+	Height    int64
+	Hash      string `gorm:"uniqueIndex"`
+	Code      uint32
+	Codespace string
+	RawLog    string
+}
+
+// TxDecodeFailure is a tx that could not be decoded in a block committed without it in base.tolerant-block mode
+type TxDecodeFailure struct {
+	ID      uint
+	BlockID uint `gorm:"index:idx_tx_decode_failure_block"`
+	Block   Block
+	Height  int64
+	Hash    string `gorm:"uniqueIndex"`
+	Error   string
+}
+
 type Fee struct {
 	ID             uint            `gorm:"primaryKey"`
 	TxID           uint            `gorm:"uniqueIndex:txDenomFee"`
@@ -52,10 +87,32 @@ type Message struct {
 	ID            uint
 	TxID          uint `gorm:"uniqueIndex:messageIndex,priority:1"`
 	Tx            Tx
-	MessageTypeID uint `gorm:"foreignKey:MessageTypeID,index:idx_txid_typeid"`
+	MessageTypeID uint `gorm:"index:idx_txid_typeid"`
 	MessageType   MessageType
 	MessageIndex  int `gorm:"uniqueIndex:messageIndex,priority:2"`
 	MessageBytes  []byte
+	// Gas attributed to the message, null when the chain does not make it determinable
+	GasUsed *int64
+	// Violation of the JSON schema configured for the message type, null when the message conforms or has no schema
+	SchemaError *string
+	// Type URL of the message copied from its message type, only set with database.index-message-type. Its index is created by
+	// CreateMessageTypeIndex rather than the migrations since it is optional.
+	TypeURL *string
+	// Human readable action label of the message type (e.g. send, delegate), only set with base.index-message-action
+	Action *string `gorm:"index"`
+}
+
+// MessageAddress links a message to an address it references (signer, recipient, validator, etc.), for address-centric queries
+type MessageAddress struct {
+	ID        uint
+	Height    int64 `gorm:"index:idx_message_address_height"`
+	TxID      uint  `gorm:"index:idx_message_address_tx"`
+	Tx        Tx
+	MessageID uint `gorm:"uniqueIndex:messageAddressRole,priority:1"`
+	Message   Message
+	AddressID uint `gorm:"uniqueIndex:messageAddressRole,priority:2;index:idx_message_address_address"`
+	Address   Address
+	Role      string `gorm:"uniqueIndex:messageAddressRole,priority:3"`
 }
 
 type FailedMessage struct {
@@ -0,0 +1,12 @@
+package models
+
+// TxRaw is the raw bytes of a transaction exactly as they were included in the block (flags.index-tx-raw), stored apart from the
+// decoded tx data so the transaction can be reproduced byte for byte.
+type TxRaw struct {
+	ID     uint
+	Hash   string `gorm:"uniqueIndex"`
+	TxID   uint   `gorm:"index:idx_tx_raw_tx_id"`
+	Tx     Tx
+	Height int64 `gorm:"index:idx_tx_raw_height"`
+	Raw    []byte
+}
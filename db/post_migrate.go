@@ -0,0 +1,72 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+)
+
+// ApplyPostMigrateSQL runs the .sql files in the directory in lexical order, each in its own transaction, returning the names of the
+// files applied. Applied files are tracked by name in the post_migrate_sql_files table and are not run again on later starts.
+// The first failing file stops the run, files before it stay applied.
+func ApplyPostMigrateSQL(db *gorm.DB, dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading post-migrate SQL dir %s: %w", dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".sql" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	if err := db.AutoMigrate(&models.PostMigrateSQLFile{}); err != nil {
+		return nil, err
+	}
+
+	var appliedFiles []models.PostMigrateSQLFile
+	if err := db.Find(&appliedFiles).Error; err != nil {
+		return nil, err
+	}
+
+	alreadyApplied := make(map[string]bool)
+	for _, appliedFile := range appliedFiles {
+		alreadyApplied[appliedFile.Name] = true
+	}
+
+	var applied []string
+	for _, name := range names {
+		if alreadyApplied[name] {
+			continue
+		}
+
+		sql, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return applied, fmt.Errorf("error reading post-migrate SQL file %s: %w", name, err)
+		}
+
+		err = db.Transaction(func(dbTransaction *gorm.DB) error {
+			if err := dbTransaction.Exec(string(sql)).Error; err != nil {
+				return err
+			}
+			return dbTransaction.Create(&models.PostMigrateSQLFile{Name: name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("post-migrate SQL file %s failed: %w", name, err)
+		}
+
+		config.Log.Infof("Applied post-migrate SQL file %s", name)
+		applied = append(applied, name)
+	}
+
+	return applied, nil
+}
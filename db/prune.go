@@ -0,0 +1,241 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+)
+
+// PruneBelow deletes all indexed data for blocks below the given height in a single transaction.
+// Rows are deleted children first to respect foreign-key order. Pruning above the resume cursor (the highest indexed block)
+// would remove the cursor itself and is refused unless force is set.
+// Rows of custom models that reference the pruned rows through a foreign key are deleted along with them.
+func PruneBelow(db *gorm.DB, chainID uint, height int64, force bool) (int64, error) {
+	highestBlock := GetHighestIndexedBlock(db, chainID)
+	if height > highestBlock.Height && !force {
+		return 0, fmt.Errorf("refusing to prune below height %d, it is above the current resume cursor at height %d", height, highestBlock.Height)
+	}
+
+	var prunedBlocks int64
+	err := db.Transaction(func(dbTransaction *gorm.DB) error {
+		blockIDs := dbTransaction.Model(&models.Block{}).Select("id").Where("chain_id = ? AND height < ?", chainID, height)
+
+		if err := deleteBlockTxData(dbTransaction, blockIDs); err != nil {
+			return err
+		}
+
+		if err := deleteBlockEventData(dbTransaction, blockIDs); err != nil {
+			return err
+		}
+
+		deletes := []rowDelete{
+			{&models.ModuleBalance{}, "block_id IN (?)", blockIDs},
+			{&models.SupplyDelta{}, "block_id IN (?)", blockIDs},
+			{&models.BlockEvidence{}, "block_id IN (?)", blockIDs},
+		}
+
+		if err := deleteCustomReferences(dbTransaction, map[any]*gorm.DB{&models.Block{}: blockIDs}, deletes); err != nil {
+			return err
+		}
+
+		for _, toDelete := range deletes {
+			if err := dbTransaction.Where(toDelete.query, toDelete.arg).Delete(toDelete.model).Error; err != nil {
+				config.Log.Errorf("Error pruning %T rows. Err: %v", toDelete.model, err)
+				return err
+			}
+		}
+
+		result := dbTransaction.Where("chain_id = ? AND height < ?", chainID, height).Delete(&models.Block{})
+		if result.Error != nil {
+			config.Log.Error("Error pruning blocks.", result.Error)
+			return result.Error
+		}
+		prunedBlocks = result.RowsAffected
+
+		if err := dbTransaction.Where("blockchain_id = ? AND height < ?", chainID, height).Delete(&models.FailedBlock{}).Error; err != nil {
+			config.Log.Error("Error pruning failed blocks.", err)
+			return err
+		}
+
+		if err := dbTransaction.Where("blockchain_id = ? AND height < ?", chainID, height).Delete(&models.FailedEventBlock{}).Error; err != nil {
+			config.Log.Error("Error pruning failed event blocks.", err)
+			return err
+		}
+
+		if err := dbTransaction.Where("blockchain_id = ? AND height < ?", chainID, height).Delete(&models.SkippedBlock{}).Error; err != nil {
+			config.Log.Error("Error pruning skipped blocks.", err)
+			return err
+		}
+
+		return nil
+	})
+
+	return prunedBlocks, err
+}
+
+// rowDelete is a delete of the rows of model matching query, with arg usually a subquery selecting the parent IDs
+type rowDelete struct {
+	model any
+	query string
+	arg   any
+}
+
+// deleteBlockTxData deletes the transactions of the given blocks along with all of their child rows (messages, message events, fees, etc.),
+// children first to respect foreign-key order. blockIDs is a subquery selecting block IDs.
+func deleteBlockTxData(dbTransaction *gorm.DB, blockIDs *gorm.DB) error {
+	txIDs := dbTransaction.Model(&models.Tx{}).Select("id").Where("block_id IN (?)", blockIDs)
+	messageIDs := dbTransaction.Model(&models.Message{}).Select("id").Where("tx_id IN (?)", txIDs)
+	messageEventIDs := dbTransaction.Model(&models.MessageEvent{}).Select("id").Where("message_id IN (?)", messageIDs)
+
+	deletes := []rowDelete{
+		{&models.MessageParserError{}, "message_id IN (?)", messageIDs},
+		{&models.MessageAddress{}, "message_id IN (?)", messageIDs},
+		{&models.GovernanceMessage{}, "message_id IN (?)", messageIDs},
+		{&models.RewardEvent{}, "message_id IN (?)", messageIDs},
+		{&models.NFTActivity{}, "message_id IN (?)", messageIDs},
+		{&models.BalanceDelta{}, "message_id IN (?)", messageIDs},
+		{&models.MessageExecutionContext{}, "message_id IN (?)", messageIDs},
+		{&models.BankMessage{}, "message_id IN (?)", messageIDs},
+		{&models.StakingMessage{}, "message_id IN (?)", messageIDs},
+		{&models.DistributionMessage{}, "message_id IN (?)", messageIDs},
+		{&models.GovMessage{}, "message_id IN (?)", messageIDs},
+		{&models.IBCMessage{}, "message_id IN (?)", messageIDs},
+		{&models.WasmMessage{}, "message_id IN (?)", messageIDs},
+		{&models.OtherMessage{}, "message_id IN (?)", messageIDs},
+		{&models.MessageEventAttribute{}, "message_event_id IN (?)", messageEventIDs},
+		{&models.MessageEvent{}, "message_id IN (?)", messageIDs},
+		{&models.Message{}, "tx_id IN (?)", txIDs},
+		{&models.FailedMessage{}, "tx_id IN (?)", txIDs},
+		{&models.Fee{}, "tx_id IN (?)", txIDs},
+		{&models.TxSignerInfo{}, "tx_id IN (?)", txIDs},
+		{&models.TxRaw{}, "tx_id IN (?)", txIDs},
+		{&models.FailedTx{}, "block_id IN (?)", blockIDs},
+		{&models.FailedTxLog{}, "block_id IN (?)", blockIDs},
+		{&models.TxDecodeFailure{}, "block_id IN (?)", blockIDs},
+	}
+
+	// Custom parser rows reference the messages, message events and txs, so they go first
+	parents := map[any]*gorm.DB{&models.MessageEvent{}: messageEventIDs, &models.Message{}: messageIDs, &models.Tx{}: txIDs}
+	if err := deleteCustomReferences(dbTransaction, parents, append(deletes, rowDelete{&models.Tx{}, "", nil})); err != nil {
+		return err
+	}
+
+	// The signer join table has no model of its own
+	if err := dbTransaction.Exec("DELETE FROM tx_signer_addresses WHERE tx_id IN (?)", txIDs).Error; err != nil {
+		config.Log.Error("Error deleting tx signer addresses.", err)
+		return err
+	}
+
+	for _, toDelete := range deletes {
+		if err := dbTransaction.Where(toDelete.query, toDelete.arg).Delete(toDelete.model).Error; err != nil {
+			config.Log.Errorf("Error deleting %T rows. Err: %v", toDelete.model, err)
+			return err
+		}
+	}
+
+	if err := dbTransaction.Where("block_id IN (?)", blockIDs).Delete(&models.Tx{}).Error; err != nil {
+		config.Log.Error("Error deleting txes.", err)
+		return err
+	}
+
+	return nil
+}
+
+// deleteBlockEventData deletes the block events of the given blocks along with their attributes, custom parser rows and the block
+// level rows extracted from them, children first to respect foreign-key order. blockIDs is a subquery selecting block IDs.
+func deleteBlockEventData(dbTransaction *gorm.DB, blockIDs *gorm.DB) error {
+	blockEventIDs := dbTransaction.Model(&models.BlockEvent{}).Select("id").Where("block_id IN (?)", blockIDs)
+
+	deletes := []rowDelete{
+		{&models.BlockEventParserError{}, "block_event_id IN (?)", blockEventIDs},
+		{&models.StaticBlockEventRun{}, "block_event_id IN (?)", blockEventIDs},
+		{&models.BlockEventAttribute{}, "block_event_id IN (?)", blockEventIDs},
+		{&models.SlashingEvent{}, "block_id IN (?)", blockIDs},
+		{&models.RewardEvent{}, "block_id IN (?) AND message_id IS NULL", blockIDs},
+		{&models.BalanceDelta{}, "block_id IN (?) AND message_id IS NULL", blockIDs},
+		{&models.ArchivedBlockEvents{}, "block_id IN (?)", blockIDs},
+		{&models.BlockEvent{}, "block_id IN (?)", blockIDs},
+	}
+
+	if err := deleteCustomReferences(dbTransaction, map[any]*gorm.DB{&models.BlockEvent{}: blockEventIDs}, deletes); err != nil {
+		return err
+	}
+
+	for _, toDelete := range deletes {
+		if err := dbTransaction.Where(toDelete.query, toDelete.arg).Delete(toDelete.model).Error; err != nil {
+			config.Log.Errorf("Error deleting %T rows. Err: %v", toDelete.model, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteCustomReferences deletes the rows of custom model tables, such as the ones custom parsers write to, that reference the rows
+// selected from the parent models through a single column foreign key. The indexer's own tables in known are left to the caller,
+// which deletes them in foreign-key order. Custom models are registered by the indexer's user, so their tables are found from the
+// foreign-key constraints of the schema.
+func deleteCustomReferences(dbTransaction *gorm.DB, parents map[any]*gorm.DB, known []rowDelete) error {
+	knownTables := make(map[string]bool, len(known))
+	for _, toDelete := range known {
+		table, err := modelTable(dbTransaction, toDelete.model)
+		if err != nil {
+			return err
+		}
+		knownTables[table] = true
+	}
+
+	parentIDs := make(map[string]*gorm.DB, len(parents))
+	parentTables := make([]string, 0, len(parents))
+	for model, ids := range parents {
+		table, err := modelTable(dbTransaction, model)
+		if err != nil {
+			return err
+		}
+		parentIDs[table] = ids
+		parentTables = append(parentTables, table)
+	}
+
+	var references []struct {
+		Child      string
+		Parent     string
+		ColumnName string
+	}
+
+	err := dbTransaction.Raw(`SELECT child.relname AS child, parent.relname AS parent, a.attname AS column_name
+		FROM pg_constraint c
+		JOIN pg_class child ON c.conrelid = child.oid
+		JOIN pg_class parent ON c.confrelid = parent.oid
+		JOIN pg_namespace n ON child.relnamespace = n.oid
+		JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+		WHERE c.contype = 'f' AND n.nspname = current_schema() AND array_length(c.conkey, 1) = 1 AND parent.relname IN ?`, parentTables).Scan(&references).Error
+	if err != nil {
+		config.Log.Error("Error finding custom tables referencing deleted rows.", err)
+		return err
+	}
+
+	for _, reference := range references {
+		if knownTables[reference.Child] || reference.Child == reference.Parent {
+			continue
+		}
+
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s IN (?)", quoteIdentifier(reference.Child), quoteIdentifier(reference.ColumnName))
+		if err := dbTransaction.Exec(query, parentIDs[reference.Parent]).Error; err != nil {
+			config.Log.Errorf("Error deleting custom %s rows. Err: %v", reference.Child, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// modelTable returns the table name of the model
+func modelTable(db *gorm.DB, model any) (string, error) {
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(model); err != nil {
+		return "", err
+	}
+	return statement.Schema.Table, nil
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Redactor scrubs sensitive fields of a record before it is stored. It receives a pointer to the record (e.g. *models.Tx) and
+// returns the record to store, either the same pointer after modifying it in place or a replacement of the same type.
+type Redactor func(record any) any
+
+// UseRedactors applies the redactors, chained in order, to every record inserted (or upserted) through the database connection,
+// including custom models, just before it is written. Updates of stored rows are redacted too: the updated columns are set on an
+// otherwise empty record of the model, which is passed through the redactors, and the redacted values are written. Updated columns
+// that are not fields of the model, e.g. registered tx columns, are written as they are.
+func UseRedactors(db *gorm.DB, redactors []Redactor) error {
+	if len(redactors) == 0 {
+		return nil
+	}
+
+	err := db.Callback().Create().Before("gorm:create").Register("cosmos_indexer:redact", func(tx *gorm.DB) {
+		if err := redactValue(tx.Statement.ReflectValue, redactors); err != nil {
+			_ = tx.AddError(err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.Callback().Update().Before("gorm:update").Register("cosmos_indexer:redact_update", func(tx *gorm.DB) {
+		var err error
+		if columns, ok := tx.Statement.Dest.(map[string]any); ok {
+			err = redactColumns(tx.Statement, columns, redactors)
+		} else {
+			err = redactValue(reflect.ValueOf(tx.Statement.Dest), redactors)
+		}
+		if err != nil {
+			_ = tx.AddError(err)
+		}
+	})
+}
+
+// redactValue redacts the record, or every record of the slice or array
+func redactValue(value reflect.Value, redactors []Redactor) error {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := redactRecord(value.Index(i), redactors); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct, reflect.Pointer:
+		return redactRecord(value, redactors)
+	}
+	return nil
+}
+
+// redactColumns redacts the values of the columns updated on rows of the statement model
+func redactColumns(statement *gorm.Statement, columns map[string]any, redactors []Redactor) error {
+	if statement.Schema == nil {
+		return nil
+	}
+
+	record := reflect.New(statement.Schema.ModelType)
+	var redactedColumns []string
+	for column, value := range columns {
+		field := statement.Schema.LookUpField(column)
+		if _, isExpression := value.(clause.Expression); field == nil || isExpression {
+			continue
+		}
+		if err := field.Set(statement.Context, record.Elem(), value); err != nil {
+			return fmt.Errorf("error setting the updated %s column to redact it: %w", column, err)
+		}
+		redactedColumns = append(redactedColumns, column)
+	}
+
+	if len(redactedColumns) == 0 {
+		return nil
+	}
+	if err := redactRecord(record, redactors); err != nil {
+		return err
+	}
+
+	for _, column := range redactedColumns {
+		columns[column], _ = statement.Schema.LookUpField(column).ValueOf(statement.Context, record.Elem())
+	}
+	return nil
+}
+
+func redactRecord(value reflect.Value, redactors []Redactor) error {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct || !value.CanAddr() {
+		return nil
+	}
+
+	record := value.Addr().Interface()
+	for _, redactor := range redactors {
+		record = redactor(record)
+	}
+
+	redacted := reflect.ValueOf(record)
+	switch {
+	case !redacted.IsValid():
+		return fmt.Errorf("redactor returned nil for a %s record", value.Type())
+	case redacted.Type() == value.Addr().Type():
+		if redacted.IsNil() {
+			return fmt.Errorf("redactor returned a nil %T record", record)
+		}
+		if redacted.Pointer() != value.Addr().Pointer() {
+			value.Set(redacted.Elem())
+		}
+	case redacted.Type() == value.Type():
+		value.Set(redacted)
+	default:
+		return fmt.Errorf("redactor returned a %T record for a %s record", record, value.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type RedactTestSuite struct {
+	suite.Suite
+}
+
+func (suite *RedactTestSuite) TestNilRedactedRecord() {
+	tx := models.Tx{Memo: "memo"}
+	nilRedactor := func(record any) any { return nil }
+
+	var err error
+	suite.NotPanics(func() { err = redactValue(reflect.ValueOf(&tx), []Redactor{nilRedactor}) })
+	suite.ErrorContains(err, "returned nil")
+}
+
+func (suite *RedactTestSuite) TestUpdatedColumnsRedacted() {
+	txSchema, err := schema.Parse(&models.Tx{}, &sync.Map{}, schema.NamingStrategy{})
+	suite.Require().NoError(err)
+	statement := &gorm.Statement{Schema: txSchema, Context: context.Background()}
+
+	redactor := func(record any) any {
+		if tx, ok := record.(*models.Tx); ok {
+			tx.Memo = "redacted"
+		}
+		return record
+	}
+
+	// Registered tx columns are not fields of the model and are written as they are
+	columns := map[string]any{"memo": "ssn 123-45-6789", "chain_fee_tier": "high"}
+	suite.Require().NoError(redactColumns(statement, columns, []Redactor{redactor}))
+	suite.Equal(map[string]any{"memo": "redacted", "chain_fee_tier": "high"}, columns)
+}
+
+func TestRedactTestSuite(t *testing.T) {
+	suite.Run(t, new(RedactTestSuite))
+}
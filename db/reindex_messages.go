@@ -0,0 +1,200 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+)
+
+// ErrMissingMessageBytes is returned when messages of the type being reindexed were stored without their raw bytes
+var ErrMissingMessageBytes = errors.New("messages are missing their raw bytes, they must be indexed with flags.index-tx-message-raw to be reindexed")
+
+// StoredMessage is an indexed message being reindexed from its raw bytes, along with where it came from and its stored events
+type StoredMessage struct {
+	Message       models.Message
+	Height        int64
+	TxHash        string
+	MessageEvents []MessageEventDBWrapper
+}
+
+// MessageRedecoder re-decodes a stored message from its raw bytes. The SchemaError, Addresses and MessageParsedDatasets
+// of the returned wrapper replace the stored ones, every other column and row of the message is left untouched.
+type MessageRedecoder func(message StoredMessage) (MessageDBWrapper, error)
+
+type storedMessageRow struct {
+	ID     uint
+	Height int64
+	TxHash string
+}
+
+// ReindexMessages re-decodes every message of the type indexed for the chain and updates the derived data in place,
+// batchSize messages per transaction. It errors before updating anything if any of the messages has no raw bytes.
+// It returns the number of messages reindexed.
+func ReindexMessages(db *gorm.DB, conf config.IndexConfig, chainID uint, messageType string, batchSize int, redecode MessageRedecoder, messageParserTrackers map[string]models.MessageParser) (int64, error) {
+	var storedType models.MessageType
+	err := db.Where("message_type = ?", messageType).First(&storedType).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("no messages of type %s have been indexed", messageType)
+		}
+		return 0, err
+	}
+
+	typeMessages := func() *gorm.DB {
+		return db.Table("messages").
+			Joins("JOIN txes ON txes.id = messages.tx_id").
+			Joins("JOIN blocks ON blocks.id = txes.block_id").
+			Where("blocks.chain_id = ? AND messages.message_type_id = ?", chainID, storedType.ID)
+	}
+
+	var missing int64
+	err = typeMessages().Where("messages.message_bytes IS NULL OR length(messages.message_bytes) = 0").Count(&missing).Error
+	if err != nil {
+		return 0, err
+	}
+	if missing != 0 {
+		return 0, fmt.Errorf("%d messages of type %s: %w", missing, messageType, ErrMissingMessageBytes)
+	}
+
+	var reindexed int64
+	var lastID uint
+	for {
+		var rows []storedMessageRow
+		err = typeMessages().
+			Select("messages.id, blocks.height, txes.hash AS tx_hash").
+			Where("messages.id > ?", lastID).
+			Order("messages.id").
+			Limit(batchSize).
+			Scan(&rows).Error
+		if err != nil {
+			return reindexed, err
+		}
+
+		if len(rows) == 0 {
+			return reindexed, nil
+		}
+
+		err = db.Transaction(func(dbTransaction *gorm.DB) error {
+			return reindexMessageBatch(dbTransaction, conf, rows, redecode, messageParserTrackers)
+		})
+		if err != nil {
+			return reindexed, err
+		}
+
+		reindexed += int64(len(rows))
+		lastID = rows[len(rows)-1].ID
+		config.Log.Infof("Reindexed %d messages of type %s", reindexed, messageType)
+	}
+}
+
+func reindexMessageBatch(db *gorm.DB, conf config.IndexConfig, rows []storedMessageRow, redecode MessageRedecoder, messageParserTrackers map[string]models.MessageParser) error {
+	storedMessages, err := loadStoredMessages(db, rows)
+	if err != nil {
+		return err
+	}
+
+	var parsedMessages []MessageDBWrapper
+	for _, stored := range storedMessages {
+		wrapper, err := redecode(stored)
+		if err != nil {
+			return fmt.Errorf("error re-decoding message %d at height %d (tx %s): %w", stored.Message.ID, stored.Height, stored.TxHash, err)
+		}
+
+		message := stored.Message
+		message.SchemaError = wrapper.Message.SchemaError
+		wrapper.Message = message
+		wrapper.MessageEvents = stored.MessageEvents
+
+		err = db.Model(&models.Message{}).Where("id = ?", message.ID).Update("schema_error", message.SchemaError).Error
+		if err != nil {
+			config.Log.Error("Error updating message schema error.", err)
+			return err
+		}
+
+		if conf.Base.IndexAddresses {
+			err = db.Where("message_id = ?", message.ID).Delete(&models.MessageAddress{}).Error
+			if err != nil {
+				config.Log.Error("Error clearing message addresses.", err)
+				return err
+			}
+
+			err = indexMessageAddresses(db, models.Block{Height: stored.Height}, TxDBWrapper{Tx: models.Tx{ID: message.TxID}, Messages: []MessageDBWrapper{wrapper}})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(wrapper.MessageParsedDatasets) != 0 {
+			parsedMessages = append(parsedMessages, wrapper)
+		}
+	}
+
+	if len(parsedMessages) != 0 {
+		return IndexCustomMessages(conf, db, nil, false, []TxDBWrapper{{Messages: parsedMessages}}, messageParserTrackers)
+	}
+
+	return nil
+}
+
+// loadStoredMessages loads the messages of the rows with their type and their events with the attributes, in row order
+func loadStoredMessages(db *gorm.DB, rows []storedMessageRow) ([]StoredMessage, error) {
+	messageIDs := make([]uint, len(rows))
+	for i, row := range rows {
+		messageIDs[i] = row.ID
+	}
+
+	var messages []models.Message
+	err := db.Preload("MessageType").Where("id IN ?", messageIDs).Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var events []models.MessageEvent
+	err = db.Preload("MessageEventType").Where("message_id IN ?", messageIDs).Order(`message_id, "index"`).Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	eventIDs := make([]uint, len(events))
+	for i, event := range events {
+		eventIDs[i] = event.ID
+	}
+
+	attributesByEvent := make(map[uint][]models.MessageEventAttribute)
+	if len(eventIDs) != 0 {
+		var attributes []models.MessageEventAttribute
+		err = db.Preload("MessageEventAttributeKey").Where("message_event_id IN ?", eventIDs).Order(`message_event_id, "index"`).Find(&attributes).Error
+		if err != nil {
+			return nil, err
+		}
+
+		for _, attribute := range attributes {
+			attributesByEvent[attribute.MessageEventID] = append(attributesByEvent[attribute.MessageEventID], attribute)
+		}
+	}
+
+	eventsByMessage := make(map[uint][]MessageEventDBWrapper)
+	for _, event := range events {
+		eventsByMessage[event.MessageID] = append(eventsByMessage[event.MessageID], MessageEventDBWrapper{MessageEvent: event, Attributes: attributesByEvent[event.ID]})
+	}
+
+	messagesByID := make(map[uint]models.Message)
+	for _, message := range messages {
+		messagesByID[message.ID] = message
+	}
+
+	storedMessages := make([]StoredMessage, len(rows))
+	for i, row := range rows {
+		storedMessages[i] = StoredMessage{
+			Message:       messagesByID[row.ID],
+			Height:        row.Height,
+			TxHash:        row.TxHash,
+			MessageEvents: eventsByMessage[row.ID],
+		}
+	}
+
+	return storedMessages, nil
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Postgres error codes for transactions aborted due to concurrent transactions, safe to re-run
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+// Postgres error codes for connections dropped by the server, e.g. during a failover or restart
+const (
+	pgConnectionExceptionClass = "08"
+	pgAdminShutdown            = "57P01"
+	pgCrashShutdown            = "57P02"
+	pgCannotConnectNow         = "57P03"
+)
+
+// IsSerializationFailure reports whether the error is a Postgres serialization failure or deadlock
+func IsSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+}
+
+// RetryOnSerializationFailure runs fn and re-runs it up to retries more times while it fails with a serialization failure or deadlock.
+// fn must run its inserts in a fresh DB transaction on every call. Any other error is returned immediately.
+func RetryOnSerializationFailure(retries int64, fn func() error) error {
+	return RetryCommit(config.Database{CommitRetries: retries}, fn)
+}
+
+// IsConnectionFailure reports whether the error is a dropped or refused database connection rather than a logical error of the
+// statements. Cancelled and timed out contexts are not connection failures.
+func IsConnectionFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, pgConnectionExceptionClass) || pgErr.Code == pgAdminShutdown || pgErr.Code == pgCrashShutdown || pgErr.Code == pgCannotConnectNow
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr) || pgconn.SafeToRetry(err)
+}
+
+// RetryCommit runs the block commit fn like RetryOnSerializationFailure, re-running it up to database.commit-retries more times
+// right away on serialization failures and deadlocks. With database.reconnect-retries it is also re-run after connection failures,
+// waiting database.connect-retry-delay before the first reconnection attempt and doubling the wait after each further failure, so
+// the connection pool can replace the dropped connections once the database is back. Any other error is returned immediately.
+func RetryCommit(dbConf config.Database, fn func() error) error {
+	serializationRetries, reconnectRetries := int64(0), int64(0)
+	delay := time.Duration(dbConf.ConnectRetryDelay) * time.Second
+
+	err := fn()
+	for {
+		switch {
+		case IsSerializationFailure(err) && serializationRetries < dbConf.CommitRetries:
+			serializationRetries++
+			config.Log.Warnf("DB transaction aborted by a concurrent transaction, retrying (attempt %d of %d). Err: %v", serializationRetries, dbConf.CommitRetries, err)
+		case IsConnectionFailure(err) && reconnectRetries < dbConf.ReconnectRetries:
+			reconnectRetries++
+			config.Log.Warnf("Lost the database connection, retrying the block in %s (attempt %d of %d). Err: %v", delay, reconnectRetries, dbConf.ReconnectRetries, err)
+			connectSleep(delay)
+			delay = min(delay*2, maxConnectRetryDelay)
+		default:
+			return err
+		}
+		err = fn()
+	}
+}
+
+// Upper bound on the wait between initial connection attempts, the delay doubles after each failed attempt until it reaches this
+const maxConnectRetryDelay = time.Minute
+
+// connectSleep waits between connection attempts, overridden in tests
+var connectSleep = time.Sleep
+
+// RetryConnect runs connect and re-runs it up to retries more times while it fails, waiting delay before the first retry and
+// doubling the wait after each further failure. It is used for the initial connection, when the database may still be starting up.
+func RetryConnect(retries int64, delay time.Duration, connect func() (*gorm.DB, error)) (*gorm.DB, error) {
+	database, err := connect()
+	for attempt := int64(1); attempt <= retries && err != nil; attempt++ {
+		config.Log.Warnf("Could not connect to the database, retrying in %s (attempt %d of %d). Err: %v", delay, attempt, retries, err)
+		connectSleep(delay)
+		delay = min(delay*2, maxConnectRetryDelay)
+		database, err = connect()
+	}
+	return database, err
+}
+
+// PostgresDbConnectWithRetry connects to the configured database, retrying the initial connection according to
+// database.connect-retries and database.connect-retry-delay
+func PostgresDbConnectWithRetry(dbConf config.Database) (*gorm.DB, error) {
+	return RetryConnect(dbConf.ConnectRetries, time.Duration(dbConf.ConnectRetryDelay)*time.Second, func() (*gorm.DB, error) {
+		return PostgresDbConnect(dbConf.Host, dbConf.Port, dbConf.Database, dbConf.User, dbConf.Password, strings.ToLower(dbConf.LogLevel))
+	})
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type RetryTestSuite struct {
+	suite.Suite
+}
+
+func (suite *RetryTestSuite) TestRetryOnSerializationFailure() {
+	attempts := 0
+	err := RetryOnSerializationFailure(3, func() error {
+		attempts++
+		if attempts == 1 {
+			return &pgconn.PgError{Code: pgSerializationFailure}
+		}
+		return nil
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(2, attempts)
+}
+
+func (suite *RetryTestSuite) TestRetriesExhausted() {
+	attempts := 0
+	err := RetryOnSerializationFailure(2, func() error {
+		attempts++
+		return &pgconn.PgError{Code: pgDeadlockDetected}
+	})
+
+	suite.True(IsSerializationFailure(err))
+	suite.Equal(3, attempts)
+}
+
+func (suite *RetryTestSuite) TestNoRetryOnOtherErrors() {
+	attempts := 0
+	err := RetryOnSerializationFailure(3, func() error {
+		attempts++
+		return &pgconn.PgError{Code: "23505"}
+	})
+
+	suite.Error(err)
+	suite.False(IsSerializationFailure(err))
+	suite.Equal(1, attempts)
+
+	suite.False(IsSerializationFailure(errors.New("connection refused")))
+}
+
+func (suite *RetryTestSuite) TestRetryConnectUntilAvailable() {
+	originalSleep := connectSleep
+	defer func() { connectSleep = originalSleep }()
+
+	var delays []time.Duration
+	connectSleep = func(delay time.Duration) { delays = append(delays, delay) }
+
+	attempts := 0
+	database, err := RetryConnect(5, time.Second, func() (*gorm.DB, error) {
+		attempts++
+		if attempts <= 2 {
+			return nil, errors.New("connection refused")
+		}
+		return &gorm.DB{}, nil
+	})
+
+	suite.Require().NoError(err)
+	suite.NotNil(database)
+	suite.Equal(3, attempts)
+	suite.Equal([]time.Duration{time.Second, 2 * time.Second}, delays)
+}
+
+func (suite *RetryTestSuite) TestRetryConnectExhausted() {
+	originalSleep := connectSleep
+	defer func() { connectSleep = originalSleep }()
+	connectSleep = func(time.Duration) {}
+
+	attempts := 0
+	_, err := RetryConnect(2, time.Second, func() (*gorm.DB, error) {
+		attempts++
+		return nil, errors.New("connection refused")
+	})
+
+	suite.Error(err)
+	suite.Equal(3, attempts)
+}
+
+func (suite *RetryTestSuite) TestRetryCommitReconnects() {
+	originalSleep := connectSleep
+	defer func() { connectSleep = originalSleep }()
+
+	var delays []time.Duration
+	connectSleep = func(delay time.Duration) { delays = append(delays, delay) }
+
+	attempts := 0
+	err := RetryCommit(config.Database{CommitRetries: 1, ReconnectRetries: 3, ConnectRetryDelay: 1}, func() error {
+		attempts++
+		switch attempts {
+		case 1:
+			return &pgconn.PgError{Code: pgAdminShutdown}
+		case 2:
+			return fmt.Errorf("error creating block: %w", io.ErrUnexpectedEOF)
+		case 3:
+			return &pgconn.PgError{Code: pgSerializationFailure}
+		}
+		return nil
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(4, attempts)
+	// Serialization failures are retried right away
+	suite.Equal([]time.Duration{time.Second, 2 * time.Second}, delays)
+}
+
+func (suite *RetryTestSuite) TestRetryCommitReconnectsExhausted() {
+	originalSleep := connectSleep
+	defer func() { connectSleep = originalSleep }()
+	connectSleep = func(time.Duration) {}
+
+	attempts := 0
+	err := RetryCommit(config.Database{ReconnectRetries: 2}, func() error {
+		attempts++
+		return driver.ErrBadConn
+	})
+
+	suite.True(IsConnectionFailure(err))
+	suite.Equal(3, attempts)
+}
+
+func (suite *RetryTestSuite) TestNoReconnectOnLogicalErrors() {
+	attempts := 0
+	err := RetryCommit(config.Database{ReconnectRetries: 3}, func() error {
+		attempts++
+		return &pgconn.PgError{Code: "23505"}
+	})
+
+	suite.Error(err)
+	suite.Equal(1, attempts)
+
+	suite.False(IsConnectionFailure(context.DeadlineExceeded))
+	suite.False(IsConnectionFailure(errors.New("relation \"blocks\" does not exist")))
+}
+
+func TestRetryTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryTestSuite))
+}
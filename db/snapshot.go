@@ -0,0 +1,361 @@
+package db
+
+import (
+	"bufio"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"gorm.io/gorm"
+)
+
+const (
+	snapshotFormat  = "cosmos-indexer-snapshot"
+	snapshotVersion = 1
+	// Rows are restored in batches through json_populate_recordset to keep the number of round trips down
+	restoreBatchSize = 500
+)
+
+// snapshotLine is a single JSON line in the (gzipped) snapshot stream. The stream starts with a header line holding the
+// format and version, followed by one table line per table and the rows of that table.
+// Rows are stored as column name to value objects so a snapshot can be restored into a schema with added or removed columns.
+type snapshotLine struct {
+	Format  string          `json:"format,omitempty"`
+	Version int             `json:"version,omitempty"`
+	Table   string          `json:"table,omitempty"`
+	Columns []string        `json:"columns,omitempty"`
+	Row     json.RawMessage `json:"row,omitempty"`
+}
+
+// SnapshotResult holds the number of rows written or restored per table.
+type SnapshotResult map[string]int64
+
+// Snapshot writes every table in the current schema to the writer as gzipped JSON lines.
+// Tables are written parents first, so a restore can insert rows in stream order without breaking foreign-key constraints.
+// All tables are read in a single repeatable read transaction, so the snapshot is consistent while the indexer keeps writing.
+func Snapshot(db *gorm.DB, writer io.Writer) (SnapshotResult, error) {
+	var result SnapshotResult
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = snapshot(tx, writer)
+		return err
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func snapshot(db *gorm.DB, writer io.Writer) (SnapshotResult, error) {
+	tables, err := snapshotTableOrder(db)
+	if err != nil {
+		return nil, err
+	}
+
+	gzipWriter := gzip.NewWriter(writer)
+	encoder := json.NewEncoder(gzipWriter)
+
+	if err := encoder.Encode(snapshotLine{Format: snapshotFormat, Version: snapshotVersion}); err != nil {
+		return nil, err
+	}
+
+	result := make(SnapshotResult)
+	for _, table := range tables {
+		columnTypes, err := db.Migrator().ColumnTypes(table)
+		if err != nil {
+			return nil, err
+		}
+
+		columns := make([]string, len(columnTypes))
+		for i, columnType := range columnTypes {
+			columns[i] = columnType.Name()
+		}
+
+		if err := encoder.Encode(snapshotLine{Table: table, Columns: columns}); err != nil {
+			return nil, err
+		}
+
+		rows, err := db.Raw(fmt.Sprintf("SELECT row_to_json(t)::text FROM %s t", quoteIdentifier(table))).Rows()
+		if err != nil {
+			return nil, err
+		}
+
+		var count int64
+		for rows.Next() {
+			var row string
+			if err := rows.Scan(&row); err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			if err := encoder.Encode(snapshotLine{Row: json.RawMessage(row)}); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			count++
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		config.Log.Infof("Snapshot wrote %d rows from table %s", count, table)
+		result[table] = count
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Restore reads a snapshot written by Snapshot into the database in a single transaction. The target schema is expected to
+// be migrated and empty. Only the columns present in both the snapshot and the target table are restored, columns missing from
+// the snapshot get their default value and tables missing from the target are skipped.
+func Restore(db *gorm.DB, reader io.Reader) (SnapshotResult, error) {
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	scanner := bufio.NewScanner(gzipReader)
+	// Rows with large event attribute values can go well past the default scanner limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("snapshot is empty")
+	}
+
+	var header snapshotLine
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, fmt.Errorf("error reading snapshot header: %w", err)
+	}
+
+	if header.Format != snapshotFormat {
+		return nil, errors.New("file is not a cosmos-indexer snapshot")
+	}
+
+	if header.Version > snapshotVersion {
+		return nil, fmt.Errorf("snapshot version %d is newer than the supported version %d", header.Version, snapshotVersion)
+	}
+
+	result := make(SnapshotResult)
+	err = db.Transaction(func(dbTransaction *gorm.DB) error {
+		var table string
+		var columns []string
+		var batch []json.RawMessage
+
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+
+			if err := restoreRows(dbTransaction, table, columns, batch); err != nil {
+				return err
+			}
+			result[table] += int64(len(batch))
+			batch = batch[:0]
+			return nil
+		}
+
+		for scanner.Scan() {
+			var line snapshotLine
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				return fmt.Errorf("error reading snapshot line: %w", err)
+			}
+
+			if line.Table != "" {
+				if err := flush(); err != nil {
+					return err
+				}
+				if err := finishRestoredTable(dbTransaction, table, columns); err != nil {
+					return err
+				}
+
+				table = line.Table
+				columns, err = restoreColumns(dbTransaction, table, line.Columns)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Rows of a table that does not exist in the target schema
+			if len(columns) == 0 {
+				continue
+			}
+
+			batch = append(batch, append(json.RawMessage(nil), line.Row...))
+			if len(batch) >= restoreBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+
+		return finishRestoredTable(dbTransaction, table, columns)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// restoreColumns returns the snapshot columns that also exist in the target table. The table must be empty.
+func restoreColumns(db *gorm.DB, table string, snapshotColumns []string) ([]string, error) {
+	if !db.Migrator().HasTable(table) {
+		config.Log.Warnf("Snapshot table %s does not exist in the target database, skipping it", table)
+		return nil, nil
+	}
+
+	var rowCount int64
+	if err := db.Table(table).Count(&rowCount).Error; err != nil {
+		return nil, err
+	}
+
+	if rowCount != 0 {
+		return nil, fmt.Errorf("refusing to restore into table %s, it already has %d rows", table, rowCount)
+	}
+
+	columnTypes, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return nil, err
+	}
+
+	targetColumns := make(map[string]bool)
+	for _, columnType := range columnTypes {
+		targetColumns[columnType.Name()] = true
+	}
+
+	var columns []string
+	for _, column := range snapshotColumns {
+		if targetColumns[column] {
+			columns = append(columns, column)
+		} else {
+			config.Log.Warnf("Snapshot column %s.%s does not exist in the target database, skipping it", table, column)
+		}
+	}
+
+	return columns, nil
+}
+
+func restoreRows(db *gorm.DB, table string, columns []string, rows []json.RawMessage) error {
+	quotedColumns := make([]string, len(columns))
+	for i, column := range columns {
+		quotedColumns[i] = quoteIdentifier(column)
+	}
+	columnList := strings.Join(quotedColumns, ", ")
+
+	rowsJSON, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM json_populate_recordset(NULL::%s, ?::json)", quoteIdentifier(table), columnList, columnList, quoteIdentifier(table))
+	return db.Exec(query, string(rowsJSON)).Error
+}
+
+// finishRestoredTable moves the id sequence of a restored table past the restored rows so new inserts do not collide.
+func finishRestoredTable(db *gorm.DB, table string, columns []string) error {
+	hasID := false
+	for _, column := range columns {
+		if column == "id" {
+			hasID = true
+			break
+		}
+	}
+
+	if !hasID {
+		return nil
+	}
+
+	var sequence *string
+	if err := db.Raw("SELECT pg_get_serial_sequence(?, 'id')", quoteIdentifier(table)).Scan(&sequence).Error; err != nil {
+		return err
+	}
+
+	if sequence == nil {
+		return nil
+	}
+
+	query := fmt.Sprintf("SELECT setval(?, COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)", quoteIdentifier(table))
+	return db.Exec(query, *sequence).Error
+}
+
+// snapshotTableOrder returns the tables in the current schema sorted so that every table comes after the tables it references.
+func snapshotTableOrder(db *gorm.DB) ([]string, error) {
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tables)
+
+	var references []struct {
+		Child  string
+		Parent string
+	}
+
+	err = db.Raw(`SELECT child.relname AS child, parent.relname AS parent
+		FROM pg_constraint c
+		JOIN pg_class child ON c.conrelid = child.oid
+		JOIN pg_class parent ON c.confrelid = parent.oid
+		JOIN pg_namespace n ON child.relnamespace = n.oid
+		WHERE c.contype = 'f' AND n.nspname = current_schema()`).Scan(&references).Error
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make(map[string][]string)
+	for _, reference := range references {
+		if reference.Child != reference.Parent {
+			parents[reference.Child] = append(parents[reference.Child], reference.Parent)
+		}
+	}
+
+	var ordered []string
+	visited := make(map[string]bool)
+	var visit func(table string)
+	visit = func(table string) {
+		if visited[table] {
+			return
+		}
+		visited[table] = true
+		for _, parent := range parents[table] {
+			visit(parent)
+		}
+		ordered = append(ordered, table)
+	}
+
+	for _, table := range tables {
+		visit(table)
+	}
+
+	return ordered, nil
+}
+
+func quoteIdentifier(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
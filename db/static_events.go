@@ -0,0 +1,114 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+)
+
+// StaticEventDeduper drops the block events that are unchanged from the previous block before they are stored
+// (base.dedupe-static-events), extending the run of the stored occurrence instead. It compares each block to the last block
+// recorded with it, so only consecutive heights are deduplicated, blocks written out of height order are stored in full.
+// It is not safe for concurrent use, the DB worker is its only user.
+type StaticEventDeduper struct {
+	lastHeight int64
+	// The events of the last recorded block by key
+	occurrences map[string]staticEventOccurrence
+}
+
+type staticEventOccurrence struct {
+	blockEventID uint
+	firstHeight  int64
+	count        int64
+}
+
+func NewStaticEventDeduper() *StaticEventDeduper {
+	return &StaticEventDeduper{occurrences: make(map[string]staticEventOccurrence)}
+}
+
+// Dedupe removes the events of the block that the previous block emitted unchanged, adding a run extension for each of them
+// to the wrapper. A nil deduper leaves the wrapper untouched.
+func (deduper *StaticEventDeduper) Dedupe(blockDBWrapper *BlockDBWrapper) {
+	if deduper == nil || deduper.lastHeight == 0 || blockDBWrapper.Block.Height != deduper.lastHeight+1 {
+		return
+	}
+
+	seen := make(map[string]int)
+	dedupe := func(events []BlockEventDBWrapper) []BlockEventDBWrapper {
+		var kept []BlockEventDBWrapper
+		for _, event := range events {
+			key := staticEventKey(event, seen)
+			occurrence, ok := deduper.occurrences[key]
+			if !ok {
+				kept = append(kept, event)
+				continue
+			}
+
+			blockDBWrapper.StaticEventRuns = append(blockDBWrapper.StaticEventRuns, models.StaticBlockEventRun{
+				BlockEventID: occurrence.blockEventID,
+				FirstHeight:  occurrence.firstHeight,
+				LastHeight:   blockDBWrapper.Block.Height,
+				Count:        occurrence.count + 1,
+			})
+			blockDBWrapper.staticEventRunKeys = append(blockDBWrapper.staticEventRunKeys, key)
+		}
+		return kept
+	}
+
+	blockDBWrapper.BeginBlockEvents = dedupe(blockDBWrapper.BeginBlockEvents)
+	blockDBWrapper.EndBlockEvents = dedupe(blockDBWrapper.EndBlockEvents)
+}
+
+// Record remembers the events of the block once it has been stored, for deduplicating the next block against
+func (deduper *StaticEventDeduper) Record(blockDBWrapper *BlockDBWrapper) {
+	if deduper == nil {
+		return
+	}
+
+	height := blockDBWrapper.Block.Height
+	occurrences := make(map[string]staticEventOccurrence)
+	seen := make(map[string]int)
+	for _, events := range [][]BlockEventDBWrapper{blockDBWrapper.BeginBlockEvents, blockDBWrapper.EndBlockEvents} {
+		for _, event := range events {
+			occurrences[staticEventKey(event, seen)] = staticEventOccurrence{blockEventID: event.BlockEvent.ID, firstHeight: height, count: 1}
+		}
+	}
+
+	for i, run := range blockDBWrapper.StaticEventRuns {
+		occurrences[blockDBWrapper.staticEventRunKeys[i]] = staticEventOccurrence{blockEventID: run.BlockEventID, firstHeight: run.FirstHeight, count: run.Count}
+	}
+
+	deduper.lastHeight = height
+	deduper.occurrences = occurrences
+}
+
+// staticEventKey identifies the event by its lifecycle position, type and attributes. Identical events within a block are told
+// apart by their occurrence number, counted in seen.
+func staticEventKey(event BlockEventDBWrapper, seen map[string]int) string {
+	type attribute struct {
+		Key         string
+		Value       string
+		ValueBase64 bool
+	}
+
+	content := struct {
+		LifecyclePosition models.BlockLifecyclePosition
+		Type              string
+		Attributes        []attribute
+	}{LifecyclePosition: event.BlockEvent.LifecyclePosition, Type: event.BlockEvent.BlockEventType.Type}
+	for _, eventAttribute := range event.Attributes {
+		content.Attributes = append(content.Attributes, attribute{Key: eventAttribute.BlockEventAttributeKey.Key, Value: eventAttribute.Value, ValueBase64: eventAttribute.ValueBase64})
+	}
+
+	// Marshaling plain strings and numbers cannot fail
+	serialized, _ := json.Marshal(content)
+	sum := sha256.Sum256(serialized)
+	key := hex.EncodeToString(sum[:])
+
+	occurrence := seen[key]
+	seen[key]++
+	return fmt.Sprintf("%s/%d", key, occurrence)
+}
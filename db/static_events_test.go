@@ -0,0 +1,105 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/stretchr/testify/suite"
+)
+
+type StaticEventsTestSuite struct {
+	suite.Suite
+}
+
+// staticEventsBlock is a block with the three static events of the mock block and a reward event that changes every block
+func staticEventsBlock(height int64) *BlockDBWrapper {
+	wrapper := mockBlockEventsWrapper(models.Chain{ID: 1}, height)
+	wrapper.EndBlockEvents = append(wrapper.EndBlockEvents, BlockEventDBWrapper{
+		BlockEvent: models.BlockEvent{Index: 1, LifecyclePosition: models.EndBlockEvent, BlockEventType: models.BlockEventType{Type: "rewards"}},
+		Attributes: []models.BlockEventAttribute{{Index: 0, Value: fmt.Sprintf("%duatom", height), BlockEventAttributeKey: models.BlockEventAttributeKey{Key: "amount"}}},
+	})
+	return wrapper
+}
+
+// storeEvents assigns IDs to the events left in the block like the insert does and returns the number of events stored
+func storeEvents(wrapper *BlockDBWrapper, nextID *uint) int {
+	stored := 0
+	for _, events := range [][]BlockEventDBWrapper{wrapper.BeginBlockEvents, wrapper.EndBlockEvents} {
+		for i := range events {
+			*nextID++
+			events[i].BlockEvent.ID = *nextID
+			stored++
+		}
+	}
+	return stored
+}
+
+func (suite *StaticEventsTestSuite) TestStaticEventAcross100Blocks() {
+	deduper := NewStaticEventDeduper()
+	var nextID uint
+
+	first := staticEventsBlock(1)
+	deduper.Dedupe(first)
+	suite.Equal(4, storeEvents(first, &nextID))
+	suite.Empty(first.StaticEventRuns)
+	deduper.Record(first)
+
+	var last *BlockDBWrapper
+	for height := int64(2); height <= 100; height++ {
+		last = staticEventsBlock(height)
+		deduper.Dedupe(last)
+		// Only the changing reward event is stored again
+		suite.Require().Equal(1, storeEvents(last, &nextID))
+		suite.Require().Equal("rewards", last.EndBlockEvents[0].BlockEvent.BlockEventType.Type)
+		deduper.Record(last)
+	}
+
+	suite.Equal([]models.StaticBlockEventRun{
+		{BlockEventID: first.BeginBlockEvents[0].BlockEvent.ID, FirstHeight: 1, LastHeight: 100, Count: 100},
+		{BlockEventID: first.BeginBlockEvents[1].BlockEvent.ID, FirstHeight: 1, LastHeight: 100, Count: 100},
+		{BlockEventID: first.EndBlockEvents[0].BlockEvent.ID, FirstHeight: 1, LastHeight: 100, Count: 100},
+	}, last.StaticEventRuns)
+
+	// A gap in the heights ends the runs, the block is stored in full
+	gap := staticEventsBlock(102)
+	deduper.Dedupe(gap)
+	suite.Equal(4, storeEvents(gap, &nextID))
+	suite.Empty(gap.StaticEventRuns)
+}
+
+func (suite *StaticEventsTestSuite) TestIdenticalEventsWithinBlock() {
+	deduper := NewStaticEventDeduper()
+	var nextID uint
+
+	duplicated := func(height int64) *BlockDBWrapper {
+		wrapper := mockBlockEventsWrapper(models.Chain{ID: 1}, height)
+		wrapper.BeginBlockEvents = append(wrapper.BeginBlockEvents, wrapper.BeginBlockEvents[1])
+		return wrapper
+	}
+
+	first := duplicated(1)
+	suite.Equal(4, storeEvents(first, &nextID))
+	deduper.Record(first)
+
+	// Both copies of the repeated mint event extend their own run
+	second := duplicated(2)
+	deduper.Dedupe(second)
+	suite.Equal(0, storeEvents(second, &nextID))
+	suite.Len(second.StaticEventRuns, 4)
+	suite.Equal(first.BeginBlockEvents[1].BlockEvent.ID, second.StaticEventRuns[1].BlockEventID)
+	suite.Equal(first.BeginBlockEvents[2].BlockEvent.ID, second.StaticEventRuns[2].BlockEventID)
+}
+
+func (suite *StaticEventsTestSuite) TestNilDeduper() {
+	var deduper *StaticEventDeduper
+	wrapper := staticEventsBlock(1)
+	deduper.Dedupe(wrapper)
+	deduper.Record(wrapper)
+	suite.Len(wrapper.BeginBlockEvents, 2)
+	suite.Len(wrapper.EndBlockEvents, 2)
+}
+
+func TestStaticEventsTestSuite(t *testing.T) {
+	suite.Run(t, new(StaticEventsTestSuite))
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// TxColumn is a chain-specific column added to the txes table, populated for every stored tx from the value its extractor returns.
+// A nil value stores NULL.
+type TxColumn struct {
+	Name      string
+	SQLType   string
+	Extractor func(tx TxDBWrapper) any
+}
+
+var txColumnPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// ValidateTxColumn checks the column can be added alongside the already registered columns. Columns must be lowercase SQL
+// identifiers, need an SQL type (e.g. "text" or "bigint") and an extractor, and cannot shadow the built-in tx columns.
+func ValidateTxColumn(registered []TxColumn, column TxColumn) error {
+	name := column.Name
+	if !txColumnPattern.MatchString(name) {
+		return fmt.Errorf("invalid tx column name %q, names must be lowercase SQL identifiers", name)
+	}
+
+	if column.SQLType == "" || column.Extractor == nil {
+		return fmt.Errorf("tx column %q needs an SQL type and an extractor", name)
+	}
+
+	txSchema, err := schema.Parse(&models.Tx{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		return err
+	}
+	if _, ok := txSchema.FieldsByDBName[name]; ok {
+		return fmt.Errorf("tx column %q is a built-in tx column", name)
+	}
+
+	for _, existing := range registered {
+		if existing.Name == name {
+			return fmt.Errorf("found duplicate tx column %q, tx columns must be uniquely named", name)
+		}
+	}
+
+	return nil
+}
+
+// MigrateTxColumns adds the tx columns missing from the txes table. Columns are only added, a column that is no longer
+// registered is left in place.
+func MigrateTxColumns(db *gorm.DB, txColumns []TxColumn) error {
+	for _, column := range txColumns {
+		if err := db.Exec("ALTER TABLE txes ADD COLUMN IF NOT EXISTS ? "+column.SQLType, clause.Column{Name: column.Name}).Error; err != nil {
+			return fmt.Errorf("error adding tx column %s: %w", column.Name, err)
+		}
+	}
+	return nil
+}
+
+// indexTxColumns populates the tx columns of the stored txs
+func indexTxColumns(db *gorm.DB, txColumns []TxColumn, txs []TxDBWrapper, storedTxes map[string]models.Tx) error {
+	if len(txColumns) == 0 {
+		return nil
+	}
+
+	for _, tx := range txs {
+		storedTx, ok := storedTxes[tx.Tx.Hash]
+		if !ok || storedTx.ID == 0 {
+			continue
+		}
+		tx.Tx = storedTx
+
+		values := make(map[string]any, len(txColumns))
+		for _, column := range txColumns {
+			values[column.Name] = column.Extractor(tx)
+		}
+
+		if err := db.Model(&models.Tx{}).Where("id = ?", storedTx.ID).UpdateColumns(values).Error; err != nil {
+			config.Log.Error("Error populating registered tx columns.", err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TxColumnsTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TxColumnsTestSuite) TestValidateTxColumn() {
+	extractor := func(tx TxDBWrapper) any { return tx.Tx.Hash }
+	registered := []TxColumn{{Name: "chain_fee_tier", SQLType: "text", Extractor: extractor}}
+
+	suite.NoError(ValidateTxColumn(registered, TxColumn{Name: "chain_fee_level", SQLType: "text", Extractor: extractor}))
+
+	suite.ErrorContains(ValidateTxColumn(registered, TxColumn{Name: "chain_fee_tier", SQLType: "text", Extractor: extractor}), "duplicate")
+	suite.ErrorContains(ValidateTxColumn(registered, TxColumn{Name: "memo", SQLType: "text", Extractor: extractor}), "built-in")
+	suite.ErrorContains(ValidateTxColumn(registered, TxColumn{Name: "Fee Tier", SQLType: "text", Extractor: extractor}), "invalid")
+	suite.ErrorContains(ValidateTxColumn(registered, TxColumn{Name: "fee_tier; DROP TABLE txes", SQLType: "text", Extractor: extractor}), "invalid")
+	suite.Error(ValidateTxColumn(registered, TxColumn{Name: "fee_tier", Extractor: extractor}))
+	suite.Error(ValidateTxColumn(registered, TxColumn{Name: "fee_tier", SQLType: "text"}))
+}
+
+func TestTxColumnsTestSuite(t *testing.T) {
+	suite.Run(t, new(TxColumnsTestSuite))
+}
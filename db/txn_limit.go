@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// TransactionLimiter bounds the number of block commit transactions running at once. Unlike the connection pool, which also
+// serves the queries outside of block commits, it only limits the transactions that take the row locks of a block's data.
+// A nil limiter leaves them unbounded.
+type TransactionLimiter struct {
+	slots chan struct{}
+}
+
+// NewTransactionLimiter creates a limiter allowing at most maxTransactions block commit transactions at once, 0 returns a nil
+// limiter that leaves them unbounded.
+func NewTransactionLimiter(maxTransactions int64) *TransactionLimiter {
+	if maxTransactions <= 0 {
+		return nil
+	}
+	return &TransactionLimiter{slots: make(chan struct{}, maxTransactions)}
+}
+
+// acquire waits for room for another block commit transaction, giving up when the context is done.
+// The returned function frees the slot.
+func (limiter *TransactionLimiter) acquire(ctx context.Context) (func(), error) {
+	if limiter == nil {
+		return func() {}, nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	select {
+	case limiter.slots <- struct{}{}:
+		return func() { <-limiter.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// blockTransaction runs a block commit in a transaction once there is room under the database.max-concurrent-txns limit
+func blockTransaction(db *gorm.DB, limiter *TransactionLimiter, fc func(dbTransaction *gorm.DB) error) error {
+	release, err := limiter.acquire(db.Statement.Context)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return db.Transaction(fc)
+}
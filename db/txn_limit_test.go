@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TxnLimitTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TxnLimitTestSuite) TestConcurrentTransactionsStayUnderLimit() {
+	const limit = 3
+	limiter := NewTransactionLimiter(limit)
+
+	var open, peak atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.acquire(context.Background())
+			suite.NoError(err)
+			defer release()
+
+			current := open.Add(1)
+			for {
+				highest := peak.Load()
+				if current <= highest || peak.CompareAndSwap(highest, current) {
+					break
+				}
+			}
+			// Hold the slot like a commit would, so the other workers pile up behind the limit
+			time.Sleep(10 * time.Millisecond)
+			open.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	suite.LessOrEqual(peak.Load(), int64(limit))
+	suite.Greater(peak.Load(), int64(1), "transactions should still run concurrently up to the limit")
+}
+
+func (suite *TxnLimitTestSuite) TestWaitGivesUpWithContext() {
+	limiter := NewTransactionLimiter(1)
+
+	release, err := limiter.acquire(context.Background())
+	suite.Require().NoError(err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = limiter.acquire(ctx)
+	suite.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (suite *TxnLimitTestSuite) TestUnlimited() {
+	limiter := NewTransactionLimiter(0)
+	for i := 0; i < 100; i++ {
+		_, err := limiter.acquire(context.Background())
+		suite.Require().NoError(err)
+	}
+}
+
+func TestTxnLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(TxnLimitTestSuite))
+}
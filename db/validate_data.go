@@ -0,0 +1,165 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"gorm.io/gorm"
+)
+
+// errMaxViolations stops the validation pass once the violation limit is reached
+var errMaxViolations = errors.New("maximum number of violations reached")
+
+// DataViolation is an indexed row that breaks an invariant checked by a data validator. Height and TxHash reference
+// where the row came from, TxHash is empty for rows that do not belong to a transaction.
+type DataViolation struct {
+	Validator string
+	Table     string
+	RowID     uint
+	Height    int64
+	TxHash    string
+	Reason    string
+}
+
+// DataValidatorRow is a row streamed to a data validator, along with the block and transaction it belongs to
+type DataValidatorRow struct {
+	RowID  uint
+	Height int64
+	TxHash string
+	// Value checked by the validator, if the check is not done in the query
+	Value string
+}
+
+// DataValidator checks already-indexed rows against an invariant. Query selects the rows of the chain to check as
+// row_id, height, tx_hash and value columns, with the chain's DB ID as its only parameter. Check returns the reason a row
+// violates the invariant or an empty string, a nil Check reports every selected row with the validator's Reason.
+type DataValidator struct {
+	Name   string
+	Table  string
+	Query  string
+	Reason string
+	Check  func(row DataValidatorRow) string
+}
+
+var dataValidators = []DataValidator{
+	{
+		Name:   "block-time-stamp",
+		Table:  "blocks",
+		Query:  "SELECT id AS row_id, height, '' AS tx_hash, '' AS value FROM blocks WHERE chain_id = ? AND (time_stamp IS NULL OR time_stamp <= '0001-01-01 00:00:00+00')",
+		Reason: "block has no time stamp",
+	},
+	{
+		Name:   "block-proposer",
+		Table:  "blocks",
+		Query:  "SELECT id AS row_id, height, '' AS tx_hash, '' AS value FROM blocks WHERE chain_id = ? AND (proposer_cons_address_id IS NULL OR proposer_cons_address_id = 0)",
+		Reason: "block has no proposer address",
+	},
+	{
+		Name:   "tx-hash",
+		Table:  "txes",
+		Query:  "SELECT txes.id AS row_id, blocks.height, '' AS tx_hash, '' AS value FROM txes JOIN blocks ON blocks.id = txes.block_id WHERE blocks.chain_id = ? AND (txes.hash IS NULL OR txes.hash = '')",
+		Reason: "transaction has no hash",
+	},
+	{
+		Name:   "message-type",
+		Table:  "messages",
+		Query:  "SELECT messages.id AS row_id, blocks.height, txes.hash AS tx_hash, '' AS value FROM messages JOIN txes ON txes.id = messages.tx_id JOIN blocks ON blocks.id = txes.block_id WHERE blocks.chain_id = ? AND (messages.message_type_id IS NULL OR messages.message_type_id = 0)",
+		Reason: "message has no message type",
+	},
+	{
+		Name:  "signer-address-format",
+		Table: "addresses",
+		Query: `SELECT addresses.id AS row_id, blocks.height, txes.hash AS tx_hash, addresses.address AS value FROM tx_signer_addresses
+			JOIN addresses ON addresses.id = tx_signer_addresses.address_id
+			JOIN txes ON txes.id = tx_signer_addresses.tx_id
+			JOIN blocks ON blocks.id = txes.block_id
+			WHERE blocks.chain_id = ?`,
+		Check: checkBech32Address,
+	},
+}
+
+// RegisterDataValidator adds a validator run by the validate-data command after the built-in validators
+func RegisterDataValidator(validator DataValidator) {
+	dataValidators = append(dataValidators, validator)
+}
+
+// DataValidators returns the built-in and registered data validators
+func DataValidators() []DataValidator {
+	return dataValidators
+}
+
+func checkBech32Address(row DataValidatorRow) string {
+	if _, _, err := bech32.DecodeAndConvert(row.Value); err != nil {
+		return fmt.Sprintf("address %q is not a valid bech32 address: %v", row.Value, err)
+	}
+	return ""
+}
+
+// ValidateData streams the indexed rows of the chain through the validators in a read-only transaction, calling report for
+// every violation until maxViolations are reported (0 reports every violation). It returns the number of violations reported.
+func ValidateData(db *gorm.DB, chainID uint, validators []DataValidator, maxViolations int64, report func(DataViolation)) (int64, error) {
+	var violations int64
+
+	err := db.Transaction(func(dbTransaction *gorm.DB) error {
+		if err := dbTransaction.Exec("SET TRANSACTION READ ONLY").Error; err != nil {
+			return err
+		}
+
+		for _, validator := range validators {
+			if err := runDataValidator(dbTransaction, chainID, validator, func(violation DataViolation) error {
+				report(violation)
+				violations++
+				if maxViolations > 0 && violations >= maxViolations {
+					return errMaxViolations
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if errors.Is(err, errMaxViolations) {
+		err = nil
+	}
+
+	return violations, err
+}
+
+func runDataValidator(db *gorm.DB, chainID uint, validator DataValidator, report func(DataViolation) error) error {
+	rows, err := db.Raw(validator.Query, chainID).Rows()
+	if err != nil {
+		return fmt.Errorf("data validator %s failed: %w", validator.Name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row DataValidatorRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			return fmt.Errorf("data validator %s failed: %w", validator.Name, err)
+		}
+
+		reason := validator.Reason
+		if validator.Check != nil {
+			reason = validator.Check(row)
+		}
+		if reason == "" {
+			continue
+		}
+
+		if err := report(DataViolation{
+			Validator: validator.Name,
+			Table:     validator.Table,
+			RowID:     row.RowID,
+			Height:    row.Height,
+			TxHash:    row.TxHash,
+			Reason:    reason,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"gorm.io/gorm"
+)
+
+// TxMismatch is a difference between the tx data stored for a block and the data decoded from the node again. TxHash is empty
+// for differences of the block itself, Stored and Fetched hold the differing values.
+type TxMismatch struct {
+	Height  int64  `json:"height"`
+	TxHash  string `json:"tx_hash,omitempty"`
+	Field   string `json:"field"`
+	Stored  string `json:"stored"`
+	Fetched string `json:"fetched"`
+}
+
+// VerifyBlockTxs compares the txs stored for the block at the height, with their fees, messages and, with compareMessageEvents,
+// message events, to the txs freshly decoded from the node and returns every difference. Nothing is written.
+func VerifyBlockTxs(db *gorm.DB, chainID uint, height int64, fetched []TxDBWrapper, compareMessageEvents bool) ([]TxMismatch, error) {
+	var block models.Block
+	err := db.Select("id").Where("chain_id = ? AND height = ? AND tx_indexed = ?", chainID, height, true).First(&block).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return []TxMismatch{{Height: height, Field: "block", Stored: "not indexed", Fetched: fmt.Sprintf("%d txs", len(fetched))}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := loadBlockChecksumContent(db, block.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffBlockTxs(height, stored.Txs, checksumTxsOf(fetched), compareMessageEvents), nil
+}
+
+// checksumTxsOf returns the decoded txs in the form their stored rows are loaded in
+func checksumTxsOf(txs []TxDBWrapper) []checksumTx {
+	checksumTxs := make([]checksumTx, len(txs))
+	for i, tx := range txs {
+		checksumTxs[i] = checksumTx{Hash: tx.Tx.Hash, Code: tx.Tx.Code, Memo: tx.Tx.Memo, Fees: []checksumFee{}, Messages: []checksumMessage{}}
+		for _, fee := range tx.Tx.Fees {
+			checksumTxs[i].Fees = append(checksumTxs[i].Fees, checksumFee{Denom: fee.Denomination.Base, Amount: fee.Amount.String()})
+		}
+
+		for _, message := range tx.Messages {
+			checksumMessage := checksumMessage{Index: message.Message.MessageIndex, Type: message.Message.MessageType.MessageType, Events: []checksumEvent{}}
+			for _, event := range message.MessageEvents {
+				checksumEvent := checksumEvent{Index: event.MessageEvent.Index, Type: event.MessageEvent.MessageEventType.Type, Attributes: []checksumAttribute{}}
+				for _, attribute := range event.Attributes {
+					checksumEvent.Attributes = append(checksumEvent.Attributes, checksumAttribute{Index: attribute.Index, Key: attribute.MessageEventAttributeKey.Key, Value: attribute.Value})
+				}
+				checksumMessage.Events = append(checksumMessage.Events, checksumEvent)
+			}
+			checksumTxs[i].Messages = append(checksumTxs[i].Messages, checksumMessage)
+		}
+	}
+	return checksumTxs
+}
+
+// diffBlockTxs matches the stored and fetched txs by hash and returns their differences in hash order
+func diffBlockTxs(height int64, stored []checksumTx, fetched []checksumTx, compareMessageEvents bool) []TxMismatch {
+	sortChecksumTxs(stored)
+	sortChecksumTxs(fetched)
+
+	var mismatches []TxMismatch
+	mismatch := func(txHash string, field string, storedValue string, fetchedValue string) {
+		mismatches = append(mismatches, TxMismatch{Height: height, TxHash: txHash, Field: field, Stored: storedValue, Fetched: fetchedValue})
+	}
+
+	storedIndex, fetchedIndex := 0, 0
+	for storedIndex < len(stored) || fetchedIndex < len(fetched) {
+		switch {
+		case fetchedIndex == len(fetched) || (storedIndex < len(stored) && stored[storedIndex].Hash < fetched[fetchedIndex].Hash):
+			mismatch(stored[storedIndex].Hash, "tx", "present", "missing")
+			storedIndex++
+			continue
+		case storedIndex == len(stored) || fetched[fetchedIndex].Hash < stored[storedIndex].Hash:
+			mismatch(fetched[fetchedIndex].Hash, "tx", "missing", "present")
+			fetchedIndex++
+			continue
+		}
+
+		storedTx, fetchedTx := stored[storedIndex], fetched[fetchedIndex]
+		storedIndex++
+		fetchedIndex++
+
+		if storedTx.Code != fetchedTx.Code {
+			mismatch(storedTx.Hash, "code", fmt.Sprint(storedTx.Code), fmt.Sprint(fetchedTx.Code))
+		}
+		if storedTx.Memo != fetchedTx.Memo {
+			mismatch(storedTx.Hash, "memo", storedTx.Memo, fetchedTx.Memo)
+		}
+		if storedFees, fetchedFees := marshalVerifyValue(storedTx.Fees), marshalVerifyValue(fetchedTx.Fees); storedFees != fetchedFees {
+			mismatch(storedTx.Hash, "fees", storedFees, fetchedFees)
+		}
+
+		if !compareMessageEvents {
+			for _, messages := range [][]checksumMessage{storedTx.Messages, fetchedTx.Messages} {
+				for i := range messages {
+					messages[i].Events = nil
+				}
+			}
+		}
+		if storedMessages, fetchedMessages := marshalVerifyValue(storedTx.Messages), marshalVerifyValue(fetchedTx.Messages); storedMessages != fetchedMessages {
+			mismatch(storedTx.Hash, "messages", storedMessages, fetchedMessages)
+		}
+	}
+
+	return mismatches
+}
+
+// marshalVerifyValue returns the JSON form of a loaded value, which only holds strings and numbers and always marshals
+func marshalVerifyValue(value any) string {
+	serialized, _ := json.Marshal(value)
+	return string(serialized)
+}
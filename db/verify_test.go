@@ -0,0 +1,70 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+type VerifyTestSuite struct {
+	suite.Suite
+}
+
+// verifyTxs returns the decoded txs of a block with a send and a failed tx
+func verifyTxs() []TxDBWrapper {
+	return []TxDBWrapper{
+		{
+			Tx: models.Tx{Hash: "AAAA", Memo: "first", Fees: []models.Fee{{Amount: decimal.NewFromInt(5000), Denomination: models.Denom{Base: "uatom"}}}},
+			Messages: []MessageDBWrapper{{
+				Message: models.Message{MessageIndex: 0, MessageType: models.MessageType{MessageType: "/cosmos.bank.v1beta1.MsgSend"}},
+				MessageEvents: []MessageEventDBWrapper{{
+					MessageEvent: models.MessageEvent{Index: 0, MessageEventType: models.MessageEventType{Type: "transfer"}},
+					Attributes:   []models.MessageEventAttribute{{Index: 0, Value: "10uatom", MessageEventAttributeKey: models.MessageEventAttributeKey{Key: "amount"}}},
+				}},
+			}},
+		},
+		{Tx: models.Tx{Hash: "BBBB", Code: 5}},
+	}
+}
+
+func (suite *VerifyTestSuite) TestMatchingBlock() {
+	// Rows come back from the database in any order
+	stored := checksumTxsOf(verifyTxs())
+	stored[0], stored[1] = stored[1], stored[0]
+
+	suite.Empty(diffBlockTxs(1, stored, checksumTxsOf(verifyTxs()), true))
+}
+
+func (suite *VerifyTestSuite) TestWrongRowsFlagged() {
+	stored := checksumTxsOf(verifyTxs())
+	stored[0].Memo = "tampered"
+	stored[0].Fees[0].Amount = "4000"
+	stored[0].Messages[0].Events[0].Attributes[0].Value = "1uatom"
+	// A tx that is not in the block, and one of the block that was never stored
+	stored[1].Hash = "CCCC"
+
+	mismatches := diffBlockTxs(7, stored, checksumTxsOf(verifyTxs()), true)
+	suite.Require().Len(mismatches, 5)
+	suite.Equal(TxMismatch{Height: 7, TxHash: "AAAA", Field: "memo", Stored: "tampered", Fetched: "first"}, mismatches[0])
+	suite.Equal("fees", mismatches[1].Field)
+	suite.Contains(mismatches[1].Stored, `"4000"`)
+	suite.Equal("messages", mismatches[2].Field)
+	suite.Contains(mismatches[2].Stored, `"1uatom"`)
+	suite.Equal(TxMismatch{Height: 7, TxHash: "BBBB", Field: "tx", Stored: "missing", Fetched: "present"}, mismatches[3])
+	suite.Equal(TxMismatch{Height: 7, TxHash: "CCCC", Field: "tx", Stored: "present", Fetched: "missing"}, mismatches[4])
+}
+
+func (suite *VerifyTestSuite) TestMessageEventsIgnored() {
+	// Chains indexed without message events have none stored
+	stored := checksumTxsOf(verifyTxs())
+	stored[0].Messages[0].Events = []checksumEvent{}
+
+	suite.Empty(diffBlockTxs(1, stored, checksumTxsOf(verifyTxs()), false))
+	suite.Len(diffBlockTxs(1, stored, checksumTxsOf(verifyTxs()), true), 1)
+}
+
+func TestVerifyTestSuite(t *testing.T) {
+	suite.Run(t, new(VerifyTestSuite))
+}
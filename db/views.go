@@ -0,0 +1,69 @@
+package db
+
+import (
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"gorm.io/gorm"
+)
+
+// Convenience views over the default indexed dataset for common queries. Views are created with CREATE OR REPLACE
+// so re-running the migration picks up any changes to the definitions.
+var views = []struct {
+	name       string
+	definition string
+}{
+	{
+		// One row per transaction fee, transactions without fees are returned once with null fee columns
+		name: "v_transactions_with_fees",
+		definition: `SELECT
+			txes.id AS tx_id,
+			txes.hash AS tx_hash,
+			txes.code,
+			txes.memo,
+			blocks.chain_id,
+			blocks.height,
+			blocks.time_stamp,
+			fees.amount AS fee_amount,
+			denoms.base AS fee_denom,
+			payer.address AS fee_payer
+		FROM txes
+		JOIN blocks ON blocks.id = txes.block_id
+		LEFT JOIN fees ON fees.tx_id = txes.id
+		LEFT JOIN denoms ON denoms.id = fees.denomination_id
+		LEFT JOIN addresses payer ON payer.id = fees.payer_address_id`,
+	},
+	{
+		// One row per transfer message event, requires message event indexing to be enabled
+		name: "v_transfers",
+		definition: `SELECT
+			blocks.chain_id,
+			blocks.height,
+			blocks.time_stamp,
+			txes.hash AS tx_hash,
+			messages.message_index,
+			message_events.index AS event_index,
+			MAX(CASE WHEN message_event_attribute_keys.key = 'sender' THEN message_event_attributes.value END) AS sender,
+			MAX(CASE WHEN message_event_attribute_keys.key = 'recipient' THEN message_event_attributes.value END) AS recipient,
+			MAX(CASE WHEN message_event_attribute_keys.key = 'amount' THEN message_event_attributes.value END) AS amount
+		FROM message_events
+		JOIN message_event_types ON message_event_types.id = message_events.message_event_type_id AND message_event_types.type = 'transfer'
+		JOIN messages ON messages.id = message_events.message_id
+		JOIN txes ON txes.id = messages.tx_id
+		JOIN blocks ON blocks.id = txes.block_id
+		JOIN message_event_attributes ON message_event_attributes.message_event_id = message_events.id
+		JOIN message_event_attribute_keys ON message_event_attribute_keys.id = message_event_attributes.message_event_attribute_key_id
+		GROUP BY blocks.chain_id, blocks.height, blocks.time_stamp, txes.hash, messages.message_index, message_events.index`,
+	},
+}
+
+// CreateViews creates the convenience views, it must be run after MigrateModels since the views depend on the model tables
+func CreateViews(db *gorm.DB) error {
+	return db.Transaction(func(dbTransaction *gorm.DB) error {
+		for _, view := range views {
+			if err := dbTransaction.Exec("CREATE OR REPLACE VIEW " + view.name + " AS " + view.definition).Error; err != nil {
+				config.Log.Errorf("Error creating view %s. Err: %v", view.name, err)
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/DefiantLabs/cosmos-indexer/db/models"
 )
@@ -20,12 +21,21 @@ type BlockEventFilter interface {
 }
 
 type DefaultBlockEventTypeFilter struct {
-	EventType string `json:"event_type"`
-	Inclusive bool   `json:"inclusive"`
+	EventType       string `json:"event_type"`
+	Inclusive       bool   `json:"inclusive"`
+	CaseInsensitive bool   `json:"case_insensitive"`
 }
 
 func (f DefaultBlockEventTypeFilter) EventMatches(eventData EventData) (bool, error) {
-	return eventData.Event.BlockEventType.Type == f.EventType, nil
+	return eventTypeMatches(eventData.Event.BlockEventType.Type, f.EventType, f.CaseInsensitive), nil
+}
+
+// eventTypeMatches compares event types exactly unless the filter is case-insensitive, so the common exact match stays a plain string comparison
+func eventTypeMatches(eventType string, filterEventType string, caseInsensitive bool) bool {
+	if !caseInsensitive {
+		return eventType == filterEventType
+	}
+	return strings.EqualFold(eventType, filterEventType)
 }
 
 func (f DefaultBlockEventTypeFilter) IncludeMatch() bool {
@@ -44,6 +54,8 @@ type RegexBlockEventTypeFilter struct {
 	EventTypeRegexPattern string `json:"event_type_regex"`
 	eventTypeRegex        *regexp.Regexp
 	Inclusive             bool `json:"inclusive"`
+	// Read from the filter config only, the pattern is compiled with the (?i) flag prepended
+	CaseInsensitive bool `json:"case_insensitive"`
 }
 
 func (f RegexBlockEventTypeFilter) EventMatches(eventData EventData) (bool, error) {
@@ -67,10 +79,12 @@ type DefaultBlockEventTypeAndAttributeValueFilter struct {
 	AttributeKey   string `json:"attribute_key"`
 	AttributeValue string `json:"attribute_value"`
 	Inclusive      bool   `json:"inclusive"`
+	// Only applies to the event type, attribute keys and values are always matched exactly
+	CaseInsensitive bool `json:"case_insensitive"`
 }
 
 func (f DefaultBlockEventTypeAndAttributeValueFilter) EventMatches(eventData EventData) (bool, error) {
-	if eventData.Event.BlockEventType.Type != f.EventType {
+	if !eventTypeMatches(eventData.Event.BlockEventType.Type, f.EventType, f.CaseInsensitive) {
 		return false, nil
 	}
 
@@ -0,0 +1,143 @@
+package indexer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+)
+
+// Kinds of the committed block transactions recorded in the audit log
+const (
+	AuditKindTxs         = "txs"
+	AuditKindBlockEvents = "block_events"
+)
+
+// AuditRecord is the JSON line appended to the base.audit-log-file for every committed block transaction. Tables holds the rows
+// written per table, Rows their total.
+type AuditRecord struct {
+	Time          time.Time        `json:"time"`
+	CorrelationID string           `json:"correlation_id"`
+	ChainID       string           `json:"chain_id"`
+	Height        int64            `json:"height"`
+	Kind          string           `json:"kind"`
+	Tables        map[string]int64 `json:"tables"`
+	Rows          int64            `json:"rows"`
+}
+
+// AuditLog appends an AuditRecord per committed block transaction to a file, separate from the main log. A nil AuditLog records
+// nothing.
+type AuditLog struct {
+	mu      sync.Mutex
+	file    *os.File
+	fsync   bool
+	chainID string
+}
+
+// OpenAuditLog opens the file at the path for appending, creating it if needed. With fsync every record is synced to disk before
+// the next block is written.
+func OpenAuditLog(path string, fsync bool, chainID string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit log file %s: %w", path, err)
+	}
+	return &AuditLog{file: file, fsync: fsync, chainID: chainID}, nil
+}
+
+// Close closes the audit log file
+func (auditLog *AuditLog) Close() error {
+	if auditLog == nil {
+		return nil
+	}
+	return auditLog.file.Close()
+}
+
+// recordTxs records the rows written by the commit of the txs of the block, following the tables IndexNewBlock writes to
+func (auditLog *AuditLog) recordTxs(height int64, txs []dbTypes.TxDBWrapper, indexConfig *config.IndexConfig) error {
+	if auditLog == nil {
+		return nil
+	}
+
+	tables := map[string]int64{"blocks": 1, "txes": int64(len(txs))}
+	for _, tx := range txs {
+		tables["fees"] += int64(len(tx.Tx.Fees))
+		tables["messages"] += int64(len(tx.Messages))
+		tables["tx_signer_infos"] += int64(len(tx.SignerInfos))
+		if indexConfig.Flags.IndexTxRaw && len(tx.Raw) != 0 {
+			tables["tx_raws"]++
+		}
+
+		for _, message := range tx.Messages {
+			if indexConfig.Flags.IndexMessageEvents {
+				tables["message_events"] += int64(len(message.MessageEvents))
+				for _, event := range message.MessageEvents {
+					tables["message_event_attributes"] += int64(len(event.Attributes))
+				}
+			}
+			tables["message_addresses"] += int64(len(message.Addresses))
+			tables["governance_messages"] += int64(len(message.GovernanceMessages))
+			tables["reward_events"] += int64(len(message.RewardEvents))
+			tables["balance_deltas"] += int64(len(message.BalanceDeltas))
+			tables["message_execution_contexts"] += int64(len(message.ExecutionContexts))
+			tables["nft_activities"] += int64(len(message.NFTActivities))
+		}
+	}
+
+	return auditLog.write(height, AuditKindTxs, tables)
+}
+
+// recordBlockEvents records the rows written by the commit of the block events of the block
+func (auditLog *AuditLog) recordBlockEvents(blockDBWrapper *dbTypes.BlockDBWrapper) error {
+	if auditLog == nil {
+		return nil
+	}
+
+	tables := map[string]int64{"blocks": 1}
+	for _, events := range [][]dbTypes.BlockEventDBWrapper{blockDBWrapper.BeginBlockEvents, blockDBWrapper.EndBlockEvents} {
+		tables["block_events"] += int64(len(events))
+		for _, event := range events {
+			tables["block_event_attributes"] += int64(len(event.Attributes))
+		}
+	}
+
+	return auditLog.write(blockDBWrapper.Block.Height, AuditKindBlockEvents, tables)
+}
+
+// write appends the record with the non-empty tables under a new correlation ID
+func (auditLog *AuditLog) write(height int64, kind string, tables map[string]int64) error {
+	record := AuditRecord{Time: time.Now().UTC(), ChainID: auditLog.chainID, Height: height, Kind: kind, Tables: make(map[string]int64)}
+	for table, rows := range tables {
+		if rows != 0 {
+			record.Tables[table] = rows
+			record.Rows += rows
+		}
+	}
+
+	correlationID := make([]byte, 16)
+	if _, err := rand.Read(correlationID); err != nil {
+		return err
+	}
+	record.CorrelationID = hex.EncodeToString(correlationID)
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	auditLog.mu.Lock()
+	defer auditLog.mu.Unlock()
+
+	if _, err := auditLog.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if auditLog.fsync {
+		return auditLog.file.Sync()
+	}
+	return nil
+}
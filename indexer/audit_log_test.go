@@ -0,0 +1,125 @@
+package indexer
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditLogTestSuite struct {
+	suite.Suite
+	path string
+}
+
+func (suite *AuditLogTestSuite) SetupTest() {
+	suite.path = filepath.Join(suite.T().TempDir(), "audit.log")
+}
+
+// readAuditRecords parses every line of the audit log
+func (suite *AuditLogTestSuite) readAuditRecords() []AuditRecord {
+	file, err := os.Open(suite.path)
+	suite.Require().NoError(err)
+	defer file.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record AuditRecord
+		suite.Require().NoError(json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	suite.Require().NoError(scanner.Err())
+	return records
+}
+
+func (suite *AuditLogTestSuite) TestRecordPerCommittedBlock() {
+	// Records of a previous run are kept
+	suite.Require().NoError(os.WriteFile(suite.path, []byte(`{"height":1}`+"\n"), 0o600))
+
+	auditLog, err := OpenAuditLog(suite.path, true, "testchain-1")
+	suite.Require().NoError(err)
+
+	indexConfig := &config.IndexConfig{}
+	indexConfig.Flags.IndexMessageEvents = true
+
+	txs := []dbTypes.TxDBWrapper{
+		{
+			Tx: models.Tx{Hash: "AAAA", Fees: []models.Fee{{}}},
+			Messages: []dbTypes.MessageDBWrapper{
+				{MessageEvents: []dbTypes.MessageEventDBWrapper{{Attributes: make([]models.MessageEventAttribute, 3)}, {Attributes: make([]models.MessageEventAttribute, 1)}}},
+				{RewardEvents: make([]models.RewardEvent, 2)},
+			},
+		},
+		{Tx: models.Tx{Hash: "BBBB", Code: 5, Fees: []models.Fee{{}}}},
+	}
+	suite.Require().NoError(auditLog.recordTxs(100, txs, indexConfig))
+	suite.Require().NoError(auditLog.recordTxs(101, nil, indexConfig))
+	suite.Require().NoError(auditLog.recordBlockEvents(&dbTypes.BlockDBWrapper{
+		Block:            &models.Block{Height: 101},
+		BeginBlockEvents: []dbTypes.BlockEventDBWrapper{{Attributes: make([]models.BlockEventAttribute, 2)}},
+		EndBlockEvents:   []dbTypes.BlockEventDBWrapper{{Attributes: make([]models.BlockEventAttribute, 4)}},
+	}))
+	suite.Require().NoError(auditLog.Close())
+
+	records := suite.readAuditRecords()
+	suite.Require().Len(records, 4)
+
+	suite.Equal("testchain-1", records[1].ChainID)
+	suite.Equal(int64(100), records[1].Height)
+	suite.Equal(AuditKindTxs, records[1].Kind)
+	suite.Equal(map[string]int64{
+		"blocks":                   1,
+		"txes":                     2,
+		"fees":                     2,
+		"messages":                 2,
+		"message_events":           2,
+		"message_event_attributes": 4,
+		"reward_events":            2,
+	}, records[1].Tables)
+	suite.Equal(int64(15), records[1].Rows)
+
+	suite.Equal(int64(101), records[2].Height)
+	suite.Equal(map[string]int64{"blocks": 1}, records[2].Tables)
+	suite.Equal(int64(1), records[2].Rows)
+
+	suite.Equal(AuditKindBlockEvents, records[3].Kind)
+	suite.Equal(map[string]int64{"blocks": 1, "block_events": 2, "block_event_attributes": 6}, records[3].Tables)
+	suite.Equal(int64(9), records[3].Rows)
+
+	// Every commit gets its own correlation ID
+	suite.Len(records[1].CorrelationID, 32)
+	suite.NotEqual(records[1].CorrelationID, records[2].CorrelationID)
+	suite.NotEqual(records[2].CorrelationID, records[3].CorrelationID)
+}
+
+func (suite *AuditLogTestSuite) TestMessageEventsNotIndexed() {
+	auditLog, err := OpenAuditLog(suite.path, false, "testchain-1")
+	suite.Require().NoError(err)
+
+	txs := []dbTypes.TxDBWrapper{{Messages: []dbTypes.MessageDBWrapper{{MessageEvents: make([]dbTypes.MessageEventDBWrapper, 2)}}}}
+	suite.Require().NoError(auditLog.recordTxs(100, txs, &config.IndexConfig{}))
+	suite.Require().NoError(auditLog.Close())
+
+	records := suite.readAuditRecords()
+	suite.Require().Len(records, 1)
+	suite.NotContains(records[0].Tables, "message_events")
+}
+
+func (suite *AuditLogTestSuite) TestNilAuditLog() {
+	var auditLog *AuditLog
+	suite.NoError(auditLog.recordTxs(100, nil, &config.IndexConfig{}))
+	suite.NoError(auditLog.recordBlockEvents(&dbTypes.BlockDBWrapper{Block: &models.Block{Height: 100}}))
+	suite.NoError(auditLog.Close())
+	suite.NoFileExists(suite.path)
+}
+
+func TestAuditLogTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditLogTestSuite))
+}
@@ -0,0 +1,137 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/core"
+)
+
+// blockSpiller records the heights of blocks that were dropped because the DB write queue was full. Only the heights are
+// persisted, not the block data, the dropped blocks are re-fetched from the node when they are re-indexed. The spill file is a
+// JSON list of heights, the same format as base.block-input-file. Heights spilled by previous runs are kept.
+type blockSpiller struct {
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	heights map[int64]struct{}
+	// Heights spilled by this run, the heights loaded from previous runs are not counted
+	spilled int
+}
+
+// newBlockSpiller loads the heights of an existing spill file and rewrites it sorted, so each spill only has to append its height
+func newBlockSpiller(path string) (*blockSpiller, error) {
+	var heights []int64
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("error reading backpressure spill file %s: %w", path, err)
+	case len(bytes.TrimSpace(data)) != 0:
+		if err := json.Unmarshal(data, &heights); err != nil {
+			return nil, fmt.Errorf("error parsing backpressure spill file %s, expected a JSON list of heights: %w", path, err)
+		}
+	}
+
+	spiller := &blockSpiller{heights: make(map[int64]struct{}, len(heights))}
+	for _, height := range heights {
+		spiller.heights[height] = struct{}{}
+	}
+
+	sorted := make([]int64, 0, len(spiller.heights))
+	for height := range spiller.heights {
+		sorted = append(sorted, height)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	data, err = json.Marshal(sorted)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write to a temp file and rename so a crash mid-write never loses the heights of previous runs
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return nil, err
+	}
+
+	spiller.file, err = os.OpenFile(path, os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	spiller.size = int64(len(data))
+	return spiller, nil
+}
+
+// spilledBlocks returns the number of heights spilled by this run, a nil spiller has spilled none
+func (s *blockSpiller) spilledBlocks() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spilled
+}
+
+// spill appends the height to the spill file by overwriting the closing bracket of the list, so the file stays a valid JSON list
+func (s *blockSpiller) spill(height int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.heights[height]; ok {
+		return nil
+	}
+
+	entry := fmt.Sprintf(",%d]", height)
+	if len(s.heights) == 0 {
+		entry = entry[1:]
+	}
+	if _, err := s.file.WriteAt([]byte(entry), s.size-1); err != nil {
+		return err
+	}
+
+	s.size += int64(len(entry)) - 1
+	s.heights[height] = struct{}{}
+	s.spilled++
+	return nil
+}
+
+// sendToDBQueue hands data to the DB writer according to the configured backpressure policy.
+// With the block policy, the default and the behaviour from before the policies existed, the send waits for room in the bounded
+// queue, pausing block processing (and in turn RPC fetching).
+// With the drop-to-disk policy a full queue causes the block to be dropped and its height spilled to disk instead.
+// The in-flight block is retained for the queued data, the DB writer releases it once the data is written.
+func sendToDBQueue[T any](indexer *Indexer, queue chan T, data T, height int64, inflightBlock *core.InflightBlock) {
+	inflightBlock.Retain()
+	if indexer.Config.Base.Backpressure != config.BackpressureDropToDisk {
+		queue <- data
+		return
+	}
+
+	select {
+	case queue <- data:
+	default:
+		inflightBlock.Release()
+		config.Log.Warnf("DB write queue is full, spilling block %d to %s", height, indexer.Config.Base.BackpressureSpillFile)
+		if indexer.spiller == nil {
+			spiller, err := newBlockSpiller(indexer.Config.Base.BackpressureSpillFile)
+			if err != nil {
+				config.Log.Fatal("Failed to open the backpressure spill file", err)
+			}
+			indexer.spiller = spiller
+		}
+		if err := indexer.spiller.spill(height); err != nil {
+			config.Log.Fatal("Failed to write block to backpressure spill file", err)
+		}
+	}
+}
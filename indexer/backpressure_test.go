@@ -0,0 +1,97 @@
+package indexer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type BackpressureTestSuite struct {
+	suite.Suite
+}
+
+// slowStore drains the queue at a fixed pace, simulating a DB that cannot keep up
+func slowStore(queue chan *DBData, delay time.Duration, done chan struct{}) {
+	for range queue {
+		time.Sleep(delay)
+	}
+	close(done)
+}
+
+func (suite *BackpressureTestSuite) TestBlockPolicyPausesSending() {
+	indexer := &Indexer{Config: &config.IndexConfig{}}
+	indexer.Config.Base.Backpressure = config.BackpressureBlock
+
+	queue := make(chan *DBData, 1)
+	done := make(chan struct{})
+	go slowStore(queue, 100*time.Millisecond, done)
+
+	start := time.Now()
+	for height := int64(1); height <= 4; height++ {
+		sendToDBQueue(indexer, queue, &DBData{}, height, nil)
+		// The queue never holds more than its capacity, the sender waits for the store instead
+		suite.LessOrEqual(len(queue), cap(queue))
+	}
+
+	// With a queue of size 1 and one item in the store, the 4th send can only succeed after the store finished 2 items
+	suite.GreaterOrEqual(time.Since(start), 150*time.Millisecond)
+	suite.Nil(indexer.spiller)
+
+	close(queue)
+	<-done
+}
+
+func (suite *BackpressureTestSuite) TestDropToDiskPolicySpills() {
+	spillFile := filepath.Join(suite.T().TempDir(), "spilled.json")
+
+	indexer := &Indexer{Config: &config.IndexConfig{}}
+	indexer.Config.Base.Backpressure = config.BackpressureDropToDisk
+	indexer.Config.Base.BackpressureSpillFile = spillFile
+
+	// No consumer, every send after the queue fills up must spill rather than block
+	queue := make(chan *DBData, 1)
+
+	start := time.Now()
+	for height := int64(1); height <= 4; height++ {
+		sendToDBQueue(indexer, queue, &DBData{}, height, nil)
+	}
+	suite.Less(time.Since(start), time.Second)
+	suite.Len(queue, 1)
+
+	data, err := os.ReadFile(spillFile)
+	suite.Require().NoError(err)
+
+	var heights []int64
+	suite.Require().NoError(json.Unmarshal(data, &heights))
+	suite.Equal([]int64{2, 3, 4}, heights)
+}
+
+func (suite *BackpressureTestSuite) TestSpillFileKeptAcrossRuns() {
+	spillFile := filepath.Join(suite.T().TempDir(), "spilled.json")
+	suite.Require().NoError(os.WriteFile(spillFile, []byte("[9, 3]"), 0o600))
+
+	spiller, err := newBlockSpiller(spillFile)
+	suite.Require().NoError(err)
+	suite.Zero(spiller.spilledBlocks())
+
+	suite.Require().NoError(spiller.spill(5))
+	suite.Require().NoError(spiller.spill(3))
+	suite.Require().NoError(spiller.spill(12))
+	suite.Equal(2, spiller.spilledBlocks())
+
+	data, err := os.ReadFile(spillFile)
+	suite.Require().NoError(err)
+
+	var heights []int64
+	suite.Require().NoError(json.Unmarshal(data, &heights))
+	suite.Equal([]int64{3, 9, 5, 12}, heights)
+}
+
+func TestBackpressureTestSuite(t *testing.T) {
+	suite.Run(t, new(BackpressureTestSuite))
+}
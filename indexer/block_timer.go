@@ -0,0 +1,63 @@
+package indexer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+)
+
+// Swapped out in tests to fire interval reports without waiting seconds
+var blockTimerIntervalUnit = time.Second
+
+// blockTimer reports the block processing rate of the DB worker, every base.block-timer blocks in the count mode or every
+// base.block-timer-interval seconds in the interval mode, however many blocks were processed in between
+type blockTimer struct {
+	mode      string
+	every     int64
+	interval  time.Duration
+	processed int
+	reported  int
+	start     time.Time
+	report    func(blocks int, elapsed time.Duration, total int)
+}
+
+func newBlockTimer(conf *config.IndexConfig, start time.Time) *blockTimer {
+	return &blockTimer{
+		mode:     conf.Base.BlockTimerMode,
+		every:    conf.Base.BlockTimer,
+		interval: time.Duration(conf.Base.BlockTimerInterval) * blockTimerIntervalUnit,
+		start:    start,
+		report: func(blocks int, elapsed time.Duration, total int) {
+			config.Log.Info(fmt.Sprintf("Processing %d blocks took %f seconds. %d total blocks have been processed.\n", blocks, elapsed.Seconds(), total))
+		},
+	}
+}
+
+// ticks returns the channel the interval reports are made on and a function stopping it, the channel is nil in the count mode
+func (timer *blockTimer) ticks() (<-chan time.Time, func()) {
+	if timer.mode != config.BlockTimerModeInterval {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(timer.interval)
+	return ticker.C, ticker.Stop
+}
+
+// blockProcessed counts a processed block, reporting every base.block-timer blocks in the count mode
+func (timer *blockTimer) blockProcessed(now time.Time) {
+	timer.processed++
+	if timer.mode != config.BlockTimerModeInterval && timer.every > 0 && int64(timer.processed)%timer.every == 0 {
+		timer.flush(now)
+	}
+}
+
+// tick reports the blocks processed since the last report, called on every interval tick
+func (timer *blockTimer) tick(now time.Time) {
+	timer.flush(now)
+}
+
+func (timer *blockTimer) flush(now time.Time) {
+	timer.report(timer.processed-timer.reported, now.Sub(timer.start), timer.processed)
+	timer.reported = timer.processed
+	timer.start = now
+}
@@ -0,0 +1,75 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type BlockTimerTestSuite struct {
+	suite.Suite
+}
+
+// recordReports replaces the timer's log with a record of the blocks of each report
+func recordReports(timer *blockTimer) *[]int {
+	reports := &[]int{}
+	timer.report = func(blocks int, elapsed time.Duration, total int) {
+		*reports = append(*reports, blocks)
+	}
+	return reports
+}
+
+func (suite *BlockTimerTestSuite) TestIntervalReportsFireOnTimer() {
+	originalUnit := blockTimerIntervalUnit
+	blockTimerIntervalUnit = time.Millisecond
+	defer func() { blockTimerIntervalUnit = originalUnit }()
+
+	cfg := &config.IndexConfig{}
+	cfg.Base.BlockTimer = 10000
+	cfg.Base.BlockTimerMode = config.BlockTimerModeInterval
+	cfg.Base.BlockTimerInterval = 10
+
+	timer := newBlockTimer(cfg, time.Now())
+	reports := recordReports(timer)
+	ticks, stop := timer.ticks()
+	defer stop()
+	suite.Require().NotNil(ticks)
+
+	// Far fewer blocks than the block timer count, then no blocks at all
+	timer.blockProcessed(time.Now())
+	timer.blockProcessed(time.Now())
+	suite.Empty(*reports)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case now := <-ticks:
+			timer.tick(now)
+		case <-time.After(time.Second):
+			suite.FailNow("interval report did not fire")
+		}
+	}
+	suite.Equal([]int{2, 0, 0}, *reports)
+}
+
+func (suite *BlockTimerTestSuite) TestCountReports() {
+	cfg := &config.IndexConfig{}
+	cfg.Base.BlockTimer = 3
+	cfg.Base.BlockTimerMode = config.BlockTimerModeCount
+
+	timer := newBlockTimer(cfg, time.Now())
+	reports := recordReports(timer)
+	ticks, stop := timer.ticks()
+	defer stop()
+	suite.Nil(ticks)
+
+	for i := 0; i < 7; i++ {
+		timer.blockProcessed(time.Now())
+	}
+	suite.Equal([]int{3, 3}, *reports)
+}
+
+func TestBlockTimerTestSuite(t *testing.T) {
+	suite.Run(t, new(BlockTimerTestSuite))
+}
@@ -0,0 +1,90 @@
+package indexer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+)
+
+// CompletionMarker is the JSON document written to the base.completion-marker-file once a bounded run completes cleanly
+type CompletionMarker struct {
+	ChainID      string    `json:"chain_id"`
+	FinalHeight  int64     `json:"final_height"`
+	Blocks       int64     `json:"blocks"`
+	Transactions int64     `json:"transactions"`
+	Messages     int64     `json:"messages"`
+	BlockEvents  int64     `json:"block_events"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+// runCounts counts the rows written by the DB worker during the run
+type runCounts struct {
+	txBlocks     atomic.Int64
+	eventBlocks  atomic.Int64
+	transactions atomic.Int64
+	messages     atomic.Int64
+	blockEvents  atomic.Int64
+}
+
+func (counts *runCounts) recordTxs(txDBWrappers []dbTypes.TxDBWrapper) {
+	counts.txBlocks.Add(1)
+	counts.transactions.Add(int64(len(txDBWrappers)))
+	for _, tx := range txDBWrappers {
+		counts.messages.Add(int64(len(tx.Messages)))
+	}
+}
+
+func (counts *runCounts) recordBlockEvents(numEvents int) {
+	counts.eventBlocks.Add(1)
+	counts.blockEvents.Add(int64(numEvents))
+}
+
+// RemoveCompletionMarker removes the marker of a previous run when the run starts, so a stale marker is never left behind by a run
+// that fails
+func RemoveCompletionMarker(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing the previous completion marker: %w", err)
+	}
+	return nil
+}
+
+// WriteCompletionMarker writes the marker with the final height and the rows written by the run. It errors without writing the
+// marker if any block failed or was spilled during the run, since those blocks are missing from the indexed range. The marker is
+// written to a temporary file first and renamed, so readers never see a partial marker.
+func (indexer *Indexer) WriteCompletionMarker(path string) error {
+	if failed := indexer.FailedBlocks.Count(); failed > 0 {
+		return fmt.Errorf("%d blocks failed during the run", failed)
+	}
+	if spilled := indexer.spiller.spilledBlocks(); spilled > 0 {
+		return fmt.Errorf("%d blocks were dropped by the drop-to-disk backpressure policy during the run", spilled)
+	}
+
+	blocks := indexer.runCounts.txBlocks.Load()
+	if eventBlocks := indexer.runCounts.eventBlocks.Load(); eventBlocks > blocks {
+		blocks = eventBlocks
+	}
+
+	marker, err := json.Marshal(CompletionMarker{
+		ChainID:      indexer.Config.Probe.ChainID,
+		FinalHeight:  indexer.lastIndexedHeight.height.Load(),
+		Blocks:       blocks,
+		Transactions: indexer.runCounts.transactions.Load(),
+		Messages:     indexer.runCounts.messages.Load(),
+		BlockEvents:  indexer.runCounts.blockEvents.Load(),
+		CompletedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, marker, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
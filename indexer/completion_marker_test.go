@@ -0,0 +1,78 @@
+package indexer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/stretchr/testify/suite"
+)
+
+type CompletionMarkerTestSuite struct {
+	suite.Suite
+	path string
+}
+
+func (suite *CompletionMarkerTestSuite) SetupTest() {
+	suite.path = filepath.Join(suite.T().TempDir(), "indexer.done")
+}
+
+// runIndexer records the rows of a run of two blocks as the DB worker does
+func (suite *CompletionMarkerTestSuite) runIndexer() *Indexer {
+	indexer := &Indexer{Config: &config.IndexConfig{}, FailedBlocks: &dbTypes.FailedBlockCounter{}}
+	indexer.Config.Probe.ChainID = "testchain-1"
+
+	txs := []dbTypes.TxDBWrapper{{Messages: make([]dbTypes.MessageDBWrapper, 2)}, {Messages: make([]dbTypes.MessageDBWrapper, 1)}}
+	for height := int64(10); height <= 11; height++ {
+		indexer.runCounts.recordTxs(txs)
+		indexer.runCounts.recordBlockEvents(4)
+		indexer.lastIndexedHeight.update(height)
+	}
+	return indexer
+}
+
+func (suite *CompletionMarkerTestSuite) TestWrittenOnSuccess() {
+	suite.Require().NoError(os.WriteFile(suite.path, []byte("stale"), 0o600))
+	suite.Require().NoError(RemoveCompletionMarker(suite.path))
+	suite.NoFileExists(suite.path)
+
+	suite.Require().NoError(suite.runIndexer().WriteCompletionMarker(suite.path))
+
+	data, err := os.ReadFile(suite.path)
+	suite.Require().NoError(err)
+
+	var marker CompletionMarker
+	suite.Require().NoError(json.Unmarshal(data, &marker))
+	suite.Equal("testchain-1", marker.ChainID)
+	suite.Equal(int64(11), marker.FinalHeight)
+	suite.Equal(int64(2), marker.Blocks)
+	suite.Equal(int64(4), marker.Transactions)
+	suite.Equal(int64(6), marker.Messages)
+	suite.Equal(int64(8), marker.BlockEvents)
+}
+
+func (suite *CompletionMarkerTestSuite) TestAbsentOnFailure() {
+	indexer := suite.runIndexer()
+	indexer.FailedBlocks.Add()
+
+	suite.Require().Error(indexer.WriteCompletionMarker(suite.path))
+	suite.NoFileExists(suite.path)
+}
+
+func (suite *CompletionMarkerTestSuite) TestAbsentWhenBlocksSpilled() {
+	indexer := suite.runIndexer()
+	spiller, err := newBlockSpiller(filepath.Join(suite.T().TempDir(), "spilled-blocks.json"))
+	suite.Require().NoError(err)
+	indexer.spiller = spiller
+	suite.Require().NoError(indexer.spiller.spill(12))
+
+	suite.Require().Error(indexer.WriteCompletionMarker(suite.path))
+	suite.NoFileExists(suite.path)
+}
+
+func TestCompletionMarkerTestSuite(t *testing.T) {
+	suite.Run(t, new(CompletionMarkerTestSuite))
+}
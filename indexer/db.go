@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/core"
 	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"gorm.io/gorm"
 )
 
 // doDBUpdates will read the data out of the db data chan that had been processed by the workers
@@ -14,12 +16,29 @@ import (
 // otherwise we will index the data in the DB.
 // it will also read rewars data and index that.
 func (indexer *Indexer) DoDBUpdates(wg *sync.WaitGroup, txDataChan chan *DBData, blockEventsDataChan chan *BlockEventsDBData, dbChainID uint) {
-	blocksProcessed := 0
 	dbWrites := 0
 	dbReattempts := 0
-	timeStart := time.Now()
 	defer wg.Done()
 
+	timer := newBlockTimer(indexer.Config, time.Now())
+	timerTicks, stopTimer := timer.ticks()
+	defer stopTimer()
+
+	// Block event IDs are only known for committed writes, dry runs are never deduplicated
+	var staticEvents *dbTypes.StaticEventDeduper
+	if indexer.Config.Base.DedupeStaticEvents && !indexer.DryRun {
+		staticEvents = dbTypes.NewStaticEventDeduper()
+	}
+
+	if indexer.Config.Base.ProfileEvents {
+		indexer.eventProfiler = newEventProfiler()
+	}
+
+	// Blocks of the background re-index range replace their existing tx data and block events whatever the reindex mode of the main pipeline
+	backgroundReindexConfig := *indexer.Config
+	backgroundReindexConfig.Base.ReIndex = true
+	backgroundReindexConfig.Base.ReIndexMode = config.ReIndexModeReplace
+
 	for {
 		// break out of loop once all channels are fully consumed
 		if txDataChan == nil && blockEventsDataChan == nil {
@@ -35,34 +54,76 @@ func (indexer *Indexer) DoDBUpdates(wg *sync.WaitGroup, txDataChan chan *DBData,
 				continue
 			}
 			dbWrites++
+			_, commitSpan := core.StartBlockSpan(data.traceContext, core.SpanBlockCommitTxs, data.block.Height)
 			// While debugging we'll sometimes want to turn off INSERTS to the DB
 			// Note that this does not turn off certain reads or DB connections.
+			indexer.eventProfiler.profileTxs(data.block.Height, data.txDBWrappers)
 			indexedBlock := data.block
 			indexedDataset := data.txDBWrappers
+			indexConfig := indexer.Config
+			if data.backgroundReindex {
+				indexConfig = &backgroundReindexConfig
+			}
 
 			if !indexer.DryRun {
 				var err error
 				config.Log.Info(fmt.Sprintf("Indexing %v TXs from block %d", len(data.txDBWrappers), data.block.Height))
-				indexedBlock, indexedDataset, err = dbTypes.IndexNewBlock(indexer.DB, data.block, data.txDBWrappers, *indexer.Config)
-				if err != nil {
-					// Do a single reattempt on failure
+				// The block context bounds the DB transactions, cancelling it rolls back any in-flight transaction
+				// The block's budget only runs again now, the time spent in the DB write queue is not counted
+				ctx, cancel := data.budget.context()
+				blockDB := indexer.DB.WithContext(ctx)
+				commitStart := time.Now()
+				err = dbTypes.RetryCommit(indexer.Config.Database, func() error {
+					var err error
+					indexedBlock, indexedDataset, err = dbTypes.IndexNewBlock(blockDB, indexer.TransactionLimiter, indexer.TxColumns, data.block, data.txDBWrappers, *indexConfig)
+					return err
+				})
+				if err != nil && !isBlockTimeout(err) && !dbTypes.IsSerializationFailure(err) {
+					// Do a single reattempt on failure, serialization failures have already used up their retries
 					dbReattempts++
-					_, _, err = dbTypes.IndexNewBlock(indexer.DB, data.block, data.txDBWrappers, *indexer.Config)
-					if err != nil {
-						config.Log.Fatal(fmt.Sprintf("Error indexing block %v.", data.block.Height), err)
+					indexedBlock, indexedDataset, err = dbTypes.IndexNewBlock(blockDB, indexer.TransactionLimiter, indexer.TxColumns, data.block, data.txDBWrappers, *indexConfig)
+				}
+
+				if err == nil {
+					err = dbTypes.IndexCustomMessages(*indexer.Config, blockDB, indexer.TransactionLimiter, indexer.DryRun, indexedDataset, indexer.CustomMessageParserTrackers)
+					if err != nil && !isBlockTimeout(err) {
+						config.Log.Fatal(fmt.Sprintf("Error indexing custom messages for block %d", data.block.Height), err)
+					}
+				}
+				if err == nil && indexer.Config.Base.IndexBlockChecksums {
+					err = dbTypes.UpdateBlockChecksum(blockDB, indexedBlock.ID)
+					if err != nil && !isBlockTimeout(err) {
+						config.Log.Fatal(fmt.Sprintf("Error updating the checksum of block %d", data.block.Height), err)
 					}
 				}
+				cancel()
+				indexer.FetchThrottle.Observe(time.Since(commitStart))
 
-				err = dbTypes.IndexCustomMessages(*indexer.Config, indexer.DB, indexer.DryRun, indexedDataset, indexer.CustomMessageParserTrackers)
-				if err != nil {
-					config.Log.Fatal(fmt.Sprintf("Error indexing custom messages for block %d", data.block.Height), err)
+				if isBlockTimeout(err) {
+					indexer.handleDBTimeout(data.block.Height, err, dbTypes.UpsertFailedBlock, false, data.backgroundReindex)
+					commitSpan.RecordError(err)
+					commitSpan.End()
+					data.inflight.Release()
+					continue
+				} else if err != nil {
+					config.Log.Fatal(fmt.Sprintf("Error indexing block %v.", data.block.Height), err)
 				}
 
+				indexer.lastIndexedHeight.update(data.block.Height)
+				indexer.Phase.Observe(data.block.Height)
+				indexer.runCounts.recordTxs(indexedDataset)
+				if err := indexer.AuditLog.recordTxs(indexedBlock.Height, indexedDataset, indexConfig); err != nil {
+					config.Log.Fatal(fmt.Sprintf("Error writing the audit record of block %d", data.block.Height), err)
+				}
 				config.Log.Info(fmt.Sprintf("Finished indexing %v TXs from block %d", len(data.txDBWrappers), data.block.Height))
 			} else {
 				config.Log.Info(fmt.Sprintf("Processing block %d (dry run, block data will not be stored in DB).", data.block.Height))
 			}
 
+			aggregate := core.NewBlockAggregate(indexedBlock, indexedDataset)
+			aggregate.Phase = indexer.Phase.Phase()
+			indexer.InfluxSink.Write(aggregate)
+
 			if indexer.PostIndexCustomMessageFunction != nil {
 				config.Log.Info(fmt.Sprintf("Running PostIndexCustomMessageFunction for block %d", data.block.Height))
 
@@ -81,39 +142,94 @@ func (indexer *Indexer) DoDBUpdates(wg *sync.WaitGroup, txDataChan chan *DBData,
 				}
 			}
 
+			commitSpan.End()
+			data.inflight.Release()
+
 			// Just measuring how many blocks/second we can process
+			timer.blockProcessed(time.Now())
 			if indexer.Config.Base.BlockTimer > 0 {
-				blocksProcessed++
-				if blocksProcessed%int(indexer.Config.Base.BlockTimer) == 0 {
-					totalTime := time.Since(timeStart)
-					config.Log.Info(fmt.Sprintf("Processing %d blocks took %f seconds. %d total blocks have been processed.\n", indexer.Config.Base.BlockTimer, totalTime.Seconds(), blocksProcessed))
-					timeStart = time.Now()
-				}
 				if float64(dbReattempts)/float64(dbWrites) > .1 {
 					config.Log.Fatalf("More than 10%% of the last %v DB writes have failed.", dbWrites)
 				}
 			}
+		case now := <-timerTicks:
+			// Interval reports, made whether or not blocks were processed since the last one
+			timer.tick(now)
 		case eventData, ok := <-blockEventsDataChan:
 			if !ok {
 				blockEventsDataChan = nil
 				continue
 			}
 			dbWrites++
-			numEvents := len(eventData.blockDBWrapper.BeginBlockEvents) + len(eventData.blockDBWrapper.EndBlockEvents)
+			_, commitSpan := core.StartBlockSpan(eventData.traceContext, core.SpanBlockCommitEvents, eventData.blockDBWrapper.Block.Height)
+			indexer.eventProfiler.profileBlockEvents(eventData.blockDBWrapper)
+			numEvents := len(eventData.blockDBWrapper.BeginBlockEvents) + len(eventData.blockDBWrapper.EndBlockEvents) + len(eventData.blockDBWrapper.Block.Events)
 			config.Log.Info(fmt.Sprintf("Indexing %v Block Events from block %d", numEvents, eventData.blockDBWrapper.Block.Height))
 			identifierLoggingString := fmt.Sprintf("block %d", eventData.blockDBWrapper.Block.Height)
 
-			indexedDataset, err := dbTypes.IndexBlockEvents(indexer.DB, indexer.DryRun, eventData.blockDBWrapper, identifierLoggingString)
-			if err != nil {
-				config.Log.Fatal(fmt.Sprintf("Error indexing block events for %s.", identifierLoggingString), err)
+			staticEvents.Dedupe(eventData.blockDBWrapper)
+
+			replaceBlockEvents := !indexer.DryRun && (dbTypes.ReplacesExisting(*indexer.Config) || eventData.backgroundReindex)
+
+			ctx, cancel := eventData.budget.context()
+			blockDB := indexer.DB.WithContext(ctx)
+			commitStart := time.Now()
+			var indexedDataset *dbTypes.BlockDBWrapper
+			err := dbTypes.RetryCommit(indexer.Config.Database, func() error {
+				var err error
+				indexedDataset, err = dbTypes.IndexBlockEvents(blockDB, indexer.TransactionLimiter, indexer.DryRun, replaceBlockEvents, eventData.blockDBWrapper, identifierLoggingString)
+				return err
+			})
+			if err == nil {
+				err = dbTypes.IndexCustomBlockEvents(*indexer.Config, blockDB, indexer.TransactionLimiter, indexer.DryRun, indexedDataset, identifierLoggingString, indexer.CustomBeginBlockParserTrackers, indexer.CustomEndBlockParserTrackers)
+				if err != nil && !isBlockTimeout(err) {
+					config.Log.Fatal(fmt.Sprintf("Error indexing custom block events for %s.", identifierLoggingString), err)
+				}
+			}
+			if err == nil && !indexer.DryRun && indexer.Config.Base.IndexBlockChecksums {
+				err = dbTypes.UpdateBlockChecksum(blockDB, indexedDataset.Block.ID)
+				if err != nil && !isBlockTimeout(err) {
+					config.Log.Fatal(fmt.Sprintf("Error updating the checksum of %s.", identifierLoggingString), err)
+				}
+			}
+			cancel()
+			if !indexer.DryRun {
+				indexer.FetchThrottle.Observe(time.Since(commitStart))
 			}
 
-			err = dbTypes.IndexCustomBlockEvents(*indexer.Config, indexer.DB, indexer.DryRun, indexedDataset, identifierLoggingString, indexer.CustomBeginBlockParserTrackers, indexer.CustomEndBlockParserTrackers)
-			if err != nil {
-				config.Log.Fatal(fmt.Sprintf("Error indexing custom block events for %s.", identifierLoggingString), err)
+			if isBlockTimeout(err) {
+				indexer.handleDBTimeout(eventData.blockDBWrapper.Block.Height, err, dbTypes.UpsertFailedEventBlock, true, eventData.backgroundReindex)
+				commitSpan.RecordError(err)
+				commitSpan.End()
+				eventData.inflight.Release()
+				continue
+			} else if err != nil {
+				config.Log.Fatal(fmt.Sprintf("Error indexing block events for %s.", identifierLoggingString), err)
 			}
 
+			staticEvents.Record(indexedDataset)
+			commitSpan.End()
+			eventData.inflight.Release()
+			indexer.lastIndexedHeight.update(eventData.blockDBWrapper.Block.Height)
+			indexer.Phase.Observe(eventData.blockDBWrapper.Block.Height)
+			indexer.runCounts.recordBlockEvents(numEvents)
+			if !indexer.DryRun {
+				if err := indexer.AuditLog.recordBlockEvents(indexedDataset); err != nil {
+					config.Log.Fatal(fmt.Sprintf("Error writing the audit record of %s.", identifierLoggingString), err)
+				}
+			}
 			config.Log.Info(fmt.Sprintf("Finished indexing %v Block Events from block %d", numEvents, eventData.blockDBWrapper.Block.Height))
 		}
 	}
 }
+
+// handleDBTimeout records a block whose DB writes were cancelled by the block timeout so it can be reattempted later
+func (indexer *Indexer) handleDBTimeout(height int64, err error, upsertFailed func(*gorm.DB, *dbTypes.FailedBlockCounter, int64, string, string) error, blockEvents bool, backgroundReindex bool) {
+	config.Log.Errorf("Timed out indexing block %d, adding to failed blocks table", height)
+	indexer.ErrorWebhook.FailedBlockHandler()(height, core.BlockProcessingTimeout, err)
+	err = upsertFailed(indexer.DB, indexer.FailedBlocks, height, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
+	if err != nil {
+		config.Log.Fatal("Failed to insert failed block", err)
+	}
+	indexer.retryTimedOutBlock(height, blockEvents, backgroundReindex)
+}
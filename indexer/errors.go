@@ -0,0 +1,22 @@
+package indexer
+
+import "errors"
+
+// Errors returned by the index command. They are wrapped with the underlying cause, so embedding applications can
+// check for them with errors.Is on the error returned from executing the root command.
+var (
+	// ErrConfigInvalid is returned when the index configuration or one of the files it references fails validation
+	ErrConfigInvalid = errors.New("invalid indexer configuration")
+	// ErrChainMismatch is returned when the configured chain ID does not match the chain ID reported by the node
+	ErrChainMismatch = errors.New("configured chain ID does not match the node")
+	// ErrNodeTxIndexDisabled is returned when the node has tx indexing disabled and the configuration relies on tx search
+	ErrNodeTxIndexDisabled = errors.New("node has tx indexing disabled")
+	// ErrNodeUnreachable is returned when the node RPC cannot be queried
+	ErrNodeUnreachable = errors.New("node RPC is unreachable")
+	// ErrDBUnavailable is returned when the database cannot be connected to
+	ErrDBUnavailable = errors.New("database is unavailable")
+	// ErrEnqueueFailed is returned when the block enqueue function cannot be generated or fails while enqueueing blocks
+	ErrEnqueueFailed = errors.New("block enqueue failed")
+	// ErrCustomFunctionFailed is returned when the post setup or pre-exit custom function of an embedding application fails
+	ErrCustomFunctionFailed = errors.New("custom function failed")
+)
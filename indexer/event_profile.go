@@ -0,0 +1,206 @@
+package indexer
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+)
+
+// Sources of the profiled events
+const (
+	EventSourceBeginBlock = "begin_block"
+	EventSourceEndBlock   = "end_block"
+	EventSourceMessage    = "message"
+)
+
+// EventProfile is the JSON report written to the base.profile-events-file at the end of a base.profile-events run
+type EventProfile struct {
+	ChainID    string             `json:"chain_id"`
+	FromHeight int64              `json:"from_height"`
+	ToHeight   int64              `json:"to_height"`
+	Blocks     int64              `json:"blocks"`
+	Events     []EventTypeProfile `json:"events"`
+	ProfiledAt time.Time          `json:"profiled_at"`
+}
+
+// EventTypeProfile is the cardinality of one event type from one source over the profiled blocks
+type EventTypeProfile struct {
+	Source            string  `json:"source"`
+	Type              string  `json:"type"`
+	Count             int64   `json:"count"`
+	Blocks            int64   `json:"blocks"`
+	AverageAttributes float64 `json:"average_attributes"`
+	MaxAttributes     int     `json:"max_attributes"`
+	// Number of events per attribute count
+	AttributeCounts map[int]int64 `json:"attribute_counts"`
+}
+
+type eventTypeKey struct {
+	source    string
+	eventType string
+}
+
+type eventTypeStats struct {
+	count           int64
+	attributes      int64
+	maxAttributes   int
+	attributeCounts map[int]int64
+	// Last height the type was seen at, blocks are counted once per type
+	lastHeight int64
+	blocks     int64
+}
+
+// eventProfiler tallies the events of the blocks the DB worker receives with base.profile-events, removing them from the block
+// data so they are never written. A nil profiler leaves the events in place.
+type eventProfiler struct {
+	types   map[eventTypeKey]*eventTypeStats
+	heights map[int64]struct{}
+	from    int64
+	to      int64
+}
+
+func newEventProfiler() *eventProfiler {
+	return &eventProfiler{
+		types:   make(map[eventTypeKey]*eventTypeStats),
+		heights: make(map[int64]struct{}),
+	}
+}
+
+// profileTxs tallies and removes the message events of the txs
+func (profiler *eventProfiler) profileTxs(height int64, txs []dbTypes.TxDBWrapper) {
+	if profiler == nil {
+		return
+	}
+
+	profiler.observeHeight(height)
+	for i := range txs {
+		for j := range txs[i].Messages {
+			message := &txs[i].Messages[j]
+			for _, event := range message.MessageEvents {
+				profiler.observe(height, EventSourceMessage, event.MessageEvent.MessageEventType.Type, len(event.Attributes))
+			}
+			message.MessageEvents = nil
+		}
+		txs[i].UniqueMessageEventTypes = map[string]models.MessageEventType{}
+		txs[i].UniqueMessageAttributeKeys = map[string]models.MessageEventAttributeKey{}
+	}
+}
+
+// profileBlockEvents tallies and removes the block events, whether they are stored normalized or as jsonb
+func (profiler *eventProfiler) profileBlockEvents(blockDBWrapper *dbTypes.BlockDBWrapper) {
+	if profiler == nil {
+		return
+	}
+
+	height := blockDBWrapper.Block.Height
+	profiler.observeHeight(height)
+	for _, event := range blockDBWrapper.BeginBlockEvents {
+		profiler.observe(height, EventSourceBeginBlock, event.BlockEvent.BlockEventType.Type, len(event.Attributes))
+	}
+	for _, event := range blockDBWrapper.EndBlockEvents {
+		profiler.observe(height, EventSourceEndBlock, event.BlockEvent.BlockEventType.Type, len(event.Attributes))
+	}
+	for _, event := range blockDBWrapper.Block.Events {
+		source := EventSourceBeginBlock
+		if event.LifecyclePosition == models.JSONEndBlockEvent {
+			source = EventSourceEndBlock
+		}
+		profiler.observe(height, source, event.Type, len(event.Attributes))
+	}
+
+	blockDBWrapper.BeginBlockEvents = nil
+	blockDBWrapper.EndBlockEvents = nil
+	blockDBWrapper.Block.Events = nil
+	blockDBWrapper.UniqueBlockEventTypes = map[string]models.BlockEventType{}
+	blockDBWrapper.UniqueBlockEventAttributeKeys = map[string]models.BlockEventAttributeKey{}
+}
+
+func (profiler *eventProfiler) observeHeight(height int64) {
+	if len(profiler.heights) == 0 || height < profiler.from {
+		profiler.from = height
+	}
+	if height > profiler.to {
+		profiler.to = height
+	}
+	profiler.heights[height] = struct{}{}
+}
+
+func (profiler *eventProfiler) observe(height int64, source string, eventType string, attributes int) {
+	key := eventTypeKey{source: source, eventType: eventType}
+	stats, ok := profiler.types[key]
+	if !ok {
+		stats = &eventTypeStats{attributeCounts: make(map[int]int64)}
+		profiler.types[key] = stats
+	}
+
+	stats.count++
+	stats.attributes += int64(attributes)
+	stats.attributeCounts[attributes]++
+	if attributes > stats.maxAttributes {
+		stats.maxAttributes = attributes
+	}
+	if !ok || stats.lastHeight != height {
+		stats.blocks++
+		stats.lastHeight = height
+	}
+}
+
+// profile returns the tallied event types, the most frequent first
+func (profiler *eventProfiler) profile(chainID string) EventProfile {
+	profile := EventProfile{
+		ChainID:    chainID,
+		FromHeight: profiler.from,
+		ToHeight:   profiler.to,
+		Blocks:     int64(len(profiler.heights)),
+		Events:     make([]EventTypeProfile, 0, len(profiler.types)),
+		ProfiledAt: time.Now().UTC(),
+	}
+
+	for key, stats := range profiler.types {
+		profile.Events = append(profile.Events, EventTypeProfile{
+			Source:            key.source,
+			Type:              key.eventType,
+			Count:             stats.count,
+			Blocks:            stats.blocks,
+			AverageAttributes: float64(stats.attributes) / float64(stats.count),
+			MaxAttributes:     stats.maxAttributes,
+			AttributeCounts:   stats.attributeCounts,
+		})
+	}
+
+	sort.Slice(profile.Events, func(i, j int) bool {
+		a, b := profile.Events[i], profile.Events[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Type < b.Type
+	})
+
+	return profile
+}
+
+// WriteEventProfile writes the event profile of the run to the path. Like the completion marker, the report is written to a
+// temporary file first and renamed, so readers never see a partial report.
+func (indexer *Indexer) WriteEventProfile(path string) error {
+	if indexer.eventProfiler == nil {
+		return nil
+	}
+
+	report, err := json.MarshalIndent(indexer.eventProfiler.profile(indexer.Config.Probe.ChainID), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, report, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
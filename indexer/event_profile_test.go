@@ -0,0 +1,124 @@
+package indexer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventProfileTestSuite struct {
+	suite.Suite
+}
+
+func blockEventFixture(eventType string, attributes int) dbTypes.BlockEventDBWrapper {
+	return dbTypes.BlockEventDBWrapper{
+		BlockEvent: models.BlockEvent{BlockEventType: models.BlockEventType{Type: eventType}},
+		Attributes: make([]models.BlockEventAttribute, attributes),
+	}
+}
+
+func messageEventFixture(eventType string, attributes int) dbTypes.MessageEventDBWrapper {
+	return dbTypes.MessageEventDBWrapper{
+		MessageEvent: models.MessageEvent{MessageEventType: models.MessageEventType{Type: eventType}},
+		Attributes:   make([]models.MessageEventAttribute, attributes),
+	}
+}
+
+func txFixture(events ...dbTypes.MessageEventDBWrapper) dbTypes.TxDBWrapper {
+	return dbTypes.TxDBWrapper{
+		Messages:                []dbTypes.MessageDBWrapper{{MessageEvents: events}},
+		UniqueMessageEventTypes: map[string]models.MessageEventType{"transfer": {Type: "transfer"}},
+	}
+}
+
+func (suite *EventProfileTestSuite) TestProfileMatchesFixture() {
+	profiler := newEventProfiler()
+
+	// Block events: one mint per block, coin_spent in two of the blocks
+	blocks := []*dbTypes.BlockDBWrapper{
+		{Block: &models.Block{Height: 100}, BeginBlockEvents: []dbTypes.BlockEventDBWrapper{blockEventFixture("mint", 4)}, EndBlockEvents: []dbTypes.BlockEventDBWrapper{blockEventFixture("coin_spent", 3), blockEventFixture("coin_spent", 3)}},
+		{Block: &models.Block{Height: 101}, BeginBlockEvents: []dbTypes.BlockEventDBWrapper{blockEventFixture("mint", 4)}},
+		{Block: &models.Block{Height: 102}, BeginBlockEvents: []dbTypes.BlockEventDBWrapper{blockEventFixture("mint", 4)}, EndBlockEvents: []dbTypes.BlockEventDBWrapper{blockEventFixture("coin_spent", 5)}},
+		{Block: &models.Block{Height: 103}, BeginBlockEvents: []dbTypes.BlockEventDBWrapper{blockEventFixture("mint", 4)}},
+		// Stored as jsonb
+		{Block: &models.Block{Height: 104, Events: models.BlockEventsJSON{
+			{LifecyclePosition: models.JSONBeginBlockEvent, Type: "mint", Attributes: make([]models.BlockEventAttributeJSON, 4)},
+		}}},
+	}
+	for _, block := range blocks {
+		profiler.profileBlockEvents(block)
+		suite.Empty(block.BeginBlockEvents)
+		suite.Empty(block.EndBlockEvents)
+		suite.Empty(block.Block.Events)
+		suite.Empty(block.UniqueBlockEventTypes)
+	}
+
+	// Message events: three transfers over two blocks, two message events in the first block only
+	txs100 := []dbTypes.TxDBWrapper{
+		txFixture(messageEventFixture("transfer", 3), messageEventFixture("message", 1)),
+		txFixture(messageEventFixture("transfer", 3), messageEventFixture("message", 1)),
+	}
+	txs101 := []dbTypes.TxDBWrapper{txFixture(messageEventFixture("transfer", 3))}
+	profiler.profileTxs(100, txs100)
+	profiler.profileTxs(101, txs101)
+	for _, tx := range append(txs100, txs101...) {
+		suite.Empty(tx.Messages[0].MessageEvents)
+		suite.Empty(tx.UniqueMessageEventTypes)
+	}
+
+	profile := profiler.profile("testchain-1")
+	suite.Equal("testchain-1", profile.ChainID)
+	suite.Equal(int64(100), profile.FromHeight)
+	suite.Equal(int64(104), profile.ToHeight)
+	suite.Equal(int64(5), profile.Blocks)
+
+	suite.Require().Len(profile.Events, 4)
+	suite.Equal(EventTypeProfile{Source: EventSourceBeginBlock, Type: "mint", Count: 5, Blocks: 5, AverageAttributes: 4, MaxAttributes: 4, AttributeCounts: map[int]int64{4: 5}}, profile.Events[0])
+	suite.Equal(EventTypeProfile{Source: EventSourceEndBlock, Type: "coin_spent", Count: 3, Blocks: 2, AverageAttributes: 11.0 / 3, MaxAttributes: 5, AttributeCounts: map[int]int64{3: 2, 5: 1}}, profile.Events[1])
+	suite.Equal(EventTypeProfile{Source: EventSourceMessage, Type: "transfer", Count: 3, Blocks: 2, AverageAttributes: 3, MaxAttributes: 3, AttributeCounts: map[int]int64{3: 3}}, profile.Events[2])
+	suite.Equal(EventTypeProfile{Source: EventSourceMessage, Type: "message", Count: 2, Blocks: 1, AverageAttributes: 1, MaxAttributes: 1, AttributeCounts: map[int]int64{1: 2}}, profile.Events[3])
+}
+
+func (suite *EventProfileTestSuite) TestWriteReport() {
+	indexer := &Indexer{Config: &config.IndexConfig{}, eventProfiler: newEventProfiler()}
+	indexer.Config.Probe.ChainID = "testchain-1"
+	indexer.eventProfiler.profileBlockEvents(&dbTypes.BlockDBWrapper{
+		Block:            &models.Block{Height: 7},
+		BeginBlockEvents: []dbTypes.BlockEventDBWrapper{blockEventFixture("mint", 2)},
+	})
+
+	path := filepath.Join(suite.T().TempDir(), "event-profile.json")
+	suite.Require().NoError(indexer.WriteEventProfile(path))
+
+	data, err := os.ReadFile(path)
+	suite.Require().NoError(err)
+
+	var profile EventProfile
+	suite.Require().NoError(json.Unmarshal(data, &profile))
+	suite.Equal(int64(1), profile.Blocks)
+	suite.Require().Len(profile.Events, 1)
+	suite.Equal(map[int]int64{2: 1}, profile.Events[0].AttributeCounts)
+	suite.NoFileExists(path + ".tmp")
+}
+
+func (suite *EventProfileTestSuite) TestNilProfilerKeepsEvents() {
+	var profiler *eventProfiler
+	block := &dbTypes.BlockDBWrapper{Block: &models.Block{Height: 1}, BeginBlockEvents: []dbTypes.BlockEventDBWrapper{blockEventFixture("mint", 1)}}
+	profiler.profileBlockEvents(block)
+	suite.Len(block.BeginBlockEvents, 1)
+
+	indexer := &Indexer{Config: &config.IndexConfig{}}
+	path := filepath.Join(suite.T().TempDir(), "event-profile.json")
+	suite.Require().NoError(indexer.WriteEventProfile(path))
+	suite.NoFileExists(path)
+}
+
+func TestEventProfileTestSuite(t *testing.T) {
+	suite.Run(t, new(EventProfileTestSuite))
+}
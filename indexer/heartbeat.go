@@ -0,0 +1,46 @@
+package indexer
+
+import (
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+)
+
+// A few blocks of lag are normal while following the chain tip, more than this while blocks are being indexed is a catch-up
+const heartbeatCatchUpLag = 5
+
+// heartbeat decides when the periodic alive log is emitted. It stays quiet while the indexer is actively catching up,
+// since the block timer logs progress then, and emits while idle or stalled.
+type heartbeat struct {
+	lastHeight int64
+}
+
+func (h *heartbeat) shouldEmit(height int64, lag int64) bool {
+	advanced := height > h.lastHeight
+	h.lastHeight = height
+	return !advanced || lag <= heartbeatCatchUpLag
+}
+
+// RunHeartbeat calls emit with the highest indexed block and the lag behind the chain tip every interval, unless the indexer
+// is actively catching up. It does not return.
+func (indexer *Indexer) RunHeartbeat(interval time.Duration, latestHeight func() (int64, error), emit func(height int64, lag int64)) {
+	state := &heartbeat{lastHeight: indexer.lastIndexedHeight.height.Load()}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		height := indexer.lastIndexedHeight.height.Load()
+
+		tip, err := latestHeight()
+		if err != nil {
+			config.Log.Warnf("Failed to get latest block height for heartbeat. Err: %v", err)
+			// Still report liveness, the lag is unknown
+			tip = height
+		}
+
+		lag := tip - height
+		if state.shouldEmit(height, lag) {
+			emit(height, lag)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type HeartbeatTestSuite struct {
+	suite.Suite
+}
+
+func (suite *HeartbeatTestSuite) TestSuppressedDuringCatchUp() {
+	state := &heartbeat{lastHeight: 100}
+
+	// Indexing quickly while far behind the tip
+	suite.False(state.shouldEmit(200, 1000))
+	suite.False(state.shouldEmit(300, 900))
+
+	// Stalled while far behind
+	suite.True(state.shouldEmit(300, 900))
+
+	// Following the tip
+	suite.True(state.shouldEmit(301, 1))
+}
+
+func (suite *HeartbeatTestSuite) TestFiresWhileIdle() {
+	indexer := &Indexer{Config: &config.IndexConfig{}}
+	indexer.lastIndexedHeight.update(500)
+
+	type beat struct {
+		height int64
+		lag    int64
+	}
+	beats := make(chan beat, 10)
+
+	// Caught up with no new blocks arriving
+	go indexer.RunHeartbeat(10*time.Millisecond, func() (int64, error) {
+		return 500, nil
+	}, func(height int64, lag int64) {
+		beats <- beat{height: height, lag: lag}
+	})
+
+	select {
+	case received := <-beats:
+		suite.Equal(int64(500), received.height)
+		suite.Equal(int64(0), received.lag)
+	case <-time.After(time.Second):
+		suite.Fail("no heartbeat emitted while idle")
+	}
+}
+
+func TestHeartbeatTestSuite(t *testing.T) {
+	suite.Run(t, new(HeartbeatTestSuite))
+}
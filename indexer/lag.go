@@ -0,0 +1,85 @@
+package indexer
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+)
+
+// lagMonitor detects indexing lag that stays above a block threshold for longer than the allowed duration.
+// It only arms once the indexer has caught up to within the threshold, so the initial back-fill never trips it.
+type lagMonitor struct {
+	threshold int64
+	duration  time.Duration
+	caughtUp  bool
+	lagStart  time.Time
+}
+
+func newLagMonitor(threshold int64, duration time.Duration) *lagMonitor {
+	return &lagMonitor{
+		threshold: threshold,
+		duration:  duration,
+	}
+}
+
+// observe records the current lag, returning an error once the lag has been sustained for too long
+func (m *lagMonitor) observe(lag int64, now time.Time) error {
+	if lag <= m.threshold {
+		m.caughtUp = true
+		m.lagStart = time.Time{}
+		return nil
+	}
+
+	if !m.caughtUp {
+		return nil
+	}
+
+	if m.lagStart.IsZero() {
+		m.lagStart = now
+	}
+
+	if now.Sub(m.lagStart) >= m.duration {
+		return fmt.Errorf("indexing lag of %d blocks has exceeded the threshold of %d blocks for %s", lag, m.threshold, now.Sub(m.lagStart).Round(time.Second))
+	}
+
+	return nil
+}
+
+// indexedHeight tracks the highest block height written by the DB worker
+type indexedHeight struct {
+	height atomic.Int64
+}
+
+func (h *indexedHeight) update(height int64) {
+	for {
+		current := h.height.Load()
+		if height <= current || h.height.CompareAndSwap(current, height) {
+			return
+		}
+	}
+}
+
+// MonitorSustainedLag polls the chain tip and compares it against the highest indexed block, calling onExceeded if the lag
+// stays above base.max-sustained-lag blocks for longer than base.max-sustained-lag-duration. It returns after calling onExceeded.
+func (indexer *Indexer) MonitorSustainedLag(interval time.Duration, latestHeight func() (int64, error), onExceeded func(error)) {
+	monitor := newLagMonitor(indexer.Config.Base.MaxSustainedLag, time.Duration(indexer.Config.Base.MaxSustainedLagDuration)*time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		tip, err := latestHeight()
+		if err != nil {
+			// A failing node is handled by the RPC workers, not counted as lag
+			config.Log.Warnf("Failed to get latest block height for lag monitoring. Err: %v", err)
+			continue
+		}
+
+		lag := tip - indexer.lastIndexedHeight.height.Load()
+		if err := monitor.observe(lag, time.Now()); err != nil {
+			onExceeded(err)
+			return
+		}
+	}
+}
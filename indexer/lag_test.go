@@ -0,0 +1,72 @@
+package indexer
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type LagTestSuite struct {
+	suite.Suite
+}
+
+func (suite *LagTestSuite) TestBackfillDoesNotTrigger() {
+	monitor := newLagMonitor(10, time.Minute)
+	start := time.Now()
+
+	// Far behind the tip for an hour while back-filling, the monitor is not armed yet
+	for minute := 0; minute <= 60; minute++ {
+		suite.Require().NoError(monitor.observe(100000, start.Add(time.Duration(minute)*time.Minute)))
+	}
+}
+
+func (suite *LagTestSuite) TestSustainedLagTriggers() {
+	monitor := newLagMonitor(10, time.Minute)
+	start := time.Now()
+
+	suite.Require().NoError(monitor.observe(5, start))
+	suite.Require().NoError(monitor.observe(50, start.Add(time.Second)))
+	suite.Require().NoError(monitor.observe(50, start.Add(30*time.Second)))
+
+	// Recovering resets the lag window
+	suite.Require().NoError(monitor.observe(0, start.Add(40*time.Second)))
+	suite.Require().NoError(monitor.observe(50, start.Add(50*time.Second)))
+	suite.Require().NoError(monitor.observe(50, start.Add(90*time.Second)))
+
+	suite.Require().Error(monitor.observe(50, start.Add(110*time.Second)))
+}
+
+func (suite *LagTestSuite) TestMonitorSustainedLagExits() {
+	indexer := &Indexer{Config: &config.IndexConfig{}}
+	indexer.Config.Base.MaxSustainedLag = 10
+	indexer.Config.Base.MaxSustainedLagDuration = 1
+	indexer.lastIndexedHeight.update(100)
+
+	// The tip is caught up on the first poll, then runs away while the indexed height stays put
+	var polls atomic.Int64
+	latestHeight := func() (int64, error) {
+		if polls.Add(1) == 1 {
+			return 100, nil
+		}
+		return 500, nil
+	}
+
+	exited := make(chan error, 1)
+	go indexer.MonitorSustainedLag(10*time.Millisecond, latestHeight, func(err error) {
+		exited <- err
+	})
+
+	select {
+	case err := <-exited:
+		suite.Require().Error(err)
+	case <-time.After(5 * time.Second):
+		suite.Fail("sustained lag did not cause an exit")
+	}
+}
+
+func TestLagTestSuite(t *testing.T) {
+	suite.Run(t, new(LagTestSuite))
+}
@@ -0,0 +1,71 @@
+package indexer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+)
+
+// Phases reported with base.report-phase
+const (
+	PhaseBackfilling = "backfilling"
+	PhaseTailing     = "tailing"
+	PhaseIdle        = "idle"
+)
+
+// A tailing indexer that has not written a block for this long is idle, e.g. the chain halted or a bounded run has no blocks left
+const phaseIdleAfter = time.Minute
+
+// PhaseTracker reports whether the indexer is back-filling up to the chain tip at startup, tailing the chain after reaching it
+// or idle, tailing without writing new blocks. The DB worker reports each written block. A nil tracker reports no phase.
+type PhaseTracker struct {
+	lock       sync.Mutex
+	startupTip int64
+	reachedTip bool
+	lastWrite  time.Time
+	now        func() time.Time
+}
+
+// NewPhaseTracker creates a tracker that switches from back-filling to tailing once a block at or above the startup tip is written
+func NewPhaseTracker(startupTip int64) *PhaseTracker {
+	return &PhaseTracker{startupTip: startupTip, now: time.Now}
+}
+
+// Observe records a written block
+func (tracker *PhaseTracker) Observe(height int64) {
+	if tracker == nil {
+		return
+	}
+
+	tracker.lock.Lock()
+	tracker.lastWrite = tracker.now()
+	reached := !tracker.reachedTip && height >= tracker.startupTip
+	if reached {
+		tracker.reachedTip = true
+	}
+	tracker.lock.Unlock()
+
+	if reached {
+		config.Log.Infof("Reached the chain tip at startup (block %d), switching from back-filling to tailing", tracker.startupTip)
+	}
+}
+
+// Phase returns the current phase, empty for a nil tracker
+func (tracker *PhaseTracker) Phase() string {
+	if tracker == nil {
+		return ""
+	}
+
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	switch {
+	case !tracker.reachedTip:
+		return PhaseBackfilling
+	case tracker.now().Sub(tracker.lastWrite) >= phaseIdleAfter:
+		return PhaseIdle
+	default:
+		return PhaseTailing
+	}
+}
@@ -0,0 +1,59 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PhaseTestSuite struct {
+	suite.Suite
+}
+
+func (suite *PhaseTestSuite) TestTransitionsAtStartupTip() {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewPhaseTracker(1000)
+	tracker.now = func() time.Time { return now }
+
+	suite.Equal(PhaseBackfilling, tracker.Phase())
+
+	// Blocks below the startup tip, written out of order, are still part of the back-fill
+	for _, height := range []int64{500, 998, 997, 999} {
+		tracker.Observe(height)
+		suite.Equal(PhaseBackfilling, tracker.Phase())
+	}
+
+	tracker.Observe(1000)
+	suite.Equal(PhaseTailing, tracker.Phase())
+
+	// Blocks indexed after the tip was reached never switch back to back-filling
+	tracker.Observe(600)
+	suite.Equal(PhaseTailing, tracker.Phase())
+
+	now = now.Add(phaseIdleAfter)
+	suite.Equal(PhaseIdle, tracker.Phase())
+
+	tracker.Observe(1001)
+	suite.Equal(PhaseTailing, tracker.Phase())
+}
+
+func (suite *PhaseTestSuite) TestStalledBackfillIsNotIdle() {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewPhaseTracker(1000)
+	tracker.now = func() time.Time { return now }
+
+	tracker.Observe(10)
+	now = now.Add(time.Hour)
+	suite.Equal(PhaseBackfilling, tracker.Phase())
+}
+
+func (suite *PhaseTestSuite) TestNilTracker() {
+	var tracker *PhaseTracker
+	suite.NotPanics(func() { tracker.Observe(1) })
+	suite.Empty(tracker.Phase())
+}
+
+func TestPhaseTestSuite(t *testing.T) {
+	suite.Run(t, new(PhaseTestSuite))
+}
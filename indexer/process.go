@@ -1,11 +1,15 @@
 package indexer
 
 import (
+	"context"
+	"errors"
 	"sync"
 
 	"github.com/DefiantLabs/cosmos-indexer/config"
 	"github.com/DefiantLabs/cosmos-indexer/core"
 	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
 )
 
 // This function is responsible for processing raw RPC data into app-usable types. It handles both block events and transactions.
@@ -15,52 +19,158 @@ func (indexer *Indexer) ProcessBlocks(wg *sync.WaitGroup, failedBlockHandler cor
 	defer close(txDataChan)
 	defer wg.Done()
 
+	var validatorResolver *core.ValidatorOperatorResolver
+	if indexer.Config.Base.IndexSlashing {
+		validatorResolver = core.NewValidatorOperatorResolver(func() (map[string]string, error) {
+			return rpc.GetValidatorOperatorAddresses(indexer.ChainClient)
+		})
+	}
+
 	for blockData := range blockRPCWorkerChan {
 		currentHeight := blockData.BlockData.Block.Height
 		config.Log.Infof("Parsing data for block %d", currentHeight)
+		budget := indexer.newBlockBudget()
+		traceContext, decodeSpan := core.StartBlockSpan(blockData.TraceContext, core.SpanBlockDecode, currentHeight)
 
 		block, err := core.ProcessBlock(blockData.BlockData, blockData.BlockResultsData, chainID)
 		if err != nil {
 			config.Log.Error("ProcessBlock: unhandled error", err)
+			decodeSpan.RecordError(err)
+			decodeSpan.End()
 			failedBlockHandler(currentHeight, core.UnprocessableTxError, err)
-			err := dbTypes.UpsertFailedBlock(indexer.DB, currentHeight, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
+			err := dbTypes.UpsertFailedBlock(indexer.DB, indexer.FailedBlocks, currentHeight, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
 			if err != nil {
 				config.Log.Fatal("Failed to insert failed block", err)
 			}
+			blockData.Inflight.Release()
 			continue
 		}
 
+		if indexer.Config.Base.IndexEvidence {
+			block.Evidence, err = core.ExtractBlockEvidence(blockData.BlockData)
+			if err != nil {
+				// The block is still indexed, without its evidence
+				config.Log.Errorf("Failed to extract evidence during block %d: %v", currentHeight, err)
+			}
+		}
+
+		block.Tags = indexer.Config.Base.RowTags
+		block.Annotations = indexer.HeightAnnotations.At(currentHeight)
+		block.GasUsed = blockData.GasUsed
+		block.MaxGas = blockData.MaxGas
+		block.TxCount = blockData.TxCount
+		block.FailedTxCount = blockData.FailedTxCount
+		if blockData.SourceEndpoint != "" {
+			block.SourceEndpoint = &blockData.SourceEndpoint
+		}
+
 		if blockData.IndexBlockEvents && !blockData.BlockEventRequestsFailed {
 			config.Log.Info("Parsing block events")
-			blockDBWrapper, err := core.ProcessRPCBlockResults(*indexer.Config, block, blockData.BlockResultsData, indexer.CustomBeginBlockEventParserRegistry, indexer.CustomEndBlockEventParserRegistry)
-			if err != nil {
+			var blockDBWrapper *dbTypes.BlockDBWrapper
+			err := budget.run(func(ctx context.Context) error {
+				var err error
+				blockDBWrapper, err = core.ProcessRPCBlockResults(ctx, *indexer.Config, block, blockData.BlockResultsData, indexer.CustomBeginBlockEventParserRegistry, indexer.CustomEndBlockEventParserRegistry)
+				return err
+			})
+			if isBlockTimeout(err) {
+				config.Log.Errorf("Timed out processing block events during block %d, adding to failed block events table", currentHeight)
+				failedBlockHandler(currentHeight, core.BlockProcessingTimeout, err)
+				err := dbTypes.UpsertFailedEventBlock(indexer.DB, indexer.FailedBlocks, currentHeight, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
+				if err != nil {
+					config.Log.Fatal("Failed to insert failed block event", err)
+				}
+				indexer.retryTimedOutBlock(currentHeight, true, blockData.BackgroundReindex)
+			} else if err != nil {
 				config.Log.Errorf("Failed to process block events during block %d event processing, adding to failed block events table", currentHeight)
 				failedBlockHandler(currentHeight, core.FailedBlockEventHandling, err)
-				err := dbTypes.UpsertFailedEventBlock(indexer.DB, currentHeight, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
+				err := dbTypes.UpsertFailedEventBlock(indexer.DB, indexer.FailedBlocks, currentHeight, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
 				if err != nil {
 					config.Log.Fatal("Failed to insert failed block event", err)
 				}
 			} else {
 				config.Log.Infof("Finished parsing block event data for block %d", currentHeight)
 
+				// Slashing, reward and balance delta rows are extracted before filtering so block event filters do not affect them
+				if indexer.Config.Base.IndexRewards {
+					beginBlockRewards, beginErr := core.ExtractBlockRewardEvents(currentHeight, blockDBWrapper.BeginBlockEvents)
+					endBlockRewards, endErr := core.ExtractBlockRewardEvents(currentHeight, blockDBWrapper.EndBlockEvents)
+					if beginErr != nil || endErr != nil {
+						config.Log.Errorf("Failed to extract reward events during block %d. Begin blocker error %v. End blocker error %v", currentHeight, beginErr, endErr)
+					}
+					blockDBWrapper.RewardEvents = append(beginBlockRewards, endBlockRewards...)
+				}
+
+				if indexer.Config.Base.IndexBalanceDeltas {
+					beginBlockDeltas, beginErr := core.ExtractBlockBalanceDeltas(currentHeight, blockDBWrapper.BeginBlockEvents)
+					endBlockDeltas, endErr := core.ExtractBlockBalanceDeltas(currentHeight, blockDBWrapper.EndBlockEvents)
+					if beginErr != nil || endErr != nil {
+						config.Log.Errorf("Failed to extract balance deltas during block %d. Begin blocker error %v. End blocker error %v", currentHeight, beginErr, endErr)
+					}
+					blockDBWrapper.BalanceDeltas = append(beginBlockDeltas, endBlockDeltas...)
+				}
+
+				if indexer.Config.Base.IndexSupplyDeltas {
+					beginBlockSupply, beginErr := core.ExtractBlockSupplyDeltas(currentHeight, models.SupplyDeltaSourceBeginBlock, blockDBWrapper.BeginBlockEvents, indexer.Config.Base.SupplyDeltaDenoms)
+					endBlockSupply, endErr := core.ExtractBlockSupplyDeltas(currentHeight, models.SupplyDeltaSourceEndBlock, blockDBWrapper.EndBlockEvents, indexer.Config.Base.SupplyDeltaDenoms)
+					if beginErr != nil || endErr != nil {
+						config.Log.Errorf("Failed to extract supply deltas during block %d. Begin blocker error %v. End blocker error %v", currentHeight, beginErr, endErr)
+					}
+					// Never nil, so the rows of a reindexed block without supply changes are removed
+					blockDBWrapper.SupplyDeltas = append(append([]models.SupplyDelta{}, beginBlockSupply...), endBlockSupply...)
+				}
+
+				blockDBWrapper.ModuleBalances = blockData.ModuleBalances
+
+				if validatorResolver != nil {
+					blockDBWrapper.SlashingEvents = append(core.ExtractSlashingEvents(currentHeight, blockDBWrapper.BeginBlockEvents), core.ExtractSlashingEvents(currentHeight, blockDBWrapper.EndBlockEvents)...)
+					if err := validatorResolver.ResolveOperatorAddresses(blockDBWrapper.SlashingEvents); err != nil {
+						// The slashing rows are still stored, without the operator address
+						config.Log.Errorf("Failed to resolve validator operator addresses for slashing events during block %d: %v", currentHeight, err)
+					}
+				}
+
+				// Lifecycles that are not indexed only feed the slashing, reward and balance delta rows above
+				if !indexer.Config.IndexesBeginBlockEvents() {
+					blockDBWrapper.BeginBlockEvents = nil
+				}
+				if !indexer.Config.IndexesEndBlockEvents() {
+					blockDBWrapper.EndBlockEvents = nil
+				}
+
 				var beginBlockFilterError error
 				var endBlockFilterError error
+				var droppedBeginBlockEvents, droppedEndBlockEvents []dbTypes.BlockEventDBWrapper
 				if blockEventFilterRegistry.BeginBlockEventFilterRegistry != nil && blockEventFilterRegistry.BeginBlockEventFilterRegistry.NumFilters() > 0 {
-					blockDBWrapper.BeginBlockEvents, beginBlockFilterError = core.FilterRPCBlockEvents(blockDBWrapper.BeginBlockEvents, *blockEventFilterRegistry.BeginBlockEventFilterRegistry)
+					blockDBWrapper.BeginBlockEvents, droppedBeginBlockEvents, beginBlockFilterError = core.PartitionRPCBlockEvents(blockDBWrapper.BeginBlockEvents, *blockEventFilterRegistry.BeginBlockEventFilterRegistry)
 				}
 
 				if blockEventFilterRegistry.EndBlockEventFilterRegistry != nil && blockEventFilterRegistry.EndBlockEventFilterRegistry.NumFilters() > 0 {
-					blockDBWrapper.EndBlockEvents, endBlockFilterError = core.FilterRPCBlockEvents(blockDBWrapper.EndBlockEvents, *blockEventFilterRegistry.EndBlockEventFilterRegistry)
+					blockDBWrapper.EndBlockEvents, droppedEndBlockEvents, endBlockFilterError = core.PartitionRPCBlockEvents(blockDBWrapper.EndBlockEvents, *blockEventFilterRegistry.EndBlockEventFilterRegistry)
+				}
+
+				if beginBlockFilterError == nil && endBlockFilterError == nil && indexer.Config.Base.ArchiveFilteredEvents {
+					if err := dbTypes.ArchiveFilteredBlockEvents(blockDBWrapper, droppedBeginBlockEvents, droppedEndBlockEvents, indexer.Config.Base.ArchiveFilteredEventsRetention); err != nil {
+						// The block is still indexed, only the filtered events are not recoverable from the archive
+						config.Log.Errorf("Failed to archive filtered block events during block %d: %v", currentHeight, err)
+					}
 				}
 
 				if beginBlockFilterError == nil && endBlockFilterError == nil {
-					blockEventsDataChan <- &BlockEventsDBData{
-						blockDBWrapper: blockDBWrapper,
+					if indexer.Config.Base.EventsStorageMode == config.EventsStorageModeJSONB {
+						dbTypes.MoveBlockEventsToJSON(blockDBWrapper)
 					}
+
+					sendToDBQueue(indexer, blockEventsDataChan, &BlockEventsDBData{
+						blockDBWrapper:    blockDBWrapper,
+						budget:            budget,
+						inflight:          blockData.Inflight,
+						traceContext:      traceContext,
+						backgroundReindex: blockData.BackgroundReindex,
+					}, currentHeight, blockData.Inflight)
 				} else {
 					config.Log.Errorf("Failed to filter block events during block %d event processing, adding to failed block events table. Begin blocker filter error %s. End blocker filter error %s", currentHeight, beginBlockFilterError, endBlockFilterError)
 					failedBlockHandler(currentHeight, core.FailedBlockEventHandling, err)
-					err := dbTypes.UpsertFailedEventBlock(indexer.DB, currentHeight, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
+					err := dbTypes.UpsertFailedEventBlock(indexer.DB, indexer.FailedBlocks, currentHeight, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
 					if err != nil {
 						config.Log.Fatal("Failed to insert failed block event", err)
 					}
@@ -73,28 +183,68 @@ func (indexer *Indexer) ProcessBlocks(wg *sync.WaitGroup, failedBlockHandler cor
 			var txDBWrappers []dbTypes.TxDBWrapper
 			var err error
 
-			if blockData.GetTxsResponse != nil {
-				config.Log.Debug("Processing TXs from RPC TX Search response")
-				txDBWrappers, _, err = core.ProcessRPCTXs(indexer.Config, indexer.DB, indexer.ChainClient, indexer.MessageTypeFilters, indexer.MessageFilters, blockData.GetTxsResponse, indexer.CustomMessageParserRegistry)
-			} else if blockData.BlockResultsData != nil {
-				config.Log.Debug("Processing TXs from BlockResults search response")
-				txDBWrappers, _, err = core.ProcessRPCBlockByHeightTXs(indexer.Config, indexer.DB, indexer.ChainClient, indexer.MessageTypeFilters, indexer.MessageFilters, blockData.BlockData, blockData.BlockResultsData, indexer.CustomMessageParserRegistry)
+			err = budget.run(func(ctx context.Context) error {
+				var err error
+				// Decode with the codec in effect at the block's height, chain upgrades can change message encodings
+				decodeClient := indexer.CodecSchedule.ClientAtHeight(indexer.ChainClient, currentHeight)
+				if blockData.GetTxsResponse != nil {
+					config.Log.Debug("Processing TXs from RPC TX Search response")
+					txDBWrappers, _, err = core.ProcessRPCTXs(ctx, indexer.Config, indexer.DB, decodeClient, indexer.MessageTypeFilters, indexer.MessageFilters, blockData.GetTxsResponse, indexer.CustomMessageParserRegistry, indexer.TxLookups)
+				} else if blockData.BlockResultsData != nil {
+					config.Log.Debug("Processing TXs from BlockResults search response")
+					txDBWrappers, _, err = core.ProcessRPCBlockByHeightTXs(ctx, indexer.Config, indexer.DB, decodeClient, indexer.MessageTypeFilters, indexer.MessageFilters, blockData.BlockData, blockData.BlockResultsData, indexer.CustomMessageParserRegistry, indexer.TxLookups)
+				}
+				return err
+			})
+
+			// Only returned in base.tolerant-block mode, the block is committed without the txs that failed to decode
+			var undecodable *core.UndecodableTxsError
+			if errors.As(err, &undecodable) {
+				config.Log.Warnf("Committing block %d without %d txs that could not be decoded, flagging the block partial", currentHeight, len(undecodable.Failures))
+				block.Partial = true
+				block.TxDecodeFailures = undecodable.Failures
+				err = nil
 			}
 
-			if err != nil {
+			if err == nil && indexer.Config.Flags.IndexTxRaw {
+				core.AttachRawTxs(txDBWrappers, blockData.BlockData)
+			}
+
+			if err == nil && indexer.Config.Base.IndexTxPosition {
+				core.AttachTxIndexes(txDBWrappers, blockData.BlockData)
+			}
+
+			if isBlockTimeout(err) {
+				config.Log.Errorf("Timed out processing transactions during block %d, adding to failed blocks table", currentHeight)
+				failedBlockHandler(currentHeight, core.BlockProcessingTimeout, err)
+				err := dbTypes.UpsertFailedBlock(indexer.DB, indexer.FailedBlocks, currentHeight, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
+				if err != nil {
+					config.Log.Fatal("Failed to insert failed block", err)
+				}
+				indexer.retryTimedOutBlock(currentHeight, false, blockData.BackgroundReindex)
+			} else if err != nil {
 				config.Log.Error("ProcessRpcTxs: unhandled error", err)
 				failedBlockHandler(currentHeight, core.UnprocessableTxError, err)
-				err := dbTypes.UpsertFailedBlock(indexer.DB, currentHeight, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
+				err := dbTypes.UpsertFailedBlock(indexer.DB, indexer.FailedBlocks, currentHeight, indexer.Config.Probe.ChainID, indexer.Config.Probe.ChainName)
 				if err != nil {
 					config.Log.Fatal("Failed to insert failed block", err)
 				}
 			} else {
-				txDataChan <- &DBData{
-					txDBWrappers: txDBWrappers,
-					block:        block,
-				}
+				sendToDBQueue(indexer, txDataChan, &DBData{
+					txDBWrappers:      txDBWrappers,
+					block:             block,
+					budget:            budget,
+					inflight:          blockData.Inflight,
+					traceContext:      traceContext,
+					backgroundReindex: blockData.BackgroundReindex,
+				}, currentHeight, blockData.Inflight)
 			}
 
 		}
+
+		decodeSpan.End()
+
+		// The queued DB data holds its own reference to the block, if nothing was queued this frees the block's slot
+		blockData.Inflight.Release()
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/DefiantLabs/cosmos-indexer/config"
+	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
 	"github.com/DefiantLabs/cosmos-indexer/db/models"
 	"github.com/DefiantLabs/cosmos-indexer/filter"
 	"github.com/DefiantLabs/cosmos-indexer/parsers"
@@ -30,6 +31,20 @@ func (indexer *Indexer) RegisterCustomMsgTypesByTypeURLs(customMessageTypeURLSTo
 	return nil
 }
 
+// RegisterUpgradeCodecVariant registers a named codec variant that the base.upgrade-heights-file can switch decoding to at an upgrade height
+func (indexer *Indexer) RegisterUpgradeCodecVariant(name string, basics []module.AppModuleBasic, customMessageTypeURLSToTypes map[string]sdkTypes.Msg) error {
+	if indexer.UpgradeCodecVariants == nil {
+		indexer.UpgradeCodecVariants = make(map[string]CodecVariant)
+	}
+
+	if _, ok := indexer.UpgradeCodecVariants[name]; ok {
+		return fmt.Errorf("found duplicate codec variant \"%s\", codec variants must be uniquely named", name)
+	}
+	indexer.UpgradeCodecVariants[name] = CodecVariant{ModuleBasics: basics, CustomMsgTypeRegistry: customMessageTypeURLSToTypes}
+
+	return nil
+}
+
 func (indexer *Indexer) RegisterMessageTypeFilter(filter filter.MessageTypeFilter) {
 	indexer.MessageTypeFilters = append(indexer.MessageTypeFilters, filter)
 }
@@ -111,3 +126,21 @@ func customBlockEventRegistration(registry map[string][]parsers.BlockEventParser
 	}
 	return registry, tracker, nil
 }
+
+// RegisterRedactor adds a redactor applied to every record (blocks, txes, messages, events and custom models) just before it is
+// stored, e.g. to scrub or hash sensitive fields. Redactors are chained in registration order.
+func (indexer *Indexer) RegisterRedactor(redactor func(record any) any) {
+	indexer.Redactors = append(indexer.Redactors, redactor)
+}
+
+// RegisterTxColumn adds a chain-specific column of the SQL type to the txes table, migrated when the indexer starts and populated
+// for every stored tx from the value the extractor returns
+func (indexer *Indexer) RegisterTxColumn(name string, sqlType string, extractor func(tx dbTypes.TxDBWrapper) any) error {
+	column := dbTypes.TxColumn{Name: name, SQLType: sqlType, Extractor: extractor}
+	if err := dbTypes.ValidateTxColumn(indexer.TxColumns, column); err != nil {
+		return err
+	}
+
+	indexer.TxColumns = append(indexer.TxColumns, column)
+	return nil
+}
@@ -0,0 +1,78 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+)
+
+const statusSocketWriteTimeout = 5 * time.Second
+
+// ServeStatusSocket serves the indexer status on a Unix domain socket at the path, for local supervisors that should not need an
+// HTTP port. Every connection is answered with a single line of space separated key=value fields and closed: the highest indexed
+// height, the lag behind the chain tip, left out when the tip cannot be read, the number of in-flight blocks once the pipeline has
+// started, and the phase with base.report-phase. A socket file
+// left behind by a previous run is replaced. The returned function stops serving and removes the socket.
+func (indexer *Indexer) ServeStatusSocket(path string, latestHeight func() (int64, error)) (func() error, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error removing the previous status socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					config.Log.Warnf("Failed to accept a status socket connection. Err: %v", err)
+				}
+				return
+			}
+			go indexer.writeStatus(conn, latestHeight)
+		}
+	}()
+
+	// Closing a Unix listener removes its socket file
+	return listener.Close, nil
+}
+
+func (indexer *Indexer) writeStatus(conn net.Conn, latestHeight func() (int64, error)) {
+	defer conn.Close()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(statusSocketWriteTimeout))
+	if _, err := conn.Write([]byte(indexer.statusLine(latestHeight))); err != nil {
+		config.Log.Debugf("Failed to write to a status socket connection. Err: %v", err)
+	}
+}
+
+// statusLine returns the line protocol status, e.g. "height=1200 lag=3 inflight=8 phase=tailing"
+func (indexer *Indexer) statusLine(latestHeight func() (int64, error)) string {
+	height := indexer.lastIndexedHeight.height.Load()
+	fields := []string{fmt.Sprintf("height=%d", height)}
+
+	tip, err := latestHeight()
+	if err != nil {
+		config.Log.Warnf("Failed to get latest block height for the status socket. Err: %v", err)
+	} else {
+		fields = append(fields, fmt.Sprintf("lag=%d", tip-height))
+	}
+
+	if indexer.Inflight != nil {
+		fields = append(fields, fmt.Sprintf("inflight=%d", indexer.Inflight.InFlight()))
+	}
+
+	if phase := indexer.Phase.Phase(); phase != "" {
+		fields = append(fields, "phase="+phase)
+	}
+
+	return strings.Join(fields, " ") + "\n"
+}
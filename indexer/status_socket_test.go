@@ -0,0 +1,93 @@
+package indexer
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/core"
+	"github.com/stretchr/testify/suite"
+)
+
+type StatusSocketTestSuite struct {
+	suite.Suite
+	path string
+}
+
+func (suite *StatusSocketTestSuite) SetupTest() {
+	// Unix socket paths are limited to about a hundred bytes, test temp dirs can be longer
+	dir, err := os.MkdirTemp("", "status")
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { os.RemoveAll(dir) })
+	suite.path = filepath.Join(dir, "indexer.sock")
+}
+
+// readStatus connects to the socket and parses the status line
+func (suite *StatusSocketTestSuite) readStatus() map[string]string {
+	conn, err := net.Dial("unix", suite.path)
+	suite.Require().NoError(err)
+	defer conn.Close()
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	suite.Require().NoError(err)
+
+	status := make(map[string]string)
+	for _, field := range strings.Fields(line) {
+		key, value, ok := strings.Cut(field, "=")
+		suite.Require().True(ok, field)
+		status[key] = value
+	}
+	return status
+}
+
+func (suite *StatusSocketTestSuite) TestStatus() {
+	// A socket left behind by a crashed run is replaced
+	suite.Require().NoError(os.WriteFile(suite.path, nil, 0o600))
+
+	indexer := &Indexer{Phase: NewPhaseTracker(100), Inflight: core.NewInflightLimiter(0)}
+	indexer.lastIndexedHeight.update(90)
+	block := indexer.Inflight.Admit()
+
+	tip := int64(103)
+	closeSocket, err := indexer.ServeStatusSocket(suite.path, func() (int64, error) { return tip, nil })
+	suite.Require().NoError(err)
+
+	status := suite.readStatus()
+	suite.Equal(map[string]string{"height": "90", "lag": "13", "inflight": "1", "phase": PhaseBackfilling}, status)
+
+	indexer.lastIndexedHeight.update(101)
+	indexer.Phase.Observe(101)
+	status = suite.readStatus()
+	height, err := strconv.ParseInt(status["height"], 10, 64)
+	suite.Require().NoError(err)
+	suite.Equal(int64(101), height)
+	suite.Equal("2", status["lag"])
+	suite.Equal("1", status["inflight"])
+
+	block.Release()
+	suite.Equal("0", suite.readStatus()["inflight"])
+	suite.Equal(PhaseTailing, status["phase"])
+
+	suite.Require().NoError(closeSocket())
+	suite.NoFileExists(suite.path)
+}
+
+func (suite *StatusSocketTestSuite) TestUnknownLagAndPhase() {
+	indexer := &Indexer{}
+	indexer.lastIndexedHeight.update(7)
+
+	closeSocket, err := indexer.ServeStatusSocket(suite.path, func() (int64, error) { return 0, errors.New("node unreachable") })
+	suite.Require().NoError(err)
+	defer closeSocket()
+
+	suite.Equal(map[string]string{"height": "7"}, suite.readStatus())
+}
+
+func TestStatusSocketTestSuite(t *testing.T) {
+	suite.Run(t, new(StatusSocketTestSuite))
+}
@@ -0,0 +1,229 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/core"
+)
+
+// ErrBlockTimeout is returned when a block exceeds the configured base.block-timeout
+var ErrBlockTimeout = errors.New("block processing timed out")
+
+// Number of times a block that timed out is enqueued again before it is only left in the failed blocks tables
+const blockTimeoutRetries = 1
+
+// blockBudget is the time a block has left to be parsed and written to the DB (base.block-timeout). The clock only runs while
+// the block is being worked on, time spent waiting in the DB write queues is not counted.
+type blockBudget struct {
+	limited   bool
+	remaining time.Duration
+}
+
+// newBlockBudget returns the budget of a block that is about to be processed, unlimited if no timeout is configured
+func (indexer *Indexer) newBlockBudget() blockBudget {
+	if indexer.Config.Base.BlockTimeout <= 0 {
+		return blockBudget{}
+	}
+	return blockBudget{limited: true, remaining: time.Duration(indexer.Config.Base.BlockTimeout) * time.Second}
+}
+
+// context returns a context that is cancelled once the budget is used up, used to stop processing and abort in-flight DB transactions
+func (budget blockBudget) context() (context.Context, context.CancelFunc) {
+	if !budget.limited {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), budget.remaining)
+}
+
+// run runs fn with the budget's context and takes the time fn took off the budget
+func (budget *blockBudget) run(fn func(ctx context.Context) error) error {
+	ctx, cancel := budget.context()
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	if budget.limited {
+		budget.remaining -= time.Since(start)
+	}
+
+	if err == nil && ctx.Err() != nil {
+		return ErrBlockTimeout
+	}
+	return err
+}
+
+func isBlockTimeout(err error) bool {
+	return errors.Is(err, ErrBlockTimeout) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// timedOutBlocks collects the blocks that timed out, which are enqueued again by the enqueue function returned by RetryTimedOutBlocks.
+// The zero value accepts no blocks.
+type timedOutBlocks struct {
+	mu       sync.Mutex
+	pending  map[int64]*core.EnqueueData
+	attempts map[int64]int
+	// Set while the enqueue function is running, blocks that time out afterwards are not enqueued again
+	accepting bool
+	// Signalled when a block is queued to be enqueued again, created when blocks start being accepted
+	queued chan struct{}
+}
+
+// add queues the part of the block that timed out to be enqueued again, false if the block is out of retries
+func (blocks *timedOutBlocks) add(height int64, blockEvents bool, backgroundReindex bool) bool {
+	blocks.mu.Lock()
+	defer blocks.mu.Unlock()
+
+	if !blocks.accepting {
+		return false
+	}
+	if blocks.pending == nil {
+		blocks.pending = make(map[int64]*core.EnqueueData)
+		blocks.attempts = make(map[int64]int)
+	}
+
+	// The block events and txs of a block time out separately, both are enqueued again together
+	if block, ok := blocks.pending[height]; ok {
+		block.IndexBlockEvents = block.IndexBlockEvents || blockEvents
+		block.IndexTransactions = block.IndexTransactions || !blockEvents
+		return true
+	}
+
+	if blocks.attempts[height] >= blockTimeoutRetries {
+		return false
+	}
+	blocks.attempts[height]++
+	blocks.pending[height] = &core.EnqueueData{
+		Height:            height,
+		IndexBlockEvents:  blockEvents,
+		IndexTransactions: !blockEvents,
+		BackgroundReindex: backgroundReindex,
+	}
+
+	select {
+	case blocks.queued <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// take returns the blocks waiting to be enqueued again, nil if there are none
+func (blocks *timedOutBlocks) take() []*core.EnqueueData {
+	blocks.mu.Lock()
+	defer blocks.mu.Unlock()
+
+	if len(blocks.pending) == 0 {
+		return nil
+	}
+
+	taken := make([]*core.EnqueueData, 0, len(blocks.pending))
+	for height, block := range blocks.pending {
+		taken = append(taken, block)
+		delete(blocks.pending, height)
+	}
+	return taken
+}
+
+// startAccepting accepts blocks until stopAccepting is called, the returned channel is signalled when a block is queued
+func (blocks *timedOutBlocks) startAccepting() <-chan struct{} {
+	blocks.mu.Lock()
+	defer blocks.mu.Unlock()
+	blocks.accepting = true
+	blocks.queued = make(chan struct{}, 1)
+	return blocks.queued
+}
+
+func (blocks *timedOutBlocks) stopAccepting() {
+	blocks.mu.Lock()
+	defer blocks.mu.Unlock()
+	blocks.accepting = false
+}
+
+// retryTimedOutBlock queues the block events or txs of a block that timed out to be enqueued again. The block is recorded in the
+// failed blocks tables either way, in case the run ends before it is indexed.
+func (indexer *Indexer) retryTimedOutBlock(height int64, blockEvents bool, backgroundReindex bool) {
+	if indexer.timedOut.add(height, blockEvents, backgroundReindex) {
+		config.Log.Infof("Block %d timed out, it will be enqueued again", height)
+	}
+}
+
+// RetryTimedOutBlocks wraps the enqueue function so that blocks timing out (base.block-timeout) are enqueued again while it runs.
+// Blocks are counted as they are handed to the pipeline and as they leave it through the in-flight limiter. Once the wrapped
+// function is done, the returned function keeps enqueueing timed out blocks until every block handed over has left the pipeline,
+// and only then returns so the caller can close the channel. A block that times out is queued again before it leaves the pipeline,
+// so no retry is missed. With a non-zero deadline (base.max-run-duration) the returned function stops handing blocks over and
+// returns once the deadline passes. Blocks that time out after it returns are only left in the failed blocks tables.
+func (indexer *Indexer) RetryTimedOutBlocks(enqueue func(chan *core.EnqueueData) error, inflightLimiter *core.InflightLimiter, deadline time.Time) func(chan *core.EnqueueData) error {
+	if indexer.Config.Base.BlockTimeout <= 0 {
+		return enqueue
+	}
+
+	return func(blockChan chan *core.EnqueueData) error {
+		queued := indexer.timedOut.startAccepting()
+		defer indexer.timedOut.stopAccepting()
+
+		var deadlineReached <-chan time.Time
+		if !deadline.IsZero() {
+			timer := time.NewTimer(time.Until(deadline))
+			defer timer.Stop()
+			deadlineReached = timer.C
+		}
+
+		// The wrapped function fills a channel of the same capacity, enqueue functions pace themselves on how full it is
+		enqueueChan := make(chan *core.EnqueueData, cap(blockChan))
+		enqueueDone := make(chan error, 1)
+		go func() {
+			enqueueDone <- enqueue(enqueueChan)
+		}()
+
+		leftAtStart := inflightLimiter.Left()
+		var handedOver int64
+		handOver := func(block *core.EnqueueData) bool {
+			select {
+			case blockChan <- block:
+				handedOver++
+				return true
+			case <-deadlineReached:
+				return false
+			}
+		}
+
+		enqueueRunning := true
+		for {
+			select {
+			case err := <-enqueueDone:
+				if err != nil {
+					return err
+				}
+				enqueueRunning = false
+				// The wrapped function is done, hand over the blocks it left in the channel
+				for len(enqueueChan) != 0 {
+					if !handOver(<-enqueueChan) {
+						return nil
+					}
+				}
+			case block := <-enqueueChan:
+				if !handOver(block) {
+					return nil
+				}
+			case <-queued:
+				for _, block := range indexer.timedOut.take() {
+					config.Log.Infof("Enqueueing block %d again after it timed out", block.Height)
+					if !handOver(block) {
+						return nil
+					}
+				}
+			case <-inflightLimiter.Leaving():
+			case <-deadlineReached:
+				return nil
+			}
+
+			if !enqueueRunning && handedOver == inflightLimiter.Left()-leftAtStart && len(queued) == 0 {
+				return nil
+			}
+		}
+	}
+}
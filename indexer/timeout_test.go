@@ -0,0 +1,166 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/core"
+	"github.com/DefiantLabs/cosmos-indexer/db/models"
+	"github.com/DefiantLabs/cosmos-indexer/parsers"
+	"github.com/DefiantLabs/cosmos-indexer/rpc"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type sleepingBlockEventParser struct {
+	sleep time.Duration
+}
+
+func (p *sleepingBlockEventParser) Identifier() string {
+	return "sleeping"
+}
+
+func (p *sleepingBlockEventParser) ParseBlockEvent(abci.Event, config.IndexConfig) (*any, error) {
+	time.Sleep(p.sleep)
+	return nil, nil
+}
+
+func (p *sleepingBlockEventParser) IndexBlockEvent(*any, *gorm.DB, models.Block, models.BlockEvent, []models.BlockEventAttribute, config.IndexConfig) error {
+	return nil
+}
+
+type TimeoutTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TimeoutTestSuite) processWithParser(parser parsers.BlockEventParser, budget blockBudget) error {
+	blockResults := &rpc.CustomBlockResults{
+		Height:           1,
+		BeginBlockEvents: []abci.Event{{Type: "sleep"}, {Type: "sleep"}, {Type: "sleep"}},
+	}
+	registry := map[string][]parsers.BlockEventParser{"sleep": {parser}}
+
+	return budget.run(func(ctx context.Context) error {
+		_, err := core.ProcessRPCBlockResults(ctx, config.IndexConfig{}, models.Block{Height: 1}, blockResults, registry, nil)
+		return err
+	})
+}
+
+func (suite *TimeoutTestSuite) TestParserSleepingPastTimeout() {
+	start := time.Now()
+	err := suite.processWithParser(&sleepingBlockEventParser{sleep: 200 * time.Millisecond}, blockBudget{limited: true, remaining: 50 * time.Millisecond})
+	suite.Require().Error(err)
+	suite.True(isBlockTimeout(err))
+
+	// Processing stops before the next event once the budget is used up, instead of running the remaining parsers
+	suite.Less(time.Since(start), 400*time.Millisecond)
+}
+
+func (suite *TimeoutTestSuite) TestParserWithinTimeout() {
+	err := suite.processWithParser(&sleepingBlockEventParser{sleep: time.Millisecond}, blockBudget{limited: true, remaining: time.Second})
+	suite.Require().NoError(err)
+}
+
+func (suite *TimeoutTestSuite) TestNoTimeoutConfigured() {
+	indexer := &Indexer{Config: &config.IndexConfig{}}
+	budget := indexer.newBlockBudget()
+	suite.False(budget.limited)
+
+	err := suite.processWithParser(&sleepingBlockEventParser{sleep: 10 * time.Millisecond}, budget)
+	suite.Require().NoError(err)
+}
+
+func (suite *TimeoutTestSuite) TestBudgetExcludesQueueTime() {
+	budget := blockBudget{limited: true, remaining: 200 * time.Millisecond}
+	suite.Require().NoError(budget.run(func(ctx context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}))
+	suite.Less(budget.remaining, 160*time.Millisecond)
+
+	// Waiting in the DB write queue does not use up the budget, the DB stage gets the time the parsing left
+	time.Sleep(250 * time.Millisecond)
+	ctx, cancel := budget.context()
+	defer cancel()
+	suite.NoError(ctx.Err())
+}
+
+func (suite *TimeoutTestSuite) TestTimedOutBlocksEnqueuedAgain() {
+	cfg := &config.IndexConfig{}
+	cfg.Base.BlockTimeout = 1
+	indexer := &Indexer{Config: cfg}
+	limiter := core.NewInflightLimiter(0)
+
+	enqueue := indexer.RetryTimedOutBlocks(func(blockChan chan *core.EnqueueData) error {
+		blockChan <- &core.EnqueueData{Height: 7, IndexBlockEvents: true, IndexTransactions: true}
+		// The txs of the block time out while the enqueue function is running
+		indexer.retryTimedOutBlock(7, false, false)
+		return nil
+	}, limiter, time.Time{})
+
+	blockChan := make(chan *core.EnqueueData, 10)
+	var enqueued []*core.EnqueueData
+	done := make(chan error)
+	go func() {
+		done <- enqueue(blockChan)
+	}()
+
+	// Read the blocks like the RPC workers, holding a block in the pipeline until it is indexed
+	for {
+		select {
+		case block := <-blockChan:
+			inflight := limiter.Admit()
+			enqueued = append(enqueued, block)
+			if len(enqueued) == 2 {
+				// The retry times out again, the block is out of retries
+				suite.False(indexer.timedOut.add(7, false, false))
+			}
+			inflight.Release()
+			continue
+		case err := <-done:
+			suite.Require().NoError(err)
+		}
+		break
+	}
+
+	suite.Require().Len(enqueued, 2)
+	suite.Equal(int64(7), enqueued[1].Height)
+	suite.False(enqueued[1].IndexBlockEvents)
+	suite.True(enqueued[1].IndexTransactions)
+
+	// The enqueue is done, blocks timing out now are only left in the failed blocks tables
+	suite.False(indexer.timedOut.add(8, true, false))
+}
+
+func (suite *TimeoutTestSuite) TestTimedOutBlocksNotEnqueuedAfterDeadline() {
+	cfg := &config.IndexConfig{}
+	cfg.Base.BlockTimeout = 1
+	indexer := &Indexer{Config: cfg}
+	limiter := core.NewInflightLimiter(0)
+
+	enqueue := indexer.RetryTimedOutBlocks(func(blockChan chan *core.EnqueueData) error {
+		blockChan <- &core.EnqueueData{Height: 7, IndexBlockEvents: true, IndexTransactions: true}
+		return nil
+	}, limiter, time.Now().Add(100*time.Millisecond))
+
+	blockChan := make(chan *core.EnqueueData, 10)
+	done := make(chan error)
+	go func() {
+		done <- enqueue(blockChan)
+	}()
+
+	// The block is held in the pipeline past the deadline, its retry is not enqueued
+	<-blockChan
+	inflight := limiter.Admit()
+	suite.Require().NoError(<-done)
+	suite.False(indexer.timedOut.add(7, true, false))
+	inflight.Release()
+	suite.Empty(blockChan)
+}
+
+func TestTimeoutTestSuite(t *testing.T) {
+	suite.Run(t, new(TimeoutTestSuite))
+}
@@ -0,0 +1,108 @@
+package indexer
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/DefiantLabs/cosmos-indexer/core"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmtTypes "github.com/cometbft/cometbft/types"
+	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type TracingTestSuite struct {
+	suite.Suite
+	exporter *tracetest.InMemoryExporter
+	provider *sdktrace.TracerProvider
+}
+
+func (suite *TracingTestSuite) SetupTest() {
+	suite.exporter = tracetest.NewInMemoryExporter()
+	// The syncer exports each span as soon as it ends
+	suite.provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(suite.exporter))
+	otel.SetTracerProvider(suite.provider)
+}
+
+func (suite *TracingTestSuite) TearDownTest() {
+	suite.Require().NoError(suite.provider.Shutdown(context.Background()))
+}
+
+// fetchedBlock returns the data the RPC worker passes on for a block without transactions, with its fetch span ended
+func fetchedBlock(height int64) core.IndexerBlockEventData {
+	traceContext, fetchSpan := core.StartBlockSpan(context.Background(), core.SpanBlockFetch, height)
+	fetchSpan.End()
+
+	return core.IndexerBlockEventData{
+		BlockData: &ctypes.ResultBlock{Block: &cmtTypes.Block{Header: cmtTypes.Header{
+			Height:          height,
+			ProposerAddress: make([]byte, 20),
+		}}},
+		GetTxsResponse:    &txTypes.GetTxsEventResponse{},
+		IndexTransactions: true,
+		TraceContext:      traceContext,
+	}
+}
+
+func (suite *TracingTestSuite) TestSpanHierarchyPerBlock() {
+	indexer := &Indexer{Config: &config.IndexConfig{}, DryRun: true}
+
+	rpcChan := make(chan core.IndexerBlockEventData, 2)
+	blockEventsDataChan := make(chan *BlockEventsDBData, 2)
+	txDataChan := make(chan *DBData, 2)
+	rpcChan <- fetchedBlock(10)
+	rpcChan <- fetchedBlock(11)
+	close(rpcChan)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	indexer.ProcessBlocks(&wg, core.HandleFailedBlock, rpcChan, blockEventsDataChan, txDataChan, 1, BlockEventFilterRegistries{})
+	indexer.DoDBUpdates(&wg, txDataChan, blockEventsDataChan, 1)
+	wg.Wait()
+
+	spans := suite.exporter.GetSpans()
+	suite.Require().Len(spans, 6)
+
+	type blockSpans map[string]tracetest.SpanStub
+	byHeight := make(map[int64]blockSpans)
+	for _, span := range spans {
+		var height int64
+		for _, attr := range span.Attributes {
+			if attr.Key == attribute.Key("block.height") {
+				height = attr.Value.AsInt64()
+			}
+		}
+		if byHeight[height] == nil {
+			byHeight[height] = make(blockSpans)
+		}
+		byHeight[height][span.Name] = span
+	}
+
+	for _, height := range []int64{10, 11} {
+		stages := byHeight[height]
+		suite.Require().Len(stages, 3, "spans of block %d", height)
+
+		fetch := stages[core.SpanBlockFetch]
+		decode := stages[core.SpanBlockDecode]
+		commit := stages[core.SpanBlockCommitTxs]
+
+		suite.False(fetch.Parent.IsValid(), "the fetch span is the root of block %d", height)
+		suite.Equal(fetch.SpanContext.SpanID(), decode.Parent.SpanID())
+		suite.Equal(decode.SpanContext.SpanID(), commit.Parent.SpanID())
+
+		// All stages of a block share a trace, different blocks do not
+		suite.Equal(fetch.SpanContext.TraceID(), decode.SpanContext.TraceID())
+		suite.Equal(fetch.SpanContext.TraceID(), commit.SpanContext.TraceID())
+	}
+	suite.NotEqual(byHeight[10][core.SpanBlockFetch].SpanContext.TraceID(), byHeight[11][core.SpanBlockFetch].SpanContext.TraceID())
+}
+
+func TestTracingTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
@@ -1,6 +1,8 @@
 package indexer
 
 import (
+	"context"
+
 	"github.com/DefiantLabs/cosmos-indexer/config"
 	"github.com/DefiantLabs/cosmos-indexer/core"
 	dbTypes "github.com/DefiantLabs/cosmos-indexer/db"
@@ -60,10 +62,39 @@ type Indexer struct {
 	CustomMessageParserRegistry         map[string][]parsers.MessageParser    // Used for associating parsers to message types
 	CustomMessageParserTrackers         map[string]models.MessageParser       // Used for tracking message parsers in the database
 	CustomModels                        []any
+	SkipHeights                         core.SkipHeights                           // Heights never enqueued, loaded from base.skip-heights-file
+	HeightAnnotations                   core.HeightAnnotations                     // Height ranges block rows are annotated from, loaded from base.height-annotations-file
+	TxLookups                           core.TxLookups                             // Lookup tables loaded during setup that tx processing consults
+	Redactors                           []dbTypes.Redactor                         // Applied in order to every record just before it is stored
+	TxColumns                           []dbTypes.TxColumn                         // Chain-specific txes columns, migrated during setup and populated for every stored tx
+	UpgradeCodecVariants                map[string]CodecVariant                    // Codecs selected by name in the base.upgrade-heights-file, used from their upgrade height on
+	CodecSchedule                       *core.CodecSchedule                        // Codecs of the upgrade heights blocks are decoded with, only set with base.upgrade-heights-file
 	PostIndexCustomMessageFunction      func(*PostIndexCustomMessageDataset) error // Called post indexing of the custom messages with the indexed dataset, useful for custom indexing on the whole dataset or for additional processing
 	PostSetupCustomFunction             func(PostSetupCustomDataset) error         // Called post setup of the indexer, useful for custom indexing on the whole dataset or for additional processing
 	PostSetupDatasetChannel             chan *PostSetupDataset                     // passes configured indexer data to any reader
 	PreExitCustomFunction               func(*PreExitCustomDataset) error          // Called post indexing of the custom messages with the indexed dataset, useful for custom indexing on the whole dataset or for additional processing
+	Inflight                            *core.InflightLimiter                      // Counts the blocks in the pipeline, capped with base.max-inflight-blocks
+	TransactionLimiter                  *dbTypes.TransactionLimiter                // Bounds the concurrent block commit transactions, only set with database.max-concurrent-txns
+	FetchThrottle                       *core.AdaptiveThrottle                     // Slows the RPC workers from the DB commit latency, only set with base.adaptive-throttle-target-ms
+	InfluxSink                          *core.InfluxSink                           // Receives the per-block aggregates of the written blocks, only set with sink.url
+	ErrorWebhook                        *core.ErrorWebhook                         // Notified of failed blocks, only set with base.error-webhook
+	FailedBlocks                        *dbTypes.FailedBlockCounter                // Counts the failed blocks recorded during the run, a run with failed blocks writes no completion marker
+	AuditLog                            *AuditLog                                  // Records the rows of every committed block transaction, only set with base.audit-log-file
+	Phase                               *PhaseTracker                              // Back-fill or tailing phase reported in the logs and sink points, only set with base.report-phase
+	LeaderElector                       *dbTypes.LeaderElector                     // Holds the HA leader lock taken during setup, only set with base.ha-mode
+	spiller                             *blockSpiller                              // Records blocks dropped by the drop-to-disk backpressure policy
+	lastIndexedHeight                   indexedHeight                              // Highest block written by the DB worker, used for lag monitoring
+	runCounts                           runCounts                                  // Rows written by the DB worker, reported in the completion marker
+	eventProfiler                       *eventProfiler                             // Tallies the events of the received blocks instead of writing them, only set with base.profile-events
+	timedOut                            timedOutBlocks                             // Blocks that timed out to be enqueued again, only filled with base.block-timeout
+}
+
+// CodecVariant holds the module basics and message types used to decode blocks after a chain upgrade that changed message encodings.
+// The variant replaces the custom module basics and message types registered on the indexer, so it must include every custom type
+// still needed after the upgrade.
+type CodecVariant struct {
+	ModuleBasics          []module.AppModuleBasic
+	CustomMsgTypeRegistry map[string]sdkTypes.Msg
 }
 
 type BlockEventFilterRegistries struct {
@@ -74,8 +105,20 @@ type BlockEventFilterRegistries struct {
 type DBData struct {
 	txDBWrappers []dbTypes.TxDBWrapper
 	block        models.Block
+	budget       blockBudget
+	inflight     *core.InflightBlock
+	// Context carrying the block's decode span, the parent of the commit span
+	traceContext context.Context
+	// Written in replace mode, the block is part of the base.background-reindex range
+	backgroundReindex bool
 }
 
 type BlockEventsDBData struct {
 	blockDBWrapper *dbTypes.BlockDBWrapper
+	budget         blockBudget
+	inflight       *core.InflightBlock
+	// Context carrying the block's decode span, the parent of the commit span
+	traceContext context.Context
+	// The block is part of the base.background-reindex range
+	backgroundReindex bool
 }
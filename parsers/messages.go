@@ -26,3 +26,15 @@ type MessageParsedData struct {
 	Error  error
 	Parser *MessageParser
 }
+
+// An address referenced by a message, along with the role the address plays in the message (e.g. signer, recipient)
+type MessageAddress struct {
+	Address string
+	Role    string
+}
+
+// MessageAddressParser can optionally be implemented by a MessageParser to extract additional addresses from the messages it handles.
+// The addresses are stored in the message address index when base.index-addresses is enabled.
+type MessageAddressParser interface {
+	ParseMessageAddresses(sdkTypes.Msg, config.IndexConfig) ([]MessageAddress, error)
+}
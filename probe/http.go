@@ -0,0 +1,208 @@
+package probe
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// GetHTTPClient builds the HTTP client used for all outbound node traffic. Proxy settings from the Probe config take
+// precedence, with the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY env vars used for any that are not set.
+// If a CA file is configured, its certificates are trusted in addition to the system roots.
+// 429 responses with a Retry-After header pause requests to the endpoint and are retried once the pause is over, unless the
+// header asks for a longer wait than the configured max-retry-after.
+func GetHTTPClient(conf config.Probe, timeout time.Duration) (*http.Client, error) {
+	transport, err := getHTTPTransport(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if conf.MaxRetryAfter > 0 {
+		roundTripper = newRateLimitTransport(transport, time.Duration(conf.MaxRetryAfter)*time.Second)
+	}
+
+	return &http.Client{
+		Transport: roundTripper,
+		Timeout:   timeout,
+	}, nil
+}
+
+// Number of times a single request is retried after being rate limited before the 429 response is returned
+const rateLimitRetries = 3
+
+var rateLimitedResponses atomic.Int64
+
+// The time requests to each host may resume at. The pauses are shared by every client, since the indexer builds a separate
+// client for each of its node connections and a rate limited node has to be paused for all of them.
+var (
+	pausedUntilLock sync.Mutex
+	pausedUntil     = make(map[string]time.Time)
+)
+
+// RateLimitedResponses returns the number of 429 responses received from nodes since startup, counted apart from other failed
+// requests since they are not an error of the node
+func RateLimitedResponses() int64 {
+	return rateLimitedResponses.Load()
+}
+
+// rateLimitTransport honors the Retry-After header of 429 responses. The endpoint is paused for the requested duration, so
+// every request to it waits, from any client, instead of only the one that was rate limited.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	maxWait time.Duration
+}
+
+func newRateLimitTransport(next http.RoundTripper, maxWait time.Duration) *rateLimitTransport {
+	return &rateLimitTransport{
+		next:    next,
+		maxWait: maxWait,
+	}
+}
+
+func (transport *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := waitForHost(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := transport.next.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+		rateLimitedResponses.Add(1)
+
+		wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok || wait > transport.maxWait {
+			return resp, nil
+		}
+		pauseHost(req.URL.Host, wait)
+
+		// Requests with a body that cannot be rewound cannot be sent again
+		if attempt >= rateLimitRetries || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return resp, nil
+		}
+
+		retryReq := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if retryReq.Body, err = req.GetBody(); err != nil {
+				return resp, nil
+			}
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		config.Log.Warnf("Node %s rate limited the request, retrying in %s", req.URL.Host, wait)
+		req = retryReq
+	}
+}
+
+func pauseHost(host string, wait time.Duration) {
+	pausedUntilLock.Lock()
+	defer pausedUntilLock.Unlock()
+
+	if until := time.Now().Add(wait); until.After(pausedUntil[host]) {
+		pausedUntil[host] = until
+	}
+}
+
+func waitForHost(req *http.Request) error {
+	pausedUntilLock.Lock()
+	until := pausedUntil[req.URL.Host]
+	pausedUntilLock.Unlock()
+
+	wait := time.Until(until)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+// parseRetryAfter returns the wait requested by a Retry-After header, given either in seconds or as an HTTP date
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(header, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+func getHTTPTransport(conf config.Probe) (*http.Transport, error) {
+	proxyConf := httpproxy.FromEnvironment()
+	if conf.HTTPProxy != "" {
+		proxyConf.HTTPProxy = conf.HTTPProxy
+	}
+	if conf.HTTPSProxy != "" {
+		proxyConf.HTTPSProxy = conf.HTTPSProxy
+	}
+	if conf.NoProxy != "" {
+		proxyConf.NoProxy = conf.NoProxy
+	}
+	proxyFunc := proxyConf.ProxyFunc()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+	// Matches the CometBFT default client, prevents GZIP-bomb DoS attacks
+	transport.DisableCompression = true
+
+	if conf.CAFile != "" {
+		pem, err := os.ReadFile(conf.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read probe ca-file: %w", err)
+		}
+
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("probe ca-file does not contain any valid PEM encoded certificates")
+		}
+
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    rootCAs,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return transport, nil
+}
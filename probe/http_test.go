@@ -0,0 +1,182 @@
+package probe
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-indexer/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type HTTPClientTestSuite struct {
+	suite.Suite
+	proxy        *httptest.Server
+	proxiedHosts []string
+	mu           sync.Mutex
+}
+
+func (suite *HTTPClientTestSuite) SetupTest() {
+	suite.proxiedHosts = nil
+	suite.proxy = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.mu.Lock()
+		suite.proxiedHosts = append(suite.proxiedHosts, r.URL.Host)
+		suite.mu.Unlock()
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":-1,"result":{}}`))
+	}))
+}
+
+func (suite *HTTPClientTestSuite) TearDownTest() {
+	suite.proxy.Close()
+}
+
+func (suite *HTTPClientTestSuite) TestRequestsRouteThroughProxy() {
+	client, err := GetHTTPClient(config.Probe{HTTPProxy: suite.proxy.URL}, 0)
+	suite.Require().NoError(err)
+
+	resp, err := client.Get("http://rpc.node.invalid:26657/status")
+	suite.Require().NoError(err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	suite.Require().NoError(err)
+	suite.Contains(string(body), "jsonrpc")
+	suite.Equal([]string{"rpc.node.invalid:26657"}, suite.proxiedHosts)
+}
+
+func (suite *HTTPClientTestSuite) TestNoProxyBypassesProxy() {
+	client, err := GetHTTPClient(config.Probe{HTTPProxy: suite.proxy.URL, NoProxy: "rpc.node.invalid"}, 0)
+	suite.Require().NoError(err)
+
+	// The host does not resolve, so the request can only succeed if it went through the proxy
+	resp, err := client.Get("http://rpc.node.invalid:26657/status")
+	if resp != nil {
+		resp.Body.Close()
+	}
+	suite.Require().Error(err)
+	suite.Empty(suite.proxiedHosts)
+}
+
+func (suite *HTTPClientTestSuite) TestInvalidCAFile() {
+	_, err := GetHTTPClient(config.Probe{CAFile: "does-not-exist.pem"}, 0)
+	suite.Require().Error(err)
+}
+
+func (suite *HTTPClientTestSuite) TestRetryAfterPausesEndpoint() {
+	var requestTimes []time.Time
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.mu.Lock()
+		requestTimes = append(requestTimes, time.Now())
+		attempt := len(requestTimes)
+		suite.mu.Unlock()
+
+		if attempt == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":-1,"result":{}}`))
+	}))
+	defer node.Close()
+
+	client, err := GetHTTPClient(config.Probe{MaxRetryAfter: 5}, 0)
+	suite.Require().NoError(err)
+	rateLimited := RateLimitedResponses()
+
+	resp, err := client.Get(node.URL + "/status")
+	suite.Require().NoError(err)
+	resp.Body.Close()
+	suite.Equal(http.StatusOK, resp.StatusCode)
+
+	suite.Require().Len(requestTimes, 2)
+	suite.GreaterOrEqual(requestTimes[1].Sub(requestTimes[0]), time.Second)
+	suite.Equal(rateLimited+1, RateLimitedResponses())
+}
+
+func (suite *HTTPClientTestSuite) TestRetryAfterPausesEndpointForAllClients() {
+	var requestTimes []time.Time
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.mu.Lock()
+		requestTimes = append(requestTimes, time.Now())
+		attempt := len(requestTimes)
+		suite.mu.Unlock()
+
+		if attempt == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":-1,"result":{}}`))
+	}))
+	defer node.Close()
+
+	limitedClient, err := GetHTTPClient(config.Probe{MaxRetryAfter: 5}, 0)
+	suite.Require().NoError(err)
+	otherClient, err := GetHTTPClient(config.Probe{MaxRetryAfter: 5}, 0)
+	suite.Require().NoError(err)
+
+	limitedDone := make(chan struct{})
+	go func() {
+		defer close(limitedDone)
+		resp, err := limitedClient.Get(node.URL + "/status")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	// Wait for the first client to be rate limited, then the second client's request has to wait out the same pause
+	suite.Eventually(func() bool {
+		suite.mu.Lock()
+		defer suite.mu.Unlock()
+		return len(requestTimes) == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := otherClient.Get(node.URL + "/status")
+	suite.Require().NoError(err)
+	resp.Body.Close()
+	<-limitedDone
+
+	suite.Require().Len(requestTimes, 3)
+	for _, requestTime := range requestTimes[1:] {
+		suite.GreaterOrEqual(requestTime.Sub(requestTimes[0]), time.Second)
+	}
+}
+
+func (suite *HTTPClientTestSuite) TestRetryAfterAboveMaxReturnsResponse() {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer node.Close()
+
+	client, err := GetHTTPClient(config.Probe{MaxRetryAfter: 5}, 0)
+	suite.Require().NoError(err)
+
+	resp, err := client.Get(node.URL + "/status")
+	suite.Require().NoError(err)
+	resp.Body.Close()
+	suite.Equal(http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func (suite *HTTPClientTestSuite) TestParseRetryAfter() {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	wait, ok := parseRetryAfter("30", now)
+	suite.True(ok)
+	suite.Equal(30*time.Second, wait)
+
+	wait, ok = parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)
+	suite.True(ok)
+	suite.Equal(time.Minute, wait)
+
+	_, ok = parseRetryAfter("soon", now)
+	suite.False(ok)
+}
+
+func TestHTTPClientTestSuite(t *testing.T) {
+	suite.Run(t, new(HTTPClientTestSuite))
+}
@@ -1,14 +1,47 @@
 package probe
 
 import (
+	"time"
+
 	"github.com/DefiantLabs/cosmos-indexer/config"
 	probeClient "github.com/DefiantLabs/probe/client"
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
 	sdkTypes "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 )
 
 func GetProbeClient(conf config.Probe, appModuleBasicsExtensions []module.AppModuleBasic, customMsgTypeRegistry map[string]sdkTypes.Msg) (*probeClient.ChainClient, error) {
-	return probeClient.NewChainClient(GetProbeConfig(conf, true, appModuleBasicsExtensions, customMsgTypeRegistry), "", nil, nil)
+	probeConf := GetProbeConfig(conf, true, appModuleBasicsExtensions, customMsgTypeRegistry)
+	cl, err := probeClient.NewChainClient(probeConf, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Swap the default RPC client for one using our transport so proxy and CA settings are respected
+	timeout, err := time.ParseDuration(probeConf.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := GetHTTPClient(conf, timeout)
+	if err != nil {
+		return nil, err
+	}
+	rpcClient, err := rpchttp.NewWithClient(probeConf.RPCAddr, "/websocket", httpClient)
+	if err != nil {
+		return nil, err
+	}
+	cl.RPCClient = rpcClient
+
+	return cl, nil
+}
+
+// GetCodec builds a codec with the same default module basics as the probe client, extended by the given module basics and message types
+func GetCodec(appModuleBasicsExtensions []module.AppModuleBasic, customMsgTypeRegistry map[string]sdkTypes.Msg) (probeClient.Codec, error) {
+	moduleBasics := []module.AppModuleBasic{}
+	moduleBasics = append(moduleBasics, probeClient.DefaultModuleBasics...)
+	moduleBasics = append(moduleBasics, appModuleBasicsExtensions...)
+
+	return probeClient.MakeCodec(moduleBasics, customMsgTypeRegistry)
 }
 
 // Will include the protos provided by the Probe package for Osmosis module interfaces
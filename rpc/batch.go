@@ -0,0 +1,160 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	tmjson "github.com/cometbft/cometbft/libs/json"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	types "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// ErrBatchUnsupported is returned when the endpoint rejects JSON-RPC batch requests
+var ErrBatchUnsupported = errors.New("rpc endpoint does not support batch requests")
+
+// BlockBatch holds the blocks and block results fetched for several heights in a single batch request. Heights missing from the
+// batch, e.g. because the endpoint returned an error for them, are fetched with individual requests.
+type BlockBatch struct {
+	Blocks       map[int64]*coretypes.ResultBlock
+	BlockResults map[int64]*CustomBlockResults
+}
+
+// Block returns the batched block at the height, nil if it was not batched. A nil batch holds no blocks.
+func (batch *BlockBatch) Block(height int64) *coretypes.ResultBlock {
+	if batch == nil {
+		return nil
+	}
+	return batch.Blocks[height]
+}
+
+// BlockResult returns the batched block results at the height, nil if they were not batched. A nil batch holds no block results.
+func (batch *BlockBatch) BlockResult(height int64) *CustomBlockResults {
+	if batch == nil {
+		return nil
+	}
+	return batch.BlockResults[height]
+}
+
+type batchRequest struct {
+	height  int64
+	results bool
+}
+
+// GetBlockBatch fetches the blocks at the heights, and the block results of the heights in resultHeights, in a single JSON-RPC
+// batch request. ErrBatchUnsupported is returned if the endpoint answers with a JSON-RPC error saying batches are not supported,
+// or with a successful response that is not a batch response. Any other failure is returned as a regular error, so the blocks
+// can be fetched individually and batching is tried again for the next blocks.
+func GetBlockBatch(client URIClient, heights []int64, resultHeights []int64) (*BlockBatch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	requests := make([]batchRequest, 0, len(heights)+len(resultHeights))
+	rpcRequests := make([]types.RPCRequest, 0, len(heights)+len(resultHeights))
+	for _, height := range heights {
+		requests = append(requests, batchRequest{height: height})
+	}
+	for _, height := range resultHeights {
+		requests = append(requests, batchRequest{height: height, results: true})
+	}
+
+	for id, request := range requests {
+		method := "block"
+		if request.results {
+			method = "block_results"
+		}
+
+		rpcRequest, err := types.MapToRequest(types.JSONRPCIntID(id), method, map[string]interface{}{"height": strconv.FormatInt(request.height, 10)})
+		if err != nil {
+			return nil, err
+		}
+		rpcRequests = append(rpcRequests, rpcRequest)
+	}
+
+	body, err := json.Marshal(rpcRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.Address, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client.AuthHeader != "" {
+		req.Header.Add("Authorization", client.AuthHeader)
+	}
+
+	resp, err := client.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("post: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	if isBatchUnsupportedError(responseBytes) {
+		return nil, ErrBatchUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("batch request failed with status %d: %s", resp.StatusCode, responseBytes)
+	}
+
+	// A successful response that is not a list of responses means the endpoint answered the batch as a single request
+	trimmed := bytes.TrimSpace(responseBytes)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return nil, ErrBatchUnsupported
+	}
+
+	var responses []types.RPCResponse
+	if err := json.Unmarshal(trimmed, &responses); err != nil {
+		return nil, fmt.Errorf("error unmarshalling batch response: %w", err)
+	}
+
+	batch := &BlockBatch{
+		Blocks:       make(map[int64]*coretypes.ResultBlock),
+		BlockResults: make(map[int64]*CustomBlockResults),
+	}
+	for _, response := range responses {
+		id, ok := response.ID.(types.JSONRPCIntID)
+		if !ok || int(id) < 0 || int(id) >= len(requests) || response.Error != nil {
+			continue
+		}
+
+		request := requests[id]
+		if request.results {
+			result := new(CustomBlockResults)
+			if err := tmjson.Unmarshal(response.Result, result); err == nil {
+				batch.BlockResults[request.height] = result
+			}
+		} else {
+			result := new(coretypes.ResultBlock)
+			if err := tmjson.Unmarshal(response.Result, result); err == nil && result.Block != nil {
+				batch.Blocks[request.height] = result
+			}
+		}
+	}
+
+	return batch, nil
+}
+
+// isBatchUnsupportedError reports whether the response is a single JSON-RPC error rejecting the batch request
+func isBatchUnsupportedError(responseBytes []byte) bool {
+	var response types.RPCResponse
+	if err := json.Unmarshal(responseBytes, &response); err != nil || response.Error == nil {
+		return false
+	}
+
+	message := strings.ToLower(response.Error.Message + " " + response.Error.Data)
+	return strings.Contains(message, "batch")
+}
@@ -8,8 +8,11 @@ import (
 	"github.com/DefiantLabs/cosmos-indexer/config"
 	probeClient "github.com/DefiantLabs/probe/client"
 	probeQuery "github.com/DefiantLabs/probe/query"
+	probeStaking "github.com/DefiantLabs/probe/query/staking"
+	"github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/query"
 	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 )
 
 // GetBlockTimestamp
@@ -64,6 +67,32 @@ func IsCatchingUp(cl *probeClient.ChainClient) (bool, error) {
 	return resStatus.SyncInfo.CatchingUp, nil
 }
 
+// GetNodeChainID returns the chain ID (network) the node reports in its status
+func GetNodeChainID(cl *probeClient.ChainClient) (string, error) {
+	query := probeQuery.Query{Client: cl, Options: &probeQuery.QueryOptions{}}
+	ctx, cancel := query.GetQueryContext()
+	defer cancel()
+
+	resStatus, err := query.Client.RPCClient.Status(ctx)
+	if err != nil {
+		return "", err
+	}
+	return resStatus.NodeInfo.Network, nil
+}
+
+// GetNodeTxIndexEnabled reports whether the node indexes txs (tx_index in its status), tx search queries fail on nodes that do not
+func GetNodeTxIndexEnabled(cl *probeClient.ChainClient) (bool, error) {
+	query := probeQuery.Query{Client: cl, Options: &probeQuery.QueryOptions{}}
+	ctx, cancel := query.GetQueryContext()
+	defer cancel()
+
+	resStatus, err := query.Client.RPCClient.Status(ctx)
+	if err != nil {
+		return false, err
+	}
+	return resStatus.NodeInfo.Other.TxIndex == "on", nil
+}
+
 func GetLatestBlockHeight(cl *probeClient.ChainClient) (int64, error) {
 	query := probeQuery.Query{Client: cl, Options: &probeQuery.QueryOptions{}}
 	ctx, cancel := query.GetQueryContext()
@@ -127,3 +156,81 @@ func GetEarliestAndLatestBlockHeights(cl *probeClient.ChainClient) (int64, int64
 	}
 	return resStatus.SyncInfo.EarliestBlockHeight, resStatus.SyncInfo.LatestBlockHeight, nil
 }
+
+// GetUnconfirmedTxs returns up to limit of the txs in the node's mempool, the node caps the limit on its side too
+func GetUnconfirmedTxs(cl *probeClient.ChainClient, limit int) (*coretypes.ResultUnconfirmedTxs, error) {
+	query := probeQuery.Query{Client: cl, Options: &probeQuery.QueryOptions{}}
+	ctx, cancel := query.GetQueryContext()
+	defer cancel()
+
+	return query.Client.RPCClient.UnconfirmedTxs(ctx, &limit)
+}
+
+// GetValidatorOperatorAddresses returns the operator address of every validator in the staking module, keyed by consensus address
+func GetValidatorOperatorAddresses(cl *probeClient.ChainClient) (map[string]string, error) {
+	query := probeQuery.Query{Client: cl, Options: &probeQuery.QueryOptions{}}
+	operatorAddresses := make(map[string]string)
+
+	var paginationKey []byte
+	for {
+		resp, err := probeStaking.ValidatorsRPC(&query, nil, paginationKey)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, validator := range resp.Validators {
+			consAddress, err := validator.GetConsAddr()
+			if err != nil {
+				return nil, err
+			}
+			operatorAddresses[types.ConsAddress(consAddress).String()] = validator.OperatorAddress
+		}
+
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			return operatorAddresses, nil
+		}
+		paginationKey = resp.Pagination.NextKey
+	}
+}
+
+// GetAllBalances returns every coin held by the address as of the end of the block at the height
+func GetAllBalances(cl *probeClient.ChainClient, address string, height int64) (types.Coins, error) {
+	balanceQuery := probeQuery.Query{Client: cl, Options: &probeQuery.QueryOptions{Height: height}}
+	queryClient := bankTypes.NewQueryClient(balanceQuery.Client)
+	var balances types.Coins
+
+	var paginationKey []byte
+	for {
+		req := bankTypes.QueryAllBalancesRequest{Address: address}
+		if paginationKey != nil {
+			req.Pagination = &query.PageRequest{Key: paginationKey}
+		}
+
+		ctx, cancel := balanceQuery.GetQueryContext()
+		resp, err := queryClient.AllBalances(ctx, &req)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		balances = append(balances, resp.Balances...)
+
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			return balances, nil
+		}
+		paginationKey = resp.Pagination.NextKey
+	}
+}
+
+// GetBlockMaxGas returns the block max gas consensus param in effect at the height, -1 when block gas is unlimited
+func GetBlockMaxGas(cl *probeClient.ChainClient, height int64) (int64, error) {
+	query := probeQuery.Query{Client: cl, Options: &probeQuery.QueryOptions{}}
+	ctx, cancel := query.GetQueryContext()
+	defer cancel()
+
+	resParams, err := query.Client.RPCClient.ConsensusParams(ctx, &height)
+	if err != nil {
+		return 0, err
+	}
+	return resParams.ConsensusParams.Block.MaxGas, nil
+}